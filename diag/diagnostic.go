@@ -32,6 +32,27 @@ func (diags Diagnostics) HasError() bool {
 	return false
 }
 
+// Err returns the Error severity diagnostics joined into a single error, one
+// diagnostic per line, or nil if there are none. Each line is formatted as
+// the Summary followed by the Detail, when present. Warnings are omitted.
+//
+// This is intended for returning diagnostics through code that only handles
+// errors, as the AttributePath and Detail structure are lost.
+func (diags Diagnostics) Err() error {
+	var errs []error
+	for _, d := range diags {
+		if d.Severity != Error {
+			continue
+		}
+		if d.Detail == "" {
+			errs = append(errs, errors.New(d.Summary))
+			continue
+		}
+		errs = append(errs, fmt.Errorf("%s: %s", d.Summary, d.Detail))
+	}
+	return errors.Join(errs...)
+}
+
 // Diagnostic is a contextual message intended at outlining problems in user
 // configuration.
 //
@@ -76,6 +97,15 @@ type Diagnostic struct {
 	// developer, Terraform should render the root block (provider, resource,
 	// datasource) in cases where the attribute path is invalid.
 	AttributePath cty.Path
+
+	// DocURL is an optional link to documentation about the problem, such as
+	// how to fix it. As the protocol has no dedicated field, the SDK appends
+	// it to the Detail sent to Terraform. Use WithDocURL to set it.
+	DocURL string
+
+	// deferred is set by Deferred to signal that a data source read cannot
+	// be completed yet.
+	deferred bool
 }
 
 // Validate ensures a valid Severity and a non-empty Summary are set.
@@ -96,6 +126,11 @@ func (d Diagnostic) Validate() error {
 	return nil
 }
 
+// IsDeferred returns true if the Diagnostic was created by Deferred.
+func (d Diagnostic) IsDeferred() bool {
+	return d.Severity == Error && d.deferred
+}
+
 // Severity is an enum type marking the severity level of a Diagnostic
 type Severity int
 
@@ -24,6 +24,19 @@ func FromErr(err error) Diagnostics {
 	}
 }
 
+// FromErrors will convert multiple errors into a Diagnostics with an Error
+// level Diagnostic entry for each error. Nil errors are skipped, and nil is
+// returned if all errors are nil.
+//
+//	return diag.FromErrors(errA, errB)
+func FromErrors(errs ...error) Diagnostics {
+	var diags Diagnostics
+	for _, err := range errs {
+		diags = append(diags, FromErr(err)...)
+	}
+	return diags
+}
+
 // Errorf creates a Diagnostics with a single Error level Diagnostic entry.
 // The summary is populated by performing a fmt.Sprintf with the supplied
 // values. This returns a single error in a Diagnostics as errors typically
@@ -40,3 +53,83 @@ func Errorf(format string, a ...interface{}) Diagnostics {
 		},
 	}
 }
+
+// Tagged returns a copy of the diagnostics with the detail of each
+// diagnostic prefixed with the given source tag in square brackets. Use it
+// to keep track of the origin of diagnostics aggregated from several
+// sub-operations, such as calls to different APIs.
+//
+//	diags = append(diags, diag.Tagged("compute", computeDiags)...)
+func Tagged(source string, diags Diagnostics) Diagnostics {
+	if diags == nil {
+		return nil
+	}
+
+	tag := "[" + source + "]"
+	result := make(Diagnostics, len(diags))
+
+	for i, d := range diags {
+		if d.Detail == "" {
+			d.Detail = tag
+		} else {
+			d.Detail = tag + " " + d.Detail
+		}
+		result[i] = d
+	}
+
+	return result
+}
+
+// MergeTagged combines sets of diagnostics, usually returned by Tagged, into
+// a single Diagnostics, preserving the order of the sets and of the
+// diagnostics within them.
+//
+//	return diag.MergeTagged(
+//	  diag.Tagged("compute", computeDiags),
+//	  diag.Tagged("network", networkDiags),
+//	)
+func MergeTagged(sets ...Diagnostics) Diagnostics {
+	var result Diagnostics
+	for _, diags := range sets {
+		result = append(result, diags...)
+	}
+	return result
+}
+
+// WithDocURL returns a copy of the diagnostic with DocURL set to the given
+// link to documentation about the problem.
+//
+//	return diag.Diagnostics{
+//	  diag.WithDocURL(d, "https://example.com/docs/errors#quota"),
+//	}
+func WithDocURL(d Diagnostic, url string) Diagnostic {
+	d.DocURL = url
+	return d
+}
+
+// Deferred creates a Diagnostics with a single Error level Diagnostic entry
+// signaling that a data source read cannot be completed yet, such as when a
+// dependency does not exist or is not known, with the given reason as Detail.
+//
+//	if !dependencyReady {
+//	  return diag.Deferred("the network is not created yet")
+//	}
+//
+// When Terraform supports deferred actions, the SDK defers the read instead
+// of reporting the error, and Terraform reads the data source again in a
+// later plan. Otherwise the diagnostic is reported as an error, so the reason
+// should be written for practitioners.
+//
+// NOTE: This functionality is related to deferred action support, which is
+// currently experimental and is subject to change or break without warning.
+// It is not protected by version compatibility guarantees.
+func Deferred(reason string) Diagnostics {
+	return Diagnostics{
+		Diagnostic{
+			Severity: Error,
+			Summary:  "Read Deferred",
+			Detail:   reason,
+			deferred: true,
+		},
+	}
+}
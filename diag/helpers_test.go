@@ -0,0 +1,190 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package diag
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFromErrors(t *testing.T) {
+	errA := errors.New("error a")
+	errB := errors.New("error b")
+
+	cases := map[string]struct {
+		Errs     []error
+		Expected Diagnostics
+	}{
+		"none": {},
+		"nil": {
+			Errs: []error{nil, nil},
+		},
+		"multiple": {
+			Errs: []error{errA, nil, errB},
+			Expected: Diagnostics{
+				{Severity: Error, Summary: "error a"},
+				{Severity: Error, Summary: "error b"},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			actual := FromErrors(tc.Errs...)
+
+			if diff := cmp.Diff(tc.Expected, actual, cmp.AllowUnexported(Diagnostic{})); diff != "" {
+				t.Fatalf("unexpected difference: %s", diff)
+			}
+		})
+	}
+}
+
+func TestDiagnosticsErr(t *testing.T) {
+	cases := map[string]struct {
+		Diags    Diagnostics
+		Expected string
+	}{
+		"none": {},
+		"warnings only": {
+			Diags: Diagnostics{
+				{Severity: Warning, Summary: "warning"},
+			},
+		},
+		"summary and detail": {
+			Diags: Diagnostics{
+				{Severity: Error, Summary: "first", Detail: "first detail"},
+				{Severity: Warning, Summary: "warning"},
+				{Severity: Error, Summary: "second"},
+			},
+			Expected: "first: first detail\nsecond",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := tc.Diags.Err()
+
+			if tc.Expected == "" {
+				if err != nil {
+					t.Fatalf("expected no error, got: %s", err)
+				}
+				return
+			}
+
+			if err == nil {
+				t.Fatal("expected error, got none")
+			}
+
+			if diff := cmp.Diff(tc.Expected, err.Error()); diff != "" {
+				t.Fatalf("unexpected difference: %s", diff)
+			}
+		})
+	}
+}
+
+func TestTagged(t *testing.T) {
+	cases := map[string]struct {
+		Diags    Diagnostics
+		Expected Diagnostics
+	}{
+		"none": {},
+		"detail": {
+			Diags: Diagnostics{
+				{Severity: Error, Summary: "error", Detail: "error detail"},
+				{Severity: Warning, Summary: "warning"},
+			},
+			Expected: Diagnostics{
+				{Severity: Error, Summary: "error", Detail: "[compute] error detail"},
+				{Severity: Warning, Summary: "warning", Detail: "[compute]"},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var original Diagnostics
+			if tc.Diags != nil {
+				original = append(Diagnostics{}, tc.Diags...)
+			}
+
+			actual := Tagged("compute", tc.Diags)
+
+			if diff := cmp.Diff(tc.Expected, actual, cmp.AllowUnexported(Diagnostic{})); diff != "" {
+				t.Fatalf("unexpected difference: %s", diff)
+			}
+
+			if diff := cmp.Diff(original, tc.Diags, cmp.AllowUnexported(Diagnostic{})); diff != "" {
+				t.Fatalf("unexpected modification of the original diagnostics: %s", diff)
+			}
+		})
+	}
+}
+
+func TestMergeTagged(t *testing.T) {
+	actual := MergeTagged(
+		Tagged("compute", Diagnostics{
+			{Severity: Error, Summary: "first", Detail: "first detail"},
+			{Severity: Warning, Summary: "second"},
+		}),
+		nil,
+		Tagged("network", Diagnostics{
+			{Severity: Error, Summary: "third", Detail: "third detail"},
+		}),
+	)
+
+	expected := Diagnostics{
+		{Severity: Error, Summary: "first", Detail: "[compute] first detail"},
+		{Severity: Warning, Summary: "second", Detail: "[compute]"},
+		{Severity: Error, Summary: "third", Detail: "[network] third detail"},
+	}
+
+	if diff := cmp.Diff(expected, actual, cmp.AllowUnexported(Diagnostic{})); diff != "" {
+		t.Fatalf("unexpected difference: %s", diff)
+	}
+}
+
+func TestDeferred(t *testing.T) {
+	diags := Deferred("dependency is not created yet")
+
+	expected := Diagnostics{
+		{Severity: Error, Summary: "Read Deferred", Detail: "dependency is not created yet", deferred: true},
+	}
+
+	if diff := cmp.Diff(expected, diags, cmp.AllowUnexported(Diagnostic{})); diff != "" {
+		t.Fatalf("unexpected difference: %s", diff)
+	}
+
+	if !diags[0].IsDeferred() {
+		t.Fatal("expected diagnostic to be deferred")
+	}
+
+	notDeferred := Diagnostics{
+		{Severity: Error, Summary: "error"},
+		{Severity: Error, Summary: "Read Deferred", Detail: "same summary as Deferred"},
+	}
+
+	for _, d := range notDeferred {
+		if d.IsDeferred() {
+			t.Fatalf("expected diagnostic %q (%s) not to be deferred", d.Summary, d.Detail)
+		}
+	}
+}
+
+func TestWithDocURL(t *testing.T) {
+	d := Diagnostic{Severity: Error, Summary: "quota exceeded"}
+
+	actual := WithDocURL(d, "https://example.com/docs/quota")
+
+	expected := Diagnostic{Severity: Error, Summary: "quota exceeded", DocURL: "https://example.com/docs/quota"}
+
+	if diff := cmp.Diff(expected, actual, cmp.AllowUnexported(Diagnostic{})); diff != "" {
+		t.Fatalf("unexpected difference: %s", diff)
+	}
+
+	if d.DocURL != "" {
+		t.Fatalf("expected original diagnostic to be unchanged, got DocURL %q", d.DocURL)
+	}
+}
@@ -24,6 +24,7 @@ require (
 	github.com/mitchellh/reflectwalk v1.0.2
 	github.com/zclconf/go-cty v1.16.3
 	golang.org/x/crypto v0.38.0
+	google.golang.org/grpc v1.72.1
 )
 
 require (
@@ -56,6 +57,5 @@ require (
 	golang.org/x/tools v0.21.1-0.20240508182429-e35e4ccd0d2d // indirect
 	google.golang.org/appengine v1.6.8 // indirect
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20250218202821-56aae31c358a // indirect
-	google.golang.org/grpc v1.72.1 // indirect
 	google.golang.org/protobuf v1.36.6 // indirect
 )
@@ -52,6 +52,25 @@ func IfValueChange(key string, cond ValueChangeConditionFunc, f schema.Customize
 	}
 }
 
+// IfAnyValueChange returns a CustomizeDiffFunc that calls the given condition
+// function with the old and new values of each of the given keys in turn and
+// then calls the given CustomizeDiffFunc once if the condition function
+// returns true for any of them.
+//
+// The condition function is not called for the keys after the first one it
+// returns true for.
+func IfAnyValueChange(cond ValueChangeConditionFunc, f schema.CustomizeDiffFunc, keys ...string) schema.CustomizeDiffFunc {
+	return func(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+		for _, key := range keys {
+			oldValue, newValue := d.GetChange(key)
+			if cond(ctx, oldValue, newValue, meta) {
+				return f(ctx, d, meta)
+			}
+		}
+		return nil
+	}
+}
+
 // IfValue returns a CustomizeDiffFunc that calls the given condition
 // function with the new values of the given key and then calls the
 // given CustomizeDiffFunc only if the condition function returns true.
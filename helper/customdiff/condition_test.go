@@ -6,6 +6,7 @@ package customdiff
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -243,6 +244,121 @@ func TestIfValueChange(t *testing.T) {
 	})
 }
 
+func TestIfAnyValueChange(t *testing.T) {
+	t.Run("true", func(t *testing.T) {
+		var condKeys []string
+		var customCalled int
+
+		provider := testProvider(
+			map[string]*schema.Schema{
+				"foo": {
+					Type:     schema.TypeString,
+					Optional: true,
+				},
+				"bar": {
+					Type:     schema.TypeString,
+					Optional: true,
+				},
+				"baz": {
+					Type:     schema.TypeString,
+					Optional: true,
+				},
+			},
+			IfAnyValueChange(
+				func(_ context.Context, oldValue, newValue, meta interface{}) bool {
+					condKeys = append(condKeys, oldValue.(string))
+					return oldValue != newValue
+				},
+				func(_ context.Context, d *schema.ResourceDiff, meta interface{}) error {
+					customCalled++
+					return errors.New("bad")
+				},
+				"foo", "bar", "baz",
+			),
+		)
+
+		_, err := testDiff(
+			provider,
+			map[string]string{
+				"foo": "foo",
+				"bar": "bar",
+				"baz": "baz",
+			},
+			map[string]string{
+				"foo": "foo",
+				"bar": "bar2",
+				"baz": "baz2",
+			},
+		)
+
+		if err == nil {
+			t.Fatal("Diff succeeded; want error")
+		}
+		if got, want := err.Error(), "bad"; got != want {
+			t.Fatalf("wrong error message %q; want %q", got, want)
+		}
+
+		if got, want := strings.Join(condKeys, ","), "foo,bar"; got != want {
+			t.Errorf("condition callback called with old values %q; want %q", got, want)
+		}
+
+		if customCalled != 1 {
+			t.Errorf("customize callback was called %d times; want 1", customCalled)
+		}
+	})
+	t.Run("false", func(t *testing.T) {
+		var condCalled int
+		var customCalled bool
+
+		provider := testProvider(
+			map[string]*schema.Schema{
+				"foo": {
+					Type:     schema.TypeString,
+					Optional: true,
+				},
+				"bar": {
+					Type:     schema.TypeString,
+					Optional: true,
+				},
+			},
+			IfAnyValueChange(
+				func(_ context.Context, oldValue, newValue, meta interface{}) bool {
+					condCalled++
+					return oldValue != newValue
+				},
+				func(_ context.Context, d *schema.ResourceDiff, meta interface{}) error {
+					customCalled = true
+					return errors.New("bad")
+				},
+				"foo", "bar",
+			),
+		)
+
+		_, err := testDiff(
+			provider,
+			map[string]string{
+				"foo": "foo",
+				"bar": "bar",
+			},
+			map[string]string{
+				"foo": "foo",
+				"bar": "bar",
+			},
+		)
+
+		if err != nil {
+			t.Fatalf("Diff failed with error: %s", err)
+		}
+
+		if condCalled != 2 {
+			t.Errorf("condition callback was called %d times; want 2", condCalled)
+		}
+		if customCalled {
+			t.Error("customize callback was called (should not have been)")
+		}
+	})
+}
+
 func TestIfValue(t *testing.T) {
 	t.Run("true", func(t *testing.T) {
 		var condCalled, customCalled bool
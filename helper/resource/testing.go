@@ -294,6 +294,10 @@ type ImportStateCheckFunc func([]*terraform.InstanceState) error
 // generation for ImportState tests.
 type ImportStateIdFunc func(*terraform.State) (string, error)
 
+// ImportStateIdentityFunc is an identity generation function to help with
+// complex identity generation for ImportState tests importing by identity.
+type ImportStateIdentityFunc func(*terraform.State) (map[string]string, error)
+
 // ErrorCheckFunc is a function providers can use to handle errors.
 type ErrorCheckFunc func(error) error
 
@@ -550,6 +554,23 @@ type TestStep struct {
 	// desired format.
 	ImportStateIdFunc ImportStateIdFunc
 
+	// ImportStateIdentity is the resource identity to perform an ImportState
+	// operation with, instead of an ID. The resource is imported with an
+	// import block added to the configuration, which requires Terraform 1.12
+	// or later, and ResourceName must be set. ImportStateIdPrefix is ignored.
+	//
+	// The import is planned before it is applied, and the test fails unless
+	// the plan only imports the resource, without any other changes.
+	//
+	// Identity attribute values are given as strings, which Terraform
+	// converts to the types of the identity schema.
+	ImportStateIdentity map[string]string
+
+	// ImportStateIdentityFunc is a function that can be used to dynamically
+	// generate the identity for the ImportState tests, like
+	// ImportStateIdFunc. It takes precedence over ImportStateIdentity.
+	ImportStateIdentityFunc ImportStateIdentityFunc
+
 	// ImportStateCheck checks the results of ImportState. It should be
 	// used to verify that the resulting value of ImportState has the
 	// proper resources, IDs, and attributes.
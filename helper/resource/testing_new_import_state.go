@@ -10,7 +10,12 @@ import (
 	"strings"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	tfjson "github.com/hashicorp/terraform-json"
 	"github.com/mitchellh/go-testing-interface"
+	"github.com/zclconf/go-cty/cty"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/internal/logging"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/internal/plugintest"
@@ -38,9 +43,28 @@ func testStepNewImportState(ctx context.Context, t testing.T, helper *plugintest
 		t.Fatalf("Error getting state: %s", err)
 	}
 
-	// Determine the ID to import
+	// Determine the ID or identity to import
 	var importId string
+	var importIdentity map[string]string
 	switch {
+	case step.ImportStateIdentityFunc != nil:
+		logging.HelperResourceTrace(ctx, "Using TestStep ImportStateIdentityFunc for import identity")
+
+		var err error
+
+		logging.HelperResourceDebug(ctx, "Calling TestStep ImportStateIdentityFunc")
+
+		importIdentity, err = step.ImportStateIdentityFunc(state)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		logging.HelperResourceDebug(ctx, "Called TestStep ImportStateIdentityFunc")
+	case step.ImportStateIdentity != nil:
+		logging.HelperResourceTrace(ctx, "Using TestStep ImportStateIdentity for import identity")
+
+		importIdentity = step.ImportStateIdentity
 	case step.ImportStateIdFunc != nil:
 		logging.HelperResourceTrace(ctx, "Using TestStep ImportStateIdFunc for import identifier")
 
@@ -69,13 +93,15 @@ func testStepNewImportState(ctx context.Context, t testing.T, helper *plugintest
 		importId = resource.Primary.ID
 	}
 
-	if step.ImportStateIdPrefix != "" {
-		logging.HelperResourceTrace(ctx, "Prepending TestStep ImportStateIdPrefix for import identifier")
+	if importIdentity == nil {
+		if step.ImportStateIdPrefix != "" {
+			logging.HelperResourceTrace(ctx, "Prepending TestStep ImportStateIdPrefix for import identifier")
 
-		importId = step.ImportStateIdPrefix + importId
-	}
+			importId = step.ImportStateIdPrefix + importId
+		}
 
-	logging.HelperResourceTrace(ctx, fmt.Sprintf("Using import identifier: %s", importId))
+		logging.HelperResourceTrace(ctx, fmt.Sprintf("Using import identifier: %s", importId))
+	}
 
 	// Create working directory for import tests
 	if step.Config == "" {
@@ -97,7 +123,17 @@ func testStepNewImportState(ctx context.Context, t testing.T, helper *plugintest
 		defer importWd.Close()
 	}
 
-	err = importWd.SetConfig(ctx, step.Config)
+	importConfig := step.Config
+	if importIdentity != nil {
+		importBlock, err := importIdentityBlock(step.ResourceName, importIdentity)
+		if err != nil {
+			t.Fatalf("Error generating import block: %s", err)
+		}
+
+		importConfig += "\n" + importBlock
+	}
+
+	err = importWd.SetConfig(ctx, importConfig)
 	if err != nil {
 		t.Fatalf("Error setting test config: %s", err)
 	}
@@ -114,6 +150,23 @@ func testStepNewImportState(ctx context.Context, t testing.T, helper *plugintest
 	}
 
 	err = runProviderCommand(ctx, t, func() error {
+		if importIdentity != nil {
+			if err := importWd.CreateTargetPlan(ctx, step.ResourceName); err != nil {
+				return err
+			}
+
+			plan, err := importWd.SavedPlan(ctx)
+			if err != nil {
+				return err
+			}
+
+			if err := checkIdentityImportPlan(plan, step.ResourceName); err != nil {
+				return err
+			}
+
+			return importWd.Apply(ctx)
+		}
+
 		return importWd.Import(ctx, step.ResourceName, importId)
 	}, importWd, providers)
 	if err != nil {
@@ -279,3 +332,51 @@ func testStepNewImportState(ctx context.Context, t testing.T, helper *plugintest
 
 	return nil
 }
+
+// importIdentityBlock returns an import block importing the resource at the
+// given address with the given identity.
+func importIdentityBlock(address string, identity map[string]string) (string, error) {
+	traversal, diags := hclsyntax.ParseTraversalAbs([]byte(address), "", hcl.InitialPos)
+	if diags.HasErrors() {
+		return "", fmt.Errorf("invalid ResourceName %q: %s", address, diags.Error())
+	}
+
+	attrs := make(map[string]cty.Value, len(identity))
+	for k, v := range identity {
+		attrs[k] = cty.StringVal(v)
+	}
+
+	f := hclwrite.NewEmptyFile()
+	block := f.Body().AppendNewBlock("import", nil)
+	block.Body().SetAttributeTraversal("to", traversal)
+	block.Body().SetAttributeValue("identity", cty.ObjectVal(attrs))
+
+	return string(f.Bytes()), nil
+}
+
+// checkIdentityImportPlan returns an error unless the plan only imports the
+// resource at the given address, without any other changes, such as an
+// update caused by the configuration differing from the imported resource.
+func checkIdentityImportPlan(plan *tfjson.Plan, address string) error {
+	var imported bool
+
+	for _, rc := range plan.ResourceChanges {
+		if rc.Change == nil {
+			continue
+		}
+
+		if rc.Address == address && rc.Change.Importing != nil {
+			imported = true
+		}
+
+		if !rc.Change.Actions.NoOp() {
+			return fmt.Errorf("expected a no-op import of %s, but the plan has actions %v for %s", address, rc.Change.Actions, rc.Address)
+		}
+	}
+
+	if !imported {
+		return fmt.Errorf("expected the plan to import %s", address)
+	}
+
+	return nil
+}
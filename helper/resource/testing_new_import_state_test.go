@@ -9,6 +9,9 @@ import (
 	"regexp"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
+	tfjson "github.com/hashicorp/terraform-json"
+
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
@@ -219,3 +222,154 @@ func TestTest_TestStep_ExpectError_ImportState(t *testing.T) {
 		},
 	})
 }
+
+func TestImportIdentityBlock(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		address       string
+		identity      map[string]string
+		expected      string
+		expectedError *regexp.Regexp
+	}{
+		"resource": {
+			address: "examplecloud_thing.test",
+			identity: map[string]string{
+				"name":   "test",
+				"region": "us-east-1",
+			},
+			expected: `import {
+  to = examplecloud_thing.test
+  identity = {
+    name   = "test"
+    region = "us-east-1"
+  }
+}
+`,
+		},
+		"module-resource-instance": {
+			address: `module.child.examplecloud_thing.test["a"]`,
+			identity: map[string]string{
+				"name": "${test}",
+			},
+			expected: `import {
+  to = module.child.examplecloud_thing.test["a"]
+  identity = {
+    name = "$${test}"
+  }
+}
+`,
+		},
+		"invalid-address": {
+			address:       "examplecloud_thing.",
+			identity:      map[string]string{},
+			expectedError: regexp.MustCompile(`invalid ResourceName "examplecloud_thing."`),
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := importIdentityBlock(testCase.address, testCase.identity)
+
+			if err != nil {
+				if testCase.expectedError == nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+
+				if !testCase.expectedError.MatchString(err.Error()) {
+					t.Fatalf("expected error %q, got: %s", testCase.expectedError, err)
+				}
+
+				return
+			}
+
+			if testCase.expectedError != nil {
+				t.Fatalf("expected error %q, got none", testCase.expectedError)
+			}
+
+			if diff := cmp.Diff(testCase.expected, got); diff != "" {
+				t.Fatalf("unexpected difference: %s", diff)
+			}
+		})
+	}
+}
+
+func TestCheckIdentityImportPlan(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		plan          *tfjson.Plan
+		expectedError *regexp.Regexp
+	}{
+		"no-op-import": {
+			plan: &tfjson.Plan{
+				ResourceChanges: []*tfjson.ResourceChange{
+					{
+						Address: "examplecloud_thing.test",
+						Change: &tfjson.Change{
+							Actions:   tfjson.Actions{tfjson.ActionNoop},
+							Importing: &tfjson.Importing{},
+						},
+					},
+				},
+			},
+		},
+		"import-with-update": {
+			plan: &tfjson.Plan{
+				ResourceChanges: []*tfjson.ResourceChange{
+					{
+						Address: "examplecloud_thing.test",
+						Change: &tfjson.Change{
+							Actions:   tfjson.Actions{tfjson.ActionUpdate},
+							Importing: &tfjson.Importing{},
+						},
+					},
+				},
+			},
+			expectedError: regexp.MustCompile(`expected a no-op import of examplecloud_thing.test, but the plan has actions \[update\]`),
+		},
+		"no-import": {
+			plan: &tfjson.Plan{
+				ResourceChanges: []*tfjson.ResourceChange{
+					{
+						Address: "examplecloud_thing.test",
+						Change: &tfjson.Change{
+							Actions: tfjson.Actions{tfjson.ActionCreate},
+						},
+					},
+				},
+			},
+			expectedError: regexp.MustCompile(`expected a no-op import of examplecloud_thing.test, but the plan has actions \[create\]`),
+		},
+		"empty-plan": {
+			plan:          &tfjson.Plan{},
+			expectedError: regexp.MustCompile(`expected the plan to import examplecloud_thing.test`),
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := checkIdentityImportPlan(testCase.plan, "examplecloud_thing.test")
+
+			if err != nil {
+				if testCase.expectedError == nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+
+				if !testCase.expectedError.MatchString(err.Error()) {
+					t.Fatalf("expected error %q, got: %s", testCase.expectedError, err)
+				}
+
+				return
+			}
+
+			if testCase.expectedError != nil {
+				t.Fatalf("expected error %q, got none", testCase.expectedError)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package resource
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/go-cty/cty"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+// TestCheckResourceAttrPath ensures a specific value is stored in state for
+// the given name and attribute path combination. It is equivalent to
+// TestCheckResourceAttr, but the attribute is addressed with a cty.Path
+// instead of a "flatmap" key, which is less brittle for attributes nested
+// under list blocks or map attributes:
+//
+//	resource.TestCheckResourceAttrPath(
+//	  "myprovider_thing.example",
+//	  cty.GetAttrPath("rule").IndexInt(0).GetAttr("action"),
+//	  "allow",
+//	)
+//
+// Paths support attribute steps, list index steps with whole number keys and
+// map index steps with string keys. Set elements cannot be addressed by
+// path, use the TestCheckTypeSet* functions for sets instead.
+//
+// See TestCheckResourceAttr for the name and value parameters.
+func TestCheckResourceAttrPath(name string, path cty.Path, value string) TestCheckFunc {
+	return func(s *terraform.State) error {
+		key, err := flatmapKeyFromPath(path)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+
+		return TestCheckResourceAttr(name, key, value)(s)
+	}
+}
+
+// TestCheckResourceAttrPathSet ensures any value exists in the state for the
+// given name and attribute path combination. It is equivalent to
+// TestCheckResourceAttrSet, but the attribute is addressed with a cty.Path
+// instead of a "flatmap" key. See TestCheckResourceAttrPath for the
+// supported path steps.
+func TestCheckResourceAttrPathSet(name string, path cty.Path) TestCheckFunc {
+	return func(s *terraform.State) error {
+		key, err := flatmapKeyFromPath(path)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+
+		return TestCheckResourceAttrSet(name, key)(s)
+	}
+}
+
+// flatmapKeyFromPath returns the "flatmap" state key of the attribute at the
+// given path.
+func flatmapKeyFromPath(path cty.Path) (string, error) {
+	if len(path) == 0 {
+		return "", fmt.Errorf("attribute path must not be empty")
+	}
+
+	if _, ok := path[0].(cty.GetAttrStep); !ok {
+		return "", fmt.Errorf("attribute path must start with an attribute name")
+	}
+
+	parts := make([]string, 0, len(path))
+
+	for _, step := range path {
+		switch step := step.(type) {
+		case cty.GetAttrStep:
+			parts = append(parts, step.Name)
+		case cty.IndexStep:
+			switch {
+			case step.Key.IsNull() || !step.Key.IsKnown():
+				return "", fmt.Errorf("attribute path index must be known and not null")
+			case step.Key.Type() == cty.String:
+				parts = append(parts, step.Key.AsString())
+			case step.Key.Type() == cty.Number:
+				i, accuracy := step.Key.AsBigFloat().Int64()
+				if accuracy != 0 || i < 0 {
+					return "", fmt.Errorf("attribute path list index must be a non-negative whole number, got %s", step.Key.AsBigFloat().String())
+				}
+				parts = append(parts, fmt.Sprintf("%d", i))
+			default:
+				return "", fmt.Errorf("attribute path cannot address set elements, use the TestCheckTypeSet functions instead")
+			}
+		default:
+			return "", fmt.Errorf("unsupported attribute path step %T", step)
+		}
+	}
+
+	return strings.Join(parts, "."), nil
+}
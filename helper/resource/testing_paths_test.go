@@ -0,0 +1,164 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package resource
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/go-cty/cty"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func testPathState() *terraform.State {
+	return &terraform.State{
+		IsBinaryDrivenTest: true, // Always true now
+		Modules: []*terraform.ModuleState{
+			{
+				Path: []string{"root"},
+				Resources: map[string]*terraform.ResourceState{
+					"test_resource": {
+						Primary: &terraform.InstanceState{
+							Attributes: map[string]string{
+								"name":                "example",
+								"rule.#":              "2",
+								"rule.0.action":       "allow",
+								"rule.1.action":       "deny",
+								"rule.1.match.#":      "1",
+								"rule.1.match.0.path": "/",
+								"tags.%":              "1",
+								"tags.environment":    "test",
+								"empty":               "",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestTestCheckResourceAttrPath(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		path          cty.Path
+		value         string
+		expectedError error
+	}{
+		"top level attribute match": {
+			path:  cty.GetAttrPath("name"),
+			value: "example",
+		},
+		"top level attribute mismatch": {
+			path:          cty.GetAttrPath("name"),
+			value:         "other",
+			expectedError: fmt.Errorf("Attribute 'name' expected \"other\", got \"example\""),
+		},
+		"nested block attribute match": {
+			path:  cty.GetAttrPath("rule").IndexInt(1).GetAttr("match").IndexInt(0).GetAttr("path"),
+			value: "/",
+		},
+		"nested block attribute not found": {
+			path:          cty.GetAttrPath("rule").IndexInt(2).GetAttr("action"),
+			value:         "allow",
+			expectedError: fmt.Errorf("Attribute 'rule.2.action' not found"),
+		},
+		"map key match": {
+			path:  cty.GetAttrPath("tags").IndexString("environment"),
+			value: "test",
+		},
+		"empty path": {
+			path:          cty.Path{},
+			value:         "example",
+			expectedError: fmt.Errorf("attribute path must not be empty"),
+		},
+		"path starting with index": {
+			path:          cty.IndexIntPath(0),
+			value:         "example",
+			expectedError: fmt.Errorf("attribute path must start with an attribute name"),
+		},
+		"negative index": {
+			path:          cty.GetAttrPath("rule").IndexInt(-1).GetAttr("action"),
+			value:         "allow",
+			expectedError: fmt.Errorf("attribute path list index must be a non-negative whole number, got -1"),
+		},
+		"set element": {
+			path:          cty.GetAttrPath("rule").Index(cty.ObjectVal(map[string]cty.Value{"action": cty.StringVal("allow")})),
+			value:         "allow",
+			expectedError: fmt.Errorf("attribute path cannot address set elements"),
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := TestCheckResourceAttrPath("test_resource", testCase.path, testCase.value)(testPathState())
+
+			if err != nil {
+				if testCase.expectedError == nil {
+					t.Fatalf("expected no error, got: %s", err)
+				}
+
+				if !strings.Contains(err.Error(), testCase.expectedError.Error()) {
+					t.Fatalf("expected error %q, got: %s", testCase.expectedError, err)
+				}
+			}
+
+			if err == nil && testCase.expectedError != nil {
+				t.Fatalf("expected error: %s", testCase.expectedError)
+			}
+		})
+	}
+}
+
+func TestTestCheckResourceAttrPathSet(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		path          cty.Path
+		expectedError error
+	}{
+		"nested block attribute set": {
+			path: cty.GetAttrPath("rule").IndexInt(0).GetAttr("action"),
+		},
+		"empty value": {
+			path:          cty.GetAttrPath("empty"),
+			expectedError: fmt.Errorf("Attribute 'empty' expected to be set"),
+		},
+		"nested block attribute not found": {
+			path:          cty.GetAttrPath("rule").IndexInt(0).GetAttr("match").IndexInt(0).GetAttr("path"),
+			expectedError: fmt.Errorf("Attribute 'rule.0.match.0.path' expected to be set"),
+		},
+		"list attribute": {
+			path:          cty.GetAttrPath("rule"),
+			expectedError: fmt.Errorf("list or set attribute 'rule' must be checked by element count key"),
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := TestCheckResourceAttrPathSet("test_resource", testCase.path)(testPathState())
+
+			if err != nil {
+				if testCase.expectedError == nil {
+					t.Fatalf("expected no error, got: %s", err)
+				}
+
+				if !strings.Contains(err.Error(), testCase.expectedError.Error()) {
+					t.Fatalf("expected error %q, got: %s", testCase.expectedError, err)
+				}
+			}
+
+			if err == nil && testCase.expectedError != nil {
+				t.Fatalf("expected error: %s", testCase.expectedError)
+			}
+		})
+	}
+}
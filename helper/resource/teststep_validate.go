@@ -59,6 +59,9 @@ func (s TestStep) hasProviders(_ context.Context) bool {
 //   - No overlapping ExternalProviders and ProviderFactories entries
 //   - ResourceName is not empty when ImportState is true, ImportStateIdFunc
 //     is not set, and ImportStateId is not set.
+//   - ImportStateIdentity and ImportStateIdentityFunc are only set with
+//     ImportState, and without ImportStateId and ImportStateIdFunc, so
+//     ResourceName is not empty.
 func (s TestStep) validate(ctx context.Context, req testStepValidateRequest) error {
 	ctx = logging.TestStepNumberContext(ctx, req.StepNumber)
 
@@ -124,5 +127,19 @@ func (s TestStep) validate(ctx context.Context, req testStepValidateRequest) err
 		}
 	}
 
+	if s.ImportStateIdentity != nil || s.ImportStateIdentityFunc != nil {
+		if !s.ImportState {
+			err := fmt.Errorf("TestStep ImportStateIdentity and ImportStateIdentityFunc require ImportState")
+			logging.HelperResourceError(ctx, "TestStep validation error", map[string]interface{}{logging.KeyError: err})
+			return err
+		}
+
+		if s.ImportStateId != "" || s.ImportStateIdFunc != nil {
+			err := fmt.Errorf("TestStep cannot have ImportStateIdentity or ImportStateIdentityFunc with ImportStateId or ImportStateIdFunc")
+			logging.HelperResourceError(ctx, "TestStep validation error", map[string]interface{}{logging.KeyError: err})
+			return err
+		}
+	}
+
 	return nil
 }
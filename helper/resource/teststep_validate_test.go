@@ -13,6 +13,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
 
 func TestTestStepHasProviders(t *testing.T) {
@@ -152,6 +153,57 @@ func TestTestStepValidate(t *testing.T) {
 			},
 			expectedError: fmt.Errorf("TestStep ImportState must be specified with ImportStateId, ImportStateIdFunc, or ResourceName"),
 		},
+		"importstateidentity-without-importstate": {
+			testStep: TestStep{
+				Config:       "# not empty",
+				ResourceName: "test_resource.test",
+				ImportStateIdentity: map[string]string{
+					"id": "test",
+				},
+			},
+			testStepValidateRequest: testStepValidateRequest{
+				TestCaseHasProviders: true,
+			},
+			expectedError: fmt.Errorf("TestStep ImportStateIdentity and ImportStateIdentityFunc require ImportState"),
+		},
+		"importstateidentity-and-importstateid": {
+			testStep: TestStep{
+				ImportState:   true,
+				ImportStateId: "test",
+				ResourceName:  "test_resource.test",
+				ImportStateIdentity: map[string]string{
+					"id": "test",
+				},
+			},
+			testStepValidateRequest: testStepValidateRequest{
+				TestCaseHasProviders: true,
+			},
+			expectedError: fmt.Errorf("TestStep cannot have ImportStateIdentity or ImportStateIdentityFunc with ImportStateId or ImportStateIdFunc"),
+		},
+		"importstateidentityfunc-missing-resourcename": {
+			testStep: TestStep{
+				ImportState: true,
+				ImportStateIdentityFunc: func(*terraform.State) (map[string]string, error) {
+					return nil, nil
+				},
+			},
+			testStepValidateRequest: testStepValidateRequest{
+				TestCaseHasProviders: true,
+			},
+			expectedError: fmt.Errorf("TestStep ImportState must be specified with ImportStateId, ImportStateIdFunc, or ResourceName"),
+		},
+		"importstateidentity": {
+			testStep: TestStep{
+				ImportState:  true,
+				ResourceName: "test_resource.test",
+				ImportStateIdentity: map[string]string{
+					"id": "test",
+				},
+			},
+			testStepValidateRequest: testStepValidateRequest{
+				TestCaseHasProviders: true,
+			},
+		},
 		"protov5providerfactories-testcase-providers": {
 			testStep: TestStep{
 				Config: "# not empty",
@@ -6,6 +6,7 @@ package retry
 import (
 	"context"
 	"log"
+	"sync"
 	"time"
 )
 
@@ -36,6 +37,67 @@ type StateChangeConf struct {
 
 	// This is to work around inconsistent APIs
 	ContinuousTargetOccurence int // Number of times the Target state has to occur continuously
+
+	// OnPoll is an optional callback invoked after each call to Refresh with
+	// the number of polls so far, starting at 1, the refreshed state and the
+	// time elapsed since the wait started. It can be used to emit progress
+	// logs or metrics during long-running waits and does not affect the
+	// result of the wait. It is not called for a refresh which completes
+	// after the wait has returned, such as on timeout or cancellation.
+	OnPoll func(pollCount int, state string, elapsed time.Duration)
+
+	// Backoff is an optional function returning the time to wait before the
+	// next refresh, given the number of refreshes so far, starting at 1, and
+	// the previous wait, which is zero before the second refresh. When set,
+	// it replaces the default exponential backoff and the MinTimeout and
+	// PollInterval fields. See ExponentialBackoff.
+	Backoff func(attempt int, prev time.Duration) time.Duration
+}
+
+// ExponentialBackoff returns a StateChangeConf Backoff function which waits
+// initial before the second refresh and doubles the wait after each
+// refresh, up to max.
+func ExponentialBackoff(initial, max time.Duration) func(attempt int, prev time.Duration) time.Duration {
+	return func(attempt int, prev time.Duration) time.Duration {
+		wait := initial
+		for i := 1; i < attempt && wait < max; i++ {
+			wait *= 2
+		}
+
+		if wait > max {
+			wait = max
+		}
+
+		return wait
+	}
+}
+
+// nextWait returns the time to wait before the next refresh, given the
+// number of refreshes so far and the previous wait.
+func (conf *StateChangeConf) nextWait(pollCount int, wait time.Duration, targetOccurence int) time.Duration {
+	if conf.Backoff != nil {
+		return conf.Backoff(pollCount, wait)
+	}
+
+	// Wait between refreshes using exponential backoff, except when
+	// waiting for the target state to reoccur.
+	if targetOccurence == 0 {
+		wait *= 2
+	}
+
+	// If a poll interval has been specified, choose that interval.
+	// Otherwise bound the default value.
+	if conf.PollInterval > 0 && conf.PollInterval < 180*time.Second {
+		wait = conf.PollInterval
+	} else {
+		if wait < conf.MinTimeout {
+			wait = conf.MinTimeout
+		} else if wait > 10*time.Second {
+			wait = 10 * time.Second
+		}
+	}
+
+	return wait
 }
 
 // WaitForStateContext watches an object and waits for it to achieve the state
@@ -57,6 +119,8 @@ type StateChangeConf struct {
 func (conf *StateChangeConf) WaitForStateContext(ctx context.Context) (interface{}, error) {
 	log.Printf("[DEBUG] Waiting for state to become: %s", conf.Target)
 
+	start := time.Now()
+	pollCount := 0
 	notfoundTick := 0
 	targetOccurence := 0
 
@@ -83,6 +147,18 @@ func (conf *StateChangeConf) WaitForStateContext(ctx context.Context) (interface
 
 	result := Result{}
 
+	// The refresh loop may still be running after a timeout or cancellation,
+	// so OnPoll is only called while holding onPollMu and before returned is
+	// set by the deferred function below.
+	var onPollMu sync.Mutex
+	returned := false
+
+	defer func() {
+		onPollMu.Lock()
+		returned = true
+		onPollMu.Unlock()
+	}()
+
 	go func() {
 		defer close(resCh)
 
@@ -105,12 +181,22 @@ func (conf *StateChangeConf) WaitForStateContext(ctx context.Context) (interface
 				return
 			case <-time.After(wait):
 				// first round had no wait
-				if wait == 0 {
+				if wait == 0 && conf.Backoff == nil {
 					wait = 100 * time.Millisecond
 				}
 			}
 
 			res, currentState, err := conf.Refresh()
+
+			pollCount++
+			if conf.OnPoll != nil {
+				onPollMu.Lock()
+				if !returned {
+					conf.OnPoll(pollCount, currentState, time.Since(start))
+				}
+				onPollMu.Unlock()
+			}
+
 			result = Result{
 				Result: res,
 				State:  currentState,
@@ -182,23 +268,7 @@ func (conf *StateChangeConf) WaitForStateContext(ctx context.Context) (interface
 				}
 			}
 
-			// Wait between refreshes using exponential backoff, except when
-			// waiting for the target state to reoccur.
-			if targetOccurence == 0 {
-				wait *= 2
-			}
-
-			// If a poll interval has been specified, choose that interval.
-			// Otherwise bound the default value.
-			if conf.PollInterval > 0 && conf.PollInterval < 180*time.Second {
-				wait = conf.PollInterval
-			} else {
-				if wait < conf.MinTimeout {
-					wait = conf.MinTimeout
-				} else if wait > 10*time.Second {
-					wait = 10 * time.Second
-				}
-			}
+			wait = conf.nextWait(pollCount, wait, targetOccurence)
 
 			log.Printf("[TRACE] Waiting %s before next try", wait)
 		}
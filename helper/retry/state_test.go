@@ -6,7 +6,9 @@ package retry
 import (
 	"context"
 	"errors"
+	"reflect"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -374,3 +376,219 @@ func TestWaitForStateContext_cancel(t *testing.T) {
 		t.Fatalf("Expected canceled context error, got: %s", err)
 	}
 }
+
+func TestWaitForState_onPoll(t *testing.T) {
+	var counts []int
+	var states []string
+	var elapsed []time.Duration
+
+	conf := &StateChangeConf{
+		Pending:                   []string{"replicating"},
+		Target:                    []string{"done"},
+		Refresh:                   InconsistentStateRefreshFunc(),
+		Timeout:                   90 * time.Millisecond,
+		PollInterval:              10 * time.Millisecond,
+		ContinuousTargetOccurence: 3,
+		OnPoll: func(pollCount int, state string, d time.Duration) {
+			counts = append(counts, pollCount)
+			states = append(states, state)
+			elapsed = append(elapsed, d)
+		},
+	}
+
+	idx, err := conf.WaitForState()
+
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// the wait result must not be affected by the callback
+	if idx != 4 {
+		t.Fatalf("Expected index 4, given %d", idx.(int))
+	}
+
+	expectedStates := []string{"done", "replicating", "done", "done", "done"}
+	if len(states) != len(expectedStates) {
+		t.Fatalf("Expected %d polls, got %d: %v", len(expectedStates), len(states), states)
+	}
+
+	for i := range expectedStates {
+		if counts[i] != i+1 {
+			t.Fatalf("Expected poll count %d, got %d", i+1, counts[i])
+		}
+
+		if states[i] != expectedStates[i] {
+			t.Fatalf("Expected state %q at poll %d, got %q", expectedStates[i], i+1, states[i])
+		}
+
+		if i > 0 && elapsed[i] < elapsed[i-1] {
+			t.Fatalf("Expected increasing elapsed time, got %s after %s", elapsed[i], elapsed[i-1])
+		}
+	}
+}
+
+func TestWaitForState_onPollAfterTimeout(t *testing.T) {
+	old := refreshGracePeriod
+	refreshGracePeriod = 5 * time.Millisecond
+	defer func() {
+		refreshGracePeriod = old
+	}()
+
+	// make this refresh func block until the wait has returned
+	unblock := make(chan struct{})
+	refreshed := make(chan struct{})
+	var refreshOnce sync.Once
+
+	var polls int32
+
+	conf := &StateChangeConf{
+		Pending: []string{"pending"},
+		Target:  []string{"running"},
+		Refresh: func() (interface{}, string, error) {
+			defer refreshOnce.Do(func() { close(refreshed) })
+			<-unblock
+			return nil, "pending", nil
+		},
+		Timeout: 1 * time.Millisecond,
+		OnPoll: func(int, string, time.Duration) {
+			atomic.AddInt32(&polls, 1)
+		},
+	}
+
+	_, err := conf.WaitForState()
+
+	var timeoutErr *TimeoutError
+	if !errors.As(err, &timeoutErr) {
+		t.Fatalf("Expected timeout error, got: %v", err)
+	}
+
+	close(unblock)
+	<-refreshed
+
+	// give the refresh loop time to call OnPoll
+	time.Sleep(50 * time.Millisecond)
+
+	if n := atomic.LoadInt32(&polls); n != 0 {
+		t.Fatalf("Expected no OnPoll calls after the wait returned, got %d", n)
+	}
+}
+
+func TestWaitForState_backoff(t *testing.T) {
+	var attempts []int
+	var prevs []time.Duration
+
+	conf := &StateChangeConf{
+		Pending:                   []string{"replicating"},
+		Target:                    []string{"done"},
+		Refresh:                   InconsistentStateRefreshFunc(),
+		Timeout:                   5 * time.Second,
+		PollInterval:              time.Hour,
+		ContinuousTargetOccurence: 3,
+		Backoff: func(attempt int, prev time.Duration) time.Duration {
+			attempts = append(attempts, attempt)
+			prevs = append(prevs, prev)
+			return time.Duration(attempt) * time.Millisecond
+		},
+	}
+
+	idx, err := conf.WaitForState()
+
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if idx != 4 {
+		t.Fatalf("Expected index 4, given %d", idx.(int))
+	}
+
+	// the last refresh reaches the target state without waiting again
+	expectedAttempts := []int{1, 2, 3, 4}
+	expectedPrevs := []time.Duration{0, time.Millisecond, 2 * time.Millisecond, 3 * time.Millisecond}
+
+	if !reflect.DeepEqual(expectedAttempts, attempts) {
+		t.Fatalf("Expected attempts %v, got %v", expectedAttempts, attempts)
+	}
+
+	if !reflect.DeepEqual(expectedPrevs, prevs) {
+		t.Fatalf("Expected previous waits %v, got %v", expectedPrevs, prevs)
+	}
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	backoff := ExponentialBackoff(100*time.Millisecond, time.Second)
+
+	var intervals []time.Duration
+	var prev time.Duration
+	for attempt := 1; attempt <= 6; attempt++ {
+		prev = backoff(attempt, prev)
+		intervals = append(intervals, prev)
+	}
+
+	expected := []time.Duration{
+		100 * time.Millisecond,
+		200 * time.Millisecond,
+		400 * time.Millisecond,
+		800 * time.Millisecond,
+		time.Second,
+		time.Second,
+	}
+
+	if !reflect.DeepEqual(expected, intervals) {
+		t.Fatalf("Expected intervals %v, got %v", expected, intervals)
+	}
+}
+
+func TestStateChangeConfNextWait(t *testing.T) {
+	cases := map[string]struct {
+		Conf            *StateChangeConf
+		Wait            time.Duration
+		TargetOccurence int
+		Expected        time.Duration
+	}{
+		"default": {
+			Conf:     &StateChangeConf{},
+			Wait:     100 * time.Millisecond,
+			Expected: 200 * time.Millisecond,
+		},
+		"default maximum": {
+			Conf:     &StateChangeConf{},
+			Wait:     8 * time.Second,
+			Expected: 10 * time.Second,
+		},
+		"min timeout": {
+			Conf:     &StateChangeConf{MinTimeout: time.Second},
+			Wait:     100 * time.Millisecond,
+			Expected: time.Second,
+		},
+		"target reoccurring": {
+			Conf:            &StateChangeConf{},
+			Wait:            100 * time.Millisecond,
+			TargetOccurence: 1,
+			Expected:        100 * time.Millisecond,
+		},
+		"poll interval": {
+			Conf:     &StateChangeConf{PollInterval: 5 * time.Second},
+			Wait:     100 * time.Millisecond,
+			Expected: 5 * time.Second,
+		},
+		"backoff": {
+			Conf: &StateChangeConf{
+				MinTimeout:   time.Second,
+				PollInterval: 5 * time.Second,
+				Backoff:      ExponentialBackoff(time.Second, time.Minute),
+			},
+			Wait:     8 * time.Second,
+			Expected: 16 * time.Second,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			actual := tc.Conf.nextWait(5, tc.Wait, tc.TargetOccurence)
+
+			if actual != tc.Expected {
+				t.Fatalf("Expected %s, got %s", tc.Expected, actual)
+			}
+		})
+	}
+}
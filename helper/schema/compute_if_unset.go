@@ -0,0 +1,158 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package schema
+
+import (
+	"sort"
+
+	"github.com/hashicorp/go-cty/cty"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+// computeIfUnsetMetaKey is the key in the resource instance private state
+// listing the ComputeIfUnset attributes whose value was taken from the
+// configuration during the last apply.
+const computeIfUnsetMetaKey = "compute_if_unset_configured"
+
+// computeIfUnsetConfigured returns whether the value of the given
+// ComputeIfUnset attribute was taken from the configuration, as recorded in
+// the private state.
+func computeIfUnsetConfigured(meta map[string]interface{}, k string) bool {
+	switch keys := meta[computeIfUnsetMetaKey].(type) {
+	case []interface{}:
+		for _, v := range keys {
+			if v == k {
+				return true
+			}
+		}
+	case []string:
+		for _, v := range keys {
+			if v == k {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// diffComputeIfUnset marks ComputeIfUnset attributes as computed when they
+// were previously configured but are no longer, so the value is computed
+// again instead of keeping the previously configured value.
+//
+// The raw configuration is used, as the configuration passed to Diff during
+// plan is the proposed new state, which keeps the prior value of optional
+// and computed attributes removed from the configuration. During apply the
+// private state is not available, so attributes planned as unknown are
+// marked as computed again instead.
+func (m schemaMapWithIdentity) diffComputeIfUnset(s *terraform.InstanceState, result *terraform.InstanceDiff) {
+	if s == nil || s.ID == "" {
+		return
+	}
+
+	config := s.RawConfig
+	if config.IsNull() || !config.IsKnown() || !config.Type().IsObjectType() {
+		return
+	}
+
+	for k, schema := range m.schemaMap {
+		if !schema.ComputeIfUnset || !config.Type().HasAttribute(k) || !config.GetAttr(k).IsNull() {
+			continue
+		}
+
+		if !computeIfUnsetConfigured(s.Meta, k) && !computeIfUnsetPlannedUnknown(s.RawPlan, k) {
+			continue
+		}
+
+		result.Attributes[k] = &terraform.ResourceAttrDiff{
+			Old:         s.Attributes[k],
+			NewComputed: true,
+		}
+	}
+}
+
+// computeIfUnsetPlannedUnknown returns whether the given attribute is unknown
+// in the planned state.
+func computeIfUnsetPlannedUnknown(plan cty.Value, k string) bool {
+	if plan.IsNull() || !plan.IsKnown() || !plan.Type().IsObjectType() || !plan.Type().HasAttribute(k) {
+		return false
+	}
+
+	return !plan.GetAttr(k).IsKnown()
+}
+
+// recordComputeIfUnset records the ComputeIfUnset attributes which are set in
+// the configuration of the applied diff in the private state.
+func (r *Resource) recordComputeIfUnset(state *terraform.InstanceState, d *terraform.InstanceDiff) *terraform.InstanceState {
+	if state == nil || d == nil {
+		return state
+	}
+
+	config := d.RawConfig
+	if config.IsNull() || !config.IsKnown() || !config.Type().IsObjectType() {
+		return state
+	}
+
+	var keys []string
+	for k, v := range r.SchemaMap() {
+		if !v.ComputeIfUnset || !config.Type().HasAttribute(k) {
+			continue
+		}
+
+		if config.GetAttr(k).IsNull() {
+			continue
+		}
+
+		keys = append(keys, k)
+	}
+
+	if len(keys) == 0 {
+		return state
+	}
+
+	sort.Strings(keys)
+	configured := make([]interface{}, len(keys))
+	for i, k := range keys {
+		configured[i] = k
+	}
+
+	if state.Meta == nil {
+		state.Meta = make(map[string]interface{})
+	}
+	state.Meta[computeIfUnsetMetaKey] = configured
+
+	return state
+}
+
+// restoreComputeIfUnset restores the prior value of configured
+// ComputeIfUnset attributes after a refresh, so the configured value is
+// never overwritten by the value read from the remote system.
+func (r *Resource) restoreComputeIfUnset(prior, state *terraform.InstanceState) {
+	if prior == nil || state == nil {
+		return
+	}
+
+	for k, v := range r.SchemaMap() {
+		if !v.ComputeIfUnset || !computeIfUnsetConfigured(prior.Meta, k) {
+			continue
+		}
+
+		if value, ok := prior.Attributes[k]; ok {
+			state.Attributes[k] = value
+		} else {
+			delete(state.Attributes, k)
+		}
+	}
+}
+
+// isComputeIfUnsetType returns whether ComputeIfUnset supports the type.
+func isComputeIfUnsetType(t ValueType) bool {
+	switch t {
+	case TypeBool, TypeInt, TypeFloat, TypeString:
+		return true
+	}
+
+	return false
+}
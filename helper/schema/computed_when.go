@@ -0,0 +1,71 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package schema
+
+import (
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+// diffComputedWhen marks ComputedWhen attributes as computed when any of the
+// attributes they reference changes. Attributes set in the configuration
+// keep their configured value.
+//
+// The raw configuration is used, as the configuration passed to Diff during
+// plan is the proposed new state, which keeps the prior value of optional
+// and computed attributes.
+func (m schemaMapWithIdentity) diffComputedWhen(s *terraform.InstanceState, result *terraform.InstanceDiff) {
+	if s == nil {
+		return
+	}
+
+	config := s.RawConfig
+
+	for k, schema := range m.schemaMap {
+		if len(schema.ComputedWhen) == 0 {
+			continue
+		}
+
+		if !config.IsNull() && config.IsKnown() && config.Type().IsObjectType() &&
+			config.Type().HasAttribute(k) && !config.GetAttr(k).IsNull() {
+			continue
+		}
+
+		if !computedWhenTriggered(schema.ComputedWhen, result) {
+			continue
+		}
+
+		key := k
+		switch schema.Type {
+		case TypeList, TypeSet:
+			key = k + ".#"
+		case TypeMap:
+			key = k + ".%"
+		}
+
+		result.Attributes[key] = &terraform.ResourceAttrDiff{
+			Old:         s.Attributes[key],
+			NewComputed: true,
+		}
+	}
+}
+
+// computedWhenTriggered returns whether the diff changes any of the given
+// attributes, or any attribute nested under them.
+func computedWhenTriggered(keys []string, result *terraform.InstanceDiff) bool {
+	for attr, d := range result.Attributes {
+		if !d.NewComputed && !d.NewRemoved && d.Old == d.New {
+			continue
+		}
+
+		for _, key := range keys {
+			if attr == key || strings.HasPrefix(attr, key+".") {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -3,8 +3,41 @@
 
 package schema
 
+import (
+	"context"
+)
+
 type Key string
 
 var (
 	StopContextKey = Key("StopContext")
+
+	// RefreshContextKey is the context key marking a read of a managed
+	// resource as a refresh requested by Terraform. Use IsRefresh to check
+	// for it.
+	RefreshContextKey = Key("Refresh")
+
+	// planDeferralContextKey is the context key of the planDeferral of a
+	// plan in progress.
+	planDeferralContextKey = Key("PlanDeferral")
+
+	// customizeDiffDiagnosticsContextKey is the context key of the
+	// *diag.Diagnostics collecting the diagnostics of the Resource type
+	// CustomizeDiffContext during a plan in progress.
+	customizeDiffDiagnosticsContextKey = Key("CustomizeDiffDiagnostics")
+
+	// operationContextKey is the context key of the resource operation
+	// returned by OperationFromContext.
+	operationContextKey = Key("Operation")
 )
+
+// IsRefresh returns true if the read function receiving ctx was called by
+// Terraform to refresh the state of an existing resource and detect drift,
+// rather than by the provider, such as at the end of a create or update
+// function, or by Terraform to complete an import. Read functions can use it
+// to skip attributes which are expensive to read and unlikely to change
+// outside of Terraform.
+func IsRefresh(ctx context.Context) bool {
+	refresh, _ := ctx.Value(RefreshContextKey).(bool)
+	return refresh
+}
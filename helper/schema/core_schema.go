@@ -285,9 +285,21 @@ func (s *Schema) coreConfigSchemaType() cty.Type {
 	}
 }
 
+// CoreSchema returns the schema of the resource as Terraform sees it, the
+// same as CoreConfigSchema, as SDK-owned types. It is supported for tooling
+// such as documentation and code generators which need to introspect block
+// nesting, attribute optionality and computed-ness.
+func (r *Resource) CoreSchema() *CoreConfigBlock {
+	return newCoreConfigBlock(r.CoreConfigSchema())
+}
+
 // CoreConfigSchema is a convenient shortcut for calling CoreConfigSchema on
 // the resource's schema. CoreConfigSchema adds the implicitly required "id"
-// attribute for top level resources if it doesn't exist.
+// attribute for top level resources if it doesn't exist, unless
+// DisableImplicitID is set.
+//
+// Tooling such as documentation and code generators should use CoreSchema,
+// which returns the same schema without referencing internal packages.
 func (r *Resource) CoreConfigSchema() *configschema.Block {
 	block := r.coreConfigSchema()
 
@@ -308,12 +320,17 @@ func (r *Resource) CoreConfigSchema() *configschema.Block {
 	}
 
 	// Add the implicitly required "id" field if it doesn't exist
-	if block.Attributes["id"] == nil {
+	if block.Attributes["id"] == nil && !r.DisableImplicitID {
 		block.Attributes["id"] = &configschema.Attribute{
 			Type:     cty.String,
 			Optional: true,
 			Computed: true,
 		}
+
+		if r.IDDescription != "" {
+			block.Attributes["id"].Description = r.IDDescription
+			block.Attributes["id"].DescriptionKind = configschema.StringKind(DescriptionKind)
+		}
 	}
 
 	_, timeoutsAttr := block.Attributes[TimeoutsConfigKey]
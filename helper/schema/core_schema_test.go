@@ -488,3 +488,99 @@ func TestSchemaMapCoreConfigSchema(t *testing.T) {
 		})
 	}
 }
+
+func TestResourceCoreSchema(t *testing.T) {
+	r := &Resource{
+		Description: "A resource.",
+		Schema: map[string]*Schema{
+			"name": {
+				Type:      TypeString,
+				Required:  true,
+				Sensitive: true,
+			},
+			"rule": {
+				Type:     TypeSet,
+				Optional: true,
+				MaxItems: 2,
+				Elem: &Resource{
+					Schema: map[string]*Schema{
+						"action": {
+							Type:     TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	want := &CoreConfigBlock{
+		Attributes: map[string]*CoreConfigAttribute{
+			"id": {
+				Type:     cty.String,
+				Optional: true,
+				Computed: true,
+			},
+			"name": {
+				Type:      cty.String,
+				Required:  true,
+				Sensitive: true,
+			},
+		},
+		BlockTypes: map[string]*CoreConfigNestedBlock{
+			"rule": {
+				CoreConfigBlock: CoreConfigBlock{
+					Attributes: map[string]*CoreConfigAttribute{
+						"action": {
+							Type:     cty.String,
+							Computed: true,
+						},
+					},
+				},
+				Nesting:  CoreConfigNestingSet,
+				MaxItems: 2,
+			},
+		},
+		Description:     "A resource.",
+		DescriptionKind: DescriptionKind,
+	}
+
+	got := r.CoreSchema()
+	if !cmp.Equal(got, want, equateEmpty, typeComparer) {
+		t.Error(cmp.Diff(got, want, equateEmpty, typeComparer))
+	}
+
+	if got, want := CoreConfigNestingSet.String(), "NestingSet"; got != want {
+		t.Errorf("expected nesting mode %q, got %q", want, got)
+	}
+}
+
+func TestResourceCoreConfigSchema_idDescription(t *testing.T) {
+	r := &Resource{
+		Schema: map[string]*Schema{
+			"name": {
+				Type:     TypeString,
+				Required: true,
+			},
+		},
+	}
+
+	id := r.CoreConfigSchema().Attributes["id"]
+	if id == nil || id.Description != "" {
+		t.Fatalf("expected implicit id attribute without description, got: %#v", id)
+	}
+
+	r.IDDescription = "The name of the bucket."
+
+	expected := &configschema.Attribute{
+		Type:            cty.String,
+		Optional:        true,
+		Computed:        true,
+		Description:     "The name of the bucket.",
+		DescriptionKind: configschema.StringKind(DescriptionKind),
+	}
+
+	if diff := cmp.Diff(expected, r.CoreConfigSchema().Attributes["id"], typeComparer); diff != "" {
+		t.Fatalf("unexpected difference: %s", diff)
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package schema
+
+import (
+	"github.com/hashicorp/go-cty/cty"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/internal/configs/configschema"
+)
+
+// The types in this file describe the schema model returned by
+// Resource.CoreSchema, so tooling such as documentation and code generators
+// can introspect block nesting, attribute optionality and computed-ness
+// without importing internal packages.
+
+// CoreConfigBlock is a configuration block of the core schema model,
+// describing its attributes and nested block types.
+type CoreConfigBlock struct {
+	// Attributes describes the attributes that may appear directly inside
+	// the block.
+	Attributes map[string]*CoreConfigAttribute
+
+	// BlockTypes describes the nested block types that may appear directly
+	// inside the block.
+	BlockTypes map[string]*CoreConfigNestedBlock
+
+	// Description and DescriptionKind contain a user facing description of
+	// the block and the format of that string.
+	Description     string
+	DescriptionKind StringKind
+
+	// Deprecated indicates whether the block has been marked as deprecated.
+	Deprecated bool
+}
+
+// CoreConfigAttribute is an attribute of a CoreConfigBlock.
+type CoreConfigAttribute struct {
+	// Type is the type of the attribute value.
+	Type cty.Type
+
+	// Description and DescriptionKind contain a user facing description of
+	// the attribute and the format of that string.
+	Description     string
+	DescriptionKind StringKind
+
+	// Required, Optional and Computed describe whether the value must, may
+	// or cannot be set in configuration, as for the Schema type fields of
+	// the same names.
+	Required bool
+	Optional bool
+	Computed bool
+
+	// Sensitive indicates that the value may contain sensitive information.
+	Sensitive bool
+
+	// Deprecated indicates whether the attribute has been marked as
+	// deprecated.
+	Deprecated bool
+
+	// WriteOnly indicates that the value is not stored in plan or state.
+	WriteOnly bool
+}
+
+// CoreConfigNestedBlock is a block type nested within a CoreConfigBlock.
+type CoreConfigNestedBlock struct {
+	// CoreConfigBlock is the description of the nested block.
+	CoreConfigBlock
+
+	// Nesting is how instances of the block are nested within their parent.
+	Nesting CoreConfigNestingMode
+
+	// MinItems and MaxItems are the limits on the number of blocks for the
+	// CoreConfigNestingList and CoreConfigNestingSet nesting modes, with
+	// zero meaning no limit.
+	MinItems, MaxItems int
+}
+
+// CoreConfigNestingMode is how instances of a CoreConfigNestedBlock are
+// nested within their parent block.
+type CoreConfigNestingMode configschema.NestingMode
+
+const (
+	// CoreConfigNestingSingle is a single optional nested block, as produced
+	// for the timeouts block.
+	CoreConfigNestingSingle = CoreConfigNestingMode(configschema.NestingSingle)
+
+	// CoreConfigNestingGroup is a single nested block whose value is never
+	// null.
+	CoreConfigNestingGroup = CoreConfigNestingMode(configschema.NestingGroup)
+
+	// CoreConfigNestingList is a list of nested blocks, as produced for
+	// TypeList blocks.
+	CoreConfigNestingList = CoreConfigNestingMode(configschema.NestingList)
+
+	// CoreConfigNestingSet is a set of nested blocks, as produced for
+	// TypeSet blocks.
+	CoreConfigNestingSet = CoreConfigNestingMode(configschema.NestingSet)
+
+	// CoreConfigNestingMap is a map of labelled nested blocks.
+	CoreConfigNestingMap = CoreConfigNestingMode(configschema.NestingMap)
+)
+
+// String returns the name of the nesting mode, such as "NestingList".
+func (m CoreConfigNestingMode) String() string {
+	return configschema.NestingMode(m).String()
+}
+
+// newCoreConfigBlock converts a block of the internal schema model.
+func newCoreConfigBlock(b *configschema.Block) *CoreConfigBlock {
+	if b == nil {
+		return nil
+	}
+
+	block := &CoreConfigBlock{
+		Description:     b.Description,
+		DescriptionKind: StringKind(b.DescriptionKind),
+		Deprecated:      b.Deprecated,
+	}
+
+	if len(b.Attributes) > 0 {
+		block.Attributes = make(map[string]*CoreConfigAttribute, len(b.Attributes))
+	}
+
+	for name, a := range b.Attributes {
+		block.Attributes[name] = &CoreConfigAttribute{
+			Type:            a.Type,
+			Description:     a.Description,
+			DescriptionKind: StringKind(a.DescriptionKind),
+			Required:        a.Required,
+			Optional:        a.Optional,
+			Computed:        a.Computed,
+			Sensitive:       a.Sensitive,
+			Deprecated:      a.Deprecated,
+			WriteOnly:       a.WriteOnly,
+		}
+	}
+
+	if len(b.BlockTypes) > 0 {
+		block.BlockTypes = make(map[string]*CoreConfigNestedBlock, len(b.BlockTypes))
+	}
+
+	for name, nb := range b.BlockTypes {
+		block.BlockTypes[name] = &CoreConfigNestedBlock{
+			CoreConfigBlock: *newCoreConfigBlock(&nb.Block),
+			Nesting:         CoreConfigNestingMode(nb.Nesting),
+			MinItems:        nb.MinItems,
+			MaxItems:        nb.MaxItems,
+		}
+	}
+
+	return block
+}
@@ -0,0 +1,191 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// decodeBlockTag is the struct tag naming the attribute decoded into a
+// struct field by DecodeBlock.
+const decodeBlockTag = "tfsdk"
+
+// DecodeBlock decodes the single block at key, such as a TypeList with
+// MaxItems 1 and an Elem *Resource, into the struct pointed to by out. Each
+// struct field tagged with `tfsdk:"name"` is set from the attribute of the
+// same name, while untagged fields and attributes without a field are
+// ignored. Fields of unset attributes, and all fields when the block is
+// absent, are left unchanged.
+//
+// Primitive attributes are decoded into fields of a matching kind, lists and
+// sets into slices, maps into maps with string keys, and nested blocks into
+// struct, pointer to struct or slice of struct fields. A type mismatch
+// returns an error with the path of the attribute, such as
+// "network.0.port".
+//
+// This is a shorthand for reading the block with
+// d.Get(key).([]interface{})[0].(map[string]interface{}).
+func DecodeBlock(d *ResourceData, key string, out interface{}) error {
+	rv := reflect.ValueOf(out)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return errors.New("DecodeBlock: out must be a non-nil pointer to a struct")
+	}
+
+	return decodeBlockValue(key, d.Get(key), rv.Elem())
+}
+
+func decodeBlockValue(path string, raw interface{}, rv reflect.Value) error {
+	if raw == nil {
+		return nil
+	}
+
+	if s, ok := raw.(*Set); ok {
+		raw = s.List()
+	}
+
+	switch rv.Kind() {
+	case reflect.Struct:
+		m, ok := raw.(map[string]interface{})
+		if !ok {
+			l, isList := raw.([]interface{})
+			if !isList {
+				return decodeBlockTypeError(path, raw, rv)
+			}
+
+			if len(l) == 0 {
+				return nil
+			}
+
+			if len(l) > 1 {
+				return fmt.Errorf("%s: cannot decode %d blocks into %s", path, len(l), rv.Type())
+			}
+
+			path += ".0"
+			if l[0] == nil {
+				return nil
+			}
+
+			m, ok = l[0].(map[string]interface{})
+			if !ok {
+				return decodeBlockTypeError(path, l[0], rv)
+			}
+		}
+
+		return decodeBlockStruct(path, m, rv)
+	case reflect.Ptr:
+		if l, ok := raw.([]interface{}); ok && len(l) == 0 {
+			return nil
+		}
+
+		v := reflect.New(rv.Type().Elem())
+		if err := decodeBlockValue(path, raw, v.Elem()); err != nil {
+			return err
+		}
+
+		rv.Set(v)
+	case reflect.Slice:
+		l, ok := raw.([]interface{})
+		if !ok {
+			return decodeBlockTypeError(path, raw, rv)
+		}
+
+		s := reflect.MakeSlice(rv.Type(), len(l), len(l))
+		for i, elem := range l {
+			if err := decodeBlockValue(path+"."+strconv.Itoa(i), elem, s.Index(i)); err != nil {
+				return err
+			}
+		}
+
+		rv.Set(s)
+	case reflect.Map:
+		m, ok := raw.(map[string]interface{})
+		if !ok || rv.Type().Key().Kind() != reflect.String {
+			return decodeBlockTypeError(path, raw, rv)
+		}
+
+		out := reflect.MakeMapWithSize(rv.Type(), len(m))
+		for k, elem := range m {
+			v := reflect.New(rv.Type().Elem()).Elem()
+			if err := decodeBlockValue(path+"."+k, elem, v); err != nil {
+				return err
+			}
+
+			out.SetMapIndex(reflect.ValueOf(k).Convert(rv.Type().Key()), v)
+		}
+
+		rv.Set(out)
+	case reflect.String:
+		v, ok := raw.(string)
+		if !ok {
+			return decodeBlockTypeError(path, raw, rv)
+		}
+
+		rv.SetString(v)
+	case reflect.Bool:
+		v, ok := raw.(bool)
+		if !ok {
+			return decodeBlockTypeError(path, raw, rv)
+		}
+
+		rv.SetBool(v)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v, ok := raw.(int)
+		if !ok || rv.OverflowInt(int64(v)) {
+			return decodeBlockTypeError(path, raw, rv)
+		}
+
+		rv.SetInt(int64(v))
+	case reflect.Float32, reflect.Float64:
+		switch v := raw.(type) {
+		case float64:
+			rv.SetFloat(v)
+		case int:
+			rv.SetFloat(float64(v))
+		default:
+			return decodeBlockTypeError(path, raw, rv)
+		}
+	case reflect.Interface:
+		v := reflect.ValueOf(raw)
+		if !v.Type().AssignableTo(rv.Type()) {
+			return decodeBlockTypeError(path, raw, rv)
+		}
+
+		rv.Set(v)
+	default:
+		return decodeBlockTypeError(path, raw, rv)
+	}
+
+	return nil
+}
+
+func decodeBlockStruct(path string, m map[string]interface{}, rv reflect.Value) error {
+	t := rv.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		name, ok := field.Tag.Lookup(decodeBlockTag)
+		if !ok || name == "" || name == "-" || !field.IsExported() {
+			continue
+		}
+
+		raw, ok := m[name]
+		if !ok {
+			continue
+		}
+
+		if err := decodeBlockValue(path+"."+name, raw, rv.Field(i)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func decodeBlockTypeError(path string, raw interface{}, rv reflect.Value) error {
+	return fmt.Errorf("%s: cannot decode %T into %s", path, raw, rv.Type())
+}
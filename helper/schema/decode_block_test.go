@@ -0,0 +1,219 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package schema
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type testDecodeBlockRule struct {
+	Action   string  `tfsdk:"action"`
+	Priority float64 `tfsdk:"priority"`
+}
+
+type testDecodeBlockNetwork struct {
+	Name     string                `tfsdk:"name"`
+	Port     int                   `tfsdk:"port"`
+	Enabled  bool                  `tfsdk:"enabled"`
+	Zones    []string              `tfsdk:"zones"`
+	Tags     map[string]string     `tfsdk:"tags"`
+	Rules    []testDecodeBlockRule `tfsdk:"rule"`
+	Default  *testDecodeBlockRule  `tfsdk:"default_rule"`
+	Ignored  string
+	Excluded string `tfsdk:"-"`
+}
+
+func testDecodeBlockSchema() map[string]*Schema {
+	rule := &Resource{
+		Schema: map[string]*Schema{
+			"action": {
+				Type:     TypeString,
+				Optional: true,
+			},
+			"priority": {
+				Type:     TypeFloat,
+				Optional: true,
+			},
+		},
+	}
+
+	return map[string]*Schema{
+		"network": {
+			Type:     TypeList,
+			Optional: true,
+			MaxItems: 1,
+			Elem: &Resource{
+				Schema: map[string]*Schema{
+					"name": {
+						Type:     TypeString,
+						Optional: true,
+					},
+					"port": {
+						Type:     TypeInt,
+						Optional: true,
+					},
+					"enabled": {
+						Type:     TypeBool,
+						Optional: true,
+					},
+					"zones": {
+						Type:     TypeSet,
+						Optional: true,
+						Elem:     &Schema{Type: TypeString},
+					},
+					"tags": {
+						Type:     TypeMap,
+						Optional: true,
+						Elem:     &Schema{Type: TypeString},
+					},
+					"rule": {
+						Type:     TypeList,
+						Optional: true,
+						Elem:     rule,
+					},
+					"default_rule": {
+						Type:     TypeList,
+						Optional: true,
+						MaxItems: 1,
+						Elem:     rule,
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestDecodeBlock(t *testing.T) {
+	cases := map[string]struct {
+		Config   map[string]interface{}
+		Expected testDecodeBlockNetwork
+	}{
+		"block": {
+			Config: map[string]interface{}{
+				"network": []interface{}{
+					map[string]interface{}{
+						"name":    "test",
+						"port":    8080,
+						"enabled": true,
+						"zones":   []interface{}{"a"},
+						"tags": map[string]interface{}{
+							"env": "dev",
+						},
+						"rule": []interface{}{
+							map[string]interface{}{
+								"action":   "allow",
+								"priority": 1.5,
+							},
+							map[string]interface{}{
+								"action": "deny",
+							},
+						},
+						"default_rule": []interface{}{
+							map[string]interface{}{
+								"action": "deny",
+							},
+						},
+					},
+				},
+			},
+			Expected: testDecodeBlockNetwork{
+				Name:    "test",
+				Port:    8080,
+				Enabled: true,
+				Zones:   []string{"a"},
+				Tags: map[string]string{
+					"env": "dev",
+				},
+				Rules: []testDecodeBlockRule{
+					{Action: "allow", Priority: 1.5},
+					{Action: "deny"},
+				},
+				Default:  &testDecodeBlockRule{Action: "deny"},
+				Ignored:  "unchanged",
+				Excluded: "unchanged",
+			},
+		},
+		"absent block": {
+			Config: map[string]interface{}{},
+			Expected: testDecodeBlockNetwork{
+				Ignored:  "unchanged",
+				Excluded: "unchanged",
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			d := TestResourceDataRaw(t, testDecodeBlockSchema(), tc.Config)
+
+			out := testDecodeBlockNetwork{
+				Ignored:  "unchanged",
+				Excluded: "unchanged",
+			}
+
+			if err := DecodeBlock(d, "network", &out); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if diff := cmp.Diff(tc.Expected, out); diff != "" {
+				t.Fatalf("unexpected difference: %s", diff)
+			}
+		})
+	}
+}
+
+func TestDecodeBlock_errors(t *testing.T) {
+	d := TestResourceDataRaw(t, testDecodeBlockSchema(), map[string]interface{}{
+		"network": []interface{}{
+			map[string]interface{}{
+				"name": "test",
+				"port": 8080,
+				"rule": []interface{}{
+					map[string]interface{}{
+						"action": "allow",
+					},
+				},
+			},
+		},
+	})
+
+	cases := map[string]struct {
+		Out      interface{}
+		Expected string
+	}{
+		"not a pointer": {
+			Out:      testDecodeBlockNetwork{},
+			Expected: "DecodeBlock: out must be a non-nil pointer to a struct",
+		},
+		"primitive mismatch": {
+			Out: &struct {
+				Port string `tfsdk:"port"`
+			}{},
+			Expected: "network.0.port: cannot decode int into string",
+		},
+		"nested mismatch": {
+			Out: &struct {
+				Rules []struct {
+					Action int `tfsdk:"action"`
+				} `tfsdk:"rule"`
+			}{},
+			Expected: "network.0.rule.0.action: cannot decode string into int",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := DecodeBlock(d, "network", tc.Out)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+
+			if err.Error() != tc.Expected {
+				t.Fatalf("expected error %q, got %q", tc.Expected, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package schema
+
+// diffKeyAttr is a nested attribute of a block along with the suffix of its
+// flatmap key, such as ".name", to append to the key of the block element.
+type diffKeyAttr struct {
+	name   string
+	suffix string
+}
+
+// diffKeyTemplates are the flatmap key templates of the schema map of a
+// block, built once and reused when diffing every element of the block in
+// every instance of the resource.
+type diffKeyTemplates struct {
+	attrs []diffKeyAttr
+}
+
+// diffKeyAttrs returns the nested attributes of the block r, whose schema map
+// is m, with their flatmap key suffixes. The templates are cached in r on
+// first use, and rebuilt if the attribute names of m no longer match, such as
+// when the Schema of r is replaced or SchemaFunc returns other attributes.
+// It is safe for concurrent use, concurrent callers may only build the same
+// templates more than once.
+func (r *Resource) diffKeyAttrs(m map[string]*Schema) []diffKeyAttr {
+	if t, ok := r.diffKeys.Load().(*diffKeyTemplates); ok && t.match(m) {
+		return t.attrs
+	}
+
+	t := &diffKeyTemplates{
+		attrs: make([]diffKeyAttr, 0, len(m)),
+	}
+
+	for k := range m {
+		t.attrs = append(t.attrs, diffKeyAttr{
+			name:   k,
+			suffix: "." + k,
+		})
+	}
+
+	r.diffKeys.Store(t)
+
+	return t.attrs
+}
+
+func (t *diffKeyTemplates) match(m map[string]*Schema) bool {
+	if len(t.attrs) != len(m) {
+		return false
+	}
+
+	for _, attr := range t.attrs {
+		if _, ok := m[attr.name]; !ok {
+			return false
+		}
+	}
+
+	return true
+}
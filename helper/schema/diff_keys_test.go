@@ -0,0 +1,75 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package schema
+
+import (
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestResourceDiffKeyAttrs(t *testing.T) {
+	r := &Resource{
+		Schema: map[string]*Schema{
+			"foo": {Type: TypeString, Optional: true},
+			"bar": {Type: TypeString, Optional: true},
+		},
+	}
+
+	suffixes := func(attrs []diffKeyAttr) []string {
+		result := make([]string, 0, len(attrs))
+		for _, attr := range attrs {
+			result = append(result, attr.suffix)
+		}
+		sort.Strings(result)
+		return result
+	}
+
+	first := r.diffKeyAttrs(r.SchemaMap())
+	if diff := cmp.Diff([]string{".bar", ".foo"}, suffixes(first)); diff != "" {
+		t.Fatalf("unexpected difference: %s", diff)
+	}
+
+	second := r.diffKeyAttrs(r.SchemaMap())
+	if &first[0] != &second[0] {
+		t.Fatal("expected cached templates to be reused")
+	}
+
+	r.Schema = map[string]*Schema{
+		"foo": {Type: TypeString, Optional: true},
+		"baz": {Type: TypeString, Optional: true},
+	}
+
+	third := r.diffKeyAttrs(r.SchemaMap())
+	if diff := cmp.Diff([]string{".baz", ".foo"}, suffixes(third)); diff != "" {
+		t.Fatalf("unexpected difference after schema change: %s", diff)
+	}
+}
+
+func TestResourceDiffKeyAttrs_concurrent(t *testing.T) {
+	r := &Resource{
+		Schema: map[string]*Schema{
+			"foo": {Type: TypeString, Optional: true},
+		},
+	}
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			attrs := r.diffKeyAttrs(r.SchemaMap())
+			if len(attrs) != 1 || attrs[0].suffix != ".foo" {
+				t.Errorf("unexpected templates: %#v", attrs)
+			}
+		}()
+	}
+
+	wg.Wait()
+}
@@ -0,0 +1,49 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package schema
+
+import (
+	"net"
+	"strings"
+)
+
+// CaseInsensitiveDiffSuppress is a SchemaDiffSuppressFunc for TypeString
+// attributes whose values are compared case-insensitively by the remote
+// system, such as hostnames or enumeration values returned in upper case.
+//
+// The comparison uses Unicode case folding, as strings.EqualFold.
+func CaseInsensitiveDiffSuppress(k, oldValue, newValue string, d *ResourceData) bool {
+	return strings.EqualFold(oldValue, newValue)
+}
+
+// TrimSpaceDiffSuppress is a SchemaDiffSuppressFunc for TypeString
+// attributes whose leading and trailing whitespace is not significant, such
+// as values which the remote system trims or which are read from heredocs
+// ending with a newline.
+func TrimSpaceDiffSuppress(k, oldValue, newValue string, d *ResourceData) bool {
+	return strings.TrimSpace(oldValue) == strings.TrimSpace(newValue)
+}
+
+// CIDRDiffSuppress is a SchemaDiffSuppressFunc for TypeString attributes
+// holding a CIDR, such as "10.0.0.0/8", whose value may be returned by the
+// remote system in another equivalent notation, such as "10.0.0.0/08" or an
+// IPv6 CIDR in upper case. Values which are not valid CIDRs are only
+// suppressed when they are equal.
+func CIDRDiffSuppress(k, oldValue, newValue string, d *ResourceData) bool {
+	if oldValue == newValue {
+		return true
+	}
+
+	oldIP, oldNet, err := net.ParseCIDR(oldValue)
+	if err != nil {
+		return false
+	}
+
+	newIP, newNet, err := net.ParseCIDR(newValue)
+	if err != nil {
+		return false
+	}
+
+	return oldIP.Equal(newIP) && oldNet.String() == newNet.String()
+}
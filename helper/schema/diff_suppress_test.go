@@ -0,0 +1,156 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package schema
+
+import (
+	"testing"
+)
+
+func TestCaseInsensitiveDiffSuppress(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		oldValue string
+		newValue string
+		expected bool
+	}{
+		"identical": {
+			oldValue: "example.com",
+			newValue: "example.com",
+			expected: true,
+		},
+		"different case": {
+			oldValue: "STANDARD",
+			newValue: "standard",
+			expected: true,
+		},
+		"different value": {
+			oldValue: "standard",
+			newValue: "premium",
+			expected: false,
+		},
+		"different whitespace": {
+			oldValue: "standard",
+			newValue: "standard ",
+			expected: false,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if actual := CaseInsensitiveDiffSuppress("tier", tc.oldValue, tc.newValue, nil); actual != tc.expected {
+				t.Fatalf("expected %t, got %t", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestTrimSpaceDiffSuppress(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		oldValue string
+		newValue string
+		expected bool
+	}{
+		"identical": {
+			oldValue: "echo hello",
+			newValue: "echo hello",
+			expected: true,
+		},
+		"trailing newline": {
+			oldValue: "echo hello",
+			newValue: "echo hello\n",
+			expected: true,
+		},
+		"leading and trailing whitespace": {
+			oldValue: "\t echo hello",
+			newValue: "echo hello  ",
+			expected: true,
+		},
+		"inner whitespace": {
+			oldValue: "echo hello",
+			newValue: "echo  hello",
+			expected: false,
+		},
+		"different case": {
+			oldValue: "echo hello",
+			newValue: "ECHO HELLO",
+			expected: false,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if actual := TrimSpaceDiffSuppress("script", tc.oldValue, tc.newValue, nil); actual != tc.expected {
+				t.Fatalf("expected %t, got %t", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestCIDRDiffSuppress(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		oldValue string
+		newValue string
+		expected bool
+	}{
+		"identical": {
+			oldValue: "10.0.0.0/8",
+			newValue: "10.0.0.0/8",
+			expected: true,
+		},
+		"leading zero prefix length": {
+			oldValue: "10.0.0.0/8",
+			newValue: "10.0.0.0/08",
+			expected: true,
+		},
+		"ipv6 case": {
+			oldValue: "2001:db8::/32",
+			newValue: "2001:DB8::/32",
+			expected: true,
+		},
+		"ipv6 zero compression": {
+			oldValue: "2001:db8::/32",
+			newValue: "2001:0db8:0:0:0:0:0:0/32",
+			expected: true,
+		},
+		"different prefix length": {
+			oldValue: "10.0.0.0/8",
+			newValue: "10.0.0.0/16",
+			expected: false,
+		},
+		"different host bits": {
+			oldValue: "10.0.0.0/8",
+			newValue: "10.0.0.1/8",
+			expected: false,
+		},
+		"invalid new value": {
+			oldValue: "10.0.0.0/8",
+			newValue: "10.0.0.0",
+			expected: false,
+		},
+		"removed": {
+			oldValue: "10.0.0.0/8",
+			newValue: "",
+			expected: false,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if actual := CIDRDiffSuppress("cidr_block", tc.oldValue, tc.newValue, nil); actual != tc.expected {
+				t.Fatalf("expected %t, got %t", tc.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package schema
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+// FormatDiff returns a human readable rendering of the attribute changes of a
+// resource diff, intended for logging while debugging plans and applies. For
+// example:
+//
+//	DESTROY/CREATE
+//	  name:     "old" => "new" (forces new resource)
+//	  password: <sensitive> => <sensitive> (attribute changed)
+//	  tags.env: "dev" => <removed>
+//
+// Attributes are sorted by their flatmap key. The values of attributes whose
+// schema is marked Sensitive are redacted, as recorded in the diff by Diff.
+func FormatDiff(d *terraform.InstanceDiff) string {
+	if d.Empty() {
+		return "NO CHANGES"
+	}
+
+	var buf strings.Builder
+
+	switch {
+	case d.RequiresNew() && (d.GetDestroy() || d.GetDestroyTainted()):
+		buf.WriteString("DESTROY/CREATE")
+	case d.GetDestroy() || d.GetDestroyDeposed():
+		buf.WriteString("DESTROY")
+	case d.RequiresNew():
+		buf.WriteString("CREATE")
+	default:
+		buf.WriteString("UPDATE")
+	}
+
+	attrs := d.CopyAttributes()
+
+	keys := make([]string, 0, len(attrs))
+	keyLen := 0
+	for k := range attrs {
+		keys = append(keys, k)
+		if len(k) > keyLen {
+			keyLen = len(k)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		attr := attrs[k]
+		if attr == nil {
+			continue
+		}
+
+		oldV := strconv.Quote(attr.Old)
+		newV := strconv.Quote(attr.New)
+
+		switch {
+		case attr.NewComputed:
+			newV = "<computed>"
+		case attr.NewRemoved:
+			newV = "<removed>"
+		}
+
+		var msg string
+
+		if attr.Sensitive {
+			oldV = "<sensitive>"
+			if !attr.NewComputed && !attr.NewRemoved {
+				newV = "<sensitive>"
+				if attr.Old != attr.New {
+					msg = " (attribute changed)"
+				}
+			}
+		}
+
+		if attr.RequiresNew {
+			msg = " (forces new resource)"
+		}
+
+		fmt.Fprintf(&buf, "\n  %s:%s %s => %s%s", k, strings.Repeat(" ", keyLen-len(k)), oldV, newV, msg)
+	}
+
+	return buf.String()
+}
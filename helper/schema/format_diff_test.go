@@ -0,0 +1,141 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package schema
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestFormatDiff(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		diff     *terraform.InstanceDiff
+		expected string
+	}{
+		"nil": {
+			diff:     nil,
+			expected: "NO CHANGES",
+		},
+		"update": {
+			diff: &terraform.InstanceDiff{
+				Attributes: map[string]*terraform.ResourceAttrDiff{
+					"description": {
+						Old: "old",
+						New: "new",
+					},
+					"endpoint": {
+						Old:         "a.example.com",
+						NewComputed: true,
+					},
+					"tags.%": {
+						Old: "1",
+						New: "0",
+					},
+					"tags.env": {
+						Old:        "dev",
+						NewRemoved: true,
+					},
+				},
+			},
+			expected: `UPDATE
+  description: "old" => "new"
+  endpoint:    "a.example.com" => <computed>
+  tags.%:      "1" => "0"
+  tags.env:    "dev" => <removed>`,
+		},
+		"destroy/create": {
+			diff: &terraform.InstanceDiff{
+				Attributes: map[string]*terraform.ResourceAttrDiff{
+					"name": {
+						Old:         "old",
+						New:         "new",
+						RequiresNew: true,
+					},
+					"password": {
+						Old:       "hunter2",
+						New:       "hunter3",
+						Sensitive: true,
+					},
+					"token": {
+						Old:       "secret",
+						New:       "secret",
+						Sensitive: true,
+					},
+				},
+				DestroyTainted: true,
+			},
+			expected: `DESTROY/CREATE
+  name:     "old" => "new" (forces new resource)
+  password: <sensitive> => <sensitive> (attribute changed)
+  token:    <sensitive> => <sensitive>`,
+		},
+		"destroy": {
+			diff: &terraform.InstanceDiff{
+				Destroy: true,
+			},
+			expected: "DESTROY",
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got := FormatDiff(testCase.diff)
+
+			if diff := cmp.Diff(testCase.expected, got); diff != "" {
+				t.Errorf("unexpected difference: %s", diff)
+			}
+		})
+	}
+}
+
+func TestFormatDiff_schemaSensitive(t *testing.T) {
+	t.Parallel()
+
+	sm := schemaMap{
+		"name": {
+			Type:     TypeString,
+			Required: true,
+			ForceNew: true,
+		},
+		"password": {
+			Type:      TypeString,
+			Optional:  true,
+			Sensitive: true,
+		},
+	}
+
+	state := &terraform.InstanceState{
+		ID: "id",
+		Attributes: map[string]string{
+			"id":       "id",
+			"name":     "example",
+			"password": "hunter2",
+		},
+	}
+
+	config := terraform.NewResourceConfigRaw(map[string]interface{}{
+		"name":     "example",
+		"password": "hunter3",
+	})
+
+	d, err := sm.Diff(context.Background(), state, config, nil, nil, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := `UPDATE
+  password: <sensitive> => <sensitive> (attribute changed)`
+
+	if diff := cmp.Diff(expected, FormatDiff(d)); diff != "" {
+		t.Errorf("unexpected difference: %s", diff)
+	}
+}
@@ -0,0 +1,286 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package schema
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"runtime/debug"
+
+	"github.com/hashicorp/go-cty/cty"
+	ctyconvert "github.com/hashicorp/go-cty/cty/convert"
+	"github.com/hashicorp/go-cty/cty/msgpack"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/internal/configs/configschema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/internal/plugin/convert"
+)
+
+// Function is a provider-defined function, which practitioners can call in
+// their configuration with the provider::<provider>::<name> syntax.
+//
+// Provider-defined functions are only supported in Terraform 1.8 and later.
+type Function struct {
+	// Summary is a short description of the function, shown in the
+	// language server and documentation.
+	Summary string
+
+	// Description is the full description of the function. It can be
+	// plain-text or markdown depending on the global DescriptionKind setting.
+	Description string
+
+	// DeprecationMessage, when set, marks the function as deprecated with
+	// the given message, which Terraform shows as a warning when the
+	// function is called.
+	DeprecationMessage string
+
+	// Parameters are the positional parameters of the function.
+	Parameters []FunctionParameter
+
+	// VariadicParameter is an optional parameter which receives any number
+	// of trailing arguments after Parameters.
+	VariadicParameter *FunctionParameter
+
+	// Return is the type of the value returned by the function.
+	Return cty.Type
+
+	// Impl is the function implementation. It receives one argument per
+	// parameter, followed by the variadic arguments if any, converted to the
+	// parameter types. The returned value is converted to the Return type.
+	//
+	// A returned FunctionArgumentError is reported against the argument it
+	// references, any other error is reported against the function call, as
+	// are a panic and a cty.NilVal result without error.
+	Impl func(ctx context.Context, args []cty.Value) (cty.Value, error)
+}
+
+// FunctionParameter is a parameter of a provider-defined function.
+type FunctionParameter struct {
+	// Name is the name of the parameter, shown in the language server and
+	// documentation and in errors about the argument. It must be a valid
+	// field name.
+	Name string
+
+	// Description is the description of the parameter. It can be plain-text
+	// or markdown depending on the global DescriptionKind setting.
+	Description string
+
+	// Type is the type of the argument. Arguments are converted to this
+	// type by Terraform. cty.DynamicPseudoType accepts any type.
+	Type cty.Type
+
+	// AllowNullValue enables null arguments. Otherwise Terraform returns
+	// an error for null arguments without calling the function.
+	AllowNullValue bool
+
+	// AllowUnknownValues enables unknown arguments. Otherwise Terraform
+	// returns an unknown result without calling the function.
+	AllowUnknownValues bool
+}
+
+// FunctionArgumentError is an error in the argument at Index of a function
+// call, which Terraform reports against that argument.
+type FunctionArgumentError struct {
+	Index int
+	Err   error
+}
+
+func (e *FunctionArgumentError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *FunctionArgumentError) Unwrap() error {
+	return e.Err
+}
+
+// InternalValidate should be called to validate the structure of the
+// function.
+func (f *Function) InternalValidate() error {
+	if f == nil {
+		return errors.New("function is nil")
+	}
+
+	if f.Impl == nil {
+		return errors.New("Impl must be implemented")
+	}
+
+	if f.Return == cty.NilType {
+		return errors.New("Return type must be set")
+	}
+
+	params := f.Parameters
+	if f.VariadicParameter != nil {
+		params = append(params[:len(params):len(params)], *f.VariadicParameter)
+	}
+
+	names := make(map[string]struct{}, len(params))
+	for i, param := range params {
+		if !isValidFieldName(param.Name) {
+			return fmt.Errorf("parameter %d: name %q may only contain lowercase alphanumeric characters & underscores", i, param.Name)
+		}
+
+		if _, ok := names[param.Name]; ok {
+			return fmt.Errorf("parameter %d: duplicate name %q", i, param.Name)
+		}
+		names[param.Name] = struct{}{}
+
+		if param.Type == cty.NilType {
+			return fmt.Errorf("parameter %s: Type must be set", param.Name)
+		}
+	}
+
+	return nil
+}
+
+// proto returns the function definition for a grpc response.
+func (f *Function) proto(ctx context.Context) (*tfprotov5.Function, error) {
+	returnType, err := convert.CtyTypeToProto(f.Return)
+	if err != nil {
+		return nil, fmt.Errorf("return type: %w", err)
+	}
+
+	fn := &tfprotov5.Function{
+		Summary:            f.Summary,
+		Description:        f.Description,
+		DescriptionKind:    convert.StringKindToProto(ctx, configschema.StringKind(DescriptionKind)),
+		DeprecationMessage: f.DeprecationMessage,
+		Parameters:         make([]*tfprotov5.FunctionParameter, 0, len(f.Parameters)),
+		Return: &tfprotov5.FunctionReturn{
+			Type: returnType,
+		},
+	}
+
+	for _, param := range f.Parameters {
+		p, err := param.proto(ctx)
+		if err != nil {
+			return nil, err
+		}
+		fn.Parameters = append(fn.Parameters, p)
+	}
+
+	if f.VariadicParameter != nil {
+		fn.VariadicParameter, err = f.VariadicParameter.proto(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return fn, nil
+}
+
+func (p FunctionParameter) proto(ctx context.Context) (*tfprotov5.FunctionParameter, error) {
+	typ, err := convert.CtyTypeToProto(p.Type)
+	if err != nil {
+		return nil, fmt.Errorf("parameter %s type: %w", p.Name, err)
+	}
+
+	return &tfprotov5.FunctionParameter{
+		Name:               p.Name,
+		Description:        p.Description,
+		DescriptionKind:    convert.StringKindToProto(ctx, configschema.StringKind(DescriptionKind)),
+		Type:               typ,
+		AllowNullValue:     p.AllowNullValue,
+		AllowUnknownValues: p.AllowUnknownValues,
+	}, nil
+}
+
+// call decodes the arguments of a function call, calls the function
+// implementation and returns the encoded result.
+func (f *Function) call(ctx context.Context, arguments []*tfprotov5.DynamicValue) (*tfprotov5.DynamicValue, *tfprotov5.FunctionError) {
+	if len(arguments) < len(f.Parameters) || (f.VariadicParameter == nil && len(arguments) > len(f.Parameters)) {
+		return nil, &tfprotov5.FunctionError{
+			Text: fmt.Sprintf("Unexpected number of arguments: expected %d, got %d.", len(f.Parameters), len(arguments)),
+		}
+	}
+
+	args := make([]cty.Value, 0, len(arguments))
+
+	for i, argument := range arguments {
+		param := f.VariadicParameter
+		if i < len(f.Parameters) {
+			param = &f.Parameters[i]
+		}
+
+		var arg cty.Value
+		var err error
+
+		if argument == nil {
+			arg = cty.NullVal(param.Type)
+		} else {
+			arg, err = msgpack.Unmarshal(argument.MsgPack, param.Type)
+		}
+
+		if err == nil && arg.IsNull() && !param.AllowNullValue {
+			err = fmt.Errorf("argument %s must not be null", param.Name)
+		}
+
+		if err != nil {
+			return nil, functionArgumentError(i, err)
+		}
+
+		args = append(args, arg)
+	}
+
+	result, err := f.callImpl(ctx, args)
+	if err != nil {
+		var argErr *FunctionArgumentError
+		if errors.As(err, &argErr) {
+			return nil, functionArgumentError(argErr.Index, argErr.Err)
+		}
+
+		return nil, &tfprotov5.FunctionError{
+			Text: err.Error(),
+		}
+	}
+
+	if result.Type() == cty.NilType {
+		return nil, &tfprotov5.FunctionError{
+			Text: "Invalid function result: the function returned no value. " +
+				"This is always a bug in the provider and should be reported to the provider developers.",
+		}
+	}
+
+	result, err = ctyconvert.Convert(result, f.Return)
+	if err != nil {
+		return nil, &tfprotov5.FunctionError{
+			Text: fmt.Sprintf("Invalid function result: %s", err),
+		}
+	}
+
+	resultMP, err := msgpack.Marshal(result, f.Return)
+	if err != nil {
+		return nil, &tfprotov5.FunctionError{
+			Text: fmt.Sprintf("Invalid function result: %s", err),
+		}
+	}
+
+	return &tfprotov5.DynamicValue{MsgPack: resultMP}, nil
+}
+
+// callImpl calls the function implementation, returning an error if it
+// panics so a provider bug does not crash the provider.
+func (f *Function) callImpl(ctx context.Context, args []cty.Value) (result cty.Value, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("[ERROR] Function panicked: %v\n%s", r, debug.Stack())
+
+			result = cty.NilVal
+			err = fmt.Errorf("Function panicked: %v. "+
+				"This is always a bug in the provider and should be reported to the provider developers.", r)
+		}
+	}()
+
+	return f.Impl(ctx, args)
+}
+
+func functionArgumentError(index int, err error) *tfprotov5.FunctionError {
+	argument := int64(index)
+
+	return &tfprotov5.FunctionError{
+		Text:             err.Error(),
+		FunctionArgument: &argument,
+	}
+}
@@ -0,0 +1,317 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package schema
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+func testFunctionJoin() *Function {
+	return &Function{
+		Summary: "Joins strings",
+		Parameters: []FunctionParameter{
+			{
+				Name: "separator",
+				Type: cty.String,
+			},
+		},
+		VariadicParameter: &FunctionParameter{
+			Name:           "values",
+			Type:           cty.String,
+			AllowNullValue: true,
+		},
+		Return: cty.String,
+		Impl: func(_ context.Context, args []cty.Value) (cty.Value, error) {
+			values := make([]string, 0, len(args)-1)
+			for i, arg := range args[1:] {
+				if arg.IsNull() {
+					return cty.NilVal, &FunctionArgumentError{Index: i + 1, Err: errors.New("value must not be null")}
+				}
+				values = append(values, arg.AsString())
+			}
+			return cty.StringVal(strings.Join(values, args[0].AsString())), nil
+		},
+	}
+}
+
+func TestFunctionInternalValidate(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		function *Function
+		err      bool
+	}{
+		"valid": {
+			function: testFunctionJoin(),
+		},
+		"missing Impl": {
+			function: &Function{
+				Return: cty.String,
+			},
+			err: true,
+		},
+		"missing Return": {
+			function: &Function{
+				Impl: func(context.Context, []cty.Value) (cty.Value, error) { return cty.NilVal, nil },
+			},
+			err: true,
+		},
+		"missing parameter Type": {
+			function: &Function{
+				Parameters: []FunctionParameter{
+					{Name: "input"},
+				},
+				Return: cty.String,
+				Impl:   func(context.Context, []cty.Value) (cty.Value, error) { return cty.NilVal, nil },
+			},
+			err: true,
+		},
+		"invalid parameter name": {
+			function: &Function{
+				Parameters: []FunctionParameter{
+					{Name: "Input", Type: cty.String},
+				},
+				Return: cty.String,
+				Impl:   func(context.Context, []cty.Value) (cty.Value, error) { return cty.NilVal, nil },
+			},
+			err: true,
+		},
+		"duplicate variadic parameter name": {
+			function: &Function{
+				Parameters: []FunctionParameter{
+					{Name: "input", Type: cty.String},
+				},
+				VariadicParameter: &FunctionParameter{Name: "input", Type: cty.String},
+				Return:            cty.String,
+				Impl:              func(context.Context, []cty.Value) (cty.Value, error) { return cty.NilVal, nil },
+			},
+			err: true,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := testCase.function.InternalValidate()
+			if err != nil && !testCase.err {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if err == nil && testCase.err {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
+
+func TestGRPCProviderServerGetFunctions(t *testing.T) {
+	t.Parallel()
+
+	server := NewGRPCProviderServer(&Provider{
+		Functions: map[string]*Function{
+			"join": testFunctionJoin(),
+		},
+	})
+
+	resp, err := server.GetFunctions(context.Background(), &tfprotov5.GetFunctionsRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// DescriptionKind is global and may be changed by other tests.
+	descriptionKind := tfprotov5.StringKindPlain
+	if DescriptionKind == StringMarkdown {
+		descriptionKind = tfprotov5.StringKindMarkdown
+	}
+
+	expected := &tfprotov5.GetFunctionsResponse{
+		Functions: map[string]*tfprotov5.Function{
+			"join": {
+				Summary:         "Joins strings",
+				DescriptionKind: descriptionKind,
+				Parameters: []*tfprotov5.FunctionParameter{
+					{
+						Name:            "separator",
+						DescriptionKind: descriptionKind,
+						Type:            tftypes.String,
+					},
+				},
+				VariadicParameter: &tfprotov5.FunctionParameter{
+					Name:            "values",
+					DescriptionKind: descriptionKind,
+					Type:            tftypes.String,
+					AllowNullValue:  true,
+				},
+				Return: &tfprotov5.FunctionReturn{
+					Type: tftypes.String,
+				},
+			},
+		},
+	}
+
+	if diff := cmp.Diff(expected, resp); diff != "" {
+		t.Errorf("unexpected difference: %s", diff)
+	}
+
+	schemaResp, err := server.GetProviderSchema(context.Background(), &tfprotov5.GetProviderSchemaRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(expected.Functions, schemaResp.Functions); diff != "" {
+		t.Errorf("unexpected provider schema functions difference: %s", diff)
+	}
+
+	metadataResp, err := server.GetMetadata(context.Background(), &tfprotov5.GetMetadataRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff([]tfprotov5.FunctionMetadata{{Name: "join"}}, metadataResp.Functions); diff != "" {
+		t.Errorf("unexpected metadata functions difference: %s", diff)
+	}
+}
+
+func TestGRPCProviderServerCallFunction(t *testing.T) {
+	t.Parallel()
+
+	argument := func(v cty.Value) *tfprotov5.DynamicValue {
+		return &tfprotov5.DynamicValue{MsgPack: mustMsgpackMarshal(cty.String, v)}
+	}
+	argumentIndex := func(i int64) *int64 {
+		return &i
+	}
+
+	testCases := map[string]struct {
+		request  *tfprotov5.CallFunctionRequest
+		expected *tfprotov5.CallFunctionResponse
+	}{
+		"success": {
+			request: &tfprotov5.CallFunctionRequest{
+				Name: "join",
+				Arguments: []*tfprotov5.DynamicValue{
+					argument(cty.StringVal("-")),
+					argument(cty.StringVal("a")),
+					argument(cty.StringVal("b")),
+				},
+			},
+			expected: &tfprotov5.CallFunctionResponse{
+				Result: argument(cty.StringVal("a-b")),
+			},
+		},
+		"unknown function": {
+			request: &tfprotov5.CallFunctionRequest{
+				Name: "split",
+			},
+			expected: &tfprotov5.CallFunctionResponse{
+				Error: &tfprotov5.FunctionError{
+					Text: `Function Not Found: No function named "split" was found in the provider.`,
+				},
+			},
+		},
+		"missing argument": {
+			request: &tfprotov5.CallFunctionRequest{
+				Name: "join",
+			},
+			expected: &tfprotov5.CallFunctionResponse{
+				Error: &tfprotov5.FunctionError{
+					Text: "Unexpected number of arguments: expected 1, got 0.",
+				},
+			},
+		},
+		"null argument": {
+			request: &tfprotov5.CallFunctionRequest{
+				Name: "join",
+				Arguments: []*tfprotov5.DynamicValue{
+					argument(cty.NullVal(cty.String)),
+				},
+			},
+			expected: &tfprotov5.CallFunctionResponse{
+				Error: &tfprotov5.FunctionError{
+					Text:             "argument separator must not be null",
+					FunctionArgument: argumentIndex(0),
+				},
+			},
+		},
+		"implementation argument error": {
+			request: &tfprotov5.CallFunctionRequest{
+				Name: "join",
+				Arguments: []*tfprotov5.DynamicValue{
+					argument(cty.StringVal("-")),
+					argument(cty.StringVal("a")),
+					argument(cty.NullVal(cty.String)),
+				},
+			},
+			expected: &tfprotov5.CallFunctionResponse{
+				Error: &tfprotov5.FunctionError{
+					Text:             "value must not be null",
+					FunctionArgument: argumentIndex(2),
+				},
+			},
+		},
+		"implementation panic": {
+			request: &tfprotov5.CallFunctionRequest{
+				Name: "panic",
+			},
+			expected: &tfprotov5.CallFunctionResponse{
+				Error: &tfprotov5.FunctionError{
+					Text: "Function panicked: boom. " +
+						"This is always a bug in the provider and should be reported to the provider developers.",
+				},
+			},
+		},
+		"implementation nil result": {
+			request: &tfprotov5.CallFunctionRequest{
+				Name: "nil",
+			},
+			expected: &tfprotov5.CallFunctionResponse{
+				Error: &tfprotov5.FunctionError{
+					Text: "Invalid function result: the function returned no value. " +
+						"This is always a bug in the provider and should be reported to the provider developers.",
+				},
+			},
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			server := NewGRPCProviderServer(&Provider{
+				Functions: map[string]*Function{
+					"join": testFunctionJoin(),
+					"panic": {
+						Return: cty.String,
+						Impl: func(context.Context, []cty.Value) (cty.Value, error) {
+							panic("boom")
+						},
+					},
+					"nil": {
+						Return: cty.String,
+						Impl: func(context.Context, []cty.Value) (cty.Value, error) {
+							return cty.NilVal, nil
+						},
+					},
+				},
+			})
+
+			resp, err := server.CallFunction(context.Background(), testCase.request)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(testCase.expected, resp); diff != "" {
+				t.Errorf("unexpected difference: %s", diff)
+			}
+		})
+	}
+}
@@ -6,9 +6,12 @@ package schema
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/hashicorp/go-cty/cty"
 	ctyconvert "github.com/hashicorp/go-cty/cty/convert"
@@ -17,6 +20,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
 	"github.com/hashicorp/terraform-plugin-go/tftypes"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/internal/configs/configschema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/internal/configs/hcl2shim"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/internal/logging"
@@ -29,6 +33,10 @@ const (
 	newExtraKey = "_new_extra_shim"
 )
 
+// stopFuncTimeout is the duration after which the context passed to the
+// Provider StopFunc is cancelled.
+const stopFuncTimeout = 10 * time.Second
+
 // Verify provider server interface implementation.
 var _ tfprotov5.ProviderServer = (*GRPCProviderServer)(nil)
 
@@ -44,6 +52,12 @@ type GRPCProviderServer struct {
 	provider *Provider
 	stopCh   chan struct{}
 	stopMu   sync.Mutex
+
+	// deprecationWarnings are the keys of the deprecation warnings already
+	// returned to Terraform, when the Provider type DedupDeprecationWarnings
+	// is enabled.
+	deprecationWarnings   map[string]struct{}
+	deprecationWarningsMu sync.Mutex
 }
 
 // mergeStop is called in a goroutine and waits for the global stop signal
@@ -71,6 +85,116 @@ func (s *GRPCProviderServer) StopContext(ctx context.Context) context.Context {
 	return stoppable
 }
 
+// diagnosticsMiddlewareContext returns a context recording the diagnostics
+// converted for the RPC response, when the provider DiagnosticsMiddleware is
+// set, so applyDiagnosticsMiddleware can pass them to it unchanged.
+func (s *GRPCProviderServer) diagnosticsMiddlewareContext(ctx context.Context) context.Context {
+	if s.provider.DiagnosticsMiddleware == nil {
+		return ctx
+	}
+
+	return convert.WithDiagnosticSources(ctx)
+}
+
+// applyDiagnosticsMiddleware removes duplicate deprecation warnings, when
+// DedupDeprecationWarnings is enabled, then replaces the given RPC response
+// diagnostics with the result of the provider DiagnosticsMiddleware, if any.
+// It is deferred by each RPC so it runs after all provider logic and before
+// the response is returned to Terraform.
+//
+// The middleware receives the diagnostics as returned by the provider logic,
+// recorded with diagnosticsMiddlewareContext, and its result is converted
+// once, so fields such as DocURL are not folded into Detail beforehand.
+// Only error and warning diagnostics are passed to DiagnosticsMiddleware, as
+// other severities cannot be represented by the diag package. They are
+// returned unchanged after its result.
+func (s *GRPCProviderServer) applyDiagnosticsMiddleware(ctx context.Context, diags *[]*tfprotov5.Diagnostic) {
+	if s.provider.DedupDeprecationWarnings {
+		*diags = s.dedupDeprecationWarnings(*diags)
+	}
+
+	if s.provider.DiagnosticsMiddleware == nil {
+		return
+	}
+
+	var known diag.Diagnostics
+	var unknown []*tfprotov5.Diagnostic
+
+	for _, d := range *diags {
+		switch d.Severity {
+		case tfprotov5.DiagnosticSeverityError, tfprotov5.DiagnosticSeverityWarning:
+			if source, ok := convert.DiagnosticSource(ctx, d); ok {
+				known = append(known, source)
+				continue
+			}
+
+			known = append(known, convert.ProtoToDiags([]*tfprotov5.Diagnostic{d})...)
+		default:
+			unknown = append(unknown, d)
+		}
+	}
+
+	*diags = append(convert.DiagsToProto(s.provider.DiagnosticsMiddleware(ctx, known)), unknown...)
+}
+
+// dedupDeprecationWarnings removes the deprecation warnings which were
+// already returned to Terraform by a previous RPC or earlier in diags.
+func (s *GRPCProviderServer) dedupDeprecationWarnings(diags []*tfprotov5.Diagnostic) []*tfprotov5.Diagnostic {
+	s.deprecationWarningsMu.Lock()
+	defer s.deprecationWarningsMu.Unlock()
+
+	var result []*tfprotov5.Diagnostic
+
+	for _, d := range diags {
+		if !isDeprecationWarning(d) {
+			result = append(result, d)
+			continue
+		}
+
+		key := deprecationWarningKey(d)
+		if _, ok := s.deprecationWarnings[key]; ok {
+			continue
+		}
+
+		if s.deprecationWarnings == nil {
+			s.deprecationWarnings = make(map[string]struct{})
+		}
+
+		s.deprecationWarnings[key] = struct{}{}
+		result = append(result, d)
+	}
+
+	return result
+}
+
+// isDeprecationWarning returns true for the warnings of the Schema type
+// Deprecated and DeprecatedValues fields.
+func isDeprecationWarning(d *tfprotov5.Diagnostic) bool {
+	if d == nil || d.Severity != tfprotov5.DiagnosticSeverityWarning {
+		return false
+	}
+
+	return d.Summary == "Argument is deprecated" || d.Summary == "Argument value is deprecated"
+}
+
+// deprecationWarningKey returns the key identifying identical deprecation
+// warnings: the summary, the detail and the attribute names of the path,
+// without element keys, so that the warnings of every instance of a
+// resource or element of a block are identical.
+func deprecationWarningKey(d *tfprotov5.Diagnostic) string {
+	var names []string
+
+	if d.Attribute != nil {
+		for _, step := range d.Attribute.Steps() {
+			if name, ok := step.(tftypes.AttributeName); ok {
+				names = append(names, string(name))
+			}
+		}
+	}
+
+	return strings.Join([]string{d.Summary, d.Detail, strings.Join(names, ".")}, "\x00")
+}
+
 func (s *GRPCProviderServer) serverCapabilities() *tfprotov5.ServerCapabilities {
 	return &tfprotov5.ServerCapabilities{
 		GetProviderSchemaOptional: true,
@@ -86,6 +210,9 @@ func (s *GRPCProviderServer) GetResourceIdentitySchemas(ctx context.Context, req
 		IdentitySchemas: make(map[string]*tfprotov5.ResourceIdentitySchema),
 	}
 
+	ctx = s.diagnosticsMiddlewareContext(ctx)
+	defer s.applyDiagnosticsMiddleware(ctx, &resp.Diagnostics)
+
 	for typ, res := range s.provider.ResourcesMap {
 		logging.HelperSchemaTrace(ctx, "Found resource identity type", map[string]interface{}{logging.KeyResourceType: typ})
 
@@ -112,6 +239,9 @@ func (s *GRPCProviderServer) UpgradeResourceIdentity(ctx context.Context, req *t
 	ctx = logging.InitContext(ctx)
 	resp := &tfprotov5.UpgradeResourceIdentityResponse{}
 
+	ctx = s.diagnosticsMiddlewareContext(ctx)
+	defer s.applyDiagnosticsMiddleware(ctx, &resp.Diagnostics)
+
 	res, ok := s.provider.ResourcesMap[req.TypeName]
 	if !ok {
 		resp.Diagnostics = convert.AppendProtoDiag(ctx, resp.Diagnostics, fmt.Errorf("unknown resource type: %s", req.TypeName))
@@ -194,11 +324,14 @@ func (s *GRPCProviderServer) GetMetadata(ctx context.Context, req *tfprotov5.Get
 	resp := &tfprotov5.GetMetadataResponse{
 		DataSources:        make([]tfprotov5.DataSourceMetadata, 0, len(s.provider.DataSourcesMap)),
 		EphemeralResources: make([]tfprotov5.EphemeralResourceMetadata, 0),
-		Functions:          make([]tfprotov5.FunctionMetadata, 0),
+		Functions:          make([]tfprotov5.FunctionMetadata, 0, len(s.provider.Functions)),
 		Resources:          make([]tfprotov5.ResourceMetadata, 0, len(s.provider.ResourcesMap)),
 		ServerCapabilities: s.serverCapabilities(),
 	}
 
+	ctx = s.diagnosticsMiddlewareContext(ctx)
+	defer s.applyDiagnosticsMiddleware(ctx, &resp.Diagnostics)
+
 	for typeName := range s.provider.DataSourcesMap {
 		resp.DataSources = append(resp.DataSources, tfprotov5.DataSourceMetadata{
 			TypeName: typeName,
@@ -211,6 +344,12 @@ func (s *GRPCProviderServer) GetMetadata(ctx context.Context, req *tfprotov5.Get
 		})
 	}
 
+	for name := range s.provider.Functions {
+		resp.Functions = append(resp.Functions, tfprotov5.FunctionMetadata{
+			Name: name,
+		})
+	}
+
 	return resp, nil
 }
 
@@ -222,11 +361,13 @@ func (s *GRPCProviderServer) GetProviderSchema(ctx context.Context, req *tfproto
 	resp := &tfprotov5.GetProviderSchemaResponse{
 		DataSourceSchemas:        make(map[string]*tfprotov5.Schema, len(s.provider.DataSourcesMap)),
 		EphemeralResourceSchemas: make(map[string]*tfprotov5.Schema, 0),
-		Functions:                make(map[string]*tfprotov5.Function, 0),
 		ResourceSchemas:          make(map[string]*tfprotov5.Schema, len(s.provider.ResourcesMap)),
 		ServerCapabilities:       s.serverCapabilities(),
 	}
 
+	ctx = s.diagnosticsMiddlewareContext(ctx)
+	defer s.applyDiagnosticsMiddleware(ctx, &resp.Diagnostics)
+
 	resp.Provider = &tfprotov5.Schema{
 		Block: convert.ConfigSchemaToProto(ctx, s.getProviderSchemaBlock()),
 	}
@@ -235,6 +376,10 @@ func (s *GRPCProviderServer) GetProviderSchema(ctx context.Context, req *tfproto
 		Block: convert.ConfigSchemaToProto(ctx, s.getProviderMetaSchemaBlock()),
 	}
 
+	functions, diags := s.functionsProto(ctx)
+	resp.Functions = functions
+	resp.Diagnostics = append(resp.Diagnostics, diags...)
+
 	for typ, res := range s.provider.ResourcesMap {
 		logging.HelperSchemaTrace(ctx, "Found resource type", map[string]interface{}{logging.KeyResourceType: typ})
 
@@ -283,6 +428,9 @@ func (s *GRPCProviderServer) PrepareProviderConfig(ctx context.Context, req *tfp
 	ctx = logging.InitContext(ctx)
 	resp := &tfprotov5.PrepareProviderConfigResponse{}
 
+	ctx = s.diagnosticsMiddlewareContext(ctx)
+	defer s.applyDiagnosticsMiddleware(ctx, &resp.Diagnostics)
+
 	logging.HelperSchemaTrace(ctx, "Preparing provider configuration")
 
 	schemaBlock := s.getProviderSchemaBlock()
@@ -372,7 +520,7 @@ func (s *GRPCProviderServer) PrepareProviderConfig(ctx context.Context, req *tfp
 	config := terraform.NewResourceConfigShimmed(configVal, schemaBlock)
 
 	logging.HelperSchemaTrace(ctx, "Calling downstream")
-	resp.Diagnostics = convert.AppendProtoDiag(ctx, resp.Diagnostics, s.provider.Validate(config))
+	resp.Diagnostics = convert.AppendProtoDiag(ctx, resp.Diagnostics, s.provider.validate(ctx, config))
 	logging.HelperSchemaTrace(ctx, "Called downstream")
 
 	preparedConfigMP, err := msgpack.Marshal(configVal, schemaBlock.ImpliedType())
@@ -390,6 +538,9 @@ func (s *GRPCProviderServer) ValidateResourceTypeConfig(ctx context.Context, req
 	ctx = logging.InitContext(ctx)
 	resp := &tfprotov5.ValidateResourceTypeConfigResponse{}
 
+	ctx = s.diagnosticsMiddlewareContext(ctx)
+	defer s.applyDiagnosticsMiddleware(ctx, &resp.Diagnostics)
+
 	schemaBlock := s.getResourceSchemaBlock(req.TypeName)
 
 	configVal, err := msgpack.Unmarshal(req.Config.MsgPack, schemaBlock.ImpliedType())
@@ -425,9 +576,10 @@ func (s *GRPCProviderServer) ValidateResourceTypeConfig(ctx context.Context, req
 	}
 
 	config := terraform.NewResourceConfigShimmed(configVal, schemaBlock)
+	config.CtyValue = configVal
 
 	logging.HelperSchemaTrace(ctx, "Calling downstream")
-	resp.Diagnostics = convert.AppendProtoDiag(ctx, resp.Diagnostics, s.provider.ValidateResource(req.TypeName, config))
+	resp.Diagnostics = convert.AppendProtoDiag(ctx, resp.Diagnostics, s.provider.validateResource(ctx, req.TypeName, config))
 	logging.HelperSchemaTrace(ctx, "Called downstream")
 
 	return resp, nil
@@ -437,6 +589,9 @@ func (s *GRPCProviderServer) ValidateDataSourceConfig(ctx context.Context, req *
 	ctx = logging.InitContext(ctx)
 	resp := &tfprotov5.ValidateDataSourceConfigResponse{}
 
+	ctx = s.diagnosticsMiddlewareContext(ctx)
+	defer s.applyDiagnosticsMiddleware(ctx, &resp.Diagnostics)
+
 	schemaBlock := s.getDatasourceSchemaBlock(req.TypeName)
 
 	configVal, err := msgpack.Unmarshal(req.Config.MsgPack, schemaBlock.ImpliedType())
@@ -454,7 +609,7 @@ func (s *GRPCProviderServer) ValidateDataSourceConfig(ctx context.Context, req *
 	config := terraform.NewResourceConfigShimmed(configVal, schemaBlock)
 
 	logging.HelperSchemaTrace(ctx, "Calling downstream")
-	resp.Diagnostics = convert.AppendProtoDiag(ctx, resp.Diagnostics, s.provider.ValidateDataSource(req.TypeName, config))
+	resp.Diagnostics = convert.AppendProtoDiag(ctx, resp.Diagnostics, s.provider.validateDataSource(ctx, req.TypeName, config))
 	logging.HelperSchemaTrace(ctx, "Called downstream")
 
 	return resp, nil
@@ -464,6 +619,9 @@ func (s *GRPCProviderServer) UpgradeResourceState(ctx context.Context, req *tfpr
 	ctx = logging.InitContext(ctx)
 	resp := &tfprotov5.UpgradeResourceStateResponse{}
 
+	ctx = s.diagnosticsMiddlewareContext(ctx)
+	defer s.applyDiagnosticsMiddleware(ctx, &resp.Diagnostics)
+
 	res, ok := s.provider.ResourcesMap[req.TypeName]
 	if !ok {
 		resp.Diagnostics = convert.AppendProtoDiag(ctx, resp.Diagnostics, fmt.Errorf("unknown resource type: %s", req.TypeName))
@@ -473,6 +631,11 @@ func (s *GRPCProviderServer) UpgradeResourceState(ctx context.Context, req *tfpr
 
 	version := int(req.Version)
 
+	if err := res.checkStateSchemaVersion(version); err != nil {
+		resp.Diagnostics = convert.AppendProtoDiag(ctx, resp.Diagnostics, err)
+		return resp, nil
+	}
+
 	jsonMap := map[string]interface{}{}
 	var err error
 
@@ -506,9 +669,9 @@ func (s *GRPCProviderServer) UpgradeResourceState(ctx context.Context, req *tfpr
 	// complete the upgrade of the JSON states
 	logging.HelperSchemaTrace(ctx, "Upgrading JSON state")
 
-	jsonMap, err = s.upgradeJSONState(ctx, version, jsonMap, res)
-	if err != nil {
-		resp.Diagnostics = convert.AppendProtoDiag(ctx, resp.Diagnostics, err)
+	jsonMap, diags := s.upgradeJSONState(ctx, version, jsonMap, res)
+	resp.Diagnostics = convert.AppendProtoDiag(ctx, resp.Diagnostics, diags)
+	if diags.HasError() {
 		return resp, nil
 	}
 
@@ -625,22 +788,32 @@ func (s *GRPCProviderServer) upgradeFlatmapState(_ context.Context, version int,
 	return jsonMap, upgradedVersion, err
 }
 
-func (s *GRPCProviderServer) upgradeJSONState(ctx context.Context, version int, m map[string]interface{}, res *Resource) (map[string]interface{}, error) {
-	var err error
+func (s *GRPCProviderServer) upgradeJSONState(ctx context.Context, version int, m map[string]interface{}, res *Resource) (map[string]interface{}, diag.Diagnostics) {
+	var diags diag.Diagnostics
 
 	for _, upgrader := range res.StateUpgraders {
 		if version != upgrader.Version {
 			continue
 		}
 
-		m, err = upgrader.Upgrade(ctx, m, s.provider.Meta())
-		if err != nil {
-			return nil, err
+		if upgrader.UpgradeWithDiags != nil {
+			var upgradeDiags diag.Diagnostics
+			m, upgradeDiags = upgrader.UpgradeWithDiags(ctx, m, s.provider.Meta())
+			diags = append(diags, upgradeDiags...)
+			if diags.HasError() {
+				return nil, diags
+			}
+		} else {
+			var err error
+			m, err = upgrader.Upgrade(ctx, m, s.provider.Meta())
+			if err != nil {
+				return nil, append(diags, diag.FromErr(err)...)
+			}
 		}
 		version++
 	}
 
-	return m, nil
+	return m, diags
 }
 
 // Remove any attributes no longer present in the schema, so that the json can
@@ -700,22 +873,41 @@ func (s *GRPCProviderServer) StopProvider(ctx context.Context, _ *tfprotov5.Stop
 	logging.HelperSchemaTrace(ctx, "Stopping provider")
 
 	s.stopMu.Lock()
-	defer s.stopMu.Unlock()
 
 	// stop
 	close(s.stopCh)
 	// reset the stop signal
 	s.stopCh = make(chan struct{})
 
+	s.stopMu.Unlock()
+
+	resp := &tfprotov5.StopProviderResponse{}
+
+	if s.provider.StopFunc != nil {
+		stopCtx, cancel := context.WithTimeout(ctx, stopFuncTimeout)
+		defer cancel()
+
+		logging.HelperSchemaTrace(ctx, "Calling downstream")
+		err := s.provider.StopFunc(stopCtx)
+		logging.HelperSchemaTrace(ctx, "Called downstream")
+
+		if err != nil {
+			resp.Error = err.Error()
+		}
+	}
+
 	logging.HelperSchemaTrace(ctx, "Stopped provider")
 
-	return &tfprotov5.StopProviderResponse{}, nil
+	return resp, nil
 }
 
 func (s *GRPCProviderServer) ConfigureProvider(ctx context.Context, req *tfprotov5.ConfigureProviderRequest) (*tfprotov5.ConfigureProviderResponse, error) {
 	ctx = logging.InitContext(ctx)
 	resp := &tfprotov5.ConfigureProviderResponse{}
 
+	ctx = s.diagnosticsMiddlewareContext(ctx)
+	defer s.applyDiagnosticsMiddleware(ctx, &resp.Diagnostics)
+
 	schemaBlock := s.getProviderSchemaBlock()
 
 	configVal, err := msgpack.Unmarshal(req.Config.MsgPack, schemaBlock.ImpliedType())
@@ -827,6 +1019,9 @@ func (s *GRPCProviderServer) ReadResource(ctx context.Context, req *tfprotov5.Re
 		Private: reqPrivate,
 	}
 
+	ctx = s.diagnosticsMiddlewareContext(ctx)
+	defer s.applyDiagnosticsMiddleware(ctx, &resp.Diagnostics)
+
 	res, ok := s.provider.ResourcesMap[req.TypeName]
 	if !ok {
 		resp.Diagnostics = convert.AppendProtoDiag(ctx, resp.Diagnostics, fmt.Errorf("unknown resource type: %s", req.TypeName))
@@ -905,7 +1100,14 @@ func (s *GRPCProviderServer) ReadResource(ctx context.Context, req *tfprotov5.Re
 		instanceState.ProviderMeta = providerSchemaVal
 	}
 
-	newInstanceState, diags := res.RefreshWithoutUpgrade(ctx, instanceState, s.provider.Meta())
+	// The read following ImportResourceState completes the import rather
+	// than refreshing an existing resource.
+	readCtx := ctx
+	if !readFollowingImport {
+		readCtx = context.WithValue(ctx, RefreshContextKey, true)
+	}
+
+	newInstanceState, diags := res.RefreshWithoutUpgrade(readCtx, instanceState, s.provider.Meta())
 	resp.Diagnostics = convert.AppendProtoDiag(ctx, resp.Diagnostics, diags)
 	if diags.HasError() {
 		return resp, nil
@@ -928,6 +1130,14 @@ func (s *GRPCProviderServer) ReadResource(ctx context.Context, req *tfprotov5.Re
 	// helper/schema should always copy the ID over, but do it again just to be safe
 	newInstanceState.Attributes["id"] = newInstanceState.ID
 
+	// Persist any private data written with ResourceData SetPrivate.
+	newPrivate, err := mergePrivateData(resp.Private, newInstanceState.Meta)
+	if err != nil {
+		resp.Diagnostics = convert.AppendProtoDiag(ctx, resp.Diagnostics, err)
+		return resp, nil
+	}
+	resp.Private = newPrivate
+
 	newStateVal, err := hcl2shim.HCL2ValueFromFlatmap(newInstanceState.Attributes, schemaBlock.ImpliedType())
 	if err != nil {
 		resp.Diagnostics = convert.AppendProtoDiag(ctx, resp.Diagnostics, err)
@@ -990,6 +1200,9 @@ func (s *GRPCProviderServer) PlanResourceChange(ctx context.Context, req *tfprot
 	ctx = logging.InitContext(ctx)
 	resp := &tfprotov5.PlanResourceChangeResponse{}
 
+	ctx = s.diagnosticsMiddlewareContext(ctx)
+	defer s.applyDiagnosticsMiddleware(ctx, &resp.Diagnostics)
+
 	res, ok := s.provider.ResourcesMap[req.TypeName]
 	if !ok {
 		resp.Diagnostics = convert.AppendProtoDiag(ctx, resp.Diagnostics, fmt.Errorf("unknown resource type: %s", req.TypeName))
@@ -1064,6 +1277,7 @@ func (s *GRPCProviderServer) PlanResourceChange(ctx context.Context, req *tfprot
 	priorState.RawState = priorStateVal
 	priorState.RawPlan = proposedNewStateVal
 	priorState.RawConfig = configVal
+	priorState.RawConfigMsgPack = req.Config.MsgPack
 	priorPrivate := make(map[string]interface{})
 	if len(req.PriorPrivate) > 0 {
 		if err := json.Unmarshal(req.PriorPrivate, &priorPrivate); err != nil {
@@ -1115,14 +1329,50 @@ func (s *GRPCProviderServer) PlanResourceChange(ctx context.Context, req *tfprot
 		priorState.Identity = identityAttrs
 	}
 
-	diff, err := res.SimpleDiff(ctx, priorState, cfg, s.provider.Meta())
+	deferral := &planDeferral{}
+	var customizeDiffDiags diag.Diagnostics
+
+	diffCtx := context.WithValue(ctx, planDeferralContextKey, deferral)
+	diffCtx = context.WithValue(diffCtx, customizeDiffDiagnosticsContextKey, &customizeDiffDiags)
+
+	diff, err := res.SimpleDiff(diffCtx, priorState, cfg, s.provider.Meta())
+	resp.Diagnostics = convert.AppendProtoDiag(ctx, resp.Diagnostics, customizeDiffDiags)
 	if err != nil {
-		resp.Diagnostics = convert.AppendProtoDiag(ctx, resp.Diagnostics, err)
+		// error diagnostics of CustomizeDiffContext were appended above
+		if !errors.Is(err, errCustomizeDiffDiagnostics) {
+			resp.Diagnostics = convert.AppendProtoDiag(ctx, resp.Diagnostics, err)
+		}
 		return resp, nil
 	}
 
-	// if this is a new instance, we need to make sure ID is going to be computed
-	if create {
+	// CustomizeDiff deferred the change with ResourceDiff Defer
+	if deferral.deferred != nil {
+		if !planDeferralAllowed(req.ClientCapabilities) {
+			resp.Diagnostics = append(resp.Diagnostics, &tfprotov5.Diagnostic{
+				Severity: tfprotov5.DiagnosticSeverityError,
+				Summary:  "Invalid Deferred Resource Response",
+				Detail: "Resource deferred its planned change but the Terraform request " +
+					"did not indicate support for deferred actions. This is an issue with the provider and should be reported to the provider developers.",
+			})
+			return resp, nil
+		}
+
+		logging.HelperSchemaDebug(
+			ctx,
+			"Resource deferred its planned change, returning deferred response with the plan.",
+			map[string]interface{}{
+				logging.KeyDeferredReason: deferral.deferred.Reason.String(),
+			},
+		)
+
+		resp.Deferred = &tfprotov5.Deferred{
+			Reason: tfprotov5.DeferredReason(deferral.deferred.Reason),
+		}
+	}
+
+	// if this is a new instance, we need to make sure ID is going to be computed,
+	// unless the resource declares its own non-computed "id" attribute
+	if create && (!res.DisableImplicitID || schemaBlock.Attributes["id"] == nil || schemaBlock.Attributes["id"].Computed) {
 		if diff == nil {
 			diff = terraform.NewInstanceDiff()
 		}
@@ -1227,6 +1477,14 @@ func (s *GRPCProviderServer) PlanResourceChange(ctx context.Context, req *tfprot
 		privateMap = map[string]interface{}{}
 	}
 
+	// Carry over the private data written with ResourceData SetPrivate, so
+	// it is available during apply.
+	if v, ok := priorPrivate[privateDataKey]; ok {
+		if _, ok := privateMap[privateDataKey]; !ok {
+			privateMap[privateDataKey] = v
+		}
+	}
+
 	newExtra := map[string]interface{}{}
 
 	for k, v := range diff.Attributes {
@@ -1339,6 +1597,9 @@ func (s *GRPCProviderServer) ApplyResourceChange(ctx context.Context, req *tfpro
 		NewState: req.PriorState,
 	}
 
+	ctx = s.diagnosticsMiddlewareContext(ctx)
+	defer s.applyDiagnosticsMiddleware(ctx, &resp.Diagnostics)
+
 	res, ok := s.provider.ResourcesMap[req.TypeName]
 	if !ok {
 		resp.Diagnostics = convert.AppendProtoDiag(ctx, resp.Diagnostics, fmt.Errorf("unknown resource type: %s", req.TypeName))
@@ -1437,6 +1698,7 @@ func (s *GRPCProviderServer) ApplyResourceChange(ctx context.Context, req *tfpro
 	} else {
 		diff.Identity = priorState.Identity
 	}
+	diff.RawConfigMsgPack = req.Config.MsgPack
 
 	// add NewExtra Fields that may have been stored in the private data
 	if newExtra := private[newExtraKey]; newExtra != nil {
@@ -1587,6 +1849,9 @@ func (s *GRPCProviderServer) ImportResourceState(ctx context.Context, req *tfpro
 	ctx = logging.InitContext(ctx)
 	resp := &tfprotov5.ImportResourceStateResponse{}
 
+	ctx = s.diagnosticsMiddlewareContext(ctx)
+	defer s.applyDiagnosticsMiddleware(ctx, &resp.Diagnostics)
+
 	info := &terraform.InstanceInfo{
 		Type: req.TypeName,
 	}
@@ -1653,13 +1918,9 @@ func (s *GRPCProviderServer) ImportResourceState(ctx context.Context, req *tfpro
 		identity = hcl2shim.FlatmapValueFromHCL2(identityVal)
 	}
 
-	newInstanceStates, err := s.provider.ImportStateWithIdentity(ctx, info, req.ID, identity)
-	if err != nil {
-		resp.Diagnostics = convert.AppendProtoDiag(ctx, resp.Diagnostics, err)
-		return resp, nil
-	}
-
-	for _, is := range newInstanceStates {
+	// Each imported state is converted as soon as it is emitted, so importers
+	// using StreamStateContext never have all of them in memory at once.
+	err := s.provider.importStateWithIdentity(ctx, info, req.ID, identity, func(is *terraform.InstanceState) error {
 		// copy the ID again just to be sure it wasn't missed
 		is.Attributes["id"] = is.ID
 
@@ -1671,8 +1932,7 @@ func (s *GRPCProviderServer) ImportResourceState(ctx context.Context, req *tfpro
 		schemaBlock := s.getResourceSchemaBlock(resourceType)
 		newStateVal, err := hcl2shim.HCL2ValueFromFlatmap(is.Attributes, schemaBlock.ImpliedType())
 		if err != nil {
-			resp.Diagnostics = convert.AppendProtoDiag(ctx, resp.Diagnostics, err)
-			return resp, nil
+			return err
 		}
 
 		// Normalize the value and fill in any missing blocks.
@@ -1699,8 +1959,7 @@ func (s *GRPCProviderServer) ImportResourceState(ctx context.Context, req *tfpro
 
 		newStateMP, err := msgpack.Marshal(newStateVal, schemaBlock.ImpliedType())
 		if err != nil {
-			resp.Diagnostics = convert.AppendProtoDiag(ctx, resp.Diagnostics, err)
-			return resp, nil
+			return err
 		}
 
 		// Set an internal private field that will get sent alongside the imported resource. This will be cleared by
@@ -1709,28 +1968,24 @@ func (s *GRPCProviderServer) ImportResourceState(ctx context.Context, req *tfpro
 
 		meta, err := json.Marshal(is.Meta)
 		if err != nil {
-			resp.Diagnostics = convert.AppendProtoDiag(ctx, resp.Diagnostics, err)
-			return resp, nil
+			return err
 		}
 
 		var identityData *tfprotov5.ResourceIdentityData
 		if is.Identity != nil {
 			identityBlock, err := s.getResourceIdentitySchemaBlock(resourceType)
 			if err != nil {
-				resp.Diagnostics = convert.AppendProtoDiag(ctx, resp.Diagnostics, fmt.Errorf("getting identity schema failed for resource '%s': %w", req.TypeName, err))
-				return resp, nil
+				return fmt.Errorf("getting identity schema failed for resource '%s': %w", req.TypeName, err)
 			}
 
 			newIdentityVal, err := hcl2shim.HCL2ValueFromFlatmap(is.Identity, identityBlock.ImpliedType())
 			if err != nil {
-				resp.Diagnostics = convert.AppendProtoDiag(ctx, resp.Diagnostics, err)
-				return resp, nil
+				return err
 			}
 
 			newIdentityMP, err := msgpack.Marshal(newIdentityVal, identityBlock.ImpliedType())
 			if err != nil {
-				resp.Diagnostics = convert.AppendProtoDiag(ctx, resp.Diagnostics, err)
-				return resp, nil
+				return err
 			}
 
 			identityData = &tfprotov5.ResourceIdentityData{
@@ -1750,6 +2005,13 @@ func (s *GRPCProviderServer) ImportResourceState(ctx context.Context, req *tfpro
 		}
 
 		resp.ImportedResources = append(resp.ImportedResources, importedResource)
+
+		return nil
+	})
+	if err != nil {
+		resp.ImportedResources = nil
+		resp.Diagnostics = convert.AppendProtoDiag(ctx, resp.Diagnostics, err)
+		return resp, nil
 	}
 
 	return resp, nil
@@ -1766,6 +2028,9 @@ func (s *GRPCProviderServer) MoveResourceState(ctx context.Context, req *tfproto
 
 	resp := &tfprotov5.MoveResourceStateResponse{}
 
+	ctx = s.diagnosticsMiddlewareContext(ctx)
+	defer s.applyDiagnosticsMiddleware(ctx, &resp.Diagnostics)
+
 	_, ok := s.provider.ResourcesMap[req.TargetTypeName]
 
 	if !ok {
@@ -1795,6 +2060,9 @@ func (s *GRPCProviderServer) ReadDataSource(ctx context.Context, req *tfprotov5.
 	ctx = logging.InitContext(ctx)
 	resp := &tfprotov5.ReadDataSourceResponse{}
 
+	ctx = s.diagnosticsMiddlewareContext(ctx)
+	defer s.applyDiagnosticsMiddleware(ctx, &resp.Diagnostics)
+
 	schemaBlock := s.getDatasourceSchemaBlock(req.TypeName)
 
 	if s.provider.providerDeferred != nil {
@@ -1854,10 +2122,45 @@ func (s *GRPCProviderServer) ReadDataSource(ctx context.Context, req *tfprotov5.
 	// will return a NullVal of the schema if there is no InstanceDiff.
 	if diff != nil {
 		diff.RawConfig = configVal
+		diff.RawConfigMsgPack = req.Config.MsgPack
 	}
 
 	// now we can get the new complete data source
 	newInstanceState, diags := res.ReadDataApply(ctx, diff, s.provider.Meta())
+
+	if readDeferralAllowed(req.ClientCapabilities) {
+		if deferredDiags, otherDiags := splitDeferredDiags(diags); len(deferredDiags) > 0 {
+			logging.HelperSchemaDebug(
+				ctx,
+				"Data source read returned a deferred diagnostic, returning deferred response.",
+				map[string]interface{}{
+					logging.KeyDeferredReason: DeferredReasonAbsentPrereq.String(),
+				},
+			)
+
+			resp.Diagnostics = convert.AppendProtoDiag(ctx, resp.Diagnostics, otherDiags)
+			if otherDiags.HasError() {
+				return resp, nil
+			}
+
+			// Send an unknown value for the data source
+			unknownVal := cty.UnknownVal(schemaBlock.ImpliedType())
+			unknownStateMp, err := msgpack.Marshal(unknownVal, schemaBlock.ImpliedType())
+			if err != nil {
+				resp.Diagnostics = convert.AppendProtoDiag(ctx, resp.Diagnostics, err)
+				return resp, nil
+			}
+
+			resp.State = &tfprotov5.DynamicValue{
+				MsgPack: unknownStateMp,
+			}
+			resp.Deferred = &tfprotov5.Deferred{
+				Reason: tfprotov5.DeferredReason(DeferredReasonAbsentPrereq),
+			}
+			return resp, nil
+		}
+	}
+
 	resp.Diagnostics = convert.AppendProtoDiag(ctx, resp.Diagnostics, diags)
 	if diags.HasError() {
 		return resp, nil
@@ -1885,14 +2188,23 @@ func (s *GRPCProviderServer) ReadDataSource(ctx context.Context, req *tfprotov5.
 func (s *GRPCProviderServer) CallFunction(ctx context.Context, req *tfprotov5.CallFunctionRequest) (*tfprotov5.CallFunctionResponse, error) {
 	ctx = logging.InitContext(ctx)
 
-	logging.HelperSchemaTrace(ctx, "Returning error for provider function call")
+	resp := &tfprotov5.CallFunctionResponse{}
 
-	resp := &tfprotov5.CallFunctionResponse{
-		Error: &tfprotov5.FunctionError{
+	f, ok := s.provider.Functions[req.Name]
+	if !ok {
+		logging.HelperSchemaTrace(ctx, "Returning error for unknown provider function call")
+
+		resp.Error = &tfprotov5.FunctionError{
 			Text: fmt.Sprintf("Function Not Found: No function named %q was found in the provider.", req.Name),
-		},
+		}
+
+		return resp, nil
 	}
 
+	logging.HelperSchemaTrace(ctx, "Calling provider function")
+
+	resp.Result, resp.Error = f.call(ctx, req.Arguments)
+
 	return resp, nil
 }
 
@@ -1901,13 +2213,35 @@ func (s *GRPCProviderServer) GetFunctions(ctx context.Context, req *tfprotov5.Ge
 
 	logging.HelperSchemaTrace(ctx, "Getting provider functions")
 
-	resp := &tfprotov5.GetFunctionsResponse{
-		Functions: make(map[string]*tfprotov5.Function, 0),
-	}
+	resp := &tfprotov5.GetFunctionsResponse{}
+
+	ctx = s.diagnosticsMiddlewareContext(ctx)
+	defer s.applyDiagnosticsMiddleware(ctx, &resp.Diagnostics)
+
+	resp.Functions, resp.Diagnostics = s.functionsProto(ctx)
 
 	return resp, nil
 }
 
+// functionsProto returns the definitions of the provider functions for a
+// grpc response, along with any conversion error.
+func (s *GRPCProviderServer) functionsProto(ctx context.Context) (map[string]*tfprotov5.Function, []*tfprotov5.Diagnostic) {
+	var diags []*tfprotov5.Diagnostic
+	functions := make(map[string]*tfprotov5.Function, len(s.provider.Functions))
+
+	for name, f := range s.provider.Functions {
+		fn, err := f.proto(ctx)
+		if err != nil {
+			diags = convert.AppendProtoDiag(ctx, diags, fmt.Errorf("function %s: %w", name, err))
+			continue
+		}
+
+		functions[name] = fn
+	}
+
+	return functions, diags
+}
+
 func (s *GRPCProviderServer) ValidateEphemeralResourceConfig(ctx context.Context, req *tfprotov5.ValidateEphemeralResourceConfigRequest) (*tfprotov5.ValidateEphemeralResourceConfigResponse, error) {
 	ctx = logging.InitContext(ctx)
 
@@ -1923,6 +2257,9 @@ func (s *GRPCProviderServer) ValidateEphemeralResourceConfig(ctx context.Context
 		},
 	}
 
+	ctx = s.diagnosticsMiddlewareContext(ctx)
+	defer s.applyDiagnosticsMiddleware(ctx, &resp.Diagnostics)
+
 	return resp, nil
 }
 
@@ -1941,6 +2278,9 @@ func (s *GRPCProviderServer) OpenEphemeralResource(ctx context.Context, req *tfp
 		},
 	}
 
+	ctx = s.diagnosticsMiddlewareContext(ctx)
+	defer s.applyDiagnosticsMiddleware(ctx, &resp.Diagnostics)
+
 	return resp, nil
 }
 
@@ -1959,6 +2299,9 @@ func (s *GRPCProviderServer) RenewEphemeralResource(ctx context.Context, req *tf
 		},
 	}
 
+	ctx = s.diagnosticsMiddlewareContext(ctx)
+	defer s.applyDiagnosticsMiddleware(ctx, &resp.Diagnostics)
+
 	return resp, nil
 }
 
@@ -1977,6 +2320,9 @@ func (s *GRPCProviderServer) CloseEphemeralResource(ctx context.Context, req *tf
 		},
 	}
 
+	ctx = s.diagnosticsMiddlewareContext(ctx)
+	defer s.applyDiagnosticsMiddleware(ctx, &resp.Diagnostics)
+
 	return resp, nil
 }
 
@@ -2061,6 +2407,7 @@ func stripResourceModifiers(r *Resource) *Resource {
 	*newResource = *r
 
 	newResource.CustomizeDiff = nil
+	newResource.CustomizeDiffContext = nil
 	newResource.Schema = map[string]*Schema{}
 
 	for k, s := range r.SchemaMap() {
@@ -2327,6 +2674,36 @@ func configureDeferralAllowed(in *tfprotov5.ConfigureProviderClientCapabilities)
 	return in.DeferralAllowed
 }
 
+func planDeferralAllowed(in *tfprotov5.PlanResourceChangeClientCapabilities) bool {
+	if in == nil {
+		return false
+	}
+
+	return in.DeferralAllowed
+}
+
+func readDeferralAllowed(in *tfprotov5.ReadDataSourceClientCapabilities) bool {
+	if in == nil {
+		return false
+	}
+
+	return in.DeferralAllowed
+}
+
+// splitDeferredDiags separates the diagnostics created by diag.Deferred from
+// the other diagnostics.
+func splitDeferredDiags(diags diag.Diagnostics) (deferred diag.Diagnostics, other diag.Diagnostics) {
+	for _, d := range diags {
+		if d.IsDeferred() {
+			deferred = append(deferred, d)
+			continue
+		}
+		other = append(other, d)
+	}
+
+	return deferred, other
+}
+
 // Resource Identity version of upgradeJSONState
 func (s *GRPCProviderServer) upgradeJSONIdentity(ctx context.Context, version int64, m map[string]interface{}, res *Resource) (map[string]interface{}, error) {
 	var err error
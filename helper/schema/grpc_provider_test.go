@@ -4,7 +4,10 @@
 package schema
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"math/big"
@@ -3925,6 +3928,346 @@ func TestGRPCProviderServerMoveResourceState(t *testing.T) {
 	}
 }
 
+func TestGRPCProviderServerDiagnosticsMiddleware(t *testing.T) {
+	t.Parallel()
+
+	resource := func() *Resource {
+		return &Resource{
+			Schema: map[string]*Schema{
+				"foo": {
+					Type:     TypeString,
+					Optional: true,
+					ValidateDiagFunc: func(interface{}, cty.Path) diag.Diagnostics {
+						return diag.Diagnostics{
+							{
+								Severity: diag.Error,
+								Summary:  "invalid foo",
+							},
+						}
+					},
+				},
+			},
+		}
+	}
+
+	request := &tfprotov5.ValidateResourceTypeConfigRequest{
+		TypeName: "test_resource",
+		Config: &tfprotov5.DynamicValue{
+			MsgPack: mustMsgpackMarshal(
+				cty.Object(map[string]cty.Type{
+					"id":  cty.String,
+					"foo": cty.String,
+				}),
+				cty.ObjectVal(map[string]cty.Value{
+					"id":  cty.NullVal(cty.String),
+					"foo": cty.StringVal("bar"),
+				}),
+			),
+		},
+	}
+
+	testCases := map[string]struct {
+		middleware func(context.Context, diag.Diagnostics) diag.Diagnostics
+		expected   *tfprotov5.ValidateResourceTypeConfigResponse
+	}{
+		"no middleware": {
+			expected: &tfprotov5.ValidateResourceTypeConfigResponse{
+				Diagnostics: []*tfprotov5.Diagnostic{
+					{
+						Severity:  tfprotov5.DiagnosticSeverityError,
+						Summary:   "invalid foo",
+						Attribute: tftypes.NewAttributePath().WithAttributeName("foo"),
+					},
+				},
+			},
+		},
+		"enrich": {
+			middleware: func(_ context.Context, diags diag.Diagnostics) diag.Diagnostics {
+				for i := range diags {
+					diags[i].Detail = "enriched"
+				}
+				return append(diags, diag.Diagnostic{
+					Severity: diag.Warning,
+					Summary:  "added",
+				})
+			},
+			expected: &tfprotov5.ValidateResourceTypeConfigResponse{
+				Diagnostics: []*tfprotov5.Diagnostic{
+					{
+						Severity:  tfprotov5.DiagnosticSeverityError,
+						Summary:   "invalid foo",
+						Detail:    "enriched",
+						Attribute: tftypes.NewAttributePath().WithAttributeName("foo"),
+					},
+					{
+						Severity: tfprotov5.DiagnosticSeverityWarning,
+						Summary:  "added",
+					},
+				},
+			},
+		},
+		"suppress": {
+			middleware: func(context.Context, diag.Diagnostics) diag.Diagnostics {
+				return nil
+			},
+			expected: &tfprotov5.ValidateResourceTypeConfigResponse{},
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			server := NewGRPCProviderServer(&Provider{
+				ResourcesMap: map[string]*Resource{
+					"test_resource": resource(),
+				},
+				DiagnosticsMiddleware: testCase.middleware,
+			})
+
+			resp, err := server.ValidateResourceTypeConfig(context.Background(), request)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(resp, testCase.expected); diff != "" {
+				t.Errorf("unexpected difference: %s", diff)
+			}
+		})
+	}
+}
+
+func TestGRPCProviderServerDiagnosticsMiddleware_docURL(t *testing.T) {
+	t.Parallel()
+
+	var docURLs []string
+
+	server := NewGRPCProviderServer(&Provider{
+		ResourcesMap: map[string]*Resource{
+			"test_resource": {
+				Schema: map[string]*Schema{
+					"foo": {
+						Type:     TypeString,
+						Optional: true,
+						ValidateDiagFunc: func(interface{}, cty.Path) diag.Diagnostics {
+							return diag.Diagnostics{
+								diag.WithDocURL(diag.Diagnostic{
+									Severity: diag.Error,
+									Summary:  "invalid foo",
+									Detail:   "foo must be bar",
+								}, "https://example.com/foo"),
+							}
+						},
+					},
+				},
+			},
+		},
+		DiagnosticsMiddleware: func(_ context.Context, diags diag.Diagnostics) diag.Diagnostics {
+			for _, d := range diags {
+				docURLs = append(docURLs, d.DocURL)
+			}
+
+			return diags
+		},
+	})
+
+	resp, err := server.ValidateResourceTypeConfig(context.Background(), &tfprotov5.ValidateResourceTypeConfigRequest{
+		TypeName: "test_resource",
+		Config: &tfprotov5.DynamicValue{
+			MsgPack: mustMsgpackMarshal(
+				cty.Object(map[string]cty.Type{
+					"id":  cty.String,
+					"foo": cty.String,
+				}),
+				cty.ObjectVal(map[string]cty.Value{
+					"id":  cty.NullVal(cty.String),
+					"foo": cty.StringVal("baz"),
+				}),
+			),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The middleware receives the DocURL unchanged.
+	if diff := cmp.Diff([]string{"https://example.com/foo"}, docURLs); diff != "" {
+		t.Errorf("unexpected middleware DocURL difference: %s", diff)
+	}
+
+	// The link is appended to the Detail once.
+	expected := &tfprotov5.ValidateResourceTypeConfigResponse{
+		Diagnostics: []*tfprotov5.Diagnostic{
+			{
+				Severity:  tfprotov5.DiagnosticSeverityError,
+				Summary:   "invalid foo",
+				Detail:    "foo must be bar\n\nFor more information, see: https://example.com/foo",
+				Attribute: tftypes.NewAttributePath().WithAttributeName("foo"),
+			},
+		},
+	}
+
+	if diff := cmp.Diff(expected, resp); diff != "" {
+		t.Errorf("unexpected difference: %s", diff)
+	}
+}
+
+func TestGRPCProviderServerDiagnosticsMiddleware_unknownSeverity(t *testing.T) {
+	t.Parallel()
+
+	server := NewGRPCProviderServer(&Provider{
+		DiagnosticsMiddleware: func(_ context.Context, diags diag.Diagnostics) diag.Diagnostics {
+			return append(diags, diag.Diagnostic{
+				Severity: diag.Warning,
+				Summary:  "added",
+			})
+		},
+	})
+
+	diags := []*tfprotov5.Diagnostic{
+		{
+			Severity: tfprotov5.DiagnosticSeverityInvalid,
+			Summary:  "unknown",
+		},
+		{
+			Severity: tfprotov5.DiagnosticSeverityError,
+			Summary:  "error",
+		},
+	}
+
+	server.applyDiagnosticsMiddleware(context.Background(), &diags)
+
+	expected := []*tfprotov5.Diagnostic{
+		{
+			Severity: tfprotov5.DiagnosticSeverityError,
+			Summary:  "error",
+		},
+		{
+			Severity: tfprotov5.DiagnosticSeverityWarning,
+			Summary:  "added",
+		},
+		{
+			Severity: tfprotov5.DiagnosticSeverityInvalid,
+			Summary:  "unknown",
+		},
+	}
+
+	if diff := cmp.Diff(diags, expected); diff != "" {
+		t.Errorf("unexpected difference: %s", diff)
+	}
+}
+
+func TestGRPCProviderServerDedupDeprecationWarnings(t *testing.T) {
+	t.Parallel()
+
+	resource := func() *Resource {
+		return &Resource{
+			Schema: map[string]*Schema{
+				"foo": {
+					Type:       TypeString,
+					Optional:   true,
+					Deprecated: "use bar",
+				},
+				"rule": {
+					Type:     TypeList,
+					Optional: true,
+					Elem: &Resource{
+						Schema: map[string]*Schema{
+							"port": {
+								Type:       TypeInt,
+								Optional:   true,
+								Deprecated: "use ports",
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	ruleType := cty.Object(map[string]cty.Type{
+		"port": cty.Number,
+	})
+
+	request := &tfprotov5.ValidateResourceTypeConfigRequest{
+		TypeName: "test_resource",
+		Config: &tfprotov5.DynamicValue{
+			MsgPack: mustMsgpackMarshal(
+				cty.Object(map[string]cty.Type{
+					"id":   cty.String,
+					"foo":  cty.String,
+					"rule": cty.List(ruleType),
+				}),
+				cty.ObjectVal(map[string]cty.Value{
+					"id":  cty.NullVal(cty.String),
+					"foo": cty.StringVal("bar"),
+					"rule": cty.ListVal([]cty.Value{
+						cty.ObjectVal(map[string]cty.Value{
+							"port": cty.NumberIntVal(80),
+						}),
+						cty.ObjectVal(map[string]cty.Value{
+							"port": cty.NumberIntVal(443),
+						}),
+					}),
+				}),
+			),
+		},
+	}
+
+	testCases := map[string]struct {
+		dedup    bool
+		expected [][]string
+	}{
+		"disabled": {
+			expected: [][]string{
+				{"use bar", "use ports", "use ports"},
+				{"use bar", "use ports", "use ports"},
+			},
+		},
+		"enabled": {
+			dedup: true,
+			expected: [][]string{
+				{"use bar", "use ports"},
+				nil,
+			},
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			server := NewGRPCProviderServer(&Provider{
+				ResourcesMap: map[string]*Resource{
+					"test_resource": resource(),
+				},
+				DedupDeprecationWarnings: testCase.dedup,
+			})
+
+			var got [][]string
+
+			for range testCase.expected {
+				resp, err := server.ValidateResourceTypeConfig(context.Background(), request)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				var details []string
+				for _, d := range resp.Diagnostics {
+					details = append(details, d.Detail)
+				}
+
+				sort.Strings(details)
+				got = append(got, details)
+			}
+
+			if diff := cmp.Diff(testCase.expected, got); diff != "" {
+				t.Errorf("unexpected difference: %s", diff)
+			}
+		})
+	}
+}
+
 func TestGRPCProviderServerValidateResourceTypeConfig(t *testing.T) {
 	t.Parallel()
 
@@ -4387,50 +4730,242 @@ func TestGRPCProviderServerValidateResourceTypeConfig(t *testing.T) {
 				},
 			},
 		},
-	}
-
-	for name, testCase := range testCases {
-		t.Run(name, func(t *testing.T) {
-			t.Parallel()
-
-			resp, err := testCase.server.ValidateResourceTypeConfig(context.Background(), testCase.request)
-
-			if testCase.request != nil && err != nil {
-				t.Fatalf("unexpected error: %s", err)
-			}
-
-			if diff := cmp.Diff(resp, testCase.expected); diff != "" {
-				t.Errorf("unexpected difference: %s", diff)
-			}
-		})
-	}
-}
-
-func TestUpgradeState_jsonState(t *testing.T) {
-	r := &Resource{
-		SchemaVersion: 2,
-		Schema: map[string]*Schema{
-			"two": {
-				Type:     TypeInt,
-				Optional: true,
-			},
-		},
-	}
 
-	r.StateUpgraders = []StateUpgrader{
-		{
-			Version: 0,
-			Type: cty.Object(map[string]cty.Type{
-				"id":   cty.String,
-				"zero": cty.Number,
+		"TypeSet MaxItems counts elements with unknown values": {
+			server: NewGRPCProviderServer(&Provider{
+				ResourcesMap: map[string]*Resource{
+					"test_resource": {
+						Schema: map[string]*Schema{
+							"rule": {
+								Type:     TypeSet,
+								Optional: true,
+								MaxItems: 1,
+								Elem: &Resource{
+									Schema: map[string]*Schema{
+										"name": {
+											Type:     TypeString,
+											Optional: true,
+										},
+									},
+								},
+							},
+						},
+					},
+				},
 			}),
-			Upgrade: func(ctx context.Context, m map[string]interface{}, meta interface{}) (map[string]interface{}, error) {
-				_, ok := m["zero"].(float64)
-				if !ok {
-					return nil, fmt.Errorf("zero not found in %#v", m)
-				}
-				m["one"] = float64(1)
-				delete(m, "zero")
+			request: &tfprotov5.ValidateResourceTypeConfigRequest{
+				TypeName: "test_resource",
+				Config: &tfprotov5.DynamicValue{
+					MsgPack: mustMsgpackMarshal(
+						cty.Object(map[string]cty.Type{
+							"id": cty.String,
+							"rule": cty.Set(cty.Object(map[string]cty.Type{
+								"name": cty.String,
+							})),
+						}),
+						cty.ObjectVal(map[string]cty.Value{
+							"id": cty.NullVal(cty.String),
+							"rule": cty.SetVal([]cty.Value{
+								cty.ObjectVal(map[string]cty.Value{"name": cty.UnknownVal(cty.String)}),
+								cty.ObjectVal(map[string]cty.Value{"name": cty.UnknownVal(cty.String)}),
+							}),
+						}),
+					),
+				},
+			},
+			expected: &tfprotov5.ValidateResourceTypeConfigResponse{
+				Diagnostics: []*tfprotov5.Diagnostic{
+					{
+						Severity:  tfprotov5.DiagnosticSeverityError,
+						Summary:   "Too many list items",
+						Detail:    "Attribute rule supports 1 item maximum, but config has 2 declared.",
+						Attribute: tftypes.NewAttributePath().WithAttributeName("rule"),
+					},
+				},
+			},
+		},
+		"TypeSet MinItems counts elements with unknown values": {
+			server: NewGRPCProviderServer(&Provider{
+				ResourcesMap: map[string]*Resource{
+					"test_resource": {
+						Schema: map[string]*Schema{
+							"rule": {
+								Type:     TypeSet,
+								Optional: true,
+								MinItems: 2,
+								Elem: &Resource{
+									Schema: map[string]*Schema{
+										"name": {
+											Type:     TypeString,
+											Optional: true,
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			}),
+			request: &tfprotov5.ValidateResourceTypeConfigRequest{
+				TypeName: "test_resource",
+				Config: &tfprotov5.DynamicValue{
+					MsgPack: mustMsgpackMarshal(
+						cty.Object(map[string]cty.Type{
+							"id": cty.String,
+							"rule": cty.Set(cty.Object(map[string]cty.Type{
+								"name": cty.String,
+							})),
+						}),
+						cty.ObjectVal(map[string]cty.Value{
+							"id": cty.NullVal(cty.String),
+							"rule": cty.SetVal([]cty.Value{
+								cty.ObjectVal(map[string]cty.Value{"name": cty.UnknownVal(cty.String)}),
+								cty.ObjectVal(map[string]cty.Value{"name": cty.UnknownVal(cty.String)}),
+							}),
+						}),
+					),
+				},
+			},
+			expected: &tfprotov5.ValidateResourceTypeConfigResponse{},
+		},
+		"TypeSet MinItems with unknown elements below minimum": {
+			server: NewGRPCProviderServer(&Provider{
+				ResourcesMap: map[string]*Resource{
+					"test_resource": {
+						Schema: map[string]*Schema{
+							"rule": {
+								Type:     TypeSet,
+								Optional: true,
+								MinItems: 2,
+								Elem: &Resource{
+									Schema: map[string]*Schema{
+										"name": {
+											Type:     TypeString,
+											Optional: true,
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			}),
+			request: &tfprotov5.ValidateResourceTypeConfigRequest{
+				TypeName: "test_resource",
+				Config: &tfprotov5.DynamicValue{
+					MsgPack: mustMsgpackMarshal(
+						cty.Object(map[string]cty.Type{
+							"id": cty.String,
+							"rule": cty.Set(cty.Object(map[string]cty.Type{
+								"name": cty.String,
+							})),
+						}),
+						cty.ObjectVal(map[string]cty.Value{
+							"id": cty.NullVal(cty.String),
+							"rule": cty.SetVal([]cty.Value{
+								cty.ObjectVal(map[string]cty.Value{"name": cty.UnknownVal(cty.String)}),
+							}),
+						}),
+					),
+				},
+			},
+			expected: &tfprotov5.ValidateResourceTypeConfigResponse{
+				Diagnostics: []*tfprotov5.Diagnostic{
+					{
+						Severity:  tfprotov5.DiagnosticSeverityError,
+						Summary:   "Not enough list items",
+						Detail:    "Attribute rule requires 2 item minimum, but config has only 1 declared.",
+						Attribute: tftypes.NewAttributePath().WithAttributeName("rule"),
+					},
+				},
+			},
+		},
+		"TypeSet MinItems skipped for wholly unknown set": {
+			server: NewGRPCProviderServer(&Provider{
+				ResourcesMap: map[string]*Resource{
+					"test_resource": {
+						Schema: map[string]*Schema{
+							"rule": {
+								Type:     TypeSet,
+								Optional: true,
+								MinItems: 2,
+								Elem: &Resource{
+									Schema: map[string]*Schema{
+										"name": {
+											Type:     TypeString,
+											Optional: true,
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			}),
+			request: &tfprotov5.ValidateResourceTypeConfigRequest{
+				TypeName: "test_resource",
+				Config: &tfprotov5.DynamicValue{
+					MsgPack: mustMsgpackMarshal(
+						cty.Object(map[string]cty.Type{
+							"id": cty.String,
+							"rule": cty.Set(cty.Object(map[string]cty.Type{
+								"name": cty.String,
+							})),
+						}),
+						cty.ObjectVal(map[string]cty.Value{
+							"id": cty.NullVal(cty.String),
+							"rule": cty.UnknownVal(cty.Set(cty.Object(map[string]cty.Type{
+								"name": cty.String,
+							}))),
+						}),
+					),
+				},
+			},
+			expected: &tfprotov5.ValidateResourceTypeConfigResponse{},
+		}}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			resp, err := testCase.server.ValidateResourceTypeConfig(context.Background(), testCase.request)
+
+			if testCase.request != nil && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if diff := cmp.Diff(resp, testCase.expected); diff != "" {
+				t.Errorf("unexpected difference: %s", diff)
+			}
+		})
+	}
+}
+
+func TestUpgradeState_jsonState(t *testing.T) {
+	r := &Resource{
+		SchemaVersion: 2,
+		Schema: map[string]*Schema{
+			"two": {
+				Type:     TypeInt,
+				Optional: true,
+			},
+		},
+	}
+
+	r.StateUpgraders = []StateUpgrader{
+		{
+			Version: 0,
+			Type: cty.Object(map[string]cty.Type{
+				"id":   cty.String,
+				"zero": cty.Number,
+			}),
+			Upgrade: func(ctx context.Context, m map[string]interface{}, meta interface{}) (map[string]interface{}, error) {
+				_, ok := m["zero"].(float64)
+				if !ok {
+					return nil, fmt.Errorf("zero not found in %#v", m)
+				}
+				m["one"] = float64(1)
+				delete(m, "zero")
 				return m, nil
 			},
 		},
@@ -4493,6 +5028,120 @@ func TestUpgradeState_jsonState(t *testing.T) {
 	}
 }
 
+func TestUpgradeState_upgradeWithDiags(t *testing.T) {
+	newResource := func(upgradeDiags diag.Diagnostics) *Resource {
+		return &Resource{
+			SchemaVersion: 1,
+			Schema: map[string]*Schema{
+				"one": {
+					Type:     TypeInt,
+					Optional: true,
+				},
+			},
+			StateUpgraders: []StateUpgrader{
+				{
+					Version: 0,
+					Type: cty.Object(map[string]cty.Type{
+						"id":   cty.String,
+						"zero": cty.Number,
+					}),
+					UpgradeWithDiags: func(ctx context.Context, m map[string]interface{}, meta interface{}) (map[string]interface{}, diag.Diagnostics) {
+						m["one"] = float64(1)
+						delete(m, "zero")
+						return m, upgradeDiags
+					},
+				},
+			},
+		}
+	}
+
+	cases := map[string]struct {
+		Diags         diag.Diagnostics
+		ExpectedDiags []*tfprotov5.Diagnostic
+		ExpectedState cty.Value
+	}{
+		"no diagnostics": {
+			ExpectedState: cty.ObjectVal(map[string]cty.Value{
+				"id":  cty.StringVal("bar"),
+				"one": cty.NumberIntVal(1),
+			}),
+		},
+		"warning": {
+			Diags: diag.Diagnostics{
+				{
+					Severity: diag.Warning,
+					Summary:  "Attribute dropped during upgrade",
+					Detail:   "Attribute zero was dropped during upgrade; please review.",
+				},
+			},
+			ExpectedDiags: []*tfprotov5.Diagnostic{
+				{
+					Severity: tfprotov5.DiagnosticSeverityWarning,
+					Summary:  "Attribute dropped during upgrade",
+					Detail:   "Attribute zero was dropped during upgrade; please review.",
+				},
+			},
+			ExpectedState: cty.ObjectVal(map[string]cty.Value{
+				"id":  cty.StringVal("bar"),
+				"one": cty.NumberIntVal(1),
+			}),
+		},
+		"error": {
+			Diags: diag.Errorf("upgrade failed"),
+			ExpectedDiags: []*tfprotov5.Diagnostic{
+				{
+					Severity: tfprotov5.DiagnosticSeverityError,
+					Summary:  "upgrade failed",
+				},
+			},
+			ExpectedState: cty.NilVal,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			r := newResource(tc.Diags)
+
+			server := NewGRPCProviderServer(&Provider{
+				ResourcesMap: map[string]*Resource{
+					"test": r,
+				},
+			})
+
+			resp, err := server.UpgradeResourceState(context.Background(), &tfprotov5.UpgradeResourceStateRequest{
+				TypeName: "test",
+				Version:  0,
+				RawState: &tfprotov5.RawState{
+					JSON: []byte(`{"id":"bar","zero":0}`),
+				},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(tc.ExpectedDiags, resp.Diagnostics); diff != "" {
+				t.Fatalf("unexpected diagnostics difference: %s", diff)
+			}
+
+			if tc.ExpectedState == cty.NilVal {
+				if resp.UpgradedState != nil {
+					t.Fatalf("expected no upgraded state, got %#v", resp.UpgradedState)
+				}
+				return
+			}
+
+			val, err := msgpack.Unmarshal(resp.UpgradedState.MsgPack, r.CoreConfigSchema().ImpliedType())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !cmp.Equal(tc.ExpectedState, val, valueComparer, equateEmpty) {
+				t.Fatal(cmp.Diff(tc.ExpectedState, val, valueComparer, equateEmpty))
+			}
+		})
+	}
+}
+
 func TestUpgradeState_jsonStateBigInt(t *testing.T) {
 	r := &Resource{
 		UseJSONNumber: true,
@@ -4546,22 +5195,117 @@ func TestUpgradeState_jsonStateBigInt(t *testing.T) {
 	}
 }
 
-func TestUpgradeState_removedAttr(t *testing.T) {
-	r1 := &Resource{
-		Schema: map[string]*Schema{
-			"two": {
-				Type:     TypeString,
-				Optional: true,
-			},
+func TestUpgradeState_schemaVersionMissingBehavior(t *testing.T) {
+	t.Parallel()
+
+	upgrader := StateUpgrader{
+		Version: 0,
+		Type:    cty.Object(map[string]cty.Type{"id": cty.String}),
+		Upgrade: func(ctx context.Context, m map[string]interface{}, meta interface{}) (map[string]interface{}, error) {
+			return m, nil
 		},
 	}
 
-	r2 := &Resource{
-		Schema: map[string]*Schema{
-			"multi": {
-				Type:     TypeSet,
-				Optional: true,
-				Elem: &Resource{
+	cases := map[string]struct {
+		behavior      SchemaVersionMissingBehavior
+		version       int64
+		upgraders     []StateUpgrader
+		expectedError bool
+	}{
+		"assume zero": {
+			behavior: SchemaVersionMissingAssumeZero,
+			version:  0,
+		},
+		"assume zero newer version": {
+			behavior: SchemaVersionMissingAssumeZero,
+			version:  2,
+		},
+		"error missing version": {
+			behavior:      SchemaVersionMissingError,
+			version:       0,
+			expectedError: true,
+		},
+		"error missing version with upgrader": {
+			behavior:  SchemaVersionMissingError,
+			version:   0,
+			upgraders: []StateUpgrader{upgrader},
+		},
+		"error current version": {
+			behavior: SchemaVersionMissingError,
+			version:  1,
+		},
+		"error newer version": {
+			behavior:      SchemaVersionMissingError,
+			version:       2,
+			expectedError: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			r := &Resource{
+				SchemaVersion:                1,
+				SchemaVersionMissingBehavior: tc.behavior,
+				StateUpgraders:               tc.upgraders,
+				Schema:                       map[string]*Schema{},
+			}
+
+			server := NewGRPCProviderServer(&Provider{
+				ResourcesMap: map[string]*Resource{
+					"test": r,
+				},
+			})
+
+			req := &tfprotov5.UpgradeResourceStateRequest{
+				TypeName: "test",
+				Version:  tc.version,
+				RawState: &tfprotov5.RawState{
+					JSON: []byte(`{"id":"bar"}`),
+				},
+			}
+
+			resp, err := server.UpgradeResourceState(context.Background(), req)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if hasError := len(resp.Diagnostics) > 0; hasError != tc.expectedError {
+				t.Fatalf("expected error %t, got diagnostics: %#v", tc.expectedError, resp.Diagnostics)
+			}
+
+			if tc.expectedError {
+				if resp.UpgradedState != nil {
+					t.Fatalf("expected no upgraded state, got %#v", resp.UpgradedState)
+				}
+
+				return
+			}
+
+			if resp.UpgradedState == nil {
+				t.Fatal("expected upgraded state")
+			}
+		})
+	}
+}
+
+func TestUpgradeState_removedAttr(t *testing.T) {
+	r1 := &Resource{
+		Schema: map[string]*Schema{
+			"two": {
+				Type:     TypeString,
+				Optional: true,
+			},
+		},
+	}
+
+	r2 := &Resource{
+		Schema: map[string]*Schema{
+			"multi": {
+				Type:     TypeSet,
+				Optional: true,
+				Elem: &Resource{
 					Schema: map[string]*Schema{
 						"set": {
 							Type:     TypeSet,
@@ -5979,63 +6723,537 @@ New Identity: cty.ObjectVal(map[string]cty.Value{"identity":cty.StringVal("chang
 							"test": cty.String,
 						}),
 						cty.ObjectVal(map[string]cty.Value{
-							"id":   cty.StringVal("initial"),
-							"test": cty.StringVal("hello"),
+							"id":   cty.StringVal("initial"),
+							"test": cty.StringVal("hello"),
+						}),
+					),
+				},
+				Private: []byte(`{"user_defined_key":"user_defined_value"}`),
+				NewIdentity: &tfprotov5.ResourceIdentityData{
+					IdentityData: &tfprotov5.DynamicValue{
+						MsgPack: mustMsgpackMarshal(
+							cty.Object(map[string]cty.Type{
+								"identity": cty.String,
+							}),
+							cty.ObjectVal(map[string]cty.Value{
+								"identity": cty.StringVal("changed"),
+							}),
+						),
+					},
+				},
+			},
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			resp, err := testCase.server.ReadResource(context.Background(), testCase.req)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(resp, testCase.expected, valueComparer); diff != "" {
+				ty := testCase.server.getResourceSchemaBlock("test").ImpliedType()
+
+				if resp != nil && resp.NewState != nil {
+					t.Logf("resp.NewState.MsgPack: %s", mustMsgpackUnmarshal(ty, resp.NewState.MsgPack))
+				}
+
+				if testCase.expected != nil && testCase.expected.NewState != nil {
+					t.Logf("expected: %s", mustMsgpackUnmarshal(ty, testCase.expected.NewState.MsgPack))
+				}
+
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestReadResource_isRefresh(t *testing.T) {
+	t.Parallel()
+
+	var refreshes []bool
+
+	read := func(ctx context.Context, d *ResourceData, meta interface{}) diag.Diagnostics {
+		refreshes = append(refreshes, IsRefresh(ctx))
+		return nil
+	}
+
+	r := &Resource{
+		Schema: map[string]*Schema{
+			"foo": {
+				Type:     TypeString,
+				Optional: true,
+			},
+		},
+		CreateContext: func(ctx context.Context, d *ResourceData, meta interface{}) diag.Diagnostics {
+			d.SetId("bar")
+			return read(ctx, d, meta)
+		},
+		ReadContext:   read,
+		DeleteContext: NoopContext,
+	}
+
+	server := NewGRPCProviderServer(&Provider{
+		ResourcesMap: map[string]*Resource{
+			"test": r,
+		},
+	})
+
+	ty := r.CoreConfigSchema().ImpliedType()
+
+	mustMarshal := func(v cty.Value) *tfprotov5.DynamicValue {
+		t.Helper()
+
+		b, err := msgpack.Marshal(v, ty)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return &tfprotov5.DynamicValue{MsgPack: b}
+	}
+
+	applyResp, err := server.ApplyResourceChange(context.Background(), &tfprotov5.ApplyResourceChangeRequest{
+		TypeName:   "test",
+		PriorState: mustMarshal(cty.NullVal(ty)),
+		PlannedState: mustMarshal(cty.ObjectVal(map[string]cty.Value{
+			"id":  cty.UnknownVal(cty.String),
+			"foo": cty.StringVal("a"),
+		})),
+		Config: mustMarshal(cty.ObjectVal(map[string]cty.Value{
+			"id":  cty.NullVal(cty.String),
+			"foo": cty.StringVal("a"),
+		})),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(applyResp.Diagnostics) > 0 {
+		t.Fatalf("unexpected create diagnostics: %#v", applyResp.Diagnostics)
+	}
+
+	readResp, err := server.ReadResource(context.Background(), &tfprotov5.ReadResourceRequest{
+		TypeName: "test",
+		CurrentState: mustMarshal(cty.ObjectVal(map[string]cty.Value{
+			"id":  cty.StringVal("bar"),
+			"foo": cty.StringVal("a"),
+		})),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(readResp.Diagnostics) > 0 {
+		t.Fatalf("unexpected read diagnostics: %#v", readResp.Diagnostics)
+	}
+
+	importPrivate, err := json.Marshal(map[string]interface{}{
+		terraform.ImportBeforeReadMetaKey: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The read following an import is not a refresh.
+	readResp, err = server.ReadResource(context.Background(), &tfprotov5.ReadResourceRequest{
+		TypeName: "test",
+		CurrentState: mustMarshal(cty.ObjectVal(map[string]cty.Value{
+			"id":  cty.StringVal("bar"),
+			"foo": cty.NullVal(cty.String),
+		})),
+		Private: importPrivate,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(readResp.Diagnostics) > 0 {
+		t.Fatalf("unexpected read diagnostics: %#v", readResp.Diagnostics)
+	}
+
+	if diff := cmp.Diff(refreshes, []bool{false, true, false}); diff != "" {
+		t.Fatalf("unexpected refreshes: %s", diff)
+	}
+}
+
+func TestPlanResourceChange(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		server   *GRPCProviderServer
+		req      *tfprotov5.PlanResourceChangeRequest
+		expected *tfprotov5.PlanResourceChangeResponse
+	}{
+		"basic-plan": {
+			server: NewGRPCProviderServer(&Provider{
+				ResourcesMap: map[string]*Resource{
+					"test": {
+						SchemaVersion: 4,
+						Schema: map[string]*Schema{
+							"foo": {
+								Type:     TypeInt,
+								Optional: true,
+							},
+						},
+					},
+				},
+			}),
+			req: &tfprotov5.PlanResourceChangeRequest{
+				TypeName: "test",
+				PriorState: &tfprotov5.DynamicValue{
+					MsgPack: mustMsgpackMarshal(
+						cty.Object(map[string]cty.Type{
+							"foo": cty.Number,
+						}),
+						cty.NullVal(
+							cty.Object(map[string]cty.Type{
+								"foo": cty.Number,
+							}),
+						),
+					),
+				},
+				ProposedNewState: &tfprotov5.DynamicValue{
+					MsgPack: mustMsgpackMarshal(
+						cty.Object(map[string]cty.Type{
+							"id":  cty.String,
+							"foo": cty.Number,
+						}),
+						cty.ObjectVal(map[string]cty.Value{
+							"id":  cty.UnknownVal(cty.String),
+							"foo": cty.NullVal(cty.Number),
+						}),
+					),
+				},
+				Config: &tfprotov5.DynamicValue{
+					MsgPack: mustMsgpackMarshal(
+						cty.Object(map[string]cty.Type{
+							"id":  cty.String,
+							"foo": cty.Number,
+						}),
+						cty.ObjectVal(map[string]cty.Value{
+							"id":  cty.NullVal(cty.String),
+							"foo": cty.NullVal(cty.Number),
+						}),
+					),
+				},
+			},
+			expected: &tfprotov5.PlanResourceChangeResponse{
+				PlannedState: &tfprotov5.DynamicValue{
+					MsgPack: mustMsgpackMarshal(
+						cty.Object(map[string]cty.Type{
+							"id":  cty.String,
+							"foo": cty.Number,
+						}),
+						cty.ObjectVal(map[string]cty.Value{
+							"id":  cty.UnknownVal(cty.String),
+							"foo": cty.NullVal(cty.Number),
+						}),
+					),
+				},
+				RequiresReplace: []*tftypes.AttributePath{
+					tftypes.NewAttributePath().WithAttributeName("id"),
+				},
+				PlannedPrivate:              []byte(`{"_new_extra_shim":{}}`),
+				UnsafeToUseLegacyTypeSystem: true,
+			},
+		},
+		"basic-plan-disable-implicit-id": {
+			server: NewGRPCProviderServer(&Provider{
+				ResourcesMap: map[string]*Resource{
+					"test": {
+						SchemaVersion: 4,
+						Schema: map[string]*Schema{
+							"id": {
+								Type:     TypeString,
+								Required: true,
+								ForceNew: true,
+							},
+							"foo": {
+								Type:     TypeInt,
+								Optional: true,
+							},
+						},
+						DisableImplicitID: true,
+					},
+				},
+			}),
+			req: &tfprotov5.PlanResourceChangeRequest{
+				TypeName: "test",
+				PriorState: &tfprotov5.DynamicValue{
+					MsgPack: mustMsgpackMarshal(
+						cty.Object(map[string]cty.Type{
+							"id":  cty.String,
+							"foo": cty.Number,
+						}),
+						cty.NullVal(
+							cty.Object(map[string]cty.Type{
+								"id":  cty.String,
+								"foo": cty.Number,
+							}),
+						),
+					),
+				},
+				ProposedNewState: &tfprotov5.DynamicValue{
+					MsgPack: mustMsgpackMarshal(
+						cty.Object(map[string]cty.Type{
+							"id":  cty.String,
+							"foo": cty.Number,
+						}),
+						cty.ObjectVal(map[string]cty.Value{
+							"id":  cty.StringVal("example"),
+							"foo": cty.NullVal(cty.Number),
+						}),
+					),
+				},
+				Config: &tfprotov5.DynamicValue{
+					MsgPack: mustMsgpackMarshal(
+						cty.Object(map[string]cty.Type{
+							"id":  cty.String,
+							"foo": cty.Number,
+						}),
+						cty.ObjectVal(map[string]cty.Value{
+							"id":  cty.StringVal("example"),
+							"foo": cty.NullVal(cty.Number),
+						}),
+					),
+				},
+			},
+			expected: &tfprotov5.PlanResourceChangeResponse{
+				PlannedState: &tfprotov5.DynamicValue{
+					MsgPack: mustMsgpackMarshal(
+						cty.Object(map[string]cty.Type{
+							"id":  cty.String,
+							"foo": cty.Number,
+						}),
+						cty.ObjectVal(map[string]cty.Value{
+							"id":  cty.StringVal("example"),
+							"foo": cty.NullVal(cty.Number),
+						}),
+					),
+				},
+				RequiresReplace: []*tftypes.AttributePath{
+					tftypes.NewAttributePath().WithAttributeName("id"),
+				},
+				PlannedPrivate:              []byte(`{"_new_extra_shim":{}}`),
+				UnsafeToUseLegacyTypeSystem: true,
+			},
+		},
+		"basic-plan-with-identity": {
+			server: NewGRPCProviderServer(&Provider{
+				ResourcesMap: map[string]*Resource{
+					"test": {
+						SchemaVersion: 4,
+						Schema: map[string]*Schema{
+							"foo": {
+								Type:     TypeInt,
+								Optional: true,
+							},
+						},
+						Identity: &ResourceIdentity{
+							Version: 1,
+							SchemaFunc: func() map[string]*Schema {
+								return map[string]*Schema{
+									"name": {
+										Type:              TypeString,
+										RequiredForImport: true,
+									},
+								}
+							},
+						},
+					},
+				},
+			}),
+			req: &tfprotov5.PlanResourceChangeRequest{
+				TypeName: "test",
+				PriorState: &tfprotov5.DynamicValue{
+					MsgPack: mustMsgpackMarshal(
+						cty.Object(map[string]cty.Type{
+							"foo": cty.Number,
+						}),
+						cty.NullVal(
+							cty.Object(map[string]cty.Type{
+								"foo": cty.Number,
+							}),
+						),
+					),
+				},
+				ProposedNewState: &tfprotov5.DynamicValue{
+					MsgPack: mustMsgpackMarshal(
+						cty.Object(map[string]cty.Type{
+							"id":  cty.String,
+							"foo": cty.Number,
+						}),
+						cty.ObjectVal(map[string]cty.Value{
+							"id":  cty.UnknownVal(cty.String),
+							"foo": cty.NullVal(cty.Number),
+						}),
+					),
+				},
+				Config: &tfprotov5.DynamicValue{
+					MsgPack: mustMsgpackMarshal(
+						cty.Object(map[string]cty.Type{
+							"id":  cty.String,
+							"foo": cty.Number,
+						}),
+						cty.ObjectVal(map[string]cty.Value{
+							"id":  cty.NullVal(cty.String),
+							"foo": cty.NullVal(cty.Number),
+						}),
+					),
+				},
+				PriorIdentity: &tfprotov5.ResourceIdentityData{
+					IdentityData: &tfprotov5.DynamicValue{
+						MsgPack: mustMsgpackMarshal(
+							cty.Object(map[string]cty.Type{
+								"name": cty.String,
+							}),
+							cty.ObjectVal(map[string]cty.Value{
+								"name": cty.StringVal("test-name"),
+							}),
+						),
+					},
+				},
+			},
+			expected: &tfprotov5.PlanResourceChangeResponse{
+				PlannedState: &tfprotov5.DynamicValue{
+					MsgPack: mustMsgpackMarshal(
+						cty.Object(map[string]cty.Type{
+							"id":  cty.String,
+							"foo": cty.Number,
+						}),
+						cty.ObjectVal(map[string]cty.Value{
+							"id":  cty.UnknownVal(cty.String),
+							"foo": cty.NullVal(cty.Number),
+						}),
+					),
+				},
+				RequiresReplace: []*tftypes.AttributePath{
+					tftypes.NewAttributePath().WithAttributeName("id"),
+				},
+				PlannedPrivate:              []byte(`{"_new_extra_shim":{}}`),
+				UnsafeToUseLegacyTypeSystem: true,
+				PlannedIdentity: &tfprotov5.ResourceIdentityData{
+					IdentityData: &tfprotov5.DynamicValue{
+						MsgPack: mustMsgpackMarshal(
+							cty.Object(map[string]cty.Type{
+								"name": cty.String,
+							}),
+							cty.ObjectVal(map[string]cty.Value{
+								"name": cty.StringVal("test-name"),
+							}),
+						),
+					},
+				},
+			},
+		},
+		"new-resource-with-identity": {
+			server: NewGRPCProviderServer(&Provider{
+				ResourcesMap: map[string]*Resource{
+					"test": {
+						SchemaVersion: 4,
+						Schema: map[string]*Schema{
+							"foo": {
+								Type:     TypeString,
+								Optional: true,
+							},
+						},
+						Identity: &ResourceIdentity{
+							Version: 1,
+							SchemaFunc: func() map[string]*Schema {
+								return map[string]*Schema{
+									"name": {
+										Type:              TypeString,
+										RequiredForImport: true,
+									},
+								}
+							},
+						},
+						CustomizeDiff: func(ctx context.Context, d *ResourceDiff, meta interface{}) error {
+							identity, err := d.Identity()
+							if err != nil {
+								return err
+							}
+							err = identity.Set("name", "Peter")
+							if err != nil {
+								return err
+							}
+							return nil
+						},
+					},
+				},
+			}),
+			req: &tfprotov5.PlanResourceChangeRequest{
+				TypeName: "test",
+				PriorState: &tfprotov5.DynamicValue{
+					MsgPack: mustMsgpackMarshal(
+						cty.Object(map[string]cty.Type{
+							"id":  cty.String,
+							"foo": cty.String,
+						}),
+						cty.ObjectVal(map[string]cty.Value{
+							"id":  cty.UnknownVal(cty.String),
+							"foo": cty.StringVal("baz"),
+						}),
+					),
+				},
+				ProposedNewState: &tfprotov5.DynamicValue{
+					MsgPack: mustMsgpackMarshal(
+						cty.Object(map[string]cty.Type{
+							"id":  cty.String,
+							"foo": cty.String,
+						}),
+						cty.ObjectVal(map[string]cty.Value{
+							"id":  cty.UnknownVal(cty.String),
+							"foo": cty.StringVal("baz"),
+						}),
+					),
+				},
+				Config: &tfprotov5.DynamicValue{
+					MsgPack: mustMsgpackMarshal(
+						cty.Object(map[string]cty.Type{
+							"id":  cty.String,
+							"foo": cty.String,
+						}),
+						cty.ObjectVal(map[string]cty.Value{
+							"id":  cty.NullVal(cty.String),
+							"foo": cty.StringVal("baz"),
+						}),
+					),
+				},
+			},
+			expected: &tfprotov5.PlanResourceChangeResponse{
+				PlannedState: &tfprotov5.DynamicValue{
+					MsgPack: mustMsgpackMarshal(
+						cty.Object(map[string]cty.Type{
+							"id":  cty.String,
+							"foo": cty.String,
+						}),
+						cty.ObjectVal(map[string]cty.Value{
+							"id":  cty.UnknownVal(cty.String),
+							"foo": cty.StringVal("baz"),
 						}),
 					),
 				},
-				Private: []byte(`{"user_defined_key":"user_defined_value"}`),
-				NewIdentity: &tfprotov5.ResourceIdentityData{
+				RequiresReplace: []*tftypes.AttributePath{
+					tftypes.NewAttributePath().WithAttributeName("id"),
+				},
+				PlannedPrivate:              []byte(`{"_new_extra_shim":{}}`),
+				UnsafeToUseLegacyTypeSystem: true,
+				PlannedIdentity: &tfprotov5.ResourceIdentityData{
 					IdentityData: &tfprotov5.DynamicValue{
 						MsgPack: mustMsgpackMarshal(
 							cty.Object(map[string]cty.Type{
-								"identity": cty.String,
+								"name": cty.String,
 							}),
 							cty.ObjectVal(map[string]cty.Value{
-								"identity": cty.StringVal("changed"),
+								"name": cty.StringVal("Peter"),
 							}),
 						),
 					},
 				},
 			},
 		},
-	}
-
-	for name, testCase := range testCases {
-		t.Run(name, func(t *testing.T) {
-			t.Parallel()
-			resp, err := testCase.server.ReadResource(context.Background(), testCase.req)
-
-			if err != nil {
-				t.Fatal(err)
-			}
-
-			if diff := cmp.Diff(resp, testCase.expected, valueComparer); diff != "" {
-				ty := testCase.server.getResourceSchemaBlock("test").ImpliedType()
-
-				if resp != nil && resp.NewState != nil {
-					t.Logf("resp.NewState.MsgPack: %s", mustMsgpackUnmarshal(ty, resp.NewState.MsgPack))
-				}
-
-				if testCase.expected != nil && testCase.expected.NewState != nil {
-					t.Logf("expected: %s", mustMsgpackUnmarshal(ty, testCase.expected.NewState.MsgPack))
-				}
-
-				t.Error(diff)
-			}
-		})
-	}
-}
-
-func TestPlanResourceChange(t *testing.T) {
-	t.Parallel()
-
-	testCases := map[string]struct {
-		server   *GRPCProviderServer
-		req      *tfprotov5.PlanResourceChangeRequest
-		expected *tfprotov5.PlanResourceChangeResponse
-	}{
-		"basic-plan": {
+		"no identity schema": {
 			server: NewGRPCProviderServer(&Provider{
 				ResourcesMap: map[string]*Resource{
 					"test": {
@@ -6046,6 +7264,9 @@ func TestPlanResourceChange(t *testing.T) {
 								Optional: true,
 							},
 						},
+						Identity: &ResourceIdentity{
+							Version: 1,
+						},
 					},
 				},
 			}),
@@ -6087,28 +7308,30 @@ func TestPlanResourceChange(t *testing.T) {
 						}),
 					),
 				},
+				PriorIdentity: &tfprotov5.ResourceIdentityData{
+					IdentityData: &tfprotov5.DynamicValue{
+						MsgPack: mustMsgpackMarshal(
+							cty.Object(map[string]cty.Type{
+								"name": cty.String,
+							}),
+							cty.ObjectVal(map[string]cty.Value{
+								"name": cty.StringVal("test-name"),
+							}),
+						),
+					},
+				},
 			},
 			expected: &tfprotov5.PlanResourceChangeResponse{
-				PlannedState: &tfprotov5.DynamicValue{
-					MsgPack: mustMsgpackMarshal(
-						cty.Object(map[string]cty.Type{
-							"id":  cty.String,
-							"foo": cty.Number,
-						}),
-						cty.ObjectVal(map[string]cty.Value{
-							"id":  cty.UnknownVal(cty.String),
-							"foo": cty.NullVal(cty.Number),
-						}),
-					),
-				},
-				RequiresReplace: []*tftypes.AttributePath{
-					tftypes.NewAttributePath().WithAttributeName("id"),
+				Diagnostics: []*tfprotov5.Diagnostic{
+					{
+						Severity: tfprotov5.DiagnosticSeverityError,
+						Summary:  "getting identity schema failed for resource 'test': resource does not have an identity schema",
+					},
 				},
-				PlannedPrivate:              []byte(`{"_new_extra_shim":{}}`),
 				UnsafeToUseLegacyTypeSystem: true,
 			},
 		},
-		"basic-plan-with-identity": {
+		"empty identity schema": {
 			server: NewGRPCProviderServer(&Provider{
 				ResourcesMap: map[string]*Resource{
 					"test": {
@@ -6122,12 +7345,7 @@ func TestPlanResourceChange(t *testing.T) {
 						Identity: &ResourceIdentity{
 							Version: 1,
 							SchemaFunc: func() map[string]*Schema {
-								return map[string]*Schema{
-									"name": {
-										Type:              TypeString,
-										RequiredForImport: true,
-									},
-								}
+								return map[string]*Schema{}
 							},
 						},
 					},
@@ -6184,6 +7402,70 @@ func TestPlanResourceChange(t *testing.T) {
 					},
 				},
 			},
+			expected: &tfprotov5.PlanResourceChangeResponse{
+				Diagnostics: []*tfprotov5.Diagnostic{
+					{
+						Severity: tfprotov5.DiagnosticSeverityError,
+						Summary:  "getting identity schema failed for resource 'test': identity schema must have at least one attribute",
+					},
+				},
+				UnsafeToUseLegacyTypeSystem: true,
+			},
+		},
+		"basic-plan-EnableLegacyTypeSystemPlanErrors": {
+			server: NewGRPCProviderServer(&Provider{
+				ResourcesMap: map[string]*Resource{
+					"test": {
+						// Will set UnsafeToUseLegacyTypeSystem to false
+						EnableLegacyTypeSystemPlanErrors: true,
+						Schema: map[string]*Schema{
+							"foo": {
+								Type:     TypeInt,
+								Optional: true,
+							},
+						},
+					},
+				},
+			}),
+			req: &tfprotov5.PlanResourceChangeRequest{
+				TypeName: "test",
+				PriorState: &tfprotov5.DynamicValue{
+					MsgPack: mustMsgpackMarshal(
+						cty.Object(map[string]cty.Type{
+							"foo": cty.Number,
+						}),
+						cty.NullVal(
+							cty.Object(map[string]cty.Type{
+								"foo": cty.Number,
+							}),
+						),
+					),
+				},
+				ProposedNewState: &tfprotov5.DynamicValue{
+					MsgPack: mustMsgpackMarshal(
+						cty.Object(map[string]cty.Type{
+							"id":  cty.String,
+							"foo": cty.Number,
+						}),
+						cty.ObjectVal(map[string]cty.Value{
+							"id":  cty.UnknownVal(cty.String),
+							"foo": cty.NullVal(cty.Number),
+						}),
+					),
+				},
+				Config: &tfprotov5.DynamicValue{
+					MsgPack: mustMsgpackMarshal(
+						cty.Object(map[string]cty.Type{
+							"id":  cty.String,
+							"foo": cty.Number,
+						}),
+						cty.ObjectVal(map[string]cty.Value{
+							"id":  cty.NullVal(cty.String),
+							"foo": cty.NullVal(cty.Number),
+						}),
+					),
+				},
+			},
 			expected: &tfprotov5.PlanResourceChangeResponse{
 				PlannedState: &tfprotov5.DynamicValue{
 					MsgPack: mustMsgpackMarshal(
@@ -6201,69 +7483,51 @@ func TestPlanResourceChange(t *testing.T) {
 					tftypes.NewAttributePath().WithAttributeName("id"),
 				},
 				PlannedPrivate:              []byte(`{"_new_extra_shim":{}}`),
-				UnsafeToUseLegacyTypeSystem: true,
-				PlannedIdentity: &tfprotov5.ResourceIdentityData{
-					IdentityData: &tfprotov5.DynamicValue{
-						MsgPack: mustMsgpackMarshal(
-							cty.Object(map[string]cty.Type{
-								"name": cty.String,
-							}),
-							cty.ObjectVal(map[string]cty.Value{
-								"name": cty.StringVal("test-name"),
-							}),
-						),
-					},
-				},
+				UnsafeToUseLegacyTypeSystem: false,
 			},
 		},
-		"new-resource-with-identity": {
+		"deferred-with-provider-plan-modification": {
 			server: NewGRPCProviderServer(&Provider{
+				providerDeferred: &Deferred{
+					Reason: DeferredReasonProviderConfigUnknown,
+				},
 				ResourcesMap: map[string]*Resource{
 					"test": {
+						ResourceBehavior: ResourceBehavior{
+							ProviderDeferred: ProviderDeferredBehavior{
+								// Will ensure that CustomizeDiff is called
+								EnablePlanModification: true,
+							},
+						},
 						SchemaVersion: 4,
+						CustomizeDiff: func(ctx context.Context, d *ResourceDiff, i interface{}) error {
+							return d.SetNew("foo", "new-foo-value")
+						},
 						Schema: map[string]*Schema{
 							"foo": {
 								Type:     TypeString,
 								Optional: true,
+								Computed: true,
 							},
 						},
-						Identity: &ResourceIdentity{
-							Version: 1,
-							SchemaFunc: func() map[string]*Schema {
-								return map[string]*Schema{
-									"name": {
-										Type:              TypeString,
-										RequiredForImport: true,
-									},
-								}
-							},
-						},
-						CustomizeDiff: func(ctx context.Context, d *ResourceDiff, meta interface{}) error {
-							identity, err := d.Identity()
-							if err != nil {
-								return err
-							}
-							err = identity.Set("name", "Peter")
-							if err != nil {
-								return err
-							}
-							return nil
-						},
 					},
 				},
 			}),
 			req: &tfprotov5.PlanResourceChangeRequest{
 				TypeName: "test",
+				ClientCapabilities: &tfprotov5.PlanResourceChangeClientCapabilities{
+					DeferralAllowed: true,
+				},
 				PriorState: &tfprotov5.DynamicValue{
 					MsgPack: mustMsgpackMarshal(
 						cty.Object(map[string]cty.Type{
-							"id":  cty.String,
 							"foo": cty.String,
 						}),
-						cty.ObjectVal(map[string]cty.Value{
-							"id":  cty.UnknownVal(cty.String),
-							"foo": cty.StringVal("baz"),
-						}),
+						cty.NullVal(
+							cty.Object(map[string]cty.Type{
+								"foo": cty.String,
+							}),
+						),
 					),
 				},
 				ProposedNewState: &tfprotov5.DynamicValue{
@@ -6274,7 +7538,7 @@ func TestPlanResourceChange(t *testing.T) {
 						}),
 						cty.ObjectVal(map[string]cty.Value{
 							"id":  cty.UnknownVal(cty.String),
-							"foo": cty.StringVal("baz"),
+							"foo": cty.UnknownVal(cty.String),
 						}),
 					),
 				},
@@ -6286,70 +7550,66 @@ func TestPlanResourceChange(t *testing.T) {
 						}),
 						cty.ObjectVal(map[string]cty.Value{
 							"id":  cty.NullVal(cty.String),
-							"foo": cty.StringVal("baz"),
+							"foo": cty.NullVal(cty.String),
 						}),
 					),
 				},
 			},
 			expected: &tfprotov5.PlanResourceChangeResponse{
+				Deferred: &tfprotov5.Deferred{
+					Reason: tfprotov5.DeferredReasonProviderConfigUnknown,
+				},
 				PlannedState: &tfprotov5.DynamicValue{
 					MsgPack: mustMsgpackMarshal(
 						cty.Object(map[string]cty.Type{
 							"id":  cty.String,
 							"foo": cty.String,
 						}),
-						cty.ObjectVal(map[string]cty.Value{
-							"id":  cty.UnknownVal(cty.String),
-							"foo": cty.StringVal("baz"),
-						}),
-					),
-				},
-				RequiresReplace: []*tftypes.AttributePath{
-					tftypes.NewAttributePath().WithAttributeName("id"),
-				},
-				PlannedPrivate:              []byte(`{"_new_extra_shim":{}}`),
-				UnsafeToUseLegacyTypeSystem: true,
-				PlannedIdentity: &tfprotov5.ResourceIdentityData{
-					IdentityData: &tfprotov5.DynamicValue{
-						MsgPack: mustMsgpackMarshal(
-							cty.Object(map[string]cty.Type{
-								"name": cty.String,
-							}),
-							cty.ObjectVal(map[string]cty.Value{
-								"name": cty.StringVal("Peter"),
-							}),
-						),
-					},
+						cty.ObjectVal(map[string]cty.Value{
+							"id":  cty.UnknownVal(cty.String),
+							"foo": cty.StringVal("new-foo-value"),
+						}),
+					),
+				},
+				RequiresReplace: []*tftypes.AttributePath{
+					tftypes.NewAttributePath().WithAttributeName("id"),
 				},
+				PlannedPrivate:              []byte(`{"_new_extra_shim":{}}`),
+				UnsafeToUseLegacyTypeSystem: true,
 			},
 		},
-		"no identity schema": {
+		"deferred-with-resource-diff-defer": {
 			server: NewGRPCProviderServer(&Provider{
 				ResourcesMap: map[string]*Resource{
 					"test": {
 						SchemaVersion: 4,
+						CustomizeDiff: func(ctx context.Context, d *ResourceDiff, i interface{}) error {
+							d.Defer(DeferredReasonResourceConfigUnknown)
+							return d.SetNew("foo", "new-foo-value")
+						},
 						Schema: map[string]*Schema{
 							"foo": {
-								Type:     TypeInt,
+								Type:     TypeString,
 								Optional: true,
+								Computed: true,
 							},
 						},
-						Identity: &ResourceIdentity{
-							Version: 1,
-						},
 					},
 				},
 			}),
 			req: &tfprotov5.PlanResourceChangeRequest{
 				TypeName: "test",
+				ClientCapabilities: &tfprotov5.PlanResourceChangeClientCapabilities{
+					DeferralAllowed: true,
+				},
 				PriorState: &tfprotov5.DynamicValue{
 					MsgPack: mustMsgpackMarshal(
 						cty.Object(map[string]cty.Type{
-							"foo": cty.Number,
+							"foo": cty.String,
 						}),
 						cty.NullVal(
 							cty.Object(map[string]cty.Type{
-								"foo": cty.Number,
+								"foo": cty.String,
 							}),
 						),
 					),
@@ -6358,11 +7618,11 @@ func TestPlanResourceChange(t *testing.T) {
 					MsgPack: mustMsgpackMarshal(
 						cty.Object(map[string]cty.Type{
 							"id":  cty.String,
-							"foo": cty.Number,
+							"foo": cty.String,
 						}),
 						cty.ObjectVal(map[string]cty.Value{
 							"id":  cty.UnknownVal(cty.String),
-							"foo": cty.NullVal(cty.Number),
+							"foo": cty.UnknownVal(cty.String),
 						}),
 					),
 				},
@@ -6370,52 +7630,52 @@ func TestPlanResourceChange(t *testing.T) {
 					MsgPack: mustMsgpackMarshal(
 						cty.Object(map[string]cty.Type{
 							"id":  cty.String,
-							"foo": cty.Number,
+							"foo": cty.String,
 						}),
 						cty.ObjectVal(map[string]cty.Value{
 							"id":  cty.NullVal(cty.String),
-							"foo": cty.NullVal(cty.Number),
+							"foo": cty.NullVal(cty.String),
 						}),
 					),
 				},
-				PriorIdentity: &tfprotov5.ResourceIdentityData{
-					IdentityData: &tfprotov5.DynamicValue{
-						MsgPack: mustMsgpackMarshal(
-							cty.Object(map[string]cty.Type{
-								"name": cty.String,
-							}),
-							cty.ObjectVal(map[string]cty.Value{
-								"name": cty.StringVal("test-name"),
-							}),
-						),
-					},
-				},
 			},
 			expected: &tfprotov5.PlanResourceChangeResponse{
-				Diagnostics: []*tfprotov5.Diagnostic{
-					{
-						Severity: tfprotov5.DiagnosticSeverityError,
-						Summary:  "getting identity schema failed for resource 'test': resource does not have an identity schema",
-					},
+				Deferred: &tfprotov5.Deferred{
+					Reason: tfprotov5.DeferredReasonResourceConfigUnknown,
+				},
+				PlannedState: &tfprotov5.DynamicValue{
+					MsgPack: mustMsgpackMarshal(
+						cty.Object(map[string]cty.Type{
+							"id":  cty.String,
+							"foo": cty.String,
+						}),
+						cty.ObjectVal(map[string]cty.Value{
+							"id":  cty.UnknownVal(cty.String),
+							"foo": cty.StringVal("new-foo-value"),
+						}),
+					),
+				},
+				RequiresReplace: []*tftypes.AttributePath{
+					tftypes.NewAttributePath().WithAttributeName("id"),
 				},
+				PlannedPrivate:              []byte(`{"_new_extra_shim":{}}`),
 				UnsafeToUseLegacyTypeSystem: true,
 			},
 		},
-		"empty identity schema": {
+		"deferred-with-resource-diff-defer-not-allowed": {
 			server: NewGRPCProviderServer(&Provider{
 				ResourcesMap: map[string]*Resource{
 					"test": {
 						SchemaVersion: 4,
+						CustomizeDiff: func(ctx context.Context, d *ResourceDiff, i interface{}) error {
+							d.Defer(DeferredReasonResourceConfigUnknown)
+							return d.SetNew("foo", "new-foo-value")
+						},
 						Schema: map[string]*Schema{
 							"foo": {
-								Type:     TypeInt,
+								Type:     TypeString,
 								Optional: true,
-							},
-						},
-						Identity: &ResourceIdentity{
-							Version: 1,
-							SchemaFunc: func() map[string]*Schema {
-								return map[string]*Schema{}
+								Computed: true,
 							},
 						},
 					},
@@ -6426,11 +7686,11 @@ func TestPlanResourceChange(t *testing.T) {
 				PriorState: &tfprotov5.DynamicValue{
 					MsgPack: mustMsgpackMarshal(
 						cty.Object(map[string]cty.Type{
-							"foo": cty.Number,
+							"foo": cty.String,
 						}),
 						cty.NullVal(
 							cty.Object(map[string]cty.Type{
-								"foo": cty.Number,
+								"foo": cty.String,
 							}),
 						),
 					),
@@ -6439,11 +7699,11 @@ func TestPlanResourceChange(t *testing.T) {
 					MsgPack: mustMsgpackMarshal(
 						cty.Object(map[string]cty.Type{
 							"id":  cty.String,
-							"foo": cty.Number,
+							"foo": cty.String,
 						}),
 						cty.ObjectVal(map[string]cty.Value{
 							"id":  cty.UnknownVal(cty.String),
-							"foo": cty.NullVal(cty.Number),
+							"foo": cty.UnknownVal(cty.String),
 						}),
 					),
 				},
@@ -6451,47 +7711,49 @@ func TestPlanResourceChange(t *testing.T) {
 					MsgPack: mustMsgpackMarshal(
 						cty.Object(map[string]cty.Type{
 							"id":  cty.String,
-							"foo": cty.Number,
+							"foo": cty.String,
 						}),
 						cty.ObjectVal(map[string]cty.Value{
 							"id":  cty.NullVal(cty.String),
-							"foo": cty.NullVal(cty.Number),
+							"foo": cty.NullVal(cty.String),
 						}),
 					),
 				},
-				PriorIdentity: &tfprotov5.ResourceIdentityData{
-					IdentityData: &tfprotov5.DynamicValue{
-						MsgPack: mustMsgpackMarshal(
-							cty.Object(map[string]cty.Type{
-								"name": cty.String,
-							}),
-							cty.ObjectVal(map[string]cty.Value{
-								"name": cty.StringVal("test-name"),
-							}),
-						),
-					},
-				},
 			},
 			expected: &tfprotov5.PlanResourceChangeResponse{
 				Diagnostics: []*tfprotov5.Diagnostic{
 					{
 						Severity: tfprotov5.DiagnosticSeverityError,
-						Summary:  "getting identity schema failed for resource 'test': identity schema must have at least one attribute",
+						Summary:  "Invalid Deferred Resource Response",
+						Detail: "Resource deferred its planned change but the Terraform request " +
+							"did not indicate support for deferred actions. This is an issue with the provider and should be reported to the provider developers.",
 					},
 				},
 				UnsafeToUseLegacyTypeSystem: true,
 			},
 		},
-		"basic-plan-EnableLegacyTypeSystemPlanErrors": {
+		"customize-diff-context-warning": {
 			server: NewGRPCProviderServer(&Provider{
 				ResourcesMap: map[string]*Resource{
 					"test": {
-						// Will set UnsafeToUseLegacyTypeSystem to false
-						EnableLegacyTypeSystemPlanErrors: true,
+						SchemaVersion: 4,
+						CustomizeDiffContext: func(ctx context.Context, d *ResourceDiff, i interface{}) diag.Diagnostics {
+							if err := d.SetNew("foo", "new-foo-value"); err != nil {
+								return diag.FromErr(err)
+							}
+
+							return diag.Diagnostics{
+								{
+									Severity: diag.Warning,
+									Summary:  "foo is computed",
+								},
+							}
+						},
 						Schema: map[string]*Schema{
 							"foo": {
-								Type:     TypeInt,
+								Type:     TypeString,
 								Optional: true,
+								Computed: true,
 							},
 						},
 					},
@@ -6502,11 +7764,11 @@ func TestPlanResourceChange(t *testing.T) {
 				PriorState: &tfprotov5.DynamicValue{
 					MsgPack: mustMsgpackMarshal(
 						cty.Object(map[string]cty.Type{
-							"foo": cty.Number,
+							"foo": cty.String,
 						}),
 						cty.NullVal(
 							cty.Object(map[string]cty.Type{
-								"foo": cty.Number,
+								"foo": cty.String,
 							}),
 						),
 					),
@@ -6515,11 +7777,11 @@ func TestPlanResourceChange(t *testing.T) {
 					MsgPack: mustMsgpackMarshal(
 						cty.Object(map[string]cty.Type{
 							"id":  cty.String,
-							"foo": cty.Number,
+							"foo": cty.String,
 						}),
 						cty.ObjectVal(map[string]cty.Value{
 							"id":  cty.UnknownVal(cty.String),
-							"foo": cty.NullVal(cty.Number),
+							"foo": cty.UnknownVal(cty.String),
 						}),
 					),
 				},
@@ -6527,25 +7789,31 @@ func TestPlanResourceChange(t *testing.T) {
 					MsgPack: mustMsgpackMarshal(
 						cty.Object(map[string]cty.Type{
 							"id":  cty.String,
-							"foo": cty.Number,
+							"foo": cty.String,
 						}),
 						cty.ObjectVal(map[string]cty.Value{
 							"id":  cty.NullVal(cty.String),
-							"foo": cty.NullVal(cty.Number),
+							"foo": cty.NullVal(cty.String),
 						}),
 					),
 				},
 			},
 			expected: &tfprotov5.PlanResourceChangeResponse{
+				Diagnostics: []*tfprotov5.Diagnostic{
+					{
+						Severity: tfprotov5.DiagnosticSeverityWarning,
+						Summary:  "foo is computed",
+					},
+				},
 				PlannedState: &tfprotov5.DynamicValue{
 					MsgPack: mustMsgpackMarshal(
 						cty.Object(map[string]cty.Type{
 							"id":  cty.String,
-							"foo": cty.Number,
+							"foo": cty.String,
 						}),
 						cty.ObjectVal(map[string]cty.Value{
 							"id":  cty.UnknownVal(cty.String),
-							"foo": cty.NullVal(cty.Number),
+							"foo": cty.StringVal("new-foo-value"),
 						}),
 					),
 				},
@@ -6553,25 +7821,25 @@ func TestPlanResourceChange(t *testing.T) {
 					tftypes.NewAttributePath().WithAttributeName("id"),
 				},
 				PlannedPrivate:              []byte(`{"_new_extra_shim":{}}`),
-				UnsafeToUseLegacyTypeSystem: false,
+				UnsafeToUseLegacyTypeSystem: true,
 			},
 		},
-		"deferred-with-provider-plan-modification": {
+		"customize-diff-context-errors": {
 			server: NewGRPCProviderServer(&Provider{
-				providerDeferred: &Deferred{
-					Reason: DeferredReasonProviderConfigUnknown,
-				},
 				ResourcesMap: map[string]*Resource{
 					"test": {
-						ResourceBehavior: ResourceBehavior{
-							ProviderDeferred: ProviderDeferredBehavior{
-								// Will ensure that CustomizeDiff is called
-								EnablePlanModification: true,
-							},
-						},
 						SchemaVersion: 4,
-						CustomizeDiff: func(ctx context.Context, d *ResourceDiff, i interface{}) error {
-							return d.SetNew("foo", "new-foo-value")
+						CustomizeDiffContext: func(ctx context.Context, d *ResourceDiff, i interface{}) diag.Diagnostics {
+							return diag.Diagnostics{
+								{
+									Severity: diag.Error,
+									Summary:  "first problem",
+								},
+								{
+									Severity: diag.Error,
+									Summary:  "second problem",
+								},
+							}
 						},
 						Schema: map[string]*Schema{
 							"foo": {
@@ -6585,9 +7853,6 @@ func TestPlanResourceChange(t *testing.T) {
 			}),
 			req: &tfprotov5.PlanResourceChangeRequest{
 				TypeName: "test",
-				ClientCapabilities: &tfprotov5.PlanResourceChangeClientCapabilities{
-					DeferralAllowed: true,
-				},
 				PriorState: &tfprotov5.DynamicValue{
 					MsgPack: mustMsgpackMarshal(
 						cty.Object(map[string]cty.Type{
@@ -6626,25 +7891,16 @@ func TestPlanResourceChange(t *testing.T) {
 				},
 			},
 			expected: &tfprotov5.PlanResourceChangeResponse{
-				Deferred: &tfprotov5.Deferred{
-					Reason: tfprotov5.DeferredReasonProviderConfigUnknown,
-				},
-				PlannedState: &tfprotov5.DynamicValue{
-					MsgPack: mustMsgpackMarshal(
-						cty.Object(map[string]cty.Type{
-							"id":  cty.String,
-							"foo": cty.String,
-						}),
-						cty.ObjectVal(map[string]cty.Value{
-							"id":  cty.UnknownVal(cty.String),
-							"foo": cty.StringVal("new-foo-value"),
-						}),
-					),
-				},
-				RequiresReplace: []*tftypes.AttributePath{
-					tftypes.NewAttributePath().WithAttributeName("id"),
+				Diagnostics: []*tfprotov5.Diagnostic{
+					{
+						Severity: tfprotov5.DiagnosticSeverityError,
+						Summary:  "first problem",
+					},
+					{
+						Severity: tfprotov5.DiagnosticSeverityError,
+						Summary:  "second problem",
+					},
 				},
-				PlannedPrivate:              []byte(`{"_new_extra_shim":{}}`),
 				UnsafeToUseLegacyTypeSystem: true,
 			},
 		},
@@ -7745,51 +9001,131 @@ func TestPlanResourceChange_bigint(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	config, err := schema.CoerceValue(cty.ObjectVal(map[string]cty.Value{
-		"id":  cty.NullVal(cty.String),
-		"foo": cty.MustParseNumberVal("7227701560655103598"),
-	}))
+	config, err := schema.CoerceValue(cty.ObjectVal(map[string]cty.Value{
+		"id":  cty.NullVal(cty.String),
+		"foo": cty.MustParseNumberVal("7227701560655103598"),
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	configBytes, err := msgpack.Marshal(config, schema.ImpliedType())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testReq := &tfprotov5.PlanResourceChangeRequest{
+		TypeName: "test",
+		PriorState: &tfprotov5.DynamicValue{
+			MsgPack: priorState,
+		},
+		ProposedNewState: &tfprotov5.DynamicValue{
+			MsgPack: proposedState,
+		},
+		Config: &tfprotov5.DynamicValue{
+			MsgPack: configBytes,
+		},
+	}
+
+	resp, err := server.PlanResourceChange(context.Background(), testReq)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plannedStateVal, err := msgpack.Unmarshal(resp.PlannedState.MsgPack, schema.ImpliedType())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !cmp.Equal(proposedVal, plannedStateVal, valueComparer) {
+		t.Fatal(cmp.Diff(proposedVal, plannedStateVal, valueComparer))
+	}
+
+	plannedStateFoo, acc := plannedStateVal.GetAttr("foo").AsBigFloat().Int64()
+	if acc != big.Exact {
+		t.Fatalf("Expected exact accuracy, got %s", acc)
+	}
+	if plannedStateFoo != 7227701560655103598 {
+		t.Fatalf("Expected %d, got %d, this represents a loss of precision in planning large numbers", 7227701560655103598, plannedStateFoo)
+	}
+}
+
+func TestPlanResourceChange_defaultFromState(t *testing.T) {
+	t.Parallel()
+
+	r := &Resource{
+		Schema: map[string]*Schema{
+			"foo": {
+				Type:             TypeString,
+				Optional:         true,
+				DefaultFromState: true,
+			},
+		},
+	}
+
+	server := NewGRPCProviderServer(&Provider{
+		ResourcesMap: map[string]*Resource{
+			"test": r,
+		},
+	})
+
+	schema := r.CoreConfigSchema()
+	ty := schema.ImpliedType()
+
+	mustMarshal := func(v cty.Value) *tfprotov5.DynamicValue {
+		t.Helper()
+
+		b, err := msgpack.Marshal(v, ty)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return &tfprotov5.DynamicValue{MsgPack: b}
+	}
+
+	priorVal := cty.ObjectVal(map[string]cty.Value{
+		"id":  cty.StringVal("bar"),
+		"foo": cty.StringVal("kept"),
+	})
+
+	// The attribute is removed from the configuration.
+	resp, err := server.PlanResourceChange(context.Background(), &tfprotov5.PlanResourceChangeRequest{
+		TypeName:   "test",
+		PriorState: mustMarshal(priorVal),
+		ProposedNewState: mustMarshal(cty.ObjectVal(map[string]cty.Value{
+			"id":  cty.StringVal("bar"),
+			"foo": cty.NullVal(cty.String),
+		})),
+		Config: mustMarshal(cty.ObjectVal(map[string]cty.Value{
+			"id":  cty.NullVal(cty.String),
+			"foo": cty.NullVal(cty.String),
+		})),
+	})
 	if err != nil {
 		t.Fatal(err)
 	}
-	configBytes, err := msgpack.Marshal(config, schema.ImpliedType())
-	if err != nil {
-		t.Fatal(err)
+
+	if len(resp.Diagnostics) > 0 {
+		t.Fatalf("unexpected diagnostics: %#v", resp.Diagnostics)
 	}
 
-	testReq := &tfprotov5.PlanResourceChangeRequest{
-		TypeName: "test",
-		PriorState: &tfprotov5.DynamicValue{
-			MsgPack: priorState,
-		},
-		ProposedNewState: &tfprotov5.DynamicValue{
-			MsgPack: proposedState,
-		},
-		Config: &tfprotov5.DynamicValue{
-			MsgPack: configBytes,
-		},
+	// Terraform rejects a planned value of a non-computed attribute which
+	// differs from its configuration value, unless the provider uses the
+	// legacy type system, in which case it only logs a warning.
+	if !resp.UnsafeToUseLegacyTypeSystem {
+		t.Fatal("expected UnsafeToUseLegacyTypeSystem to be set")
 	}
 
-	resp, err := server.PlanResourceChange(context.Background(), testReq)
-	if err != nil {
-		t.Fatal(err)
+	if len(resp.RequiresReplace) > 0 {
+		t.Fatalf("unexpected RequiresReplace: %#v", resp.RequiresReplace)
 	}
 
-	plannedStateVal, err := msgpack.Unmarshal(resp.PlannedState.MsgPack, schema.ImpliedType())
+	plannedVal, err := msgpack.Unmarshal(resp.PlannedState.MsgPack, ty)
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	if !cmp.Equal(proposedVal, plannedStateVal, valueComparer) {
-		t.Fatal(cmp.Diff(proposedVal, plannedStateVal, valueComparer))
-	}
-
-	plannedStateFoo, acc := plannedStateVal.GetAttr("foo").AsBigFloat().Int64()
-	if acc != big.Exact {
-		t.Fatalf("Expected exact accuracy, got %s", acc)
-	}
-	if plannedStateFoo != 7227701560655103598 {
-		t.Fatalf("Expected %d, got %d, this represents a loss of precision in planning large numbers", 7227701560655103598, plannedStateFoo)
+	if !cmp.Equal(priorVal, plannedVal, valueComparer) {
+		t.Fatal(cmp.Diff(priorVal, plannedVal, valueComparer))
 	}
 }
 
@@ -9161,6 +10497,152 @@ func TestApplyResourceChange_bigint(t *testing.T) {
 	}
 }
 
+func TestResourcePrivateData(t *testing.T) {
+	var updateToken, readToken []byte
+
+	r := &Resource{
+		Schema: map[string]*Schema{
+			"foo": {
+				Type:     TypeString,
+				Optional: true,
+			},
+		},
+		CreateContext: func(_ context.Context, rd *ResourceData, _ interface{}) diag.Diagnostics {
+			rd.SetId("bar")
+			rd.SetPrivate("token", []byte("secret"))
+			return nil
+		},
+		ReadContext: func(_ context.Context, rd *ResourceData, _ interface{}) diag.Diagnostics {
+			readToken, _ = rd.GetPrivate("token")
+			return nil
+		},
+		UpdateContext: func(_ context.Context, rd *ResourceData, _ interface{}) diag.Diagnostics {
+			updateToken, _ = rd.GetPrivate("token")
+			return nil
+		},
+		DeleteContext: func(_ context.Context, rd *ResourceData, _ interface{}) diag.Diagnostics {
+			return nil
+		},
+	}
+
+	server := NewGRPCProviderServer(&Provider{
+		ResourcesMap: map[string]*Resource{
+			"test": r,
+		},
+	})
+
+	schema := r.CoreConfigSchema()
+	ty := schema.ImpliedType()
+
+	mustMarshal := func(v cty.Value) *tfprotov5.DynamicValue {
+		t.Helper()
+
+		b, err := msgpack.Marshal(v, ty)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return &tfprotov5.DynamicValue{MsgPack: b}
+	}
+
+	createConfig := cty.ObjectVal(map[string]cty.Value{
+		"id":  cty.NullVal(cty.String),
+		"foo": cty.StringVal("a"),
+	})
+
+	applyResp, err := server.ApplyResourceChange(context.Background(), &tfprotov5.ApplyResourceChangeRequest{
+		TypeName:   "test",
+		PriorState: mustMarshal(cty.NullVal(ty)),
+		PlannedState: mustMarshal(cty.ObjectVal(map[string]cty.Value{
+			"id":  cty.UnknownVal(cty.String),
+			"foo": cty.StringVal("a"),
+		})),
+		Config: mustMarshal(createConfig),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(applyResp.Diagnostics) > 0 {
+		t.Fatalf("unexpected create diagnostics: %#v", applyResp.Diagnostics)
+	}
+
+	createdState := cty.ObjectVal(map[string]cty.Value{
+		"id":  cty.StringVal("bar"),
+		"foo": cty.StringVal("a"),
+	})
+
+	readResp, err := server.ReadResource(context.Background(), &tfprotov5.ReadResourceRequest{
+		TypeName:     "test",
+		CurrentState: mustMarshal(createdState),
+		Private:      applyResp.Private,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(readResp.Diagnostics) > 0 {
+		t.Fatalf("unexpected read diagnostics: %#v", readResp.Diagnostics)
+	}
+	if string(readToken) != "secret" {
+		t.Fatalf("expected token to be available during read, got %q", readToken)
+	}
+	if string(readResp.Private) != string(applyResp.Private) {
+		t.Fatalf("expected private state to be unchanged by read, got %s", readResp.Private)
+	}
+
+	updateConfig := cty.ObjectVal(map[string]cty.Value{
+		"id":  cty.NullVal(cty.String),
+		"foo": cty.StringVal("b"),
+	})
+	proposedState := cty.ObjectVal(map[string]cty.Value{
+		"id":  cty.StringVal("bar"),
+		"foo": cty.StringVal("b"),
+	})
+
+	planResp, err := server.PlanResourceChange(context.Background(), &tfprotov5.PlanResourceChangeRequest{
+		TypeName:         "test",
+		PriorState:       mustMarshal(createdState),
+		ProposedNewState: mustMarshal(proposedState),
+		Config:           mustMarshal(updateConfig),
+		PriorPrivate:     readResp.Private,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(planResp.Diagnostics) > 0 {
+		t.Fatalf("unexpected plan diagnostics: %#v", planResp.Diagnostics)
+	}
+
+	applyResp, err = server.ApplyResourceChange(context.Background(), &tfprotov5.ApplyResourceChangeRequest{
+		TypeName:       "test",
+		PriorState:     mustMarshal(createdState),
+		PlannedState:   planResp.PlannedState,
+		Config:         mustMarshal(updateConfig),
+		PlannedPrivate: planResp.PlannedPrivate,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(applyResp.Diagnostics) > 0 {
+		t.Fatalf("unexpected update diagnostics: %#v", applyResp.Diagnostics)
+	}
+	if string(updateToken) != "secret" {
+		t.Fatalf("expected token to be available during update, got %q", updateToken)
+	}
+
+	private := make(map[string]interface{})
+	if err := json.Unmarshal(applyResp.Private, &private); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]interface{}{
+		"token": base64.StdEncoding.EncodeToString([]byte("secret")),
+	}
+
+	if diff := cmp.Diff(expected, private[privateDataKey]); diff != "" {
+		t.Fatalf("unexpected private data after update: %s", diff)
+	}
+}
+
 func TestApplyResourceChange_ResourceFuncs_writeOnly(t *testing.T) {
 	t.Parallel()
 
@@ -9837,6 +11319,94 @@ func TestImportResourceState(t *testing.T) {
 
 // Timeouts should never be present in imported resources.
 // Reference: https://github.com/hashicorp/terraform-plugin-sdk/issues/1145
+func TestImportResourceState_StreamStateContext(t *testing.T) {
+	t.Parallel()
+
+	var emitted int
+
+	var resourceDefinition *Resource
+	resourceDefinition = &Resource{
+		Importer: &ResourceImporter{
+			StreamStateContext: func(_ context.Context, d *ResourceData, _ interface{}, emit func(*ResourceData) error) error {
+				for i := 0; i < 3; i++ {
+					rd := resourceDefinition.Data(nil)
+					rd.SetId(fmt.Sprintf("%s-%d", d.Id(), i))
+
+					if err := emit(rd); err != nil {
+						return err
+					}
+					emitted++
+				}
+
+				return nil
+			},
+		},
+		Schema: map[string]*Schema{
+			"string_attribute": {
+				Type:     TypeString,
+				Optional: true,
+			},
+		},
+	}
+	resourceTypeName := "test"
+
+	server := NewGRPCProviderServer(&Provider{
+		ResourcesMap: map[string]*Resource{
+			resourceTypeName: resourceDefinition,
+		},
+	})
+
+	schema := resourceDefinition.CoreConfigSchema()
+
+	testReq := &tfprotov5.ImportResourceStateRequest{
+		ID:       "test",
+		TypeName: resourceTypeName,
+	}
+
+	resp, err := server.ImportResourceState(context.Background(), testReq)
+	if err != nil {
+		t.Fatalf("unexpected error during ImportResourceState: %s", err)
+	}
+
+	if len(resp.Diagnostics) > 0 {
+		t.Fatalf("unexpected ImportResourceState diagnostics: %#v", resp.Diagnostics)
+	}
+
+	if len(resp.ImportedResources) != 3 {
+		t.Fatalf("expected 3 ImportedResources, got: %#v", resp.ImportedResources)
+	}
+
+	for i, importedResource := range resp.ImportedResources {
+		gotStateVal, err := msgpack.Unmarshal(importedResource.State.MsgPack, schema.ImpliedType())
+		if err != nil {
+			t.Fatalf("unexpected error during MessagePack unmarshal: %s", err)
+		}
+
+		expectedID := fmt.Sprintf("test-%d", i)
+		if got := gotStateVal.GetAttr("id").AsString(); got != expectedID {
+			t.Errorf("expected id %q, got %q", expectedID, got)
+		}
+	}
+
+	// A cancelled import stops at the first emitted resource.
+	emitted = 0
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	resp, err = server.ImportResourceState(ctx, testReq)
+	if err != nil {
+		t.Fatalf("unexpected error during ImportResourceState: %s", err)
+	}
+
+	if len(resp.Diagnostics) != 1 || resp.Diagnostics[0].Summary != context.Canceled.Error() {
+		t.Fatalf("expected context canceled diagnostic, got: %#v", resp.Diagnostics)
+	}
+
+	if len(resp.ImportedResources) != 0 || emitted != 0 {
+		t.Fatalf("expected no ImportedResources, got %d of %d emitted", len(resp.ImportedResources), emitted)
+	}
+}
+
 func TestImportResourceState_Timeouts_None(t *testing.T) {
 	t.Parallel()
 
@@ -10447,17 +12017,126 @@ func TestReadDataSource(t *testing.T) {
 							"test":      cty.String,
 							"test_bool": cty.Bool,
 						}),
-						cty.UnknownVal(
-							cty.Object(map[string]cty.Type{
-								"id":        cty.String,
-								"test":      cty.String,
-								"test_bool": cty.Bool,
-							}),
-						),
+						cty.UnknownVal(
+							cty.Object(map[string]cty.Type{
+								"id":        cty.String,
+								"test":      cty.String,
+								"test_bool": cty.Bool,
+							}),
+						),
+					),
+				},
+				Deferred: &tfprotov5.Deferred{
+					Reason: tfprotov5.DeferredReasonProviderConfigUnknown,
+				},
+			},
+		},
+		"read-deferred-allowed": {
+			server: NewGRPCProviderServer(&Provider{
+				DataSourcesMap: map[string]*Resource{
+					"test": {
+						Schema: map[string]*Schema{
+							"id": {
+								Type:     TypeString,
+								Computed: true,
+							},
+						},
+						ReadContext: func(ctx context.Context, d *ResourceData, meta interface{}) diag.Diagnostics {
+							return append(diag.Diagnostics{
+								{
+									Severity: diag.Warning,
+									Summary:  "warning",
+								},
+							}, diag.Deferred("network is not created yet")...)
+						},
+					},
+				},
+			}),
+			req: &tfprotov5.ReadDataSourceRequest{
+				ClientCapabilities: &tfprotov5.ReadDataSourceClientCapabilities{
+					DeferralAllowed: true,
+				},
+				TypeName: "test",
+				Config: &tfprotov5.DynamicValue{
+					MsgPack: mustMsgpackMarshal(
+						cty.Object(map[string]cty.Type{
+							"id": cty.String,
+						}),
+						cty.NullVal(cty.Object(map[string]cty.Type{
+							"id": cty.String,
+						})),
+					),
+				},
+			},
+			expected: &tfprotov5.ReadDataSourceResponse{
+				Diagnostics: []*tfprotov5.Diagnostic{
+					{
+						Severity: tfprotov5.DiagnosticSeverityWarning,
+						Summary:  "warning",
+					},
+				},
+				State: &tfprotov5.DynamicValue{
+					MsgPack: mustMsgpackMarshal(
+						cty.Object(map[string]cty.Type{
+							"id": cty.String,
+						}),
+						cty.UnknownVal(
+							cty.Object(map[string]cty.Type{
+								"id": cty.String,
+							}),
+						),
+					),
+				},
+				Deferred: &tfprotov5.Deferred{
+					Reason: tfprotov5.DeferredReasonAbsentPrereq,
+				},
+			},
+		},
+		"read-deferred-not-allowed": {
+			server: NewGRPCProviderServer(&Provider{
+				DataSourcesMap: map[string]*Resource{
+					"test": {
+						Schema: map[string]*Schema{
+							"id": {
+								Type:     TypeString,
+								Computed: true,
+							},
+						},
+						ReadContext: func(ctx context.Context, d *ResourceData, meta interface{}) diag.Diagnostics {
+							return append(diag.Diagnostics{
+								{
+									Severity: diag.Warning,
+									Summary:  "warning",
+								},
+							}, diag.Deferred("network is not created yet")...)
+						},
+					},
+				},
+			}),
+			req: &tfprotov5.ReadDataSourceRequest{
+				TypeName: "test",
+				Config: &tfprotov5.DynamicValue{
+					MsgPack: mustMsgpackMarshal(
+						cty.Object(map[string]cty.Type{
+							"id": cty.String,
+						}),
+						cty.NullVal(cty.Object(map[string]cty.Type{
+							"id": cty.String,
+						})),
 					),
 				},
-				Deferred: &tfprotov5.Deferred{
-					Reason: tfprotov5.DeferredReasonProviderConfigUnknown,
+			},
+			expected: &tfprotov5.ReadDataSourceResponse{
+				Diagnostics: []*tfprotov5.Diagnostic{
+					{
+						Severity: tfprotov5.DiagnosticSeverityWarning,
+						Summary:  "warning",
+					},
+					{
+						Severity: tfprotov5.DiagnosticSeverityError,
+						Summary:  "Read Deferred",
+						Detail:   "network is not created yet",
+					},
 				},
 			},
 		},
@@ -11184,7 +12863,7 @@ func TestValidateNulls(t *testing.T) {
 			default:
 				for _, d := range diags {
 					if d.Severity == diag.Error {
-						t.Fatalf("unexpected error: %q", d)
+						t.Fatalf("unexpected error: %s: %s", d.Summary, d.Detail)
 					}
 				}
 			}
@@ -11423,6 +13102,58 @@ func TestStopContext_stop(t *testing.T) {
 	}
 }
 
+func TestGRPCProviderServerStopProvider_stopFunc(t *testing.T) {
+	testCases := map[string]struct {
+		stopErr       error
+		expectedError string
+	}{
+		"success": {},
+		"error": {
+			stopErr:       errors.New("test error"),
+			expectedError: "test error",
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			var calls int
+
+			server := NewGRPCProviderServer(&Provider{
+				StopFunc: func(ctx context.Context) error {
+					calls++
+
+					if _, ok := ctx.Deadline(); !ok {
+						t.Error("expected StopFunc context to have a deadline")
+					}
+
+					return testCase.stopErr
+				},
+			})
+
+			requestCtx := server.StopContext(context.Background())
+
+			resp, err := server.StopProvider(context.Background(), &tfprotov5.StopProviderRequest{})
+			if err != nil {
+				t.Fatalf("unexpected StopProvider error: %s", err)
+			}
+
+			if calls != 1 {
+				t.Fatalf("expected StopFunc to be called once, got %d", calls)
+			}
+
+			if resp.Error != testCase.expectedError {
+				t.Fatalf("expected error %q, got %q", testCase.expectedError, resp.Error)
+			}
+
+			select {
+			case <-requestCtx.Done():
+			case <-time.After(5 * time.Second):
+				t.Fatal("Stop message did not cancel request context")
+			}
+		})
+	}
+}
+
 func TestStopContext_stopReset(t *testing.T) {
 	testCases := []struct {
 		Description  string
@@ -11598,3 +13329,443 @@ func mustMsgpackUnmarshal(ty cty.Type, b []byte) cty.Value {
 
 	return result
 }
+
+func TestResourceRawConfigMsgPack(t *testing.T) {
+	var planMP, applyMP, readMP []byte
+
+	r := &Resource{
+		Schema: map[string]*Schema{
+			"foo": {
+				Type:     TypeString,
+				Optional: true,
+				DiffSuppressFunc: func(_, _, _ string, rd *ResourceData) bool {
+					planMP, _ = rd.GetRawConfigMsgPack()
+					return false
+				},
+			},
+		},
+		CreateContext: func(_ context.Context, rd *ResourceData, _ interface{}) diag.Diagnostics {
+			rd.SetId("bar")
+			return nil
+		},
+		ReadContext: func(_ context.Context, rd *ResourceData, _ interface{}) diag.Diagnostics {
+			var err error
+			readMP, err = rd.GetRawConfigMsgPack()
+			return diag.FromErr(err)
+		},
+		UpdateContext: func(_ context.Context, rd *ResourceData, _ interface{}) diag.Diagnostics {
+			var err error
+			applyMP, err = rd.GetRawConfigMsgPack()
+			return diag.FromErr(err)
+		},
+		DeleteContext: func(_ context.Context, rd *ResourceData, _ interface{}) diag.Diagnostics {
+			return nil
+		},
+	}
+
+	server := NewGRPCProviderServer(&Provider{
+		ResourcesMap: map[string]*Resource{
+			"test": r,
+		},
+	})
+
+	schema := r.CoreConfigSchema()
+	ty := schema.ImpliedType()
+
+	mustMarshal := func(v cty.Value) *tfprotov5.DynamicValue {
+		t.Helper()
+
+		b, err := msgpack.Marshal(v, ty)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return &tfprotov5.DynamicValue{MsgPack: b}
+	}
+
+	priorState := cty.ObjectVal(map[string]cty.Value{
+		"id":  cty.StringVal("bar"),
+		"foo": cty.StringVal("a"),
+	})
+	config := mustMarshal(cty.ObjectVal(map[string]cty.Value{
+		"id":  cty.NullVal(cty.String),
+		"foo": cty.StringVal("b"),
+	}))
+
+	planResp, err := server.PlanResourceChange(context.Background(), &tfprotov5.PlanResourceChangeRequest{
+		TypeName:   "test",
+		PriorState: mustMarshal(priorState),
+		ProposedNewState: mustMarshal(cty.ObjectVal(map[string]cty.Value{
+			"id":  cty.StringVal("bar"),
+			"foo": cty.StringVal("b"),
+		})),
+		Config: config,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(planResp.Diagnostics) > 0 {
+		t.Fatalf("unexpected plan diagnostics: %#v", planResp.Diagnostics)
+	}
+	if !bytes.Equal(planMP, config.MsgPack) {
+		t.Fatalf("expected plan config %x, got %x", config.MsgPack, planMP)
+	}
+
+	applyResp, err := server.ApplyResourceChange(context.Background(), &tfprotov5.ApplyResourceChangeRequest{
+		TypeName:       "test",
+		PriorState:     mustMarshal(priorState),
+		PlannedState:   planResp.PlannedState,
+		Config:         config,
+		PlannedPrivate: planResp.PlannedPrivate,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(applyResp.Diagnostics) > 0 {
+		t.Fatalf("unexpected apply diagnostics: %#v", applyResp.Diagnostics)
+	}
+	if !bytes.Equal(applyMP, config.MsgPack) {
+		t.Fatalf("expected apply config %x, got %x", config.MsgPack, applyMP)
+	}
+
+	readResp, err := server.ReadResource(context.Background(), &tfprotov5.ReadResourceRequest{
+		TypeName:     "test",
+		CurrentState: applyResp.NewState,
+		Private:      applyResp.Private,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(readResp.Diagnostics) > 0 {
+		t.Fatalf("unexpected read diagnostics: %#v", readResp.Diagnostics)
+	}
+	if readMP != nil {
+		t.Fatalf("expected no config during read, got %x", readMP)
+	}
+}
+
+func TestResourceComputeIfUnset(t *testing.T) {
+	computeValue := func(rd *ResourceData) error {
+		if rd.Get("value").(string) != "" {
+			return nil
+		}
+		return rd.Set("value", "computed")
+	}
+
+	r := &Resource{
+		Schema: map[string]*Schema{
+			"value": {
+				Type:           TypeString,
+				Optional:       true,
+				Computed:       true,
+				ComputeIfUnset: true,
+			},
+			"other": {
+				Type:     TypeString,
+				Optional: true,
+			},
+		},
+		CreateContext: func(_ context.Context, rd *ResourceData, _ interface{}) diag.Diagnostics {
+			rd.SetId("bar")
+			return diag.FromErr(computeValue(rd))
+		},
+		ReadContext: func(_ context.Context, rd *ResourceData, _ interface{}) diag.Diagnostics {
+			return diag.FromErr(rd.Set("value", "remote"))
+		},
+		UpdateContext: func(_ context.Context, rd *ResourceData, _ interface{}) diag.Diagnostics {
+			return diag.FromErr(computeValue(rd))
+		},
+		DeleteContext: func(_ context.Context, rd *ResourceData, _ interface{}) diag.Diagnostics {
+			return nil
+		},
+	}
+
+	server := NewGRPCProviderServer(&Provider{
+		ResourcesMap: map[string]*Resource{
+			"test": r,
+		},
+	})
+
+	ty := r.CoreConfigSchema().ImpliedType()
+
+	mustMarshal := func(t *testing.T, v cty.Value) *tfprotov5.DynamicValue {
+		t.Helper()
+
+		b, err := msgpack.Marshal(v, ty)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return &tfprotov5.DynamicValue{MsgPack: b}
+	}
+
+	mustUnmarshal := func(t *testing.T, v *tfprotov5.DynamicValue) cty.Value {
+		t.Helper()
+
+		val, err := msgpack.Unmarshal(v.MsgPack, ty)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return val
+	}
+
+	config := func(value, other cty.Value) cty.Value {
+		return cty.ObjectVal(map[string]cty.Value{
+			"id":    cty.NullVal(cty.String),
+			"value": value,
+			"other": other,
+		})
+	}
+
+	// plan merges the config into the prior state like Terraform does to
+	// build the proposed new state, then plans and applies the change.
+	planApply := func(t *testing.T, prior cty.Value, private []byte, cfg cty.Value) (cty.Value, cty.Value, []byte) {
+		t.Helper()
+
+		proposed := cfg
+		if !prior.IsNull() {
+			value := cfg.GetAttr("value")
+			if value.IsNull() {
+				value = prior.GetAttr("value")
+			}
+			proposed = cty.ObjectVal(map[string]cty.Value{
+				"id":    prior.GetAttr("id"),
+				"value": value,
+				"other": cfg.GetAttr("other"),
+			})
+		}
+
+		planResp, err := server.PlanResourceChange(context.Background(), &tfprotov5.PlanResourceChangeRequest{
+			TypeName:         "test",
+			PriorState:       mustMarshal(t, prior),
+			ProposedNewState: mustMarshal(t, proposed),
+			Config:           mustMarshal(t, cfg),
+			PriorPrivate:     private,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(planResp.Diagnostics) > 0 {
+			t.Fatalf("unexpected plan diagnostics: %#v", planResp.Diagnostics)
+		}
+
+		applyResp, err := server.ApplyResourceChange(context.Background(), &tfprotov5.ApplyResourceChangeRequest{
+			TypeName:       "test",
+			PriorState:     mustMarshal(t, prior),
+			PlannedState:   planResp.PlannedState,
+			Config:         mustMarshal(t, cfg),
+			PlannedPrivate: planResp.PlannedPrivate,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(applyResp.Diagnostics) > 0 {
+			t.Fatalf("unexpected apply diagnostics: %#v", applyResp.Diagnostics)
+		}
+
+		return mustUnmarshal(t, planResp.PlannedState), mustUnmarshal(t, applyResp.NewState), applyResp.Private
+	}
+
+	refresh := func(t *testing.T, state cty.Value, private []byte) cty.Value {
+		t.Helper()
+
+		readResp, err := server.ReadResource(context.Background(), &tfprotov5.ReadResourceRequest{
+			TypeName:     "test",
+			CurrentState: mustMarshal(t, state),
+			Private:      private,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(readResp.Diagnostics) > 0 {
+			t.Fatalf("unexpected read diagnostics: %#v", readResp.Diagnostics)
+		}
+
+		return mustUnmarshal(t, readResp.NewState)
+	}
+
+	assertValue := func(t *testing.T, step string, val cty.Value, expected cty.Value) {
+		t.Helper()
+
+		if got := val.GetAttr("value"); !got.RawEquals(expected) {
+			t.Fatalf("%s: expected value %#v, got %#v", step, expected, got)
+		}
+	}
+
+	t.Run("config-set", func(t *testing.T) {
+		cfg := config(cty.StringVal("user"), cty.NullVal(cty.String))
+
+		planned, state, private := planApply(t, cty.NullVal(ty), nil, cfg)
+		assertValue(t, "create plan", planned, cty.StringVal("user"))
+		assertValue(t, "create", state, cty.StringVal("user"))
+
+		state = refresh(t, state, private)
+		assertValue(t, "refresh", state, cty.StringVal("user"))
+
+		planned, state, private = planApply(t, state, private, config(cty.StringVal("user"), cty.StringVal("changed")))
+		assertValue(t, "update plan", planned, cty.StringVal("user"))
+		assertValue(t, "update", state, cty.StringVal("user"))
+
+		// Removing the value from the config computes it again.
+		planned, state, private = planApply(t, state, private, config(cty.NullVal(cty.String), cty.StringVal("changed")))
+		assertValue(t, "unset plan", planned, cty.UnknownVal(cty.String))
+		assertValue(t, "unset", state, cty.StringVal("computed"))
+
+		planned, _, _ = planApply(t, state, private, config(cty.NullVal(cty.String), cty.StringVal("changed")))
+		assertValue(t, "unset stable plan", planned, cty.StringVal("computed"))
+	})
+
+	t.Run("config-unset", func(t *testing.T) {
+		cfg := config(cty.NullVal(cty.String), cty.NullVal(cty.String))
+
+		planned, state, private := planApply(t, cty.NullVal(ty), nil, cfg)
+		assertValue(t, "create plan", planned, cty.UnknownVal(cty.String))
+		assertValue(t, "create", state, cty.StringVal("computed"))
+
+		// Computed values are refreshed as usual.
+		state = refresh(t, state, private)
+		assertValue(t, "refresh", state, cty.StringVal("remote"))
+
+		planned, state, _ = planApply(t, state, private, config(cty.NullVal(cty.String), cty.StringVal("changed")))
+		assertValue(t, "update plan", planned, cty.StringVal("remote"))
+		assertValue(t, "update", state, cty.StringVal("remote"))
+	})
+}
+
+func TestResourceDefaultFromState(t *testing.T) {
+	var updated interface{}
+
+	r := &Resource{
+		Schema: map[string]*Schema{
+			"value": {
+				Type:             TypeString,
+				Optional:         true,
+				DefaultFromState: true,
+			},
+			"other": {
+				Type:     TypeString,
+				Optional: true,
+			},
+		},
+		CreateContext: func(_ context.Context, rd *ResourceData, _ interface{}) diag.Diagnostics {
+			rd.SetId("bar")
+			return nil
+		},
+		ReadContext: func(_ context.Context, rd *ResourceData, _ interface{}) diag.Diagnostics {
+			return nil
+		},
+		UpdateContext: func(_ context.Context, rd *ResourceData, _ interface{}) diag.Diagnostics {
+			updated = rd.Get("value")
+			return nil
+		},
+		DeleteContext: func(_ context.Context, rd *ResourceData, _ interface{}) diag.Diagnostics {
+			return nil
+		},
+	}
+
+	server := NewGRPCProviderServer(&Provider{
+		ResourcesMap: map[string]*Resource{
+			"test": r,
+		},
+	})
+
+	ty := r.CoreConfigSchema().ImpliedType()
+
+	prior := cty.ObjectVal(map[string]cty.Value{
+		"id":    cty.StringVal("bar"),
+		"value": cty.StringVal("prior"),
+		"other": cty.NullVal(cty.String),
+	})
+
+	cases := map[string]struct {
+		Value    cty.Value
+		Expected cty.Value
+	}{
+		"update with unset": {
+			Value:    cty.NullVal(cty.String),
+			Expected: cty.StringVal("prior"),
+		},
+		"update with set": {
+			Value:    cty.StringVal("config"),
+			Expected: cty.StringVal("config"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			updated = nil
+
+			config := cty.ObjectVal(map[string]cty.Value{
+				"id":    cty.NullVal(cty.String),
+				"value": tc.Value,
+				"other": cty.StringVal("changed"),
+			})
+			proposed := cty.ObjectVal(map[string]cty.Value{
+				"id":    cty.StringVal("bar"),
+				"value": tc.Value,
+				"other": cty.StringVal("changed"),
+			})
+
+			priorMP, err := msgpack.Marshal(prior, ty)
+			if err != nil {
+				t.Fatal(err)
+			}
+			configMP, err := msgpack.Marshal(config, ty)
+			if err != nil {
+				t.Fatal(err)
+			}
+			proposedMP, err := msgpack.Marshal(proposed, ty)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			planResp, err := server.PlanResourceChange(context.Background(), &tfprotov5.PlanResourceChangeRequest{
+				TypeName:         "test",
+				PriorState:       &tfprotov5.DynamicValue{MsgPack: priorMP},
+				ProposedNewState: &tfprotov5.DynamicValue{MsgPack: proposedMP},
+				Config:           &tfprotov5.DynamicValue{MsgPack: configMP},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(planResp.Diagnostics) > 0 {
+				t.Fatalf("unexpected plan diagnostics: %#v", planResp.Diagnostics)
+			}
+
+			planned, err := msgpack.Unmarshal(planResp.PlannedState.MsgPack, ty)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := planned.GetAttr("value"); !got.RawEquals(tc.Expected) {
+				t.Fatalf("expected planned value %#v, got %#v", tc.Expected, got)
+			}
+
+			applyResp, err := server.ApplyResourceChange(context.Background(), &tfprotov5.ApplyResourceChangeRequest{
+				TypeName:       "test",
+				PriorState:     &tfprotov5.DynamicValue{MsgPack: priorMP},
+				PlannedState:   planResp.PlannedState,
+				Config:         &tfprotov5.DynamicValue{MsgPack: configMP},
+				PlannedPrivate: planResp.PlannedPrivate,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(applyResp.Diagnostics) > 0 {
+				t.Fatalf("unexpected apply diagnostics: %#v", applyResp.Diagnostics)
+			}
+
+			newState, err := msgpack.Unmarshal(applyResp.NewState.MsgPack, ty)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := newState.GetAttr("value"); !got.RawEquals(tc.Expected) {
+				t.Fatalf("expected new state value %#v, got %#v", tc.Expected, got)
+			}
+
+			if updated != tc.Expected.AsString() {
+				t.Fatalf("expected Update to get value %q, got %#v", tc.Expected.AsString(), updated)
+			}
+		})
+	}
+}
@@ -0,0 +1,196 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// JSONDiffOptions configures the comparison performed by the
+// SchemaDiffSuppressFunc returned from NewJSONDiffSuppressFunc.
+type JSONDiffOptions struct {
+	// IgnoreKeyOrder suppresses differences in the ordering of object keys.
+	IgnoreKeyOrder bool
+
+	// IgnoreWhitespace suppresses differences in insignificant whitespace,
+	// such as indentation and newlines between tokens. Whitespace within
+	// string values is always significant.
+	IgnoreWhitespace bool
+
+	// NullAsAbsent treats object members with a null value as if they were
+	// not present, so {"a": 1, "b": null} is considered equal to {"a": 1}.
+	NullAsAbsent bool
+}
+
+// NewJSONDiffSuppressFunc returns a SchemaDiffSuppressFunc for TypeString
+// attributes holding JSON documents. Both the old and new values are parsed
+// and compared according to the given options, with numbers compared by
+// their literal representation.
+//
+// A difference is never suppressed when either value is not valid JSON, so
+// configuration errors are still surfaced in the plan.
+func NewJSONDiffSuppressFunc(opts JSONDiffOptions) SchemaDiffSuppressFunc {
+	return func(k, oldValue, newValue string, d *ResourceData) bool {
+		if oldValue == newValue {
+			return true
+		}
+
+		oldJSON, err := decodeJSONDiffValue(oldValue)
+		if err != nil {
+			return false
+		}
+
+		newJSON, err := decodeJSONDiffValue(newValue)
+		if err != nil {
+			return false
+		}
+
+		if !opts.IgnoreWhitespace && jsonWhitespace(oldValue) != jsonWhitespace(newValue) {
+			return false
+		}
+
+		oldJSON = normalizeJSONDiffValue(oldJSON, opts)
+		newJSON = normalizeJSONDiffValue(newJSON, opts)
+
+		return reflect.DeepEqual(oldJSON, newJSON)
+	}
+}
+
+// jsonDiffMember is a single object member, kept in document order so key
+// ordering can be compared when JSONDiffOptions.IgnoreKeyOrder is unset.
+type jsonDiffMember struct {
+	Key   string
+	Value interface{}
+}
+
+// decodeJSONDiffValue decodes a single JSON document. Objects are decoded
+// into []jsonDiffMember, arrays into []interface{} and numbers into
+// json.Number.
+func decodeJSONDiffValue(s string) (interface{}, error) {
+	dec := json.NewDecoder(strings.NewReader(s))
+	dec.UseNumber()
+
+	v, err := decodeJSONDiffToken(dec)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return nil, errors.New("unexpected data after JSON value")
+	}
+
+	return v, nil
+}
+
+func decodeJSONDiffToken(dec *json.Decoder) (interface{}, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch tok {
+	case json.Delim('{'):
+		members := []jsonDiffMember{}
+		for dec.More() {
+			keyTok, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			key, ok := keyTok.(string)
+			if !ok {
+				return nil, errors.New("invalid JSON object key")
+			}
+			v, err := decodeJSONDiffToken(dec)
+			if err != nil {
+				return nil, err
+			}
+			members = append(members, jsonDiffMember{Key: key, Value: v})
+		}
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+		return members, nil
+	case json.Delim('['):
+		elems := []interface{}{}
+		for dec.More() {
+			v, err := decodeJSONDiffToken(dec)
+			if err != nil {
+				return nil, err
+			}
+			elems = append(elems, v)
+		}
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+		return elems, nil
+	case json.Delim('}'), json.Delim(']'):
+		return nil, errors.New("unexpected JSON delimiter")
+	}
+
+	return tok, nil
+}
+
+func normalizeJSONDiffValue(v interface{}, opts JSONDiffOptions) interface{} {
+	switch v := v.(type) {
+	case []jsonDiffMember:
+		members := make([]jsonDiffMember, 0, len(v))
+		for _, m := range v {
+			if opts.NullAsAbsent && m.Value == nil {
+				continue
+			}
+			members = append(members, jsonDiffMember{
+				Key:   m.Key,
+				Value: normalizeJSONDiffValue(m.Value, opts),
+			})
+		}
+		if opts.IgnoreKeyOrder {
+			sort.SliceStable(members, func(i, j int) bool {
+				return members[i].Key < members[j].Key
+			})
+		}
+		return members
+	case []interface{}:
+		elems := make([]interface{}, len(v))
+		for i, e := range v {
+			elems[i] = normalizeJSONDiffValue(e, opts)
+		}
+		return elems
+	}
+
+	return v
+}
+
+// jsonWhitespace returns the insignificant whitespace of a JSON document in
+// the order it appears, ignoring whitespace within string values.
+func jsonWhitespace(s string) string {
+	var buf bytes.Buffer
+	inString, escaped := false, false
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case inString:
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+		case c == '"':
+			inString = true
+		case c == ' ', c == '\t', c == '\n', c == '\r':
+			buf.WriteByte(c)
+		}
+	}
+
+	return buf.String()
+}
@@ -0,0 +1,157 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package schema
+
+import (
+	"testing"
+)
+
+func TestNewJSONDiffSuppressFunc(t *testing.T) {
+	t.Parallel()
+
+	allOpts := JSONDiffOptions{
+		IgnoreKeyOrder:   true,
+		IgnoreWhitespace: true,
+		NullAsAbsent:     true,
+	}
+
+	cases := map[string]struct {
+		opts     JSONDiffOptions
+		oldValue string
+		newValue string
+		expected bool
+	}{
+		"identical": {
+			oldValue: `{"a":1}`,
+			newValue: `{"a":1}`,
+			expected: true,
+		},
+		"different value": {
+			opts:     allOpts,
+			oldValue: `{"a":1}`,
+			newValue: `{"a":2}`,
+			expected: false,
+		},
+		"different structure": {
+			opts:     allOpts,
+			oldValue: `{"a":1}`,
+			newValue: `{"a":1,"b":2}`,
+			expected: false,
+		},
+		"different number literal": {
+			opts:     allOpts,
+			oldValue: `{"a":1}`,
+			newValue: `{"a":1.0}`,
+			expected: false,
+		},
+		"whitespace ignored": {
+			opts: JSONDiffOptions{IgnoreWhitespace: true},
+			oldValue: `{
+				"a": [1, 2]
+			}`,
+			newValue: `{"a":[1,2]}`,
+			expected: true,
+		},
+		"whitespace not ignored": {
+			oldValue: `{ "a": 1 }`,
+			newValue: `{"a":1}`,
+			expected: false,
+		},
+		"whitespace in string values is significant": {
+			opts:     JSONDiffOptions{IgnoreWhitespace: true},
+			oldValue: `{"a":"x y"}`,
+			newValue: `{"a":"xy"}`,
+			expected: false,
+		},
+		"whitespace in string values not counted as formatting": {
+			oldValue: `{"a":"x y","b":1}`,
+			newValue: `{"b":1,"a":"x y"}`,
+			opts:     JSONDiffOptions{IgnoreKeyOrder: true},
+			expected: true,
+		},
+		"escaped quotes in strings": {
+			opts:     JSONDiffOptions{IgnoreWhitespace: true},
+			oldValue: `{"a": "say \"hi\" "}`,
+			newValue: `{"a":"say \"hi\" "}`,
+			expected: true,
+		},
+		"key order ignored": {
+			opts:     JSONDiffOptions{IgnoreKeyOrder: true},
+			oldValue: `{"a":1,"b":{"c":2,"d":3}}`,
+			newValue: `{"b":{"d":3,"c":2},"a":1}`,
+			expected: true,
+		},
+		"key order not ignored": {
+			oldValue: `{"a":1,"b":2}`,
+			newValue: `{"b":2,"a":1}`,
+			expected: false,
+		},
+		"array order is significant": {
+			opts:     allOpts,
+			oldValue: `[1,2]`,
+			newValue: `[2,1]`,
+			expected: false,
+		},
+		"null as absent": {
+			opts:     JSONDiffOptions{NullAsAbsent: true},
+			oldValue: `{"a":1,"b":null}`,
+			newValue: `{"a":1}`,
+			expected: true,
+		},
+		"null as absent nested": {
+			opts:     allOpts,
+			oldValue: `{"a":[{"b":null,"c":1}]}`,
+			newValue: `{"a": [{"c": 1}]}`,
+			expected: true,
+		},
+		"null not absent": {
+			opts:     JSONDiffOptions{IgnoreWhitespace: true},
+			oldValue: `{"a":1,"b":null}`,
+			newValue: `{"a":1}`,
+			expected: false,
+		},
+		"null array elements are kept": {
+			opts:     allOpts,
+			oldValue: `[1,null]`,
+			newValue: `[1]`,
+			expected: false,
+		},
+		"invalid old value": {
+			opts:     allOpts,
+			oldValue: `{"a":`,
+			newValue: `{"a":1}`,
+			expected: false,
+		},
+		"invalid new value": {
+			opts:     allOpts,
+			oldValue: `{"a":1}`,
+			newValue: `{"a":1`,
+			expected: false,
+		},
+		"trailing data": {
+			opts:     allOpts,
+			oldValue: `{"a":1}`,
+			newValue: `{"a":1} {"a":1}`,
+			expected: false,
+		},
+		"empty old value": {
+			opts:     allOpts,
+			oldValue: ``,
+			newValue: `{}`,
+			expected: false,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			f := NewJSONDiffSuppressFunc(tc.opts)
+
+			if actual := f("policy", tc.oldValue, tc.newValue, nil); actual != tc.expected {
+				t.Fatalf("expected %t, got %t", tc.expected, actual)
+			}
+		})
+	}
+}
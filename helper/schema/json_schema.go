@@ -0,0 +1,185 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/go-cty/cty"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/internal/configs/configschema"
+)
+
+// jsonSchemaDialect is the JSON Schema draft used by Resource.JSONSchema.
+const jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// jsonSchema is the subset of JSON Schema keywords used by
+// Resource.JSONSchema.
+type jsonSchema struct {
+	Schema               string                 `json:"$schema,omitempty"`
+	Type                 string                 `json:"type,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	Deprecated           bool                   `json:"deprecated,omitempty"`
+	Properties           map[string]*jsonSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	AdditionalProperties interface{}            `json:"additionalProperties,omitempty"`
+	Items                *jsonSchema            `json:"items,omitempty"`
+	MinItems             int                    `json:"minItems,omitempty"`
+	MaxItems             int                    `json:"maxItems,omitempty"`
+	UniqueItems          bool                   `json:"uniqueItems,omitempty"`
+}
+
+// JSONSchema returns a JSON Schema (draft 2020-12) representation of the
+// configuration of the resource, including its nested blocks, so that
+// configuration can be validated outside of Terraform. The configuration is
+// expected as a JSON object of literal values, such as the body of the
+// resource in the Terraform JSON configuration syntax.
+//
+// The mapping is lossy:
+//
+//   - Computed attributes which are not Optional are omitted, as they cannot
+//     be configured, and any other attribute is rejected.
+//   - Sets, and blocks with set nesting, are arrays with unique items, as
+//     JSON has no set type. Element order is not significant to Terraform.
+//   - TypeInt and TypeFloat attributes are both numbers, as Terraform does
+//     not distinguish integers.
+//   - Values must have the exact JSON type of the attribute, while Terraform
+//     converts values, such as the string "1" to a number.
+//   - Expressions, such as references or interpolations, are not supported
+//     except in string attributes.
+//   - Schema behaviors other than types, required attributes and block
+//     item counts are not represented, such as validation functions,
+//     ConflictsWith or ExactlyOneOf.
+func (r *Resource) JSONSchema() ([]byte, error) {
+	s, err := jsonSchemaBlock(r.CoreConfigSchema())
+	if err != nil {
+		return nil, err
+	}
+
+	s.Schema = jsonSchemaDialect
+
+	return json.MarshalIndent(s, "", "  ")
+}
+
+func jsonSchemaBlock(b *configschema.Block) (*jsonSchema, error) {
+	s := &jsonSchema{
+		Type:                 "object",
+		Description:          b.Description,
+		Deprecated:           b.Deprecated,
+		Properties:           make(map[string]*jsonSchema, len(b.Attributes)+len(b.BlockTypes)),
+		AdditionalProperties: false,
+	}
+
+	for name, attr := range b.Attributes {
+		if attr.Computed && !attr.Optional {
+			continue
+		}
+
+		prop, err := jsonSchemaType(attr.Type)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+
+		prop.Description = attr.Description
+		prop.Deprecated = attr.Deprecated
+
+		s.Properties[name] = prop
+
+		if attr.Required {
+			s.Required = append(s.Required, name)
+		}
+	}
+
+	for name, block := range b.BlockTypes {
+		nested, err := jsonSchemaBlock(&block.Block)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+
+		switch block.Nesting {
+		case configschema.NestingSingle, configschema.NestingGroup:
+			s.Properties[name] = nested
+
+			if block.MinItems > 0 {
+				s.Required = append(s.Required, name)
+			}
+		case configschema.NestingList, configschema.NestingSet:
+			s.Properties[name] = &jsonSchema{
+				Type:        "array",
+				Items:       nested,
+				MinItems:    block.MinItems,
+				MaxItems:    block.MaxItems,
+				UniqueItems: block.Nesting == configschema.NestingSet,
+			}
+
+			if block.MinItems > 0 {
+				s.Required = append(s.Required, name)
+			}
+		case configschema.NestingMap:
+			s.Properties[name] = &jsonSchema{
+				Type:                 "object",
+				AdditionalProperties: nested,
+			}
+		default:
+			return nil, fmt.Errorf("%s: unsupported nesting mode %s", name, block.Nesting)
+		}
+	}
+
+	sort.Strings(s.Required)
+
+	return s, nil
+}
+
+func jsonSchemaType(t cty.Type) (*jsonSchema, error) {
+	switch {
+	case t == cty.String:
+		return &jsonSchema{Type: "string"}, nil
+	case t == cty.Number:
+		return &jsonSchema{Type: "number"}, nil
+	case t == cty.Bool:
+		return &jsonSchema{Type: "boolean"}, nil
+	case t.IsListType(), t.IsSetType():
+		items, err := jsonSchemaType(t.ElementType())
+		if err != nil {
+			return nil, err
+		}
+
+		return &jsonSchema{
+			Type:        "array",
+			Items:       items,
+			UniqueItems: t.IsSetType(),
+		}, nil
+	case t.IsMapType():
+		elem, err := jsonSchemaType(t.ElementType())
+		if err != nil {
+			return nil, err
+		}
+
+		return &jsonSchema{
+			Type:                 "object",
+			AdditionalProperties: elem,
+		}, nil
+	case t.IsObjectType():
+		s := &jsonSchema{
+			Type:                 "object",
+			Properties:           make(map[string]*jsonSchema, len(t.AttributeTypes())),
+			AdditionalProperties: false,
+		}
+
+		for name, attrType := range t.AttributeTypes() {
+			prop, err := jsonSchemaType(attrType)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+
+			s.Properties[name] = prop
+		}
+
+		return s, nil
+	default:
+		return nil, fmt.Errorf("unsupported type %s", t.FriendlyName())
+	}
+}
@@ -0,0 +1,228 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestResourceJSONSchema(t *testing.T) {
+	r := &Resource{
+		Schema: map[string]*Schema{
+			"name": {
+				Type:        TypeString,
+				Required:    true,
+				Description: "The name.",
+			},
+			"port": {
+				Type:     TypeInt,
+				Optional: true,
+			},
+			"enabled": {
+				Type:       TypeBool,
+				Optional:   true,
+				Deprecated: "Use something else.",
+			},
+			"tags": {
+				Type:     TypeMap,
+				Optional: true,
+				Elem:     &Schema{Type: TypeString},
+			},
+			"zones": {
+				Type:     TypeSet,
+				Optional: true,
+				Elem:     &Schema{Type: TypeString},
+			},
+			"arn": {
+				Type:     TypeString,
+				Computed: true,
+			},
+			"rule": {
+				Type:     TypeList,
+				Required: true,
+				MaxItems: 2,
+				Elem: &Resource{
+					Schema: map[string]*Schema{
+						"action": {
+							Type:     TypeString,
+							Required: true,
+						},
+						"priority": {
+							Type:     TypeFloat,
+							Optional: true,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	raw, err := r.JSONSchema()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var s map[string]interface{}
+	if err := json.Unmarshal(raw, &s); err != nil {
+		t.Fatalf("invalid JSON Schema: %s", err)
+	}
+
+	if s["$schema"] != jsonSchemaDialect {
+		t.Fatalf("expected $schema %q, got %v", jsonSchemaDialect, s["$schema"])
+	}
+
+	cases := map[string]struct {
+		Config string
+		Valid  bool
+	}{
+		"conforming": {
+			Config: `{
+				"name": "test",
+				"port": 8080,
+				"enabled": true,
+				"tags": {"env": "dev"},
+				"zones": ["a", "b"],
+				"rule": [{"action": "allow", "priority": 1.5}]
+			}`,
+			Valid: true,
+		},
+		"minimal": {
+			Config: `{"name": "test", "rule": [{"action": "allow"}]}`,
+			Valid:  true,
+		},
+		"missing required attribute": {
+			Config: `{"rule": [{"action": "allow"}]}`,
+		},
+		"missing required block": {
+			Config: `{"name": "test"}`,
+		},
+		"wrong type": {
+			Config: `{"name": "test", "port": "8080", "rule": [{"action": "allow"}]}`,
+		},
+		"computed-only attribute": {
+			Config: `{"name": "test", "arn": "arn", "rule": [{"action": "allow"}]}`,
+		},
+		"unknown attribute": {
+			Config: `{"name": "test", "other": "x", "rule": [{"action": "allow"}]}`,
+		},
+		"too many blocks": {
+			Config: `{"name": "test", "rule": [{"action": "a"}, {"action": "b"}, {"action": "c"}]}`,
+		},
+		"missing nested required attribute": {
+			Config: `{"name": "test", "rule": [{"priority": 1}]}`,
+		},
+		"duplicate set elements": {
+			Config: `{"name": "test", "zones": ["a", "a"], "rule": [{"action": "allow"}]}`,
+		},
+		"wrong map element type": {
+			Config: `{"name": "test", "tags": {"env": 1}, "rule": [{"action": "allow"}]}`,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var config interface{}
+			if err := json.Unmarshal([]byte(tc.Config), &config); err != nil {
+				t.Fatalf("invalid config: %s", err)
+			}
+
+			err := testValidateJSONSchema(s, config)
+
+			if tc.Valid && err != nil {
+				t.Fatalf("expected config to be valid, got: %s", err)
+			}
+
+			if !tc.Valid && err == nil {
+				t.Fatal("expected config to be invalid")
+			}
+		})
+	}
+}
+
+// testValidateJSONSchema validates a value against the subset of JSON Schema
+// keywords emitted by Resource.JSONSchema.
+func testValidateJSONSchema(s map[string]interface{}, v interface{}) error {
+	switch s["type"] {
+	case "string":
+		if _, ok := v.(string); !ok {
+			return fmt.Errorf("expected string, got %T", v)
+		}
+	case "number":
+		if _, ok := v.(float64); !ok {
+			return fmt.Errorf("expected number, got %T", v)
+		}
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			return fmt.Errorf("expected boolean, got %T", v)
+		}
+	case "array":
+		items, ok := v.([]interface{})
+		if !ok {
+			return fmt.Errorf("expected array, got %T", v)
+		}
+
+		if minItems, ok := s["minItems"].(float64); ok && len(items) < int(minItems) {
+			return fmt.Errorf("expected at least %v items, got %d", minItems, len(items))
+		}
+
+		if maxItems, ok := s["maxItems"].(float64); ok && len(items) > int(maxItems) {
+			return fmt.Errorf("expected at most %v items, got %d", maxItems, len(items))
+		}
+
+		for i, item := range items {
+			if s["uniqueItems"] == true {
+				for _, other := range items[:i] {
+					if reflect.DeepEqual(item, other) {
+						return fmt.Errorf("duplicate item %v", item)
+					}
+				}
+			}
+
+			if err := testValidateJSONSchema(s["items"].(map[string]interface{}), item); err != nil {
+				return fmt.Errorf("%d: %w", i, err)
+			}
+		}
+	case "object":
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("expected object, got %T", v)
+		}
+
+		required, _ := s["required"].([]interface{})
+		for _, k := range required {
+			if _, ok := obj[k.(string)]; !ok {
+				return fmt.Errorf("missing required property %s", k)
+			}
+		}
+
+		properties, _ := s["properties"].(map[string]interface{})
+		for k, pv := range obj {
+			prop, ok := properties[k].(map[string]interface{})
+			if !ok {
+				switch additional := s["additionalProperties"].(type) {
+				case bool:
+					if !additional {
+						return fmt.Errorf("unexpected property %s", k)
+					}
+					continue
+				case map[string]interface{}:
+					prop = additional
+				default:
+					continue
+				}
+			}
+
+			if err := testValidateJSONSchema(prop, pv); err != nil {
+				return fmt.Errorf("%s: %w", k, err)
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported type %v", s["type"])
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package schema
+
+import (
+	"context"
+)
+
+// Operation is the resource operation a ResourceData is passed to, as
+// returned by the ResourceData type Operation method and by
+// OperationFromContext.
+type Operation int
+
+const (
+	// OperationUnknown is returned outside of the create, read, update,
+	// delete and import functions of a resource.
+	OperationUnknown Operation = iota
+
+	// OperationCreate is returned in the create function of a resource.
+	OperationCreate
+
+	// OperationRead is returned in the read function of a managed resource
+	// or data source.
+	OperationRead
+
+	// OperationUpdate is returned in the update function of a resource.
+	OperationUpdate
+
+	// OperationDelete is returned in the delete function of a resource.
+	OperationDelete
+
+	// OperationImport is returned in the import function of a resource.
+	OperationImport
+)
+
+func (o Operation) String() string {
+	switch o {
+	case OperationCreate:
+		return "Create"
+	case OperationRead:
+		return "Read"
+	case OperationUpdate:
+		return "Update"
+	case OperationDelete:
+		return "Delete"
+	case OperationImport:
+		return "Import"
+	}
+	return "Unknown"
+}
+
+// OperationFromContext returns the resource operation of the function
+// receiving ctx, and whether it is known. This allows helpers shared by
+// several operations, which only receive the context, to tell them apart.
+func OperationFromContext(ctx context.Context) (Operation, bool) {
+	op, ok := ctx.Value(operationContextKey).(Operation)
+	return op, ok
+}
+
+// withOperation returns a copy of ctx carrying the given resource operation.
+func withOperation(ctx context.Context, op Operation) context.Context {
+	return context.WithValue(ctx, operationContextKey, op)
+}
@@ -9,9 +9,11 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"reflect"
 	"sort"
 	"strings"
 
+	version "github.com/hashicorp/go-version"
 	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
@@ -73,6 +75,14 @@ type Provider struct {
 	// and must *not* implement Create, Update or Delete.
 	DataSourcesMap map[string]*Resource
 
+	// Functions is the collection of provider-defined functions that this
+	// provider implements, keyed by function name. Function names must be
+	// valid field names.
+	//
+	// Provider-defined functions are only supported in Terraform 1.8 and
+	// later.
+	Functions map[string]*Function
+
 	// ProviderMetaSchema is the schema for the configuration of the meta
 	// information for this provider. If this provider has no meta info,
 	// this can be omitted. This functionality is currently experimental
@@ -103,13 +113,76 @@ type Provider struct {
 	// Terraform sends a cancellation signal.
 	ConfigureProvider func(context.Context, ConfigureProviderRequest, *ConfigureProviderResponse)
 
+	// ValidateMetaFunc is an optional function which is called with the meta
+	// value returned by ConfigureFunc, ConfigureContextFunc, or
+	// ConfigureProvider after configuration succeeds. It can be used to
+	// perform a lightweight check that the configured meta is usable, such
+	// as verifying API connectivity or credentials, in one place.
+	//
+	// If any error diagnostics are returned, configuration is aborted and
+	// the meta value is not stored.
+	ValidateMetaFunc ValidateMetaFunc
+
+	// OnValidationDiagnostic is an optional function which is called for
+	// each diagnostic produced while validating provider, resource, or data
+	// source configuration against its schema, such as to emit metrics on
+	// which attributes most often fail validation. The resource type is
+	// empty for the provider configuration. It cannot alter the returned
+	// diagnostics.
+	OnValidationDiagnostic func(resourceType string, d diag.Diagnostic)
+
+	// DiagnosticsMiddleware is an optional function which is called with
+	// the diagnostics of every RPC response, such as validation, plan and
+	// apply, before they are returned to Terraform. It runs after all
+	// provider functions of the RPC, including OnValidationDiagnostic, and
+	// the returned diagnostics replace those of the response, so they can be
+	// enriched, suppressed or logged. Returning no diagnostics suppresses
+	// all of them, including errors. It is called for every RPC, even when
+	// there are no diagnostics, after duplicate deprecation warnings are
+	// removed when DedupDeprecationWarnings is enabled. Diagnostics with a
+	// severity other than error or warning are not passed to it and are
+	// returned unchanged.
+	DiagnosticsMiddleware func(ctx context.Context, diags diag.Diagnostics) diag.Diagnostics
+
+	// DedupDeprecationWarnings, when enabled, returns each warning of a
+	// Deprecated or DeprecatedValues schema field only once, rather than for
+	// every resource instance using the deprecated attribute. Warnings are
+	// identical when they have the same summary, detail and attribute names,
+	// ignoring list, set and map element keys. As the provider is started
+	// for each Terraform command, each warning is returned once per plan or
+	// apply. Duplicates are removed before DiagnosticsMiddleware is called.
+	DedupDeprecationWarnings bool
+
+	// StopFunc is an optional function which is called when Terraform
+	// requests the provider to stop, after the contexts of in-flight
+	// operations have been cancelled. It allows long-lived providers to
+	// clean up deterministically, such as flushing metrics or closing
+	// pooled connections, rather than relying on the process exit. It may
+	// be called more than once, as Terraform can send multiple stop
+	// requests.
+	//
+	// StopFunc has a bounded window: its context is cancelled after 10
+	// seconds, and Terraform may kill the provider process shortly after
+	// sending the stop request regardless, so it should return promptly.
+	// A returned error is reported to Terraform as the stop error.
+	StopFunc func(ctx context.Context) error
+
 	// configured is enabled after a Configure() call
 	configured bool
 
 	meta interface{}
 
+	// TerraformVersion is the version of Terraform, as sent by Terraform
+	// when configuring the provider. Use TerraformVersionSemver to compare
+	// it with other versions.
 	TerraformVersion string
 
+	// StrictInternalValidate enables stricter checks during InternalValidate.
+	// When disabled, certain likely mistakes, such as a managed resource
+	// without a Read implementation that does not enable SkipRefresh, are
+	// only logged as warnings. When enabled, they are returned as errors.
+	StrictInternalValidate bool
+
 	// deferralAllowed is populated by the ConfigureProvider RPC request and
 	// should only be used during provider configuration.
 	//
@@ -177,6 +250,10 @@ type ConfigureFunc func(*ResourceData) (interface{}, error)
 // structure, etc.
 type ConfigureContextFunc func(context.Context, *ResourceData) (interface{}, diag.Diagnostics)
 
+// ValidateMetaFunc is the function used to validate the meta value returned
+// by provider configuration. See the Provider type ValidateMetaFunc field.
+type ValidateMetaFunc func(context.Context, interface{}) diag.Diagnostics
+
 // InternalValidate should be called to validate the structure
 // of the provider.
 //
@@ -223,7 +300,18 @@ func (p *Provider) InternalValidate() error {
 				validationErrors = append(validationErrors, fmt.Errorf("resource %s identity: %s", k, err))
 			}
 		}
-		if err := r.InternalValidate(nil, true); err != nil {
+		if r.isTopLevel() && !r.readFuncSet() && !r.SkipRefresh {
+			if err := p.strictInternalValidateError(fmt.Errorf("resource %s: Read must be implemented or SkipRefresh must be enabled", k)); err != nil {
+				validationErrors = append(validationErrors, err)
+			}
+		}
+		if err := p.validateSetHashOptionalComputed("resource", k, r); err != nil {
+			validationErrors = append(validationErrors, err)
+		}
+		if err := p.validateEmptyNestedBlocks("resource", k, r); err != nil {
+			validationErrors = append(validationErrors, err)
+		}
+		if err := r.internalValidate(nil, true, true); err != nil {
 			validationErrors = append(validationErrors, fmt.Errorf("resource %s: %s", k, err))
 		}
 	}
@@ -241,11 +329,75 @@ func (p *Provider) InternalValidate() error {
 		if dataSourceSchema.hasWriteOnly() {
 			validationErrors = append(validationErrors, fmt.Errorf("data source %s cannot contain write-only attributes", k))
 		}
+
+		if err := p.validateSetHashOptionalComputed("data source", k, r); err != nil {
+			validationErrors = append(validationErrors, err)
+		}
+
+		if err := p.validateEmptyNestedBlocks("data source", k, r); err != nil {
+			validationErrors = append(validationErrors, err)
+		}
+	}
+
+	for k, f := range p.Functions {
+		if !isValidFieldName(k) {
+			validationErrors = append(validationErrors, fmt.Errorf("function %s: name may only contain lowercase alphanumeric characters & underscores", k))
+		}
+
+		if err := f.InternalValidate(); err != nil {
+			validationErrors = append(validationErrors, fmt.Errorf("function %s: %s", k, err))
+		}
 	}
 
 	return errors.Join(validationErrors...)
 }
 
+// strictInternalValidateError returns the given error if
+// StrictInternalValidate is enabled, otherwise it is logged as a warning
+// and nil is returned.
+func (p *Provider) strictInternalValidateError(err error) error {
+	if p.StrictInternalValidate {
+		return err
+	}
+
+	log.Printf("[WARN] InternalValidate: %s", err)
+
+	return nil
+}
+
+// validateSetHashOptionalComputed checks that TypeSet element schemas using
+// the default hash function do not contain Optional and Computed attributes.
+func (p *Provider) validateSetHashOptionalComputed(kind string, name string, r *Resource) error {
+	if r == nil {
+		return nil
+	}
+
+	attrs := schemaMap(r.SchemaMap()).setHashOptionalComputedAttrs()
+	if len(attrs) == 0 {
+		return nil
+	}
+
+	return p.strictInternalValidateError(fmt.Errorf("%s %s: Optional and Computed attributes in TypeSet elements using the default hash can cause unstable set hashing, "+
+		"set a custom Set function or remove Optional or Computed on: %s", kind, name, strings.Join(attrs, ", ")))
+}
+
+// validateEmptyNestedBlocks checks that nested blocks have at least one
+// attribute. Blocks without attributes are usually a mistake, but some
+// providers use them as presence markers, so this is only an error when
+// StrictInternalValidate is enabled.
+func (p *Provider) validateEmptyNestedBlocks(kind string, name string, r *Resource) error {
+	if r == nil {
+		return nil
+	}
+
+	blocks := schemaMap(r.SchemaMap()).emptyNestedBlocks()
+	if len(blocks) == 0 {
+		return nil
+	}
+
+	return p.strictInternalValidateError(fmt.Errorf("%s %s: Elem *Resource must have at least one attribute on: %s", kind, name, strings.Join(blocks, ", ")))
+}
+
 func isReservedProviderFieldName(name string) bool {
 	for _, reservedName := range ReservedProviderFields {
 		if name == reservedName {
@@ -268,6 +420,52 @@ func (p *Provider) SetMeta(v interface{}) {
 	p.meta = v
 }
 
+// Meta type asserts the meta value passed to resource and data source
+// functions, which is the value returned by the provider ConfigureFunc or
+// ConfigureContextFunc, to T. Instead of panicking like a failed type
+// assertion, an error diagnostic is returned if meta is nil or not a T.
+//
+// For example, in a ReadContext function:
+//
+//	client, diags := schema.Meta[*apiClient](meta)
+//	if diags.HasError() {
+//		return diags
+//	}
+func Meta[T any](meta interface{}) (T, diag.Diagnostics) {
+	var zero T
+
+	// The type is taken from a pointer, as %T of the zero value of an
+	// interface type is <nil>.
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+
+	if meta == nil {
+		return zero, diag.Diagnostics{
+			{
+				Severity: diag.Error,
+				Summary:  "Unconfigured Provider Meta",
+				Detail: fmt.Sprintf("Expected configured provider meta of type %s, got nil. "+
+					"This is always a bug in the provider, the provider may not have been configured. "+
+					"Please report this to the provider developers.", typ),
+			},
+		}
+	}
+
+	v, ok := meta.(T)
+	if !ok {
+		return zero, diag.Diagnostics{
+			{
+				Severity: diag.Error,
+				Summary:  "Unexpected Provider Meta Type",
+				Detail: fmt.Sprintf("Expected configured provider meta of type %s, got %T. "+
+					"This is always a bug in the provider. "+
+					"Please report this to the provider developers.", typ, meta),
+			},
+		}
+	}
+
+	return v, nil
+}
+
 // GetSchema returns the config schema for the main provider
 // configuration, as would appear in a "provider" block in the
 // configuration files.
@@ -307,6 +505,10 @@ func (p *Provider) GetSchema(req *terraform.ProviderSchemaRequest) (*terraform.P
 // The primary use case of this call is to check that required keys are
 // set.
 func (p *Provider) Validate(c *terraform.ResourceConfig) diag.Diagnostics {
+	return p.validate(context.Background(), c)
+}
+
+func (p *Provider) validate(ctx context.Context, c *terraform.ResourceConfig) diag.Diagnostics {
 	if err := p.InternalValidate(); err != nil {
 		return []diag.Diagnostic{
 			{
@@ -319,7 +521,10 @@ func (p *Provider) Validate(c *terraform.ResourceConfig) diag.Diagnostics {
 		}
 	}
 
-	return schemaMap(p.Schema).Validate(c)
+	diags := schemaMap(p.Schema).ValidateContext(ctx, c)
+	p.reportValidationDiagnostics("", diags)
+
+	return diags
 }
 
 // ValidateResource is called once at the beginning with the raw
@@ -333,6 +538,10 @@ func (p *Provider) Validate(c *terraform.ResourceConfig) diag.Diagnostics {
 // are set and that the general structure is correct.
 func (p *Provider) ValidateResource(
 	t string, c *terraform.ResourceConfig) diag.Diagnostics {
+	return p.validateResource(context.Background(), t, c)
+}
+
+func (p *Provider) validateResource(ctx context.Context, t string, c *terraform.ResourceConfig) diag.Diagnostics {
 	r, ok := p.ResourcesMap[t]
 	if !ok {
 		return []diag.Diagnostic{
@@ -343,7 +552,10 @@ func (p *Provider) ValidateResource(
 		}
 	}
 
-	return r.Validate(c)
+	diags := r.ValidateContext(ctx, c)
+	p.reportValidationDiagnostics(t, diags)
+
+	return diags
 }
 
 // Configure configures the provider itself with the configuration
@@ -382,27 +594,30 @@ func (p *Provider) Configure(ctx context.Context, c *terraform.ResourceConfig) d
 		data.config = c
 	}
 
+	meta := p.meta
+
 	if p.ConfigureFunc != nil {
-		meta, err := p.ConfigureFunc(data)
+		var err error
+		meta, err = p.ConfigureFunc(data)
 		if err != nil {
 			return diag.FromErr(err)
 		}
-		p.meta = meta
 	}
 
 	var diags diag.Diagnostics
 
 	if p.ConfigureContextFunc != nil {
-		meta, configureDiags := p.ConfigureContextFunc(ctx, data)
+		var configureDiags diag.Diagnostics
+		meta, configureDiags = p.ConfigureContextFunc(ctx, data)
 		diags = append(diags, configureDiags...)
 
 		if diags.HasError() {
 			return diags
 		}
-
-		p.meta = meta
 	}
 
+	var providerDeferred *Deferred
+
 	if p.ConfigureProvider != nil {
 		req := ConfigureProviderRequest{
 			DeferralAllowed: p.deferralAllowed,
@@ -417,8 +632,24 @@ func (p *Provider) Configure(ctx context.Context, c *terraform.ResourceConfig) d
 			return diags
 		}
 
-		p.meta = resp.Meta
-		p.providerDeferred = resp.Deferred
+		meta = resp.Meta
+		providerDeferred = resp.Deferred
+	}
+
+	if p.ValidateMetaFunc != nil {
+		logging.HelperSchemaTrace(ctx, "Calling downstream")
+		diags = append(diags, p.ValidateMetaFunc(ctx, meta)...)
+		logging.HelperSchemaTrace(ctx, "Called downstream")
+
+		if diags.HasError() {
+			return diags
+		}
+	}
+
+	p.meta = meta
+
+	if p.ConfigureProvider != nil {
+		p.providerDeferred = providerDeferred
 	}
 
 	p.configured = true
@@ -484,15 +715,37 @@ func (p *Provider) ImportStateWithIdentity(
 	info *terraform.InstanceInfo,
 	id string,
 	identity map[string]string) ([]*terraform.InstanceState, error) {
+	var states []*terraform.InstanceState
+	err := p.importStateWithIdentity(ctx, info, id, identity, func(s *terraform.InstanceState) error {
+		states = append(states, s)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return states, nil
+}
+
+// importStateWithIdentity is the streaming equivalent of
+// ImportStateWithIdentity, calling emit with each imported state as soon as
+// the importer returns it. Import stops when emit returns an error, which is
+// then returned.
+func (p *Provider) importStateWithIdentity(
+	ctx context.Context,
+	info *terraform.InstanceInfo,
+	id string,
+	identity map[string]string,
+	emit func(*terraform.InstanceState) error) error {
 	// Find the resource
 	r, ok := p.ResourcesMap[info.Type]
 	if !ok {
-		return nil, fmt.Errorf("unknown resource type: %s", info.Type)
+		return fmt.Errorf("unknown resource type: %s", info.Type)
 	}
 
 	// If it doesn't support import, error
 	if r.Importer == nil {
-		return nil, fmt.Errorf("resource %s doesn't support import", info.Type)
+		return fmt.Errorf("resource %s doesn't support import", info.Type)
 	}
 
 	// Create the data
@@ -503,11 +756,59 @@ func (p *Provider) ImportStateWithIdentity(
 	if data.identitySchema != nil {
 		identityData, err := data.Identity()
 		if err != nil {
-			return nil, err // this should not happen, as we checked above
+			return err // this should not happen, as we checked above
 		}
 		identityData.raw = identity
+		data.priorIdentity = identity
 	} else if identity != nil {
-		return nil, fmt.Errorf("resource %s doesn't support identity import", info.Type)
+		return fmt.Errorf("resource %s doesn't support identity import", info.Type)
+	}
+
+	return p.importStateData(ctx, info.Type, r, data, emit)
+}
+
+// importStateData calls the import function of the resource and emit with
+// the state of each result. Results of a StreamStateContext importer are
+// emitted as they are produced, without collecting them first.
+//
+// The resource type of each result is verified with
+// checkImportedResourceType.
+func (p *Provider) importStateData(ctx context.Context, typeName string, r *Resource, data *ResourceData, emit func(*terraform.InstanceState) error) error {
+	data.operation = OperationImport
+	ctx = withOperation(ctx, OperationImport)
+
+	if r.Importer.StreamStateContext != nil {
+		var emitErr error
+
+		logging.HelperSchemaTrace(ctx, "Calling downstream")
+		err := r.Importer.StreamStateContext(ctx, data, p.meta, func(d *ResourceData) error {
+			if emitErr != nil {
+				return emitErr
+			}
+
+			if err := ctx.Err(); err != nil {
+				emitErr = err
+				return err
+			}
+
+			s, err := importedInstanceState(d)
+			if err == nil {
+				err = p.checkImportedResourceType(typeName, d, s)
+			}
+			if err == nil {
+				err = emit(s)
+			}
+			emitErr = err
+
+			return err
+		})
+		logging.HelperSchemaTrace(ctx, "Called downstream")
+
+		if err != nil {
+			return err
+		}
+
+		return emitErr
 	}
 
 	// Call the import function
@@ -524,43 +825,110 @@ func (p *Provider) ImportStateWithIdentity(
 		logging.HelperSchemaTrace(ctx, "Called downstream")
 
 		if err != nil {
-			return nil, err
+			return err
 		}
 	}
 
-	// Convert the results to InstanceState values and return it
+	// Convert the results to InstanceState values, verifying all of them
+	// before emitting any.
 	states := make([]*terraform.InstanceState, len(results))
 	for i, r := range results {
-		if r == nil {
-			return nil, fmt.Errorf("The provider returned a missing resource during ImportResourceState. " +
-				"This is generally a bug in the resource implementation for import. " +
-				"Resource import code should return an error for missing resources and skip returning a missing or empty ResourceData. " +
-				"Please report this to the provider developers.")
+		s, err := importedInstanceState(r)
+		if err != nil {
+			return err
 		}
 
-		if r.Id() == "" {
-			return nil, fmt.Errorf("The provider returned a resource missing an identifier during ImportResourceState. " +
-				"This is generally a bug in the resource implementation for import. " +
-				"Resource import code should not call d.SetId(\"\") or create an empty ResourceData. " +
-				"If the resource is missing, instead return an error. " +
-				"Please report this to the provider developers.")
+		if err := p.checkImportedResourceType(typeName, r, s); err != nil {
+			return err
 		}
 
-		states[i] = r.State()
+		states[i] = s
 	}
 
-	// Verify that all are non-nil. If there are any nil the error
-	// isn't obvious so we circumvent that with a friendlier error.
 	for _, s := range states {
-		if s == nil {
-			return nil, fmt.Errorf("The provider returned a missing resource during ImportResourceState. " +
-				"This is generally a bug in the resource implementation for import. " +
-				"Resource import code should return an error for missing resources. " +
-				"Please report this to the provider developers.")
+		if err := emit(s); err != nil {
+			return err
 		}
 	}
 
-	return states, nil
+	return nil
+}
+
+// importedInstanceState converts a ResourceData returned by an importer into
+// its InstanceState, returning a friendly error for missing resources.
+func importedInstanceState(r *ResourceData) (*terraform.InstanceState, error) {
+	if r == nil {
+		return nil, fmt.Errorf("The provider returned a missing resource during ImportResourceState. " +
+			"This is generally a bug in the resource implementation for import. " +
+			"Resource import code should return an error for missing resources and skip returning a missing or empty ResourceData. " +
+			"Please report this to the provider developers.")
+	}
+
+	if r.Id() == "" {
+		return nil, fmt.Errorf("The provider returned a resource missing an identifier during ImportResourceState. " +
+			"This is generally a bug in the resource implementation for import. " +
+			"Resource import code should not call d.SetId(\"\") or create an empty ResourceData. " +
+			"If the resource is missing, instead return an error. " +
+			"Please report this to the provider developers.")
+	}
+
+	// Verify that the state is non-nil. If it is nil the error
+	// isn't obvious so we circumvent that with a friendlier error.
+	s := r.State()
+	if s == nil {
+		return nil, fmt.Errorf("The provider returned a missing resource during ImportResourceState. " +
+			"This is generally a bug in the resource implementation for import. " +
+			"Resource import code should return an error for missing resources. " +
+			"Please report this to the provider developers.")
+	}
+
+	return s, nil
+}
+
+// checkImportedResourceType verifies that the resource type of an imported
+// state, which defaults to the imported type when the importer did not call
+// SetType, is a registered resource type whose schema matches the schema of
+// the ResourceData. Otherwise the state would be decoded with the wrong
+// schema, failing later with a confusing error.
+func (p *Provider) checkImportedResourceType(typeName string, d *ResourceData, s *terraform.InstanceState) error {
+	resourceType := s.Ephemeral.Type
+	if resourceType == "" {
+		resourceType = typeName
+	}
+
+	r, ok := p.ResourcesMap[resourceType]
+	if !ok {
+		return fmt.Errorf("The provider returned a resource of unknown type %q during ImportResourceState. "+
+			"This is generally a bug in the resource implementation for import. "+
+			"Resource import code should only call SetType with a resource type of the provider. "+
+			"Please report this to the provider developers.", resourceType)
+	}
+
+	if !sameSchemaAttributes(d.schema, r.SchemaMap()) {
+		return fmt.Errorf("The provider returned a resource of type %q during ImportResourceState whose schema does not match that resource type. "+
+			"This is generally a bug in the resource implementation for import. "+
+			"Resource import code should create the ResourceData with the Data method of the resource matching its type. "+
+			"Please report this to the provider developers.", resourceType)
+	}
+
+	return nil
+}
+
+// sameSchemaAttributes returns whether both schema maps have the same
+// top-level attributes with the same types.
+func sameSchemaAttributes(a, b map[string]*Schema) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for k, v := range a {
+		other, ok := b[k]
+		if !ok || v.Type != other.Type {
+			return false
+		}
+	}
+
+	return true
 }
 
 // ValidateDataSource is called once at the beginning with the raw
@@ -574,6 +942,10 @@ func (p *Provider) ImportStateWithIdentity(
 // are set and that the general structure is correct.
 func (p *Provider) ValidateDataSource(
 	t string, c *terraform.ResourceConfig) diag.Diagnostics {
+	return p.validateDataSource(context.Background(), t, c)
+}
+
+func (p *Provider) validateDataSource(ctx context.Context, t string, c *terraform.ResourceConfig) diag.Diagnostics {
 	r, ok := p.DataSourcesMap[t]
 	if !ok {
 		return []diag.Diagnostic{
@@ -584,7 +956,22 @@ func (p *Provider) ValidateDataSource(
 		}
 	}
 
-	return r.Validate(c)
+	diags := r.ValidateContext(ctx, c)
+	p.reportValidationDiagnostics(t, diags)
+
+	return diags
+}
+
+// reportValidationDiagnostics calls the OnValidationDiagnostic function, if
+// set, for each of the given diagnostics.
+func (p *Provider) reportValidationDiagnostics(resourceType string, diags diag.Diagnostics) {
+	if p.OnValidationDiagnostic == nil {
+		return
+	}
+
+	for _, d := range diags {
+		p.OnValidationDiagnostic(resourceType, d)
+	}
 }
 
 // DataSources returns all of the available data sources that this
@@ -610,6 +997,176 @@ func (p *Provider) DataSources() []terraform.DataSource {
 	return result
 }
 
+// DeprecatedRef identifies a deprecated attribute or block within a
+// resource or data source schema.
+type DeprecatedRef struct {
+	// ResourceType is the name of the resource or data source type.
+	ResourceType string
+
+	// DataSource is true when ResourceType refers to a data source.
+	DataSource bool
+
+	// Path is the dot-separated path to the attribute or block, such as
+	// "rule.port" for a port attribute within a rule block.
+	Path string
+
+	// Message is the Deprecated message of the attribute or block.
+	Message string
+}
+
+// DeprecatedAttributes returns every attribute and block with a Deprecated
+// message in the provider's resources and data sources, including those
+// nested within blocks. This is intended for auditing deprecations, such as
+// in migration tooling.
+//
+// Resources are listed before data sources, each sorted by type name and
+// then by path.
+func (p *Provider) DeprecatedAttributes() []DeprecatedRef {
+	var result []DeprecatedRef
+
+	result = append(result, deprecatedRefs(p.ResourcesMap, false)...)
+	result = append(result, deprecatedRefs(p.DataSourcesMap, true)...)
+
+	return result
+}
+
+func deprecatedRefs(resources map[string]*Resource, dataSource bool) []DeprecatedRef {
+	keys := make([]string, 0, len(resources))
+	for k := range resources {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var result []DeprecatedRef
+	for _, k := range keys {
+		r := resources[k]
+		if r == nil {
+			continue
+		}
+
+		attrs := schemaMap(r.SchemaMap()).deprecatedAttrs()
+
+		paths := make([]string, 0, len(attrs))
+		for path := range attrs {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+
+		for _, path := range paths {
+			result = append(result, DeprecatedRef{
+				ResourceType: k,
+				DataSource:   dataSource,
+				Path:         path,
+				Message:      attrs[path],
+			})
+		}
+	}
+
+	return result
+}
+
+// DefaultPair references a provider attribute and a resource or data source
+// attribute which default the same value, for ValidateDefaultsConsistency.
+type DefaultPair struct {
+	// ProviderAttribute is the name of the top-level provider attribute.
+	ProviderAttribute string
+
+	// ResourceType is the type name of the resource or data source. Managed
+	// resources are looked up before data sources.
+	ResourceType string
+
+	// ResourceAttribute is the name of the top-level resource attribute.
+	ResourceAttribute string
+}
+
+// ValidateDefaultsConsistency compares the Default of the provider and
+// resource attributes of each pair, returning a warning for each pair whose
+// defaults differ. Only static Default values are compared, DefaultFunc is
+// not called.
+//
+// This is opt-in, providers can call it in their unit tests or from
+// ConfigureContextFunc. Pairs referencing an unknown attribute or resource
+// return an error.
+func (p *Provider) ValidateDefaultsConsistency(pairs []DefaultPair) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	for _, pair := range pairs {
+		providerSchema, ok := p.Schema[pair.ProviderAttribute]
+		if !ok || providerSchema == nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Invalid default pair",
+				Detail:   fmt.Sprintf("Provider attribute %q does not exist.", pair.ProviderAttribute),
+			})
+			continue
+		}
+
+		r, ok := p.ResourcesMap[pair.ResourceType]
+		if !ok {
+			r, ok = p.DataSourcesMap[pair.ResourceType]
+		}
+		if !ok || r == nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Invalid default pair",
+				Detail:   fmt.Sprintf("Resource type %q does not exist.", pair.ResourceType),
+			})
+			continue
+		}
+
+		resourceSchema, ok := r.SchemaMap()[pair.ResourceAttribute]
+		if !ok || resourceSchema == nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Invalid default pair",
+				Detail:   fmt.Sprintf("Attribute %q of resource type %q does not exist.", pair.ResourceAttribute, pair.ResourceType),
+			})
+			continue
+		}
+
+		if reflect.DeepEqual(providerSchema.Default, resourceSchema.Default) {
+			continue
+		}
+
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  "Inconsistent default values",
+			Detail: fmt.Sprintf("The provider attribute %q defaults to %#v, but the attribute %q of %s defaults to %#v.",
+				pair.ProviderAttribute, providerSchema.Default, pair.ResourceAttribute, pair.ResourceType, resourceSchema.Default),
+		})
+	}
+
+	return diags
+}
+
+// TerraformVersionSemver returns the version of Terraform in
+// TerraformVersion, as sent by Terraform when configuring the provider, such
+// as to only enable a feature with a minimum version of Terraform:
+//
+//	if v, ok := p.TerraformVersionSemver(); ok && v.GreaterThanOrEqual(version.Must(version.NewVersion("1.9.0"))) {
+//		// ...
+//	}
+//
+// The returned boolean is false when the version is unknown, such as before
+// the provider is configured, when TerraformVersion is empty or "0.0.0", or
+// cannot be parsed.
+func (p *Provider) TerraformVersionSemver() (*version.Version, bool) {
+	if p.TerraformVersion == "" {
+		return nil, false
+	}
+
+	v, err := version.NewVersion(p.TerraformVersion)
+	if err != nil {
+		return nil, false
+	}
+
+	if v.Equal(version.Must(version.NewVersion("0.0.0"))) {
+		return nil, false
+	}
+
+	return v, true
+}
+
 // UserAgent returns a string suitable for use in the User-Agent header of
 // requests generated by the provider. The generated string contains the
 // version of Terraform, the Plugin SDK, and the provider used to generate the
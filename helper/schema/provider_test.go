@@ -5,9 +5,11 @@ package schema
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"reflect"
+	"sort"
 	"strings"
 	"testing"
 	"time"
@@ -1630,6 +1632,58 @@ func TestProviderConfigure(t *testing.T) {
 				"test":  cty.StringVal("test-value"),
 			}),
 		},
+
+		"ValidateMetaFunc-no-diags": {
+			P: &Provider{
+				ConfigureContextFunc: func(ctx context.Context, d *ResourceData) (interface{}, diag.Diagnostics) {
+					return "client", nil
+				},
+				ValidateMetaFunc: func(ctx context.Context, meta interface{}) diag.Diagnostics {
+					if meta != "client" {
+						return diag.Errorf("unexpected meta: %#v", meta)
+					}
+
+					return nil
+				},
+			},
+			Config: cty.EmptyObjectVal,
+		},
+
+		"ValidateMetaFunc-error": {
+			P: &Provider{
+				ConfigureContextFunc: func(ctx context.Context, d *ResourceData) (interface{}, diag.Diagnostics) {
+					return "client", nil
+				},
+				ValidateMetaFunc: func(ctx context.Context, meta interface{}) diag.Diagnostics {
+					return diag.Errorf("invalid credentials")
+				},
+			},
+			Config: cty.EmptyObjectVal,
+			ExpectedDiags: diag.Diagnostics{
+				{
+					Severity: diag.Error,
+					Summary:  "invalid credentials",
+				},
+			},
+		},
+
+		"ValidateMetaFunc-not-called-on-configure-error": {
+			P: &Provider{
+				ConfigureContextFunc: func(ctx context.Context, d *ResourceData) (interface{}, diag.Diagnostics) {
+					return nil, diag.Errorf("configure error")
+				},
+				ValidateMetaFunc: func(ctx context.Context, meta interface{}) diag.Diagnostics {
+					return diag.Errorf("should not be called")
+				},
+			},
+			Config: cty.EmptyObjectVal,
+			ExpectedDiags: diag.Diagnostics{
+				{
+					Severity: diag.Error,
+					Summary:  "configure error",
+				},
+			},
+		},
 	}
 
 	for name, tc := range cases {
@@ -1657,13 +1711,36 @@ func TestProviderConfigure(t *testing.T) {
 
 			diags := tc.P.Configure(context.Background(), c)
 
-			if diff := cmp.Diff(tc.ExpectedDiags, diags); diff != "" {
+			if diff := cmp.Diff(tc.ExpectedDiags, diags, cmp.AllowUnexported(diag.Diagnostic{})); diff != "" {
 				t.Errorf("Unexpected diagnostics (-wanted +got): %s", diff)
 			}
 		})
 	}
 }
 
+func TestProviderConfigure_ValidateMetaFuncError(t *testing.T) {
+	t.Parallel()
+
+	p := &Provider{
+		ConfigureContextFunc: func(ctx context.Context, d *ResourceData) (interface{}, diag.Diagnostics) {
+			return "client", nil
+		},
+		ValidateMetaFunc: func(ctx context.Context, meta interface{}) diag.Diagnostics {
+			return diag.Errorf("invalid credentials")
+		},
+	}
+
+	diags := p.Configure(context.Background(), terraform.NewResourceConfigRaw(nil))
+
+	if !diags.HasError() {
+		t.Fatal("expected error diagnostics")
+	}
+
+	if p.Meta() != nil {
+		t.Errorf("expected meta to not be stored, got: %#v", p.Meta())
+	}
+}
+
 func TestProviderResources(t *testing.T) {
 	cases := []struct {
 		P      *Provider
@@ -1743,6 +1820,230 @@ func TestProviderDataSources(t *testing.T) {
 	}
 }
 
+func TestProviderValidateDefaultsConsistency(t *testing.T) {
+	p := &Provider{
+		Schema: map[string]*Schema{
+			"region": {
+				Type:     TypeString,
+				Optional: true,
+				Default:  "us-east-1",
+			},
+			"retries": {
+				Type:     TypeInt,
+				Optional: true,
+				Default:  3,
+			},
+		},
+		ResourcesMap: map[string]*Resource{
+			"test_resource": {
+				Schema: map[string]*Schema{
+					"region": {
+						Type:     TypeString,
+						Optional: true,
+						Default:  "us-east-1",
+					},
+					"retries": {
+						Type:     TypeInt,
+						Optional: true,
+						Default:  5,
+					},
+				},
+			},
+		},
+		DataSourcesMap: map[string]*Resource{
+			"test_data_source": {
+				Schema: map[string]*Schema{
+					"region": {
+						Type:     TypeString,
+						Optional: true,
+					},
+				},
+			},
+		},
+	}
+
+	cases := map[string]struct {
+		Pairs    []DefaultPair
+		Expected diag.Diagnostics
+	}{
+		"no pairs": {},
+		"consistent": {
+			Pairs: []DefaultPair{
+				{ProviderAttribute: "region", ResourceType: "test_resource", ResourceAttribute: "region"},
+			},
+		},
+		"inconsistent": {
+			Pairs: []DefaultPair{
+				{ProviderAttribute: "region", ResourceType: "test_resource", ResourceAttribute: "region"},
+				{ProviderAttribute: "retries", ResourceType: "test_resource", ResourceAttribute: "retries"},
+			},
+			Expected: diag.Diagnostics{
+				{
+					Severity: diag.Warning,
+					Summary:  "Inconsistent default values",
+					Detail:   `The provider attribute "retries" defaults to 3, but the attribute "retries" of test_resource defaults to 5.`,
+				},
+			},
+		},
+		"inconsistent data source": {
+			Pairs: []DefaultPair{
+				{ProviderAttribute: "region", ResourceType: "test_data_source", ResourceAttribute: "region"},
+			},
+			Expected: diag.Diagnostics{
+				{
+					Severity: diag.Warning,
+					Summary:  "Inconsistent default values",
+					Detail:   `The provider attribute "region" defaults to "us-east-1", but the attribute "region" of test_data_source defaults to <nil>.`,
+				},
+			},
+		},
+		"unknown provider attribute": {
+			Pairs: []DefaultPair{
+				{ProviderAttribute: "zone", ResourceType: "test_resource", ResourceAttribute: "region"},
+			},
+			Expected: diag.Diagnostics{
+				{
+					Severity: diag.Error,
+					Summary:  "Invalid default pair",
+					Detail:   `Provider attribute "zone" does not exist.`,
+				},
+			},
+		},
+		"unknown resource type": {
+			Pairs: []DefaultPair{
+				{ProviderAttribute: "region", ResourceType: "test_other", ResourceAttribute: "region"},
+			},
+			Expected: diag.Diagnostics{
+				{
+					Severity: diag.Error,
+					Summary:  "Invalid default pair",
+					Detail:   `Resource type "test_other" does not exist.`,
+				},
+			},
+		},
+		"unknown resource attribute": {
+			Pairs: []DefaultPair{
+				{ProviderAttribute: "region", ResourceType: "test_resource", ResourceAttribute: "zone"},
+			},
+			Expected: diag.Diagnostics{
+				{
+					Severity: diag.Error,
+					Summary:  "Invalid default pair",
+					Detail:   `Attribute "zone" of resource type "test_resource" does not exist.`,
+				},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			actual := p.ValidateDefaultsConsistency(tc.Pairs)
+
+			if diff := cmp.Diff(tc.Expected, actual, cmp.AllowUnexported(diag.Diagnostic{})); diff != "" {
+				t.Fatalf("unexpected difference: %s", diff)
+			}
+		})
+	}
+}
+
+func TestProviderDeprecatedAttributes(t *testing.T) {
+	cases := map[string]struct {
+		P        *Provider
+		Expected []DeprecatedRef
+	}{
+		"none": {
+			P: &Provider{
+				ResourcesMap: map[string]*Resource{
+					"foo": {
+						Schema: map[string]*Schema{
+							"bar": {Type: TypeString, Optional: true},
+						},
+					},
+				},
+			},
+			Expected: nil,
+		},
+		"top level": {
+			P: &Provider{
+				ResourcesMap: map[string]*Resource{
+					"foo": {
+						Schema: map[string]*Schema{
+							"bar": {Type: TypeString, Optional: true, Deprecated: "use baz"},
+							"baz": {Type: TypeString, Optional: true},
+						},
+					},
+					"nil": nil,
+				},
+				DataSourcesMap: map[string]*Resource{
+					"foo": {
+						Schema: map[string]*Schema{
+							"qux": {Type: TypeInt, Computed: true, Deprecated: "no longer returned"},
+						},
+					},
+				},
+			},
+			Expected: []DeprecatedRef{
+				{ResourceType: "foo", Path: "bar", Message: "use baz"},
+				{ResourceType: "foo", DataSource: true, Path: "qux", Message: "no longer returned"},
+			},
+		},
+		"nested blocks": {
+			P: &Provider{
+				ResourcesMap: map[string]*Resource{
+					"foo": {
+						Schema: map[string]*Schema{
+							"rule": {
+								Type:       TypeSet,
+								Optional:   true,
+								Deprecated: "use rules",
+								Elem: &Resource{
+									Schema: map[string]*Schema{
+										"port": {Type: TypeInt, Optional: true, Deprecated: "use ports"},
+										"nested": {
+											Type:     TypeList,
+											Optional: true,
+											Elem: &Resource{
+												Schema: map[string]*Schema{
+													"name": {Type: TypeString, Optional: true, Deprecated: "use id"},
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+					"bar": {
+						Schema: map[string]*Schema{
+							"tags": {
+								Type:       TypeMap,
+								Optional:   true,
+								Deprecated: "use labels",
+								Elem:       &Schema{Type: TypeString},
+							},
+						},
+					},
+				},
+			},
+			Expected: []DeprecatedRef{
+				{ResourceType: "bar", Path: "tags", Message: "use labels"},
+				{ResourceType: "foo", Path: "rule", Message: "use rules"},
+				{ResourceType: "foo", Path: "rule.nested.name", Message: "use id"},
+				{ResourceType: "foo", Path: "rule.port", Message: "use ports"},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			actual := tc.P.DeprecatedAttributes()
+			if diff := cmp.Diff(tc.Expected, actual); diff != "" {
+				t.Fatalf("unexpected difference: %s", diff)
+			}
+		})
+	}
+}
+
 func TestProviderValidate(t *testing.T) {
 	cases := []struct {
 		P      *Provider
@@ -2084,6 +2385,107 @@ func TestProviderValidateResource(t *testing.T) {
 	}
 }
 
+func TestProviderOnValidationDiagnostic(t *testing.T) {
+	type call struct {
+		ResourceType string
+		Summary      string
+	}
+
+	var calls []call
+
+	testSchema := map[string]*Schema{
+		"required": {
+			Type:     TypeString,
+			Required: true,
+		},
+		"deprecated": {
+			Type:       TypeString,
+			Optional:   true,
+			Deprecated: "use required",
+		},
+	}
+
+	p := &Provider{
+		Schema: testSchema,
+		ResourcesMap: map[string]*Resource{
+			"test_resource": {
+				Schema: testSchema,
+			},
+		},
+		DataSourcesMap: map[string]*Resource{
+			"test_data_source": {
+				Schema: testSchema,
+			},
+		},
+		OnValidationDiagnostic: func(resourceType string, d diag.Diagnostic) {
+			calls = append(calls, call{ResourceType: resourceType, Summary: d.Summary})
+		},
+	}
+
+	config := map[string]interface{}{
+		"deprecated": "foo",
+	}
+
+	cases := map[string]struct {
+		Validate func(*terraform.ResourceConfig) diag.Diagnostics
+		Expected []call
+	}{
+		"provider": {
+			Validate: p.Validate,
+			Expected: []call{
+				{ResourceType: "", Summary: "Missing required argument"},
+				{ResourceType: "", Summary: "Argument is deprecated"},
+			},
+		},
+		"resource": {
+			Validate: func(c *terraform.ResourceConfig) diag.Diagnostics {
+				return p.ValidateResource("test_resource", c)
+			},
+			Expected: []call{
+				{ResourceType: "test_resource", Summary: "Missing required argument"},
+				{ResourceType: "test_resource", Summary: "Argument is deprecated"},
+			},
+		},
+		"data source": {
+			Validate: func(c *terraform.ResourceConfig) diag.Diagnostics {
+				return p.ValidateDataSource("test_data_source", c)
+			},
+			Expected: []call{
+				{ResourceType: "test_data_source", Summary: "Missing required argument"},
+				{ResourceType: "test_data_source", Summary: "Argument is deprecated"},
+			},
+		},
+		"no diagnostics": {
+			Validate: func(c *terraform.ResourceConfig) diag.Diagnostics {
+				return p.ValidateResource("test_resource", terraform.NewResourceConfigRaw(map[string]interface{}{
+					"required": "foo",
+				}))
+			},
+			Expected: nil,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			calls = nil
+
+			diags := tc.Validate(terraform.NewResourceConfigRaw(config))
+
+			sort.Slice(calls, func(i, j int) bool {
+				return calls[i].Summary > calls[j].Summary
+			})
+
+			if diff := cmp.Diff(tc.Expected, calls); diff != "" {
+				t.Fatalf("unexpected calls: %s", diff)
+			}
+
+			if len(diags) != len(calls) {
+				t.Fatalf("expected one call per diagnostic, got %d calls for %d diagnostics", len(calls), len(diags))
+			}
+		})
+	}
+}
+
 func TestProviderImportState(t *testing.T) {
 	t.Parallel()
 
@@ -2155,11 +2557,25 @@ func TestProviderImportState(t *testing.T) {
 			id:          "test-id",
 			expectedErr: fmt.Errorf("The provider returned a resource missing an identifier during ImportResourceState."),
 		},
-		"Importer": {
+		"StreamStateContext": {
 			provider: &Provider{
 				ResourcesMap: map[string]*Resource{
 					"test_resource": {
-						Importer: &ResourceImporter{},
+						Importer: &ResourceImporter{
+							StreamStateContext: func(_ context.Context, d *ResourceData, _ interface{}, emit func(*ResourceData) error) error {
+								for _, id := range []string{"test-id-1", "test-id-2"} {
+									rd := (&Resource{}).Data(nil)
+									rd.SetId(id)
+									rd.SetType("test_resource")
+
+									if err := emit(rd); err != nil {
+										return err
+									}
+								}
+
+								return nil
+							},
+						},
 					},
 				},
 			},
@@ -2169,28 +2585,30 @@ func TestProviderImportState(t *testing.T) {
 			id: "test-id",
 			expectedStates: []*terraform.InstanceState{
 				{
-					Attributes: map[string]string{"id": "test-id"},
+					Attributes: map[string]string{"id": "test-id-1"},
 					Ephemeral:  terraform.EphemeralState{Type: "test_resource"},
-					ID:         "test-id",
+					ID:         "test-id-1",
+					Meta:       map[string]interface{}{"schema_version": "0"},
+				},
+				{
+					Attributes: map[string]string{"id": "test-id-2"},
+					Ephemeral:  terraform.EphemeralState{Type: "test_resource"},
+					ID:         "test-id-2",
 					Meta:       map[string]interface{}{"schema_version": "0"},
 				},
 			},
 		},
-		"Importer-State": {
+		"error-StreamStateContext": {
 			provider: &Provider{
 				ResourcesMap: map[string]*Resource{
 					"test_resource": {
 						Importer: &ResourceImporter{
-							State: func(d *ResourceData, _ interface{}) ([]*ResourceData, error) {
-								if d.Id() != "test-id" {
-									return nil, fmt.Errorf("expected d.Id() %q, got: %s", "test-id", d.Id())
-								}
-
-								if d.State().Ephemeral.Type != "test_resource" {
-									return nil, fmt.Errorf("expected d.State().Ephemeral.Type %q, got: %s", "test_resource", d.State().Ephemeral.Type)
+							StreamStateContext: func(_ context.Context, d *ResourceData, _ interface{}, emit func(*ResourceData) error) error {
+								if err := emit(d); err != nil {
+									return err
 								}
 
-								return []*ResourceData{d}, nil
+								return errors.New("test error")
 							},
 						},
 					},
@@ -2199,25 +2617,95 @@ func TestProviderImportState(t *testing.T) {
 			info: &terraform.InstanceInfo{
 				Type: "test_resource",
 			},
-			id: "test-id",
-			expectedStates: []*terraform.InstanceState{
-				{
-					Attributes: map[string]string{"id": "test-id"},
-					Ephemeral:  terraform.EphemeralState{Type: "test_resource"},
-					ID:         "test-id",
-					Meta:       map[string]interface{}{"schema_version": "0"},
-				},
-			},
+			id:          "test-id",
+			expectedErr: errors.New("test error"),
 		},
-		"Importer-StateContext": {
+		"error-StreamStateContext-missing-ResourceData-Id": {
 			provider: &Provider{
 				ResourcesMap: map[string]*Resource{
 					"test_resource": {
 						Importer: &ResourceImporter{
-							StateContext: func(_ context.Context, d *ResourceData, meta interface{}) ([]*ResourceData, error) {
-								if d.Id() != "test-id" {
-									return nil, fmt.Errorf("expected d.Id() %q, got: %s", "test-id", d.Id())
-								}
+							StreamStateContext: func(_ context.Context, d *ResourceData, _ interface{}, emit func(*ResourceData) error) error {
+								d.SetId("")
+
+								// The emit error is ignored, but still returned.
+								_ = emit(d)
+
+								return nil
+							},
+						},
+					},
+				},
+			},
+			info: &terraform.InstanceInfo{
+				Type: "test_resource",
+			},
+			id:          "test-id",
+			expectedErr: fmt.Errorf("The provider returned a resource missing an identifier during ImportResourceState."),
+		},
+		"Importer": {
+			provider: &Provider{
+				ResourcesMap: map[string]*Resource{
+					"test_resource": {
+						Importer: &ResourceImporter{},
+					},
+				},
+			},
+			info: &terraform.InstanceInfo{
+				Type: "test_resource",
+			},
+			id: "test-id",
+			expectedStates: []*terraform.InstanceState{
+				{
+					Attributes: map[string]string{"id": "test-id"},
+					Ephemeral:  terraform.EphemeralState{Type: "test_resource"},
+					ID:         "test-id",
+					Meta:       map[string]interface{}{"schema_version": "0"},
+				},
+			},
+		},
+		"Importer-State": {
+			provider: &Provider{
+				ResourcesMap: map[string]*Resource{
+					"test_resource": {
+						Importer: &ResourceImporter{
+							State: func(d *ResourceData, _ interface{}) ([]*ResourceData, error) {
+								if d.Id() != "test-id" {
+									return nil, fmt.Errorf("expected d.Id() %q, got: %s", "test-id", d.Id())
+								}
+
+								if d.State().Ephemeral.Type != "test_resource" {
+									return nil, fmt.Errorf("expected d.State().Ephemeral.Type %q, got: %s", "test_resource", d.State().Ephemeral.Type)
+								}
+
+								return []*ResourceData{d}, nil
+							},
+						},
+					},
+				},
+			},
+			info: &terraform.InstanceInfo{
+				Type: "test_resource",
+			},
+			id: "test-id",
+			expectedStates: []*terraform.InstanceState{
+				{
+					Attributes: map[string]string{"id": "test-id"},
+					Ephemeral:  terraform.EphemeralState{Type: "test_resource"},
+					ID:         "test-id",
+					Meta:       map[string]interface{}{"schema_version": "0"},
+				},
+			},
+		},
+		"Importer-StateContext": {
+			provider: &Provider{
+				ResourcesMap: map[string]*Resource{
+					"test_resource": {
+						Importer: &ResourceImporter{
+							StateContext: func(_ context.Context, d *ResourceData, meta interface{}) ([]*ResourceData, error) {
+								if d.Id() != "test-id" {
+									return nil, fmt.Errorf("expected d.Id() %q, got: %s", "test-id", d.Id())
+								}
 
 								if d.State().Ephemeral.Type != "test_resource" {
 									return nil, fmt.Errorf("expected d.State().Ephemeral.Type %q, got: %s", "test_resource", d.State().Ephemeral.Type)
@@ -2271,6 +2759,119 @@ func TestProviderImportState(t *testing.T) {
 	}
 }
 
+func TestProviderImportState_resourceTypeCheck(t *testing.T) {
+	t.Parallel()
+
+	other := &Resource{
+		Schema: map[string]*Schema{
+			"bar": {
+				Type:     TypeString,
+				Optional: true,
+			},
+		},
+	}
+
+	importer := func(f func(d *ResourceData) *ResourceData) *ResourceImporter {
+		return &ResourceImporter{
+			StateContext: func(_ context.Context, d *ResourceData, _ interface{}) ([]*ResourceData, error) {
+				return []*ResourceData{d, f(d)}, nil
+			},
+		}
+	}
+
+	newProvider := func(i *ResourceImporter) *Provider {
+		return &Provider{
+			ResourcesMap: map[string]*Resource{
+				"test_resource": {
+					Schema: map[string]*Schema{
+						"foo": {
+							Type:     TypeString,
+							Optional: true,
+						},
+					},
+					Importer: i,
+				},
+				"test_other": other,
+			},
+		}
+	}
+
+	testCases := map[string]struct {
+		provider    *Provider
+		expectedErr error
+	}{
+		"correct-type": {
+			provider: newProvider(importer(func(_ *ResourceData) *ResourceData {
+				rd := other.Data(nil)
+				rd.SetId("other-id")
+				rd.SetType("test_other")
+				return rd
+			})),
+		},
+		"wrong-type": {
+			provider: newProvider(importer(func(_ *ResourceData) *ResourceData {
+				rd := other.Data(nil)
+				rd.SetId("other-id")
+				rd.SetType("test_resource")
+				return rd
+			})),
+			expectedErr: errors.New(`The provider returned a resource of type "test_resource" during ImportResourceState whose schema does not match that resource type.`),
+		},
+		"wrong-type-without-SetType": {
+			provider: newProvider(importer(func(_ *ResourceData) *ResourceData {
+				rd := other.Data(nil)
+				rd.SetId("other-id")
+				return rd
+			})),
+			expectedErr: errors.New(`The provider returned a resource of type "test_resource" during ImportResourceState whose schema does not match that resource type.`),
+		},
+		"unknown-type": {
+			provider: newProvider(importer(func(_ *ResourceData) *ResourceData {
+				rd := other.Data(nil)
+				rd.SetId("other-id")
+				rd.SetType("test_unknown")
+				return rd
+			})),
+			expectedErr: errors.New(`The provider returned a resource of unknown type "test_unknown" during ImportResourceState.`),
+		},
+		"wrong-type-StreamStateContext": {
+			provider: newProvider(&ResourceImporter{
+				StreamStateContext: func(_ context.Context, _ *ResourceData, _ interface{}, emit func(*ResourceData) error) error {
+					rd := other.Data(nil)
+					rd.SetId("other-id")
+					rd.SetType("test_resource")
+					return emit(rd)
+				},
+			}),
+			expectedErr: errors.New(`The provider returned a resource of type "test_resource" during ImportResourceState whose schema does not match that resource type.`),
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			info := &terraform.InstanceInfo{
+				Type: "test_resource",
+			}
+
+			_, err := testCase.provider.ImportState(context.Background(), info, "test-id")
+
+			if err != nil {
+				if testCase.expectedErr == nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+
+				if !strings.Contains(err.Error(), testCase.expectedErr.Error()) {
+					t.Fatalf("expected error %q, got: %s", testCase.expectedErr, err)
+				}
+			}
+
+			if err == nil && testCase.expectedErr != nil {
+				t.Fatalf("expected error %q, got none", testCase.expectedErr)
+			}
+		})
+	}
+}
+
 func TestProviderImportStateWithIdentity(t *testing.T) {
 	t.Parallel()
 
@@ -2400,6 +3001,100 @@ func TestProviderImportStateWithIdentity(t *testing.T) {
 				},
 			},
 		},
+		"Importer-StateContext-with-prior-identity": {
+			provider: &Provider{
+				ResourcesMap: map[string]*Resource{
+					"test_resource": {
+						Importer: &ResourceImporter{
+							StateContext: func(_ context.Context, d *ResourceData, meta interface{}) ([]*ResourceData, error) {
+								identity, err := d.Identity()
+								if err != nil {
+									return nil, fmt.Errorf("error getting identity: %s", err)
+								}
+								if err := identity.Set("region", "eu-central-1"); err != nil {
+									return nil, fmt.Errorf("error setting identity region: %s", err)
+								}
+
+								prior, err := d.PriorIdentity()
+								if err != nil {
+									return nil, fmt.Errorf("error getting prior identity: %s", err)
+								}
+								if region := prior.Get("region"); region != "" {
+									return nil, fmt.Errorf("expected prior identity region to be empty, got: %s", region)
+								}
+
+								d.SetId(prior.Get("id").(string))
+
+								return []*ResourceData{d}, nil
+							},
+						},
+						Identity: &ResourceIdentity{
+							Version: 1,
+							SchemaFunc: func() map[string]*Schema {
+								return map[string]*Schema{
+									"id": {
+										Type:              TypeString,
+										RequiredForImport: true,
+									},
+									"region": {
+										Type:              TypeString,
+										OptionalForImport: true,
+									},
+								}
+							},
+						},
+					},
+				},
+			},
+			info: &terraform.InstanceInfo{
+				Type: "test_resource",
+			},
+			identity: map[string]string{
+				"id": "test-id",
+			},
+			expectedStates: []*terraform.InstanceState{
+				{
+					Attributes: map[string]string{"id": "test-id"},
+					Ephemeral:  terraform.EphemeralState{Type: "test_resource"},
+					ID:         "test-id",
+					Identity:   map[string]string{"id": "test-id", "region": "eu-central-1"},
+					Meta:       map[string]interface{}{"schema_version": "0"},
+				},
+			},
+		},
+		"Importer-StateContext-without-prior-identity": {
+			provider: &Provider{
+				ResourcesMap: map[string]*Resource{
+					"test_resource": {
+						Importer: &ResourceImporter{
+							StateContext: func(_ context.Context, d *ResourceData, meta interface{}) ([]*ResourceData, error) {
+								if _, err := d.PriorIdentity(); err != nil {
+									return nil, err
+								}
+
+								return []*ResourceData{d}, nil
+							},
+						},
+						Identity: &ResourceIdentity{
+							Version: 1,
+							SchemaFunc: func() map[string]*Schema {
+								return map[string]*Schema{
+									"id": {
+										Type:              TypeString,
+										RequiredForImport: true,
+									},
+								}
+							},
+						},
+					},
+				},
+			},
+			info: &terraform.InstanceInfo{
+				Type: "test_resource",
+			},
+			id:          "test-id",
+			expectedErr: fmt.Errorf("Resource does not have a prior identity."),
+		},
 	}
 
 	for name, testCase := range testCases {
@@ -2442,6 +3137,83 @@ func TestProviderMeta(t *testing.T) {
 	}
 }
 
+func TestMeta(t *testing.T) {
+	type testClient struct {
+		name string
+	}
+
+	client := &testClient{name: "test"}
+
+	actual, diags := Meta[*testClient](client)
+	if diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if actual != client {
+		t.Fatalf("expected %#v, got %#v", client, actual)
+	}
+
+	cases := map[string]struct {
+		Meta            interface{}
+		ExpectedSummary string
+		ExpectedDetail  string
+	}{
+		"nil": {
+			Meta:            nil,
+			ExpectedSummary: "Unconfigured Provider Meta",
+			ExpectedDetail: "Expected configured provider meta of type *schema.testClient, got nil. " +
+				"This is always a bug in the provider, the provider may not have been configured. " +
+				"Please report this to the provider developers.",
+		},
+		"wrong type": {
+			Meta:            testClient{name: "test"},
+			ExpectedSummary: "Unexpected Provider Meta Type",
+			ExpectedDetail: "Expected configured provider meta of type *schema.testClient, got schema.testClient. " +
+				"This is always a bug in the provider. " +
+				"Please report this to the provider developers.",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			actual, diags := Meta[*testClient](tc.Meta)
+
+			if actual != nil {
+				t.Fatalf("expected nil, got %#v", actual)
+			}
+
+			expected := diag.Diagnostics{
+				{
+					Severity: diag.Error,
+					Summary:  tc.ExpectedSummary,
+					Detail:   tc.ExpectedDetail,
+				},
+			}
+
+			if diff := cmp.Diff(expected, diags, cmp.AllowUnexported(diag.Diagnostic{})); diff != "" {
+				t.Fatalf("unexpected difference: %s", diff)
+			}
+		})
+	}
+}
+
+func TestMeta_interface(t *testing.T) {
+	_, diags := Meta[fmt.Stringer](nil)
+
+	expected := diag.Diagnostics{
+		{
+			Severity: diag.Error,
+			Summary:  "Unconfigured Provider Meta",
+			Detail: "Expected configured provider meta of type fmt.Stringer, got nil. " +
+				"This is always a bug in the provider, the provider may not have been configured. " +
+				"Please report this to the provider developers.",
+		},
+	}
+
+	if diff := cmp.Diff(expected, diags, cmp.AllowUnexported(diag.Diagnostic{})); diff != "" {
+		t.Fatalf("unexpected difference: %s", diff)
+	}
+}
+
 func TestProvider_InternalValidate(t *testing.T) {
 	cases := map[string]struct {
 		P           *Provider
@@ -2634,6 +3406,272 @@ func TestProvider_InternalValidate(t *testing.T) {
 			},
 			ExpectedErr: nil,
 		},
+		"Resource with Read returns no errors": {
+			P: &Provider{
+				StrictInternalValidate: true,
+				ResourcesMap: map[string]*Resource{
+					"resource-foo": {
+						CreateContext: NoopContext,
+						ReadContext:   NoopContext,
+						DeleteContext: NoopContext,
+						Schema: map[string]*Schema{
+							"foo": {
+								Type:     TypeString,
+								Required: true,
+								ForceNew: true,
+							},
+						},
+					},
+				},
+			},
+			ExpectedErr: nil,
+		},
+		"Resource without Read returns no errors": {
+			P: &Provider{
+				ResourcesMap: map[string]*Resource{
+					"resource-foo": {
+						CreateContext: NoopContext,
+						DeleteContext: NoopContext,
+						Schema: map[string]*Schema{
+							"foo": {
+								Type:     TypeString,
+								Required: true,
+								ForceNew: true,
+							},
+						},
+					},
+				},
+			},
+			ExpectedErr: nil,
+		},
+		"Resource without Read with StrictInternalValidate returns an error": {
+			P: &Provider{
+				StrictInternalValidate: true,
+				ResourcesMap: map[string]*Resource{
+					"resource-foo": {
+						CreateContext: NoopContext,
+						DeleteContext: NoopContext,
+						Schema: map[string]*Schema{
+							"foo": {
+								Type:     TypeString,
+								Required: true,
+								ForceNew: true,
+							},
+						},
+					},
+				},
+			},
+			ExpectedErr: fmt.Errorf("resource resource-foo: Read must be implemented or SkipRefresh must be enabled"),
+		},
+		"Resource with Optional and Computed attribute in TypeSet element returns no errors": {
+			P: &Provider{
+				ResourcesMap: map[string]*Resource{
+					"resource-foo": {
+						CreateContext: NoopContext,
+						ReadContext:   NoopContext,
+						DeleteContext: NoopContext,
+						Schema:        testSetOptionalComputedSchema(),
+					},
+				},
+			},
+			ExpectedErr: nil,
+		},
+		"Resource with Optional and Computed attribute in TypeSet element with StrictInternalValidate returns an error": {
+			P: &Provider{
+				StrictInternalValidate: true,
+				ResourcesMap: map[string]*Resource{
+					"resource-foo": {
+						CreateContext: NoopContext,
+						ReadContext:   NoopContext,
+						DeleteContext: NoopContext,
+						Schema:        testSetOptionalComputedSchema(),
+					},
+				},
+			},
+			ExpectedErr: fmt.Errorf("resource resource-foo: Optional and Computed attributes in TypeSet elements using the default hash can cause unstable set hashing, " +
+				"set a custom Set function or remove Optional or Computed on: rule.nested.port, rule.port"),
+		},
+		"Data source with Optional and Computed attribute in TypeSet element with StrictInternalValidate returns an error": {
+			P: &Provider{
+				StrictInternalValidate: true,
+				DataSourcesMap: map[string]*Resource{
+					"data-foo": {
+						ReadContext: NoopContext,
+						Schema:      testSetOptionalComputedSchema(),
+					},
+				},
+			},
+			ExpectedErr: fmt.Errorf("data source data-foo: Optional and Computed attributes in TypeSet elements using the default hash can cause unstable set hashing, " +
+				"set a custom Set function or remove Optional or Computed on: rule.nested.port, rule.port"),
+		},
+		"Resource with empty nested block returns no errors": {
+			P: &Provider{
+				ResourcesMap: map[string]*Resource{
+					"resource-foo": {
+						CreateContext: NoopContext,
+						ReadContext:   NoopContext,
+						DeleteContext: NoopContext,
+						Schema:        testEmptyNestedBlockSchema(),
+					},
+				},
+			},
+			ExpectedErr: nil,
+		},
+		"Resource with empty nested block with StrictInternalValidate returns an error": {
+			P: &Provider{
+				StrictInternalValidate: true,
+				ResourcesMap: map[string]*Resource{
+					"resource-foo": {
+						CreateContext: NoopContext,
+						ReadContext:   NoopContext,
+						DeleteContext: NoopContext,
+						Schema:        testEmptyNestedBlockSchema(),
+					},
+				},
+			},
+			ExpectedErr: fmt.Errorf("resource resource-foo: Elem *Resource must have at least one attribute on: block.nested, marker"),
+		},
+		"Data source with empty nested block with StrictInternalValidate returns an error": {
+			P: &Provider{
+				StrictInternalValidate: true,
+				DataSourcesMap: map[string]*Resource{
+					"data-foo": {
+						ReadContext: NoopContext,
+						Schema:      testEmptyNestedBlockSchema(),
+					},
+				},
+			},
+			ExpectedErr: fmt.Errorf("data source data-foo: Elem *Resource must have at least one attribute on: block.nested, marker"),
+		},
+		"Resource with clean TypeSet element with StrictInternalValidate returns no errors": {
+			P: &Provider{
+				StrictInternalValidate: true,
+				ResourcesMap: map[string]*Resource{
+					"resource-foo": {
+						CreateContext: NoopContext,
+						ReadContext:   NoopContext,
+						DeleteContext: NoopContext,
+						Schema: map[string]*Schema{
+							"rule": {
+								Type:     TypeSet,
+								Required: true,
+								ForceNew: true,
+								Elem: &Resource{
+									Schema: map[string]*Schema{
+										"port": {
+											Type:     TypeInt,
+											Optional: true,
+										},
+										"id": {
+											Type:     TypeString,
+											Computed: true,
+										},
+									},
+								},
+							},
+							"custom_hash": {
+								Type:     TypeSet,
+								Optional: true,
+								ForceNew: true,
+								Set:      HashResource(&Resource{Schema: map[string]*Schema{"port": {Type: TypeInt, Optional: true}}}),
+								Elem: &Resource{
+									Schema: map[string]*Schema{
+										"port": {
+											Type:     TypeInt,
+											Optional: true,
+											Computed: true,
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			ExpectedErr: nil,
+		},
+		"Function with invalid name returns an error": {
+			P: &Provider{
+				Functions: map[string]*Function{
+					"Join": testFunctionJoin(),
+				},
+			},
+			ExpectedErr: fmt.Errorf("function Join: name may only contain lowercase alphanumeric characters & underscores"),
+		},
+		"Function without Impl returns an error": {
+			P: &Provider{
+				Functions: map[string]*Function{
+					"join": {
+						Return: cty.String,
+					},
+				},
+			},
+			ExpectedErr: fmt.Errorf("function join: Impl must be implemented"),
+		},
+		"Importable resource with Read returns no errors": {
+			P: &Provider{
+				ResourcesMap: map[string]*Resource{
+					"resource-foo": {
+						CreateContext: NoopContext,
+						ReadContext:   NoopContext,
+						DeleteContext: NoopContext,
+						Importer: &ResourceImporter{
+							StateContext: ImportStatePassthroughContext,
+						},
+						Schema: map[string]*Schema{
+							"foo": {
+								Type:     TypeString,
+								Required: true,
+								ForceNew: true,
+							},
+						},
+					},
+				},
+			},
+			ExpectedErr: nil,
+		},
+		"Importable resource without Read returns an error": {
+			P: &Provider{
+				ResourcesMap: map[string]*Resource{
+					"resource-foo": {
+						CreateContext: NoopContext,
+						DeleteContext: NoopContext,
+						SkipRefresh:   true,
+						Importer: &ResourceImporter{
+							StateContext: ImportStatePassthroughContext,
+						},
+						Schema: map[string]*Schema{
+							"foo": {
+								Type:     TypeString,
+								Required: true,
+								ForceNew: true,
+							},
+						},
+					},
+				},
+			},
+			ExpectedErr: fmt.Errorf("resource resource-foo: Importer requires Read to be implemented"),
+		},
+		"Resource without Read with SkipRefresh returns no errors": {
+			P: &Provider{
+				StrictInternalValidate: true,
+				ResourcesMap: map[string]*Resource{
+					"resource-foo": {
+						CreateContext: NoopContext,
+						DeleteContext: NoopContext,
+						SkipRefresh:   true,
+						Schema: map[string]*Schema{
+							"foo": {
+								Type:     TypeString,
+								Required: true,
+								ForceNew: true,
+							},
+						},
+					},
+				},
+			},
+			ExpectedErr: nil,
+		},
 	}
 
 	for name, tc := range cases {
@@ -2654,6 +3692,129 @@ func TestProvider_InternalValidate(t *testing.T) {
 	}
 }
 
+func testEmptyNestedBlockSchema() map[string]*Schema {
+	return map[string]*Schema{
+		"marker": {
+			Type:     TypeList,
+			Optional: true,
+			ForceNew: true,
+			MaxItems: 1,
+			Elem:     &Resource{},
+		},
+		"block": {
+			Type:     TypeList,
+			Optional: true,
+			ForceNew: true,
+			Elem: &Resource{
+				Schema: map[string]*Schema{
+					"nested": {
+						Type:     TypeSet,
+						Optional: true,
+						Elem: &Resource{
+							Schema: map[string]*Schema{},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func testSetOptionalComputedSchema() map[string]*Schema {
+	return map[string]*Schema{
+		"rule": {
+			Type:     TypeSet,
+			Required: true,
+			ForceNew: true,
+			Elem: &Resource{
+				Schema: map[string]*Schema{
+					"port": {
+						Type:     TypeInt,
+						Optional: true,
+						Computed: true,
+					},
+					"nested": {
+						Type:     TypeSet,
+						Optional: true,
+						Elem: &Resource{
+							Schema: map[string]*Schema{
+								"port": {
+									Type:     TypeInt,
+									Optional: true,
+									Computed: true,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestProviderTerraformVersionSemver(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		terraformVersion string
+		expected         string
+		expectedOk       bool
+	}{
+		"empty": {
+			terraformVersion: "",
+		},
+		"zero": {
+			terraformVersion: "0.0.0",
+		},
+		"invalid": {
+			terraformVersion: "not-a-version",
+		},
+		"release": {
+			terraformVersion: "1.9.5",
+			expected:         "1.9.5",
+			expectedOk:       true,
+		},
+		"prerelease": {
+			terraformVersion: "1.10.0-beta1",
+			expected:         "1.10.0-beta1",
+			expectedOk:       true,
+		},
+		"legacy compatible": {
+			terraformVersion: "0.11+compatible",
+			expected:         "0.11.0+compatible",
+			expectedOk:       true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			p := &Provider{
+				TerraformVersion: tc.terraformVersion,
+			}
+
+			v, ok := p.TerraformVersionSemver()
+
+			if ok != tc.expectedOk {
+				t.Fatalf("expected ok %t, got %t", tc.expectedOk, ok)
+			}
+
+			if !ok {
+				if v != nil {
+					t.Fatalf("expected no version, got %s", v)
+				}
+
+				return
+			}
+
+			if v.String() != tc.expected {
+				t.Fatalf("expected version %s, got %s", tc.expected, v)
+			}
+		})
+	}
+}
+
 func TestProviderUserAgentAppendViaEnvVar(t *testing.T) {
 	if oldenv, isSet := os.LookupEnv(uaEnvVar); isSet {
 		//nolint:usetesting
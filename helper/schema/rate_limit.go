@@ -0,0 +1,102 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package schema
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// RateLimit throttles the create, read, update and delete invocations of a
+// resource type with a token bucket. The bucket holds up to Burst tokens and
+// is refilled with RequestsPerSecond tokens per second, each invocation
+// taking one token or waiting until one is available.
+//
+// The bucket is shared by all invocations within the provider process that
+// use the same RateLimit, which is the case for all instances of a resource
+// type. Throttling is best-effort: Terraform may run multiple provider
+// processes, such as for provider aliases, each with their own bucket, and
+// the API calls made by a single invocation are not throttled individually.
+type RateLimit struct {
+	// RequestsPerSecond is the number of invocations allowed per second on
+	// average. It must be greater than zero.
+	RequestsPerSecond float64
+
+	// Burst is the number of invocations which may run at once without
+	// waiting. It defaults to 1 when zero.
+	Burst int
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+func (l *RateLimit) validate() error {
+	if l.RequestsPerSecond <= 0 {
+		return fmt.Errorf("RateLimit: RequestsPerSecond must be greater than zero")
+	}
+
+	if l.Burst < 0 {
+		return fmt.Errorf("RateLimit: Burst must not be negative")
+	}
+
+	return nil
+}
+
+func (l *RateLimit) burst() float64 {
+	if l.Burst <= 0 {
+		return 1
+	}
+
+	return float64(l.Burst)
+}
+
+// wait blocks until a token is available or the context is done. A nil
+// RateLimit never blocks.
+func (l *RateLimit) wait(ctx context.Context) error {
+	if l == nil || l.RequestsPerSecond <= 0 {
+		return nil
+	}
+
+	l.mu.Lock()
+
+	now := time.Now()
+	if l.last.IsZero() {
+		l.tokens = l.burst()
+	} else {
+		l.tokens += now.Sub(l.last).Seconds() * l.RequestsPerSecond
+		if l.tokens > l.burst() {
+			l.tokens = l.burst()
+		}
+	}
+	l.last = now
+
+	// Reserve a token, possibly going into debt which is paid back by
+	// waiting for the bucket to refill.
+	l.tokens--
+	delay := time.Duration(-l.tokens / l.RequestsPerSecond * float64(time.Second))
+
+	l.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		// Give back the reserved token.
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+
+		return ctx.Err()
+	}
+}
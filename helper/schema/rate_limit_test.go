@@ -0,0 +1,135 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package schema
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestRateLimit_spacing(t *testing.T) {
+	t.Parallel()
+
+	var calls []time.Time
+
+	r := &Resource{
+		Schema: map[string]*Schema{
+			"foo": {
+				Type:     TypeString,
+				Optional: true,
+			},
+		},
+		ReadContext: func(_ context.Context, d *ResourceData, _ interface{}) diag.Diagnostics {
+			calls = append(calls, time.Now())
+			return nil
+		},
+		RateLimit: &RateLimit{
+			RequestsPerSecond: 20,
+			Burst:             2,
+		},
+	}
+
+	for i := 0; i < 5; i++ {
+		_, diags := r.RefreshWithoutUpgrade(context.Background(), &terraform.InstanceState{ID: "bar"}, nil)
+		if diags.HasError() {
+			t.Fatalf("unexpected error: %#v", diags)
+		}
+	}
+
+	// The burst runs without waiting, the remaining reads wait for the
+	// bucket to refill at 50ms per token.
+	if elapsed := calls[1].Sub(calls[0]); elapsed > 25*time.Millisecond {
+		t.Errorf("expected burst reads to run without waiting, got %s", elapsed)
+	}
+
+	if elapsed := calls[4].Sub(calls[0]); elapsed < 140*time.Millisecond {
+		t.Errorf("expected reads to be spaced by the rate limit, got %s for 3 throttled reads", elapsed)
+	}
+}
+
+func TestRateLimit_nil(t *testing.T) {
+	t.Parallel()
+
+	var l *RateLimit
+
+	if err := l.wait(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
+
+func TestRateLimit_contextCanceled(t *testing.T) {
+	t.Parallel()
+
+	l := &RateLimit{
+		RequestsPerSecond: 0.1,
+	}
+
+	if err := l.wait(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := l.wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context deadline exceeded error, got: %v", err)
+	}
+}
+
+func TestRateLimit_validate(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		limit *RateLimit
+		err   bool
+	}{
+		"valid": {
+			limit: &RateLimit{RequestsPerSecond: 1, Burst: 5},
+		},
+		"default burst": {
+			limit: &RateLimit{RequestsPerSecond: 0.5},
+		},
+		"zero rate": {
+			limit: &RateLimit{},
+			err:   true,
+		},
+		"negative burst": {
+			limit: &RateLimit{RequestsPerSecond: 1, Burst: -1},
+			err:   true,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			r := &Resource{
+				Schema: map[string]*Schema{
+					"foo": {
+						Type:     TypeString,
+						Required: true,
+						ForceNew: true,
+					},
+				},
+				Create:    Noop,
+				Read:      Noop,
+				Delete:    Noop,
+				RateLimit: testCase.limit,
+			}
+
+			err := r.InternalValidate(nil, true)
+			if err != nil && !testCase.err {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if err == nil && testCase.err {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package schema
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/go-cty/cty"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/internal/logging"
+)
+
+// RawConfigCheckFunc is a check of the entire raw resource configuration, as
+// registered with a RawConfigValidator. Diagnostics should set their
+// AttributePath to the offending attribute or block where possible.
+type RawConfigCheckFunc func(cfg cty.Value) diag.Diagnostics
+
+// RawConfigValidator is a builder of named checks of the raw resource
+// configuration, which are run as one of the Resource type
+// ValidateRawResourceConfigFuncs. Use it to write cross-field checks without
+// implementing ValidateRawResourceConfigFunc directly.
+//
+//	schema.NewRawConfigValidator().
+//	  Check("exactly one source", schema.ExactlyOneBlockOf("git", "archive")).
+//	  Register(r)
+type RawConfigValidator struct {
+	checks []rawConfigCheck
+}
+
+type rawConfigCheck struct {
+	name  string
+	check RawConfigCheckFunc
+}
+
+// NewRawConfigValidator returns a RawConfigValidator without checks.
+func NewRawConfigValidator() *RawConfigValidator {
+	return &RawConfigValidator{}
+}
+
+// Check adds a named check, which is run after all previously added checks.
+// The name is used for logging.
+func (v *RawConfigValidator) Check(name string, check RawConfigCheckFunc) *RawConfigValidator {
+	v.checks = append(v.checks, rawConfigCheck{
+		name:  name,
+		check: check,
+	})
+
+	return v
+}
+
+// Register adds the validator to the ValidateRawResourceConfigFuncs of the
+// given managed resource. Checks added after registering are also run.
+func (v *RawConfigValidator) Register(r *Resource) {
+	r.ValidateRawResourceConfigFuncs = append(r.ValidateRawResourceConfigFuncs, v.ValidateResourceConfig)
+}
+
+// ValidateResourceConfig runs all checks against the raw configuration. It
+// implements ValidateRawResourceConfigFunc, for use when declaring
+// ValidateRawResourceConfigFuncs directly instead of calling Register.
+func (v *RawConfigValidator) ValidateResourceConfig(ctx context.Context, req ValidateResourceConfigFuncRequest, resp *ValidateResourceConfigFuncResponse) {
+	for _, c := range v.checks {
+		logging.HelperSchemaTrace(ctx, "Calling raw config check", map[string]interface{}{"check": c.name})
+		resp.Diagnostics = append(resp.Diagnostics, c.check(req.RawConfig)...)
+	}
+}
+
+// ExactlyOneBlockOf returns a RawConfigCheckFunc that requires exactly one of
+// the given top level nested blocks or attributes to be present. Nested
+// blocks are present when they have at least one element, and attributes
+// when they are not null.
+//
+// The check is skipped while any of them is unknown, such as for dynamic
+// blocks with an unknown for_each, as their presence cannot be determined.
+func ExactlyOneBlockOf(names ...string) RawConfigCheckFunc {
+	return func(cfg cty.Value) diag.Diagnostics {
+		present, diags := rawConfigPresentBlocks(cfg, names)
+		if present == nil || diags.HasError() {
+			return diags
+		}
+
+		switch {
+		case len(present) == 0:
+			return diag.Diagnostics{
+				{
+					Severity: diag.Error,
+					Summary:  "Missing required argument",
+					Detail:   fmt.Sprintf("One of `%s` must be specified.", strings.Join(names, ",")),
+				},
+			}
+		case len(present) > 1:
+			return diag.Diagnostics{
+				{
+					Severity:      diag.Error,
+					Summary:       "Invalid combination of arguments",
+					Detail:        fmt.Sprintf("Only one of `%s` can be specified, but `%s` were specified.", strings.Join(names, ","), strings.Join(present, ",")),
+					AttributePath: cty.GetAttrPath(present[1]),
+				},
+			}
+		}
+
+		return nil
+	}
+}
+
+// AtLeastOneBlockOf returns a RawConfigCheckFunc that requires at least one
+// of the given top level nested blocks or attributes to be present, with the
+// same presence rules as ExactlyOneBlockOf.
+func AtLeastOneBlockOf(names ...string) RawConfigCheckFunc {
+	return func(cfg cty.Value) diag.Diagnostics {
+		present, diags := rawConfigPresentBlocks(cfg, names)
+		if present == nil || diags.HasError() {
+			return diags
+		}
+
+		if len(present) == 0 {
+			return diag.Diagnostics{
+				{
+					Severity: diag.Error,
+					Summary:  "Missing required argument",
+					Detail:   fmt.Sprintf("At least one of `%s` must be specified.", strings.Join(names, ",")),
+				},
+			}
+		}
+
+		return nil
+	}
+}
+
+// rawConfigPresentBlocks returns the names of the given top level nested
+// blocks or attributes which are present in the configuration. A nil result
+// is returned if the presence of any of them is unknown.
+func rawConfigPresentBlocks(cfg cty.Value, names []string) ([]string, diag.Diagnostics) {
+	if cfg.IsNull() || !cfg.IsKnown() {
+		return nil, nil
+	}
+
+	present := []string{}
+	for _, name := range names {
+		if !cfg.Type().IsObjectType() || !cfg.Type().HasAttribute(name) {
+			return nil, diag.Diagnostics{
+				{
+					Severity: diag.Error,
+					Summary:  "Invalid raw config check",
+					Detail: fmt.Sprintf("%q is not a top level attribute or block of the resource. "+
+						"This is always an issue in the Terraform Provider and should be reported to the provider developers.", name),
+				},
+			}
+		}
+
+		v := cfg.GetAttr(name)
+		if !v.IsKnown() {
+			return nil, nil
+		}
+
+		if v.IsNull() {
+			continue
+		}
+
+		if ty := v.Type(); (ty.IsListType() || ty.IsSetType()) && v.LengthInt() == 0 {
+			continue
+		}
+
+		present = append(present, name)
+	}
+
+	return present, nil
+}
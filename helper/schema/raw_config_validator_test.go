@@ -0,0 +1,152 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package schema
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/go-cty/cty"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+)
+
+func TestRawConfigValidator(t *testing.T) {
+	blockType := cty.List(cty.Object(map[string]cty.Type{
+		"url": cty.String,
+	}))
+	block := cty.ListVal([]cty.Value{
+		cty.ObjectVal(map[string]cty.Value{
+			"url": cty.StringVal("https://example.com"),
+		}),
+	})
+
+	config := func(git, archive, name cty.Value) cty.Value {
+		return cty.ObjectVal(map[string]cty.Value{
+			"git":     git,
+			"archive": archive,
+			"name":    name,
+		})
+	}
+
+	cases := map[string]struct {
+		Check    RawConfigCheckFunc
+		Config   cty.Value
+		Expected diag.Diagnostics
+	}{
+		"exactly one present": {
+			Check:  ExactlyOneBlockOf("git", "archive"),
+			Config: config(block, cty.ListValEmpty(blockType.ElementType()), cty.NullVal(cty.String)),
+		},
+		"exactly one attribute present": {
+			Check:  ExactlyOneBlockOf("git", "name"),
+			Config: config(cty.ListValEmpty(blockType.ElementType()), cty.ListValEmpty(blockType.ElementType()), cty.StringVal("a")),
+		},
+		"exactly one none present": {
+			Check:  ExactlyOneBlockOf("git", "archive"),
+			Config: config(cty.ListValEmpty(blockType.ElementType()), cty.NullVal(blockType), cty.NullVal(cty.String)),
+			Expected: diag.Diagnostics{
+				{
+					Severity: diag.Error,
+					Summary:  "Missing required argument",
+					Detail:   "One of `git,archive` must be specified.",
+				},
+			},
+		},
+		"exactly one multiple present": {
+			Check:  ExactlyOneBlockOf("git", "archive"),
+			Config: config(block, block, cty.NullVal(cty.String)),
+			Expected: diag.Diagnostics{
+				{
+					Severity:      diag.Error,
+					Summary:       "Invalid combination of arguments",
+					Detail:        "Only one of `git,archive` can be specified, but `git,archive` were specified.",
+					AttributePath: cty.GetAttrPath("archive"),
+				},
+			},
+		},
+		"exactly one unknown": {
+			Check:  ExactlyOneBlockOf("git", "archive"),
+			Config: config(block, cty.UnknownVal(blockType), cty.NullVal(cty.String)),
+		},
+		"exactly one unknown config": {
+			Check:  ExactlyOneBlockOf("git", "archive"),
+			Config: cty.UnknownVal(config(block, block, cty.NullVal(cty.String)).Type()),
+		},
+		"at least one present": {
+			Check:  AtLeastOneBlockOf("git", "archive"),
+			Config: config(block, block, cty.NullVal(cty.String)),
+		},
+		"at least one none present": {
+			Check:  AtLeastOneBlockOf("git", "archive"),
+			Config: config(cty.ListValEmpty(blockType.ElementType()), cty.ListValEmpty(blockType.ElementType()), cty.NullVal(cty.String)),
+			Expected: diag.Diagnostics{
+				{
+					Severity: diag.Error,
+					Summary:  "Missing required argument",
+					Detail:   "At least one of `git,archive` must be specified.",
+				},
+			},
+		},
+		"unknown name": {
+			Check:  ExactlyOneBlockOf("git", "missing"),
+			Config: config(block, block, cty.NullVal(cty.String)),
+			Expected: diag.Diagnostics{
+				{
+					Severity: diag.Error,
+					Summary:  "Invalid raw config check",
+					Detail: "\"missing\" is not a top level attribute or block of the resource. " +
+						"This is always an issue in the Terraform Provider and should be reported to the provider developers.",
+				},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			r := &Resource{}
+			NewRawConfigValidator().Check("test", tc.Check).Register(r)
+
+			if len(r.ValidateRawResourceConfigFuncs) != 1 {
+				t.Fatalf("expected 1 ValidateRawResourceConfigFunc, got %d", len(r.ValidateRawResourceConfigFuncs))
+			}
+
+			resp := &ValidateResourceConfigFuncResponse{}
+			r.ValidateRawResourceConfigFuncs[0](context.Background(), ValidateResourceConfigFuncRequest{RawConfig: tc.Config}, resp)
+
+			if diff := cmp.Diff(tc.Expected, resp.Diagnostics, cmp.Comparer(cty.Path.Equals), cmp.AllowUnexported(diag.Diagnostic{})); diff != "" {
+				t.Fatalf("unexpected difference: %s", diff)
+			}
+		})
+	}
+}
+
+func TestRawConfigValidator_checkOrder(t *testing.T) {
+	var calls []string
+
+	check := func(name string) RawConfigCheckFunc {
+		return func(cfg cty.Value) diag.Diagnostics {
+			calls = append(calls, name)
+			return diag.Errorf("%s failed", name)
+		}
+	}
+
+	v := NewRawConfigValidator().Check("first", check("first"))
+
+	r := &Resource{}
+	v.Register(r)
+	v.Check("second", check("second"))
+
+	resp := &ValidateResourceConfigFuncResponse{}
+	r.ValidateRawResourceConfigFuncs[0](context.Background(), ValidateResourceConfigFuncRequest{RawConfig: cty.EmptyObjectVal}, resp)
+
+	if diff := cmp.Diff([]string{"first", "second"}, calls); diff != "" {
+		t.Fatalf("unexpected calls: %s", diff)
+	}
+
+	if len(resp.Diagnostics) != 2 {
+		t.Fatalf("expected 2 diagnostics, got %d", len(resp.Diagnostics))
+	}
+}
@@ -7,7 +7,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
 	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/hashicorp/go-cty/cty"
 
@@ -89,6 +93,19 @@ type Resource struct {
 	// their Versioning at any integer >= 1
 	SchemaVersion int
 
+	// SchemaVersionMissingBehavior controls how UpgradeResourceState treats
+	// a stored schema version which the resource cannot upgrade from. This
+	// field is only valid when the Resource is a managed resource.
+	//
+	// Terraform sends a state without a recorded schema version, such as a
+	// state imported from a resource which was not implemented with this
+	// SDK, as version 0. By default, such a state is assumed to be version 0
+	// and decoded with the current schema if no MigrateState or
+	// StateUpgraders handle version 0. Set this field to
+	// SchemaVersionMissingError to return an error instead, which is also
+	// returned for a stored schema version greater than SchemaVersion.
+	SchemaVersionMissingBehavior SchemaVersionMissingBehavior
+
 	// Identity is a nested structure containing information about the structure
 	// and type of this resource's identity. This field is only valid when the
 	// Resource is a managed resource.
@@ -122,9 +139,9 @@ type Resource struct {
 	//
 	// StateUpgraders map specific schema versions to a StateUpgrader
 	// function. The registered versions are expected to be ordered,
-	// consecutive values. The initial value may be greater than 0 to account
-	// for legacy schemas that weren't recorded and can be handled by
-	// MigrateState.
+	// consecutive values ending at SchemaVersion-1. The initial value must be
+	// 0, unless MigrateState is set to handle legacy schemas that weren't
+	// recorded, in which case it may be greater than 0.
 	StateUpgraders []StateUpgrader
 
 	// Create is called when the provider must create a new instance of a
@@ -305,11 +322,22 @@ type Resource struct {
 	// the SetId method with an empty string ("") parameter and without
 	// returning an error.
 	//
+	// Managed resources can call IsRefresh with the context to check whether
+	// Terraform is refreshing the state to detect drift, rather than the
+	// provider reading after a create or update, or Terraform reading to
+	// complete an import, for example to skip attributes which are expensive
+	// to read.
+	//
 	// Data resources that are designed to return state for a singular
 	// infrastructure component should conventionally return an error if that
 	// infrastructure does not exist and omit any calls to the
 	// SetId method.
 	//
+	// Data resources that cannot be read yet, such as when infrastructure
+	// they depend on is not created yet, can return diag.Deferred. If
+	// Terraform supports deferred actions, the read is deferred to a later
+	// plan with an unknown state, otherwise it is reported as an error.
+	//
 	// The interface{} parameter is the result of the Provider type
 	// ConfigureFunc field execution. If the Provider does not define
 	// a ConfigureFunc, this will be nil. This parameter is conventionally
@@ -529,7 +557,9 @@ type Resource struct {
 	// The *ResourceDiff parameter is similar to ResourceData but replaces the
 	// Set method with other difference handling methods, such as SetNew,
 	// SetNewComputed, and ForceNew. In general, only Schema with Computed
-	// enabled can have those methods executed against them.
+	// enabled can have those methods executed against them. The Defer method
+	// defers the change to a later plan when Terraform supports deferred
+	// actions.
 	//
 	// The phases Terraform runs this in, and the state available via functions
 	// like Get and GetChange, are as follows:
@@ -552,6 +582,30 @@ type Resource struct {
 	// diagnostic when passed back to Terraform.
 	CustomizeDiff CustomizeDiffFunc
 
+	// CustomizeDiffContext is an alternative to CustomizeDiff which returns
+	// diagnostics instead of an error, so that multiple problems, or warnings
+	// which do not abort the plan, can be reported. It is called in the same
+	// phases and with the same parameters as CustomizeDiff. This field is
+	// only valid when the Resource is a managed resource.
+	//
+	// The diagnostics return parameter, if not nil, can contain any
+	// combination and multiple of warning and/or error diagnostics. Any
+	// error diagnostic aborts the plan. Outside of a plan requested by
+	// Terraform, such as with the Diff method, warnings are dropped and error
+	// diagnostics are returned as an error.
+	//
+	// Only one of CustomizeDiff or CustomizeDiffContext should be
+	// implemented.
+	CustomizeDiffContext CustomizeDiffContextFunc
+
+	// SkipRefresh indicates that the managed resource has no remote object
+	// to refresh, such as a resource which only performs an action during
+	// create or delete. When enabled, the Read, ReadContext, and
+	// ReadWithoutTimeout fields are not required and refresh operations
+	// return the prior state unchanged. This field is only valid when the
+	// Resource is a managed resource.
+	SkipRefresh bool
+
 	// Importer is called when the provider must import an instance of a
 	// managed resource. This field is only valid when the Resource is a
 	// managed resource.
@@ -559,7 +613,8 @@ type Resource struct {
 	// If this is nil, then this resource does not support importing. If
 	// this is non-nil, then it supports importing and ResourceImporter
 	// must be validated. The validity of ResourceImporter is verified
-	// by InternalValidate on Resource.
+	// by InternalValidate on Resource. Importing resources must implement
+	// Read, which populates the state after import.
 	Importer *ResourceImporter
 
 	// If non-empty, this string is emitted as the details of a warning
@@ -586,6 +641,24 @@ type Resource struct {
 	// always overrides any default values set here, whether shorter or longer.
 	Timeouts *ResourceTimeout
 
+	// MaxOperationTimeout, when positive, caps the operation timeouts
+	// returned by the ResourceData Timeout method, including the 20 minute
+	// system default and practitioner configured timeouts, so that an
+	// operation cannot wait longer than this duration. Unlike the
+	// ResourceTimeout MaxConfigurable field, larger configured timeouts are
+	// silently reduced instead of being rejected.
+	//
+	// The create, read, update and delete context deadlines are derived from
+	// the Timeout method, so they are capped as well, except for the
+	// *WithoutTimeout functions, which do not receive a deadline and must
+	// apply the Timeout method result themselves.
+	MaxOperationTimeout time.Duration
+
+	// RateLimit throttles the create, read, update and delete invocations
+	// of this resource type within the provider process. A nil value
+	// disables throttling. See RateLimit for the scope of the throttling.
+	RateLimit *RateLimit
+
 	// Description is used as the description for docs, the language server and
 	// other user facing usage. It can be plain-text or markdown depending on the
 	// global DescriptionKind setting. This field is valid for any Resource.
@@ -602,6 +675,29 @@ type Resource struct {
 	// details.
 	UseJSONNumber bool
 
+	// DisableImplicitID prevents the SDK from adding the implicit "id"
+	// attribute to the resource schema. The resource must then declare its
+	// own TypeString "id" attribute, which may be Required instead of
+	// Computed and use validation like any other attribute. The
+	// ResourceData SetId and Id methods keep working against the declared
+	// attribute. This field is only valid when the Resource is a managed
+	// resource.
+	DisableImplicitID bool
+
+	// IDDescription is the description of the implicit "id" attribute added
+	// to the schema, such as for documentation generated from the schema. It
+	// can be plain-text or markdown depending on the global DescriptionKind
+	// setting. It is invalid when the resource declares its own "id"
+	// attribute, which should set its Description instead.
+	IDDescription string
+
+	// ValidateIDFunc is called by the ResourceData SetIdErr method to
+	// validate an ID before it is set, in addition to the checks SetIdErr
+	// always performs. Returning an error prevents the ID from being set.
+	// It is not called by SetId. This field is only valid when the Resource
+	// is a managed resource.
+	ValidateIDFunc func(id string) error
+
 	// EnableLegacyTypeSystemApplyErrors when enabled will prevent the SDK from
 	// setting the legacy type system flag in the protocol during
 	// ApplyResourceChange (Create, Update, and Delete) operations. Before
@@ -663,6 +759,12 @@ type Resource struct {
 	// Developers should prefer other validation methods first as this validation function
 	// deals with raw cty values.
 	ValidateRawResourceConfigFuncs []ValidateRawResourceConfigFunc
+
+	// diffKeys caches the flatmap key templates of the schema when the
+	// Resource is the Elem of a block, see diffKeyAttrs.
+	// It holds a *diffKeyTemplates. An atomic.Value is used rather than
+	// atomic.Pointer, so copying a Resource value remains valid.
+	diffKeys atomic.Value
 }
 
 // ResourceBehavior controls SDK-specific logic when interacting
@@ -713,6 +815,33 @@ type ValidateResourceConfigFuncResponse struct {
 	Diagnostics diag.Diagnostics
 }
 
+// GetRawConfigAt returns the value at the given path of RawConfig, with the
+// same behavior as the ResourceData type GetRawConfigAt method.
+func (req ValidateResourceConfigFuncRequest) GetRawConfigAt(valPath cty.Path) (cty.Value, diag.Diagnostics) {
+	return getRawConfigAt(req.RawConfig, valPath)
+}
+
+// AddAttributeError appends an error diagnostic for the attribute at the
+// given path of the configuration.
+func (resp *ValidateResourceConfigFuncResponse) AddAttributeError(path cty.Path, summary, detail string) {
+	resp.addAttributeDiagnostic(diag.Error, path, summary, detail)
+}
+
+// AddAttributeWarning appends a warning diagnostic for the attribute at the
+// given path of the configuration.
+func (resp *ValidateResourceConfigFuncResponse) AddAttributeWarning(path cty.Path, summary, detail string) {
+	resp.addAttributeDiagnostic(diag.Warning, path, summary, detail)
+}
+
+func (resp *ValidateResourceConfigFuncResponse) addAttributeDiagnostic(severity diag.Severity, path cty.Path, summary, detail string) {
+	resp.Diagnostics = append(resp.Diagnostics, diag.Diagnostic{
+		Severity:      severity,
+		Summary:       summary,
+		Detail:        detail,
+		AttributePath: path,
+	})
+}
+
 // SchemaMap returns the schema information for this Resource whether it is
 // defined via the SchemaFunc field or Schema field. The SchemaFunc field, if
 // defined, takes precedence over the Schema field.
@@ -724,6 +853,43 @@ func (r *Resource) SchemaMap() map[string]*Schema {
 	return r.Schema
 }
 
+// ComputedAttributes returns the paths of the attributes which are Computed
+// but not Optional, which practitioners cannot configure, including those
+// nested in blocks. This is intended for documentation generators listing
+// read-only attributes.
+//
+// Paths only contain attribute steps, sorted by attribute name at each
+// level, with attributes of a block listed right after the block. The
+// implicit "id" attribute is not included unless declared in the schema.
+func (r *Resource) ComputedAttributes() []cty.Path {
+	return computedAttributes(r.SchemaMap(), cty.Path{})
+}
+
+func computedAttributes(m map[string]*Schema, parent cty.Path) []cty.Path {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var paths []cty.Path
+
+	for _, k := range keys {
+		schema := m[k]
+		path := parent.Copy().GetAttr(k)
+
+		if schema.Computed && !schema.Optional {
+			paths = append(paths, path)
+		}
+
+		if elem, ok := schema.Elem.(*Resource); ok {
+			paths = append(paths, computedAttributes(elem.SchemaMap(), path)...)
+		}
+	}
+
+	return paths
+}
+
 // ShimInstanceStateFromValue converts a cty.Value to a
 // terraform.InstanceState.
 func (r *Resource) ShimInstanceStateFromValue(state cty.Value) (*terraform.InstanceState, error) {
@@ -778,6 +944,25 @@ type DeleteContextFunc func(context.Context, *ResourceData, interface{}) diag.Di
 type StateMigrateFunc func(
 	int, *terraform.InstanceState, interface{}) (*terraform.InstanceState, error)
 
+// SchemaVersionMissingBehavior is the behavior of UpgradeResourceState for a
+// stored schema version which the resource cannot upgrade from, as set by
+// the SchemaVersionMissingBehavior field of Resource.
+type SchemaVersionMissingBehavior int
+
+const (
+	// SchemaVersionMissingAssumeZero assumes a missing schema version is
+	// version 0, upgrading the state with any MigrateState or StateUpgraders
+	// from version 0, or else decoding it with the current schema. This is
+	// the default behavior.
+	SchemaVersionMissingAssumeZero SchemaVersionMissingBehavior = iota
+
+	// SchemaVersionMissingError returns an error for a state with version 0
+	// when SchemaVersion is greater than 0 and neither MigrateState nor a
+	// StateUpgrader handles version 0, as well as for a state with a version
+	// greater than SchemaVersion.
+	SchemaVersionMissingError
+)
+
 // Implementation of a single schema version state upgrade.
 type StateUpgrader struct {
 	// Version is the version schema that this Upgrader will handle, converting
@@ -794,7 +979,17 @@ type StateUpgrader struct {
 	// decoded into the default json types using a map[string]interface{}. It
 	// is up to the StateUpgradeFunc to ensure that the returned value can be
 	// encoded using the new schema.
+	//
+	// Upgrade and UpgradeWithDiags are mutually exclusive.
 	Upgrade StateUpgradeFunc
+
+	// UpgradeWithDiags is an alternative to Upgrade which returns
+	// diagnostics instead of an error, such as a warning that data was
+	// dropped during the upgrade. Warnings are returned to Terraform along
+	// with the upgraded state, while any error diagnostic stops the upgrade.
+	//
+	// Upgrade and UpgradeWithDiags are mutually exclusive.
+	UpgradeWithDiags StateUpgradeWithDiagsFunc
 }
 
 // Function signature for a schema version state upgrade handler.
@@ -829,10 +1024,59 @@ type StateUpgrader struct {
 // align to the typing mentioned above.
 type StateUpgradeFunc func(ctx context.Context, rawState map[string]interface{}, meta interface{}) (map[string]interface{}, error)
 
+// Function signature for a schema version state upgrade handler returning
+// diagnostics. See the StateUpgradeFunc documentation for the parameters and
+// the returned state data.
+type StateUpgradeWithDiagsFunc func(ctx context.Context, rawState map[string]interface{}, meta interface{}) (map[string]interface{}, diag.Diagnostics)
+
 // See Resource documentation.
 type CustomizeDiffFunc func(context.Context, *ResourceDiff, interface{}) error
 
+// See Resource documentation.
+type CustomizeDiffContextFunc func(context.Context, *ResourceDiff, interface{}) diag.Diagnostics
+
+// errCustomizeDiffDiagnostics is returned by the CustomizeDiffFunc adapting
+// CustomizeDiffContext when its error diagnostics were collected by the plan
+// in progress.
+var errCustomizeDiffDiagnostics = errors.New("CustomizeDiffContext returned error diagnostics")
+
+// customizeDiff returns the CustomizeDiff of the resource or, if set, a
+// CustomizeDiffFunc calling CustomizeDiffContext. Its diagnostics are
+// collected by the plan in progress, if any, or else error diagnostics are
+// returned as an error.
+func (r *Resource) customizeDiff() CustomizeDiffFunc {
+	if r.CustomizeDiffContext == nil {
+		return r.CustomizeDiff
+	}
+
+	customizeDiffContext := r.CustomizeDiffContext
+
+	return func(ctx context.Context, d *ResourceDiff, meta interface{}) error {
+		diags := customizeDiffContext(ctx, d, meta)
+
+		collected, ok := ctx.Value(customizeDiffDiagnosticsContextKey).(*diag.Diagnostics)
+		if !ok {
+			return diags.Err()
+		}
+
+		*collected = append(*collected, diags...)
+
+		if diags.HasError() {
+			return errCustomizeDiffDiagnostics
+		}
+
+		return nil
+	}
+}
+
 func (r *Resource) create(ctx context.Context, d *ResourceData, meta interface{}) diag.Diagnostics {
+	d.operation = OperationCreate
+	ctx = withOperation(ctx, OperationCreate)
+
+	if err := r.RateLimit.wait(ctx); err != nil {
+		return diag.FromErr(err)
+	}
+
 	if r.Create != nil {
 		if err := r.Create(d, meta); err != nil {
 			return diag.FromErr(err)
@@ -849,7 +1093,26 @@ func (r *Resource) create(ctx context.Context, d *ResourceData, meta interface{}
 	return r.CreateContext(ctx, d, meta)
 }
 
+// defaultTimeouts returns the timeouts declared by the resource, used when
+// no timeouts were encoded in the instance diff or state, such as when
+// refreshing an imported resource. Otherwise operations would use the 20
+// minute system default instead of the resource Default timeout.
+func (r *Resource) defaultTimeouts() ResourceTimeout {
+	if r.Timeouts == nil {
+		return ResourceTimeout{}
+	}
+
+	return *r.Timeouts
+}
+
 func (r *Resource) read(ctx context.Context, d *ResourceData, meta interface{}) diag.Diagnostics {
+	d.operation = OperationRead
+	ctx = withOperation(ctx, OperationRead)
+
+	if err := r.RateLimit.wait(ctx); err != nil {
+		return diag.FromErr(err)
+	}
+
 	if r.Read != nil {
 		if err := r.Read(d, meta); err != nil {
 			return diag.FromErr(err)
@@ -867,6 +1130,13 @@ func (r *Resource) read(ctx context.Context, d *ResourceData, meta interface{})
 }
 
 func (r *Resource) update(ctx context.Context, d *ResourceData, meta interface{}) diag.Diagnostics {
+	d.operation = OperationUpdate
+	ctx = withOperation(ctx, OperationUpdate)
+
+	if err := r.RateLimit.wait(ctx); err != nil {
+		return diag.FromErr(err)
+	}
+
 	if r.Update != nil {
 		if err := r.Update(d, meta); err != nil {
 			return diag.FromErr(err)
@@ -884,6 +1154,13 @@ func (r *Resource) update(ctx context.Context, d *ResourceData, meta interface{}
 }
 
 func (r *Resource) delete(ctx context.Context, d *ResourceData, meta interface{}) diag.Diagnostics {
+	d.operation = OperationDelete
+	ctx = withOperation(ctx, OperationDelete)
+
+	if err := r.RateLimit.wait(ctx); err != nil {
+		return diag.FromErr(err)
+	}
+
 	if r.Delete != nil {
 		if err := r.Delete(d, meta); err != nil {
 			return diag.FromErr(err)
@@ -906,8 +1183,7 @@ func (r *Resource) Apply(
 	s *terraform.InstanceState,
 	d *terraform.InstanceDiff,
 	meta interface{}) (*terraform.InstanceState, diag.Diagnostics) {
-	schema := schemaMapWithIdentity{r.SchemaMap(), r.Identity.SchemaMap()}
-	data, err := schema.Data(s, d)
+	data, err := r.resourceData(s, d)
 	if err != nil {
 		return s, diag.FromErr(err)
 	}
@@ -918,7 +1194,7 @@ func (r *Resource) Apply(
 
 	// Instance Diff should have the timeout info, need to copy it over to the
 	// ResourceData meta
-	rt := ResourceTimeout{}
+	rt := r.defaultTimeouts()
 	if _, ok := d.Meta[TimeoutKey]; ok {
 		if err := rt.DiffDecode(d); err != nil {
 			logging.HelperSchemaError(ctx, "Error decoding ResourceTimeout", map[string]interface{}{logging.KeyError: err})
@@ -964,7 +1240,7 @@ func (r *Resource) Apply(
 		}
 
 		// Reset the data to be stateless since we just destroyed
-		data, err = schema.Data(nil, d)
+		data, err = r.resourceData(nil, d)
 		if err != nil {
 			return nil, append(diags, diag.FromErr(err)...)
 		}
@@ -991,7 +1267,7 @@ func (r *Resource) Apply(
 		logging.HelperSchemaTrace(ctx, "Called downstream")
 	}
 
-	return r.recordCurrentSchemaVersion(data.State()), diags
+	return r.recordComputeIfUnset(r.recordCurrentSchemaVersion(data.State()), d), diags
 }
 
 // Diff returns a diff of this resource.
@@ -1008,7 +1284,7 @@ func (r *Resource) Diff(
 		return nil, fmt.Errorf("[ERR] Error decoding timeout: %s", err)
 	}
 
-	instanceDiff, err := schemaMap(r.SchemaMap()).Diff(ctx, s, c, r.CustomizeDiff, meta, true)
+	instanceDiff, err := schemaMap(r.SchemaMap()).Diff(ctx, s, c, r.customizeDiff(), meta, true)
 	if err != nil {
 		return instanceDiff, err
 	}
@@ -1031,7 +1307,7 @@ func (r *Resource) SimpleDiff(
 	meta interface{}) (*terraform.InstanceDiff, error) {
 
 	// TODO: figure out if it makes sense to be able to set identity in CustomizeDiff at all
-	instanceDiff, err := schemaMapWithIdentity{r.SchemaMap(), r.Identity.SchemaMap()}.Diff(ctx, s, c, r.CustomizeDiff, meta, false)
+	instanceDiff, err := schemaMapWithIdentity{r.SchemaMap(), r.Identity.SchemaMap()}.Diff(ctx, s, c, r.customizeDiff(), meta, false)
 	if err != nil {
 		return instanceDiff, err
 	}
@@ -1057,7 +1333,13 @@ func (r *Resource) SimpleDiff(
 
 // Validate validates the resource configuration against the schema.
 func (r *Resource) Validate(c *terraform.ResourceConfig) diag.Diagnostics {
-	diags := schemaMap(r.SchemaMap()).Validate(c)
+	return r.ValidateContext(context.Background(), c)
+}
+
+// ValidateContext validates the resource configuration against the schema.
+// The given context is passed to any ValidateDiagContextFunc.
+func (r *Resource) ValidateContext(ctx context.Context, c *terraform.ResourceConfig) diag.Diagnostics {
+	diags := schemaMap(r.SchemaMap()).ValidateContext(ctx, c)
 
 	if r.DeprecationMessage != "" {
 		diags = append(diags, diag.Diagnostic{
@@ -1079,7 +1361,7 @@ func (r *Resource) ReadDataApply(
 ) (*terraform.InstanceState, diag.Diagnostics) {
 	// Data sources are always built completely from scratch
 	// on each read, so the source state is always nil.
-	data, err := schemaMap(r.SchemaMap()).Data(nil, d)
+	data, err := r.resourceData(nil, d)
 	if err != nil {
 		return nil, diag.FromErr(err)
 	}
@@ -1113,7 +1395,14 @@ func (r *Resource) RefreshWithoutUpgrade(
 		return nil, nil
 	}
 
-	rt := ResourceTimeout{}
+	// There is no remote object to refresh, so the prior state is kept
+	// as-is.
+	if r.SkipRefresh {
+		logging.HelperSchemaDebug(ctx, "Skipping refresh of resource with SkipRefresh enabled")
+		return r.recordCurrentSchemaVersion(s), nil
+	}
+
+	rt := r.defaultTimeouts()
 	if _, ok := s.Meta[TimeoutKey]; ok {
 		if err := rt.StateDecode(s); err != nil {
 			logging.HelperSchemaError(ctx, "Error decoding ResourceTimeout", map[string]interface{}{logging.KeyError: err})
@@ -1125,7 +1414,7 @@ func (r *Resource) RefreshWithoutUpgrade(
 	if r.Exists != nil {
 		// Make a copy of data so that if it is modified it doesn't
 		// affect our Read later.
-		data, err := schema.Data(s, nil)
+		data, err := r.resourceData(s, nil)
 		if err != nil {
 			return s, diag.FromErr(err)
 		}
@@ -1148,7 +1437,7 @@ func (r *Resource) RefreshWithoutUpgrade(
 		}
 	}
 
-	data, err := schema.Data(s, nil)
+	data, err := r.resourceData(s, nil)
 	if err != nil {
 		return s, diag.FromErr(err)
 	}
@@ -1162,11 +1451,18 @@ func (r *Resource) RefreshWithoutUpgrade(
 	diags := r.read(ctx, data, meta)
 	logging.HelperSchemaTrace(ctx, "Called downstream")
 
+	if !diags.HasError() && data.Id() != "" {
+		if err := r.backfillFromIdentity(data); err != nil {
+			diags = append(diags, diag.FromErr(err)...)
+		}
+	}
+
 	state := data.State()
 	if state != nil && state.ID == "" {
 		state = nil
 	}
 
+	r.restoreComputeIfUnset(s, state)
 	schema.handleDiffSuppressOnRefresh(ctx, s, state)
 	return r.recordCurrentSchemaVersion(state), diags
 }
@@ -1198,6 +1494,14 @@ func (r *Resource) deleteFuncSet() bool {
 // the resources it manages, so you don't need to call this manually if it
 // is part of a Provider.
 func (r *Resource) InternalValidate(topSchemaMap schemaMap, writable bool) error {
+	return r.internalValidate(topSchemaMap, writable, false)
+}
+
+// internalValidate implements InternalValidate. If allowMissingRead is
+// true, a writable top level resource without a Read implementation is not
+// reported as an error, which is used by the Provider type InternalValidate
+// when StrictInternalValidate is disabled.
+func (r *Resource) internalValidate(topSchemaMap schemaMap, writable bool, allowMissingRead bool) error {
 	if r == nil {
 		return errors.New("resource is nil")
 	}
@@ -1208,7 +1512,7 @@ func (r *Resource) InternalValidate(topSchemaMap schemaMap, writable bool) error
 		}
 
 		// CustomizeDiff cannot be defined for read-only resources
-		if r.CustomizeDiff != nil {
+		if r.CustomizeDiff != nil || r.CustomizeDiffContext != nil {
 			return fmt.Errorf("cannot implement CustomizeDiff")
 		}
 	}
@@ -1216,6 +1520,10 @@ func (r *Resource) InternalValidate(topSchemaMap schemaMap, writable bool) error
 	schema := schemaMap(r.SchemaMap())
 	tsm := topSchemaMap
 
+	if paths := schema.nestedTimeoutsAttrs(); len(paths) > 0 {
+		return fmt.Errorf("Timeouts is only supported on top-level resources, remove it from nested block elements: %s", strings.Join(paths, ", "))
+	}
+
 	if r.isTopLevel() && writable {
 		// All non-Computed attributes must be ForceNew if Update is not defined
 		if !r.updateFuncSet() {
@@ -1245,16 +1553,25 @@ func (r *Resource) InternalValidate(topSchemaMap schemaMap, writable bool) error
 
 		tsm = schema
 
-		// Destroy, and Read are required
-		if !r.readFuncSet() {
+		// Destroy, and Read are required, unless the resource opts out of
+		// refresh entirely
+		if !r.readFuncSet() && !r.SkipRefresh && !allowMissingRead {
 			return fmt.Errorf("Read must be implemented")
 		}
 		if !r.deleteFuncSet() {
 			return fmt.Errorf("Delete must be implemented")
 		}
 
-		// If we have an importer, we need to verify the importer.
+		if err := r.validateTimeouts(); err != nil {
+			return err
+		}
+
+		// If we have an importer, we need to verify the importer. Import
+		// relies on the following Read to populate the state.
 		if r.Importer != nil {
+			if !r.readFuncSet() {
+				return fmt.Errorf("Importer requires Read to be implemented")
+			}
 			if err := r.Importer.InternalValidate(); err != nil {
 				return err
 			}
@@ -1262,10 +1579,16 @@ func (r *Resource) InternalValidate(topSchemaMap schemaMap, writable bool) error
 
 		if f, ok := tsm["id"]; ok {
 			// if there is an explicit ID, validate it...
-			err := validateResourceID(f)
+			err := validateResourceID(f, r.DisableImplicitID)
 			if err != nil {
 				return err
 			}
+
+			if r.IDDescription != "" {
+				return fmt.Errorf(`IDDescription is only valid for the implicit "id" attribute, set the Description of the "id" attribute instead`)
+			}
+		} else if r.DisableImplicitID {
+			return fmt.Errorf(`DisableImplicitID requires an explicit "id" attribute`)
 		}
 
 		for k := range tsm {
@@ -1273,12 +1596,26 @@ func (r *Resource) InternalValidate(topSchemaMap schemaMap, writable bool) error
 				return fmt.Errorf("%s is a reserved field name", k)
 			}
 		}
+
+		if err := r.validateIdentitySources(tsm); err != nil {
+			return err
+		}
 	}
 
 	lastVersion := -1
 	for _, u := range r.StateUpgraders {
+		// The first StateUpgrader may only start above version 0 when
+		// MigrateState handles the legacy versions below it.
+		if lastVersion < 0 && u.Version > 0 && r.MigrateState == nil {
+			return fmt.Errorf("missing StateUpgrader for version 0, StateUpgraders must start at version 0 unless MigrateState is set")
+		}
+
+		if lastVersion >= 0 && u.Version <= lastVersion {
+			return fmt.Errorf("StateUpgrader version %d is out of order, must be greater than %d", u.Version, lastVersion)
+		}
+
 		if lastVersion >= 0 && u.Version-lastVersion > 1 {
-			return fmt.Errorf("missing schema version between %d and %d", lastVersion, u.Version)
+			return fmt.Errorf("missing StateUpgrader for version %d, between %d and %d", lastVersion+1, lastVersion, u.Version)
 		}
 
 		if u.Version >= r.SchemaVersion {
@@ -1289,24 +1626,40 @@ func (r *Resource) InternalValidate(topSchemaMap schemaMap, writable bool) error
 			return fmt.Errorf("StateUpgrader %d type is not cty.Object", u.Version)
 		}
 
-		if u.Upgrade == nil {
+		if u.Upgrade == nil && u.UpgradeWithDiags == nil {
 			return fmt.Errorf("StateUpgrader %d missing StateUpgradeFunc", u.Version)
 		}
 
+		if u.Upgrade != nil && u.UpgradeWithDiags != nil {
+			return fmt.Errorf("StateUpgrader %d: Upgrade and UpgradeWithDiags cannot both be set", u.Version)
+		}
+
 		lastVersion = u.Version
 	}
 
 	if lastVersion >= 0 && lastVersion != r.SchemaVersion-1 {
-		return fmt.Errorf("missing StateUpgrader between %d and %d", lastVersion, r.SchemaVersion)
+		return fmt.Errorf("missing StateUpgrader for version %d, between %d and current version %d", lastVersion+1, lastVersion, r.SchemaVersion)
 	}
 
 	// Data source
 	if r.isTopLevel() && !writable {
+		if r.DisableImplicitID {
+			return fmt.Errorf("DisableImplicitID is only supported on managed resources")
+		}
+
+		if r.ValidateIDFunc != nil {
+			return fmt.Errorf("ValidateIDFunc is only supported on managed resources")
+		}
+
 		tsm = schema
-		for k := range tsm {
+		for k, v := range tsm {
 			if isReservedDataSourceFieldName(k) {
 				return fmt.Errorf("%s is a reserved field name", k)
 			}
+
+			if v.IdentitySource != "" {
+				return fmt.Errorf("%s: IdentitySource is only supported on managed resources", k)
+			}
 		}
 	}
 
@@ -1314,7 +1667,16 @@ func (r *Resource) InternalValidate(topSchemaMap schemaMap, writable bool) error
 		return fmt.Errorf("SchemaFunc and Schema should not both be set")
 	}
 
+	if r.RateLimit != nil {
+		if err := r.RateLimit.validate(); err != nil {
+			return err
+		}
+	}
+
 	// check context funcs are not set alongside their nonctx counterparts
+	if r.CustomizeDiffContext != nil && r.CustomizeDiff != nil {
+		return fmt.Errorf("CustomizeDiffContext and CustomizeDiff should not both be set")
+	}
 	if r.CreateContext != nil && r.Create != nil {
 		return fmt.Errorf("CreateContext and Create should not both be set")
 	}
@@ -1359,6 +1721,101 @@ func (r *Resource) InternalValidate(topSchemaMap schemaMap, writable bool) error
 	return schema.InternalValidate(tsm)
 }
 
+// validateTimeouts returns an error for a default timeout declared for an
+// operation which the managed resource does not implement, such as an
+// Update timeout on a resource without Update, which is likely a copy-paste
+// mistake.
+func (r *Resource) validateTimeouts() error {
+	if r.Timeouts == nil {
+		return nil
+	}
+
+	operations := []struct {
+		name    string
+		timeout *time.Duration
+		set     bool
+	}{
+		{"Create", r.Timeouts.Create, r.createFuncSet()},
+		{"Read", r.Timeouts.Read, r.readFuncSet()},
+		{"Update", r.Timeouts.Update, r.updateFuncSet()},
+		{"Delete", r.Timeouts.Delete, r.deleteFuncSet()},
+	}
+
+	for _, op := range operations {
+		if op.timeout != nil && !op.set {
+			return fmt.Errorf("%s timeout is set but the %s operation is not implemented", op.name, op.name)
+		}
+	}
+
+	return nil
+}
+
+// validateIdentitySources verifies that the identity attributes referenced
+// by IdentitySource exist and have the same type as the attribute.
+func (r *Resource) validateIdentitySources(schema map[string]*Schema) error {
+	for k, v := range schema {
+		if v.IdentitySource == "" {
+			continue
+		}
+
+		if r.Identity == nil {
+			return fmt.Errorf("%s: IdentitySource requires the resource to have an Identity", k)
+		}
+
+		source, ok := r.Identity.SchemaMap()[v.IdentitySource]
+		if !ok {
+			return fmt.Errorf("%s: IdentitySource references unknown identity attribute %q", k, v.IdentitySource)
+		}
+
+		switch v.Type {
+		case TypeBool, TypeInt, TypeFloat, TypeString:
+		default:
+			return fmt.Errorf("%s: IdentitySource is only supported on primitive attributes", k)
+		}
+
+		if source.Type != v.Type {
+			return fmt.Errorf("%s: IdentitySource identity attribute %q is %s, must be %s", k, v.IdentitySource, source.Type, v.Type)
+		}
+	}
+
+	return nil
+}
+
+// backfillFromIdentity sets attributes with an IdentitySource which were left
+// unset by Read to the value of their identity attribute.
+func (r *Resource) backfillFromIdentity(d *ResourceData) error {
+	var identity *IdentityData
+
+	for k, v := range r.SchemaMap() {
+		if v.IdentitySource == "" {
+			continue
+		}
+
+		// Zero values set by Read, such as false or 0, count as set.
+		if d.getRaw(k, getSourceSet).Exists {
+			continue
+		}
+
+		if identity == nil {
+			var err error
+			if identity, err = d.Identity(); err != nil {
+				return err
+			}
+		}
+
+		value := identity.getRaw(v.IdentitySource)
+		if !value.Exists {
+			continue
+		}
+
+		if err := d.Set(k, value.Value); err != nil {
+			return fmt.Errorf("%s: error setting value from identity attribute %q: %w", k, v.IdentitySource, err)
+		}
+	}
+
+	return nil
+}
+
 func isReservedDataSourceFieldName(name string) bool {
 	for _, reservedName := range ReservedDataSourceFields {
 		if name == reservedName {
@@ -1368,11 +1825,20 @@ func isReservedDataSourceFieldName(name string) bool {
 	return false
 }
 
-func validateResourceID(s *Schema) error {
+func validateResourceID(s *Schema, disableImplicitID bool) error {
 	if s.Type != TypeString {
 		return fmt.Errorf(`the "id" attribute must be of TypeString`)
 	}
 
+	// Without the implicit ID, the attribute is owned by the resource and
+	// may be set in the configuration instead of being computed.
+	if disableImplicitID {
+		if !s.Required && !s.Computed {
+			return fmt.Errorf(`the "id" attribute must be marked Required or Computed`)
+		}
+		return nil
+	}
+
 	if s.Required {
 		return fmt.Errorf(`the "id" attribute cannot be marked Required`)
 	}
@@ -1403,7 +1869,7 @@ func isReservedResourceFieldName(name string) bool {
 //
 // This function is useful for unit tests and ResourceImporter functions.
 func (r *Resource) Data(s *terraform.InstanceState) *ResourceData {
-	result, err := schemaMapWithIdentity{r.SchemaMap(), r.Identity.SchemaMap()}.Data(s, nil)
+	result, err := r.resourceData(s, nil)
 	if err != nil {
 		// At the time of writing, this isn't possible (Data never returns
 		// non-nil errors). We panic to find this in the future if we have to.
@@ -1425,6 +1891,22 @@ func (r *Resource) Data(s *terraform.InstanceState) *ResourceData {
 	return result
 }
 
+// resourceData returns the ResourceData for the given state and diff,
+// configured with the ValidateIDFunc and MaxOperationTimeout of the
+// resource. It is used for every ResourceData the resource passes to its
+// functions.
+func (r *Resource) resourceData(s *terraform.InstanceState, d *terraform.InstanceDiff) (*ResourceData, error) {
+	data, err := schemaMapWithIdentity{r.SchemaMap(), r.Identity.SchemaMap()}.Data(s, d)
+	if err != nil {
+		return nil, err
+	}
+
+	data.validateID = r.ValidateIDFunc
+	data.maxTimeout = r.MaxOperationTimeout
+
+	return data, nil
+}
+
 // TestResourceData Yields a ResourceData filled with this resource's schema for use in unit testing
 //
 // TODO: May be able to be removed with the above ResourceData function.
@@ -1435,6 +1917,50 @@ func (r *Resource) TestResourceData() *ResourceData {
 	}
 }
 
+// TestValidateRawConfig runs all ValidateRawResourceConfigFuncs against the
+// given raw config in order, for use in unit testing, and returns the
+// response with the diagnostics of all of them. The request is made as if
+// from a Terraform client supporting write-only attributes.
+func (r *Resource) TestValidateRawConfig(ctx context.Context, config cty.Value) *ValidateResourceConfigFuncResponse {
+	resp := &ValidateResourceConfigFuncResponse{}
+
+	req := ValidateResourceConfigFuncRequest{
+		WriteOnlyAttributesAllowed: true,
+		RawConfig:                  config,
+	}
+
+	for _, validateFunc := range r.ValidateRawResourceConfigFuncs {
+		validateResp := &ValidateResourceConfigFuncResponse{}
+		validateFunc(ctx, req, validateResp)
+		resp.Diagnostics = append(resp.Diagnostics, validateResp.Diagnostics...)
+	}
+
+	return resp
+}
+
+// checkStateSchemaVersion returns an error if the stored schema version of a
+// state cannot be upgraded and SchemaVersionMissingBehavior is
+// SchemaVersionMissingError.
+func (r *Resource) checkStateSchemaVersion(version int) error {
+	if r.SchemaVersionMissingBehavior != SchemaVersionMissingError {
+		return nil
+	}
+
+	if version > r.SchemaVersion {
+		return fmt.Errorf("state schema version %d is greater than the resource schema version %d", version, r.SchemaVersion)
+	}
+
+	if version > 0 || r.SchemaVersion == 0 || r.MigrateState != nil {
+		return nil
+	}
+
+	if len(r.StateUpgraders) > 0 && r.StateUpgraders[0].Version == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("state has no schema version to upgrade from to the resource schema version %d", r.SchemaVersion)
+}
+
 // Returns true if the resource is "top level" i.e. not a sub-resource.
 func (r *Resource) isTopLevel() bool {
 	// TODO: This is a heuristic; replace with a definitive attribute?
@@ -1482,7 +2008,36 @@ func (r *ResourceIdentity) InternalIdentityValidate() error {
 		return fmt.Errorf(`The resource identity schema is empty`)
 	}
 
-	for k, v := range r.SchemaMap() {
+	if r.Version < 1 {
+		return fmt.Errorf("resource identity version must be at least 1, got %d", r.Version)
+	}
+
+	lastVersion := int64(-1)
+	for _, u := range r.IdentityUpgraders {
+		if lastVersion >= 0 && u.Version <= lastVersion {
+			return fmt.Errorf("IdentityUpgrader version %d must be greater than previous version %d", u.Version, lastVersion)
+		}
+
+		if lastVersion >= 0 && u.Version-lastVersion > 1 {
+			return fmt.Errorf("missing IdentityUpgrader for version %d, between %d and %d", lastVersion+1, lastVersion, u.Version)
+		}
+
+		if u.Version >= r.Version {
+			return fmt.Errorf("IdentityUpgrader version %d is >= current version %d", u.Version, r.Version)
+		}
+
+		lastVersion = u.Version
+	}
+
+	if lastVersion >= 0 && lastVersion != r.Version-1 {
+		return fmt.Errorf("missing IdentityUpgrader for version %d, between %d and current version %d", lastVersion+1, lastVersion, r.Version)
+	}
+
+	for k, v := range r.schemaFuncMap() {
+		if v == nil {
+			return fmt.Errorf("identity attribute %q does not exist in the resource schema", k)
+		}
+
 		if !v.OptionalForImport && !v.RequiredForImport {
 			return fmt.Errorf(`OptionalForImport or RequiredForImport must be set for resource identity`)
 		}
@@ -1504,6 +2059,18 @@ func (r *ResourceIdentity) InternalIdentityValidate() error {
 		}
 
 		if v.Type == TypeList {
+			if _, ok := v.Elem.(*Resource); ok {
+				return fmt.Errorf("%s: nested blocks are not valid for resource identity, only primitive types are supported", k)
+			}
+
+			if elem, ok := v.Elem.(*Schema); ok {
+				switch elem.Type {
+				case TypeBool, TypeFloat, TypeInt, TypeString:
+				default:
+					return fmt.Errorf("%s: %s is not valid for resource identity element type, only primitive types are supported", k, elem.Type)
+				}
+			}
+
 			if v.Elem != nil {
 				if v.Elem == TypeMap {
 					return fmt.Errorf(`TypeMap is not valid for resource identity element type`)
@@ -1599,6 +2166,10 @@ func (r *ResourceIdentity) InternalIdentityValidate() error {
 			return fmt.Errorf("%s: ValidateDiagFunc is for validating user input, "+
 				"there's nothing to validate for resource identity", k)
 		}
+		if v.ValidateDiagContextFunc != nil {
+			return fmt.Errorf("%s: ValidateDiagContextFunc is for validating user input, "+
+				"there's nothing to validate for resource identity", k)
+		}
 	}
 
 	return nil
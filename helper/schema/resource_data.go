@@ -4,18 +4,23 @@
 package schema
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"reflect"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
+	"unicode"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/go-cty/cty/gocty"
+	"github.com/hashicorp/go-cty/cty/msgpack"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/internal/configs/hcl2shim"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
 
@@ -36,12 +41,17 @@ type ResourceData struct {
 	meta           map[string]interface{}
 	timeouts       *ResourceTimeout
 	providerMeta   cty.Value
+	priorIdentity  map[string]string
+	operation      Operation
+	validateID     func(string) error
+	maxTimeout     time.Duration
 
 	// Don't set
 	multiReader *MultiLevelFieldReader
 	setWriter   *MapFieldWriter
 	newState    *terraform.InstanceState
 	newIdentity *IdentityData
+	private     map[string][]byte
 	partial     bool
 	once        sync.Once
 	isNew       bool
@@ -84,6 +94,116 @@ func (d *ResourceData) GetChange(key string) (interface{}, interface{}) {
 	return o.Value, n.Value
 }
 
+// GetNormalized returns the value of the given TypeString key mapped with
+// the given mapping, such as "enabled" and "disabled" mapped to "true" and
+// "false". Values which are not keys of the mapping, such as the empty
+// string of an unset attribute, are returned unchanged.
+//
+// Use validation.StringIsOneOfNormalized with the same mapping to only
+// allow configured values which can be normalized.
+func (d *ResourceData) GetNormalized(key string, mapping map[string]string) string {
+	v, _ := d.Get(key).(string)
+
+	if normalized, ok := mapping[v]; ok {
+		return normalized
+	}
+
+	return v
+}
+
+// GetStringList returns the elements of the given TypeList key whose Elem
+// is a TypeString schema, skipping null elements. It returns nil if the key
+// is not a list of strings.
+func (d *ResourceData) GetStringList(key string) []string {
+	return getPrimitiveElems[string](d, key, TypeList, TypeString)
+}
+
+// GetIntList returns the elements of the given TypeList key whose Elem is a
+// TypeInt schema, skipping null elements. It returns nil if the key is not a
+// list of integers.
+func (d *ResourceData) GetIntList(key string) []int {
+	return getPrimitiveElems[int](d, key, TypeList, TypeInt)
+}
+
+// GetStringSet returns the elements of the given TypeSet key whose Elem is
+// a TypeString schema, in the order of the Set type List method. It returns
+// nil if the key is not a set of strings.
+func (d *ResourceData) GetStringSet(key string) []string {
+	return getPrimitiveElems[string](d, key, TypeSet, TypeString)
+}
+
+// getPrimitiveElems returns the elements of the given list or set key as a
+// typed slice, if the schema of the key has the given collection and
+// element types.
+func getPrimitiveElems[T any](d *ResourceData, key string, collectionType ValueType, elemType ValueType) []T {
+	r := d.getRaw(key, getSourceSet)
+	if r.Schema == nil || r.Schema.Type != collectionType {
+		return nil
+	}
+
+	if elem, ok := r.Schema.Elem.(*Schema); !ok || elem.Type != elemType {
+		return nil
+	}
+
+	var raws []interface{}
+	switch v := r.Value.(type) {
+	case []interface{}:
+		raws = v
+	case *Set:
+		raws = v.List()
+	}
+
+	result := make([]T, 0, len(raws))
+	for _, raw := range raws {
+		if v, ok := raw.(T); ok {
+			result = append(result, v)
+		}
+	}
+
+	return result
+}
+
+// GetSingleBlock returns the attributes of the single element of the given
+// TypeList or TypeSet key with MaxItems 1 and an Elem *Resource, and whether
+// the element is present. It returns false if the block is empty or if the
+// key is not such a block.
+func (d *ResourceData) GetSingleBlock(key string) (map[string]interface{}, bool) {
+	r := d.getRaw(key, getSourceSet)
+	if !isSingleBlock(r.Schema) {
+		return nil, false
+	}
+
+	var raws []interface{}
+	switch v := r.Value.(type) {
+	case []interface{}:
+		raws = v
+	case *Set:
+		raws = v.List()
+	}
+
+	if len(raws) == 0 {
+		return nil, false
+	}
+
+	m, ok := raws[0].(map[string]interface{})
+	if !ok || m == nil {
+		return nil, false
+	}
+
+	return m, true
+}
+
+// isSingleBlock returns true if s is a TypeList or TypeSet block with
+// MaxItems 1.
+func isSingleBlock(s *Schema) bool {
+	if s == nil || (s.Type != TypeList && s.Type != TypeSet) || s.MaxItems != 1 {
+		return false
+	}
+
+	_, ok := s.Elem.(*Resource)
+	return ok
+}
+
 // GetOk returns the data for the given key and whether or not the key
 // has been set to a non-zero value at some point.
 //
@@ -111,13 +231,77 @@ func (d *ResourceData) GetOk(key string) (interface{}, bool) {
 // no Default value have been set.
 //
 // Deprecated: usage is discouraged due to undefined behaviors and may be
-// removed in a future version of the SDK
+// removed in a future version of the SDK. Use IsSet or IsNull to check
+// whether an attribute is set in the configuration, followed by Get.
 func (d *ResourceData) GetOkExists(key string) (interface{}, bool) {
 	r := d.getRaw(key, getSourceSet)
 	exists := r.Exists && !r.Computed
 	return r.Value, exists
 }
 
+// IsSet returns whether the given key is set to a non-null value in the
+// configuration returned by GetRawConfig, including zero values such as
+// false, 0 or "". Unknown values count as set. Unlike GetOk and GetOkExists,
+// the prior state and defaults are not considered.
+//
+// Keys use the same format as Get, for example "rule.0.enabled" or
+// "tags.name". Elements of sets cannot be addressed, and IsSet returns false
+// for them, as well as for keys that are not in the configuration, such as
+// list indexes beyond the configured elements.
+func (d *ResourceData) IsSet(key string) bool {
+	v, ok := d.rawConfigValue(key)
+	return ok && !v.IsNull()
+}
+
+// IsNull returns whether the given key is null in the configuration
+// returned by GetRawConfig, such as when it is omitted or explicitly set to
+// null. It is the inverse of IsSet, see IsSet for the key format.
+func (d *ResourceData) IsNull(key string) bool {
+	return !d.IsSet(key)
+}
+
+// rawConfigValue returns the value of the given key in the raw
+// configuration, and whether it could be found.
+func (d *ResourceData) rawConfigValue(key string) (cty.Value, bool) {
+	v := d.GetRawConfig()
+
+	for _, part := range strings.Split(key, ".") {
+		if v.IsNull() {
+			return v, false
+		}
+
+		if !v.IsKnown() {
+			return v, true
+		}
+
+		ty := v.Type()
+
+		switch {
+		case ty.IsObjectType():
+			if !ty.HasAttribute(part) {
+				return cty.NilVal, false
+			}
+			v = v.GetAttr(part)
+		case ty.IsListType() || ty.IsTupleType():
+			i, err := strconv.Atoi(part)
+			if err != nil || i < 0 || i >= v.LengthInt() {
+				return cty.NilVal, false
+			}
+			v = v.Index(cty.NumberIntVal(int64(i)))
+		case ty.IsMapType():
+			k := cty.StringVal(part)
+			if !v.HasIndex(k).True() {
+				return cty.NilVal, false
+			}
+			v = v.Index(k)
+		default:
+			return cty.NilVal, false
+		}
+	}
+
+	return v, true
+}
+
 func (d *ResourceData) getRaw(key string, level getSource) getResult {
 	var parts []string
 	if key != "" {
@@ -207,6 +391,97 @@ func (d *ResourceData) Partial(on bool) {
 	d.partial = on
 }
 
+// ResourceDataSnapshot is an opaque token returned by the ResourceData type
+// Snapshot method, which can later be passed to Restore.
+type ResourceDataSnapshot struct {
+	data     *ResourceData
+	set      map[string]string
+	newState *terraform.InstanceState
+	private  map[string][]byte
+}
+
+// Snapshot captures the values that have been written to the ResourceData,
+// via methods such as Set, SetId and SetPrivate, so they can later be rolled
+// back with Restore. This is useful when an operation is composed of
+// multiple remote calls and a later call fails, so the returned state only
+// reflects the values that were successfully applied.
+//
+// Identity data is not captured by the snapshot.
+func (d *ResourceData) Snapshot() *ResourceDataSnapshot {
+	d.once.Do(d.init)
+
+	set := d.setWriter.Map()
+	setCopy := make(map[string]string, len(set))
+	for k, v := range set {
+		setCopy[k] = v
+	}
+
+	return &ResourceDataSnapshot{
+		data:     d,
+		set:      setCopy,
+		newState: d.newState.DeepCopy(),
+		private:  copyPrivateData(d.private),
+	}
+}
+
+// Restore rolls back any values written to the ResourceData since the given
+// snapshot was taken. Values which have never been written are read from
+// the plan and prior state as usual.
+//
+// An error is returned if the snapshot is nil or was taken from a different
+// ResourceData.
+func (d *ResourceData) Restore(snapshot *ResourceDataSnapshot) error {
+	if snapshot == nil {
+		return fmt.Errorf("cannot restore nil snapshot")
+	}
+
+	if snapshot.data != d {
+		return fmt.Errorf("cannot restore snapshot taken from a different ResourceData")
+	}
+
+	d.once.Do(d.init)
+
+	d.replaceSetValues(snapshot.set)
+	d.newState = snapshot.newState.DeepCopy()
+	d.private = copyPrivateData(snapshot.private)
+
+	return nil
+}
+
+// Reset discards all values written with Set during the current operation,
+// so subsequent reads and the returned state fall back to the plan and prior
+// state as if Set had never been called. This is useful in failure paths of
+// CRUD functions to avoid persisting values for changes which were not
+// applied.
+//
+// Reset does not revert the ID or connection info set with SetId or
+// SetConnInfo, so a resource which was created remotely before a failure is
+// still tracked in state. Note that when an Update returns an error, Terraform
+// persists the state returned by the SDK, which includes planned values for
+// attributes that were not Set. Enable Partial to instead persist the prior
+// state for those attributes.
+func (d *ResourceData) Reset() {
+	d.once.Do(d.init)
+
+	d.replaceSetValues(nil)
+}
+
+// replaceSetValues replaces all values written with Set. The set writer map
+// is shared with the "set" level field reader, so it must be updated in
+// place rather than replaced.
+func (d *ResourceData) replaceSetValues(values map[string]string) {
+	d.setWriter.lock.Lock()
+	defer d.setWriter.lock.Unlock()
+
+	set := d.setWriter.result
+	for k := range set {
+		delete(set, k)
+	}
+	for k, v := range values {
+		set[k] = v
+	}
+}
+
 // Set sets the value for the given key.
 //
 // If the key is invalid or the value is not a correct type, an error
@@ -243,6 +518,27 @@ func (d *ResourceData) Set(key string, value interface{}) error {
 	return err
 }
 
+// SetSingleBlock sets the given TypeList or TypeSet key with MaxItems 1 and
+// an Elem *Resource to a single element with the given attributes, or to no
+// element if v is nil. An error is returned if the key is not such a block,
+// or as with Set if a value is invalid.
+func (d *ResourceData) SetSingleBlock(key string, v map[string]interface{}) error {
+	var schema *Schema
+	if schemaL := addrToSchema(strings.Split(key, "."), d.schema); len(schemaL) > 0 {
+		schema = schemaL[len(schemaL)-1]
+	}
+
+	if !isSingleBlock(schema) {
+		return fmt.Errorf("%s: must be a TypeList or TypeSet with MaxItems 1 and an Elem *Resource", key)
+	}
+
+	if v == nil {
+		return d.Set(key, []interface{}{})
+	}
+
+	return d.Set(key, []interface{}{v})
+}
+
 func (d *ResourceData) MarkNewResource() {
 	d.isNew = true
 }
@@ -251,6 +547,15 @@ func (d *ResourceData) IsNewResource() bool {
 	return d.isNew
 }
 
+// Operation returns the resource operation this ResourceData is passed to,
+// which allows functions shared by several operations, such as a combined
+// create and update function, to tell them apart. It returns
+// OperationUnknown outside of the create, read, update, delete and import
+// functions. See also OperationFromContext.
+func (d *ResourceData) Operation() Operation {
+	return d.operation
+}
+
 // Id returns the ID of the resource.
 func (d *ResourceData) Id() string {
 	var result string
@@ -304,6 +609,34 @@ func (d *ResourceData) SetId(v string) {
 	d.newState.Attributes["id"] = v
 }
 
+// SetIdErr sets the ID of the resource like SetId, but returns an error
+// instead of setting an ID which is empty or contains control characters,
+// or which is rejected by the ValidateIDFunc of the resource. This catches
+// malformed IDs, such as an empty ID returned by a remote API, before they
+// enter the state. Use SetId with an empty string to mark the resource as
+// destroyed.
+func (d *ResourceData) SetIdErr(v string) error {
+	if v == "" {
+		return errors.New("resource ID must not be empty")
+	}
+
+	for _, r := range v {
+		if unicode.IsControl(r) {
+			return fmt.Errorf("resource ID %q must not contain control characters", v)
+		}
+	}
+
+	if d.validateID != nil {
+		if err := d.validateID(v); err != nil {
+			return fmt.Errorf("invalid resource ID %q: %w", v, err)
+		}
+	}
+
+	d.SetId(v)
+
+	return nil
+}
+
 // SetConnInfo sets the connection info for a resource.
 func (d *ResourceData) SetConnInfo(v map[string]string) {
 	d.once.Do(d.init)
@@ -330,6 +663,15 @@ func (d *ResourceData) State() *terraform.InstanceState {
 		return nil
 	}
 
+	if private := d.privateData(); len(private) > 0 {
+		meta := make(map[string]interface{}, len(result.Meta)+1)
+		for k, v := range result.Meta {
+			meta[k] = v
+		}
+		meta[privateDataKey] = encodePrivateData(private)
+		result.Meta = meta
+	}
+
 	if d.timeouts != nil {
 		if err := d.timeouts.StateEncode(&result); err != nil {
 			log.Printf("[ERR] Error encoding Timeout meta to Instance State: %s", err)
@@ -446,7 +788,18 @@ func (d *ResourceData) State() *terraform.InstanceState {
 
 // Timeout returns the data for the given timeout key
 // Returns a duration of 20 minutes for any key not found, or not found and no default.
+// The result is capped by the Resource type MaxOperationTimeout field, if set.
 func (d *ResourceData) Timeout(key string) time.Duration {
+	timeout := d.timeout(key)
+
+	if d.maxTimeout > 0 && timeout > d.maxTimeout {
+		return d.maxTimeout
+	}
+
+	return timeout
+}
+
+func (d *ResourceData) timeout(key string) time.Duration {
 	key = strings.ToLower(key)
 
 	// System default of 20 minutes
@@ -612,6 +965,10 @@ func (d *ResourceData) get(addr []string, source getSource) getResult {
 	}
 }
 
+// GetProviderMeta decodes the provider_meta configuration of the module
+// calling this resource, as described by the Provider type
+// ProviderMetaSchema field, into dst with gocty. See also
+// GetProviderMetaValue to read a single attribute.
 func (d *ResourceData) GetProviderMeta(dst interface{}) error {
 	if d.providerMeta.IsNull() {
 		return nil
@@ -619,6 +976,37 @@ func (d *ResourceData) GetProviderMeta(dst interface{}) error {
 	return gocty.FromCtyValue(d.providerMeta, &dst)
 }
 
+// GetProviderMetaValue returns the value of the given top-level attribute
+// of the provider_meta configuration of the module calling this resource,
+// as described by the Provider type ProviderMetaSchema field, and whether it
+// is set. Modules conventionally use provider_meta to pass values such as a
+// module name for usage attribution.
+//
+// Values are returned in the same Go types as the configuration shims use:
+// string, bool, int or float64 for numbers, []interface{} for lists and
+// sets, and map[string]interface{} for maps and objects.
+//
+// The provider_meta configuration is available during the Create, Read,
+// Update and Delete operations of managed resources.
+func (d *ResourceData) GetProviderMetaValue(key string) (interface{}, bool) {
+	meta := d.providerMeta
+
+	if meta == cty.NilVal || meta.IsNull() || !meta.IsKnown() || !meta.Type().IsObjectType() {
+		return nil, false
+	}
+
+	if !meta.Type().HasAttribute(key) {
+		return nil, false
+	}
+
+	v := meta.GetAttr(key)
+	if v.IsNull() || !v.IsWhollyKnown() {
+		return nil, false
+	}
+
+	return hcl2shim.ConfigValueFromHCL2(v), true
+}
+
 // GetRawConfig returns the cty.Value that Terraform sent the SDK for the
 // config. If no value was sent, or if a null value was sent, the value will be
 // a null value of the resource's type.
@@ -639,6 +1027,38 @@ func (d *ResourceData) GetRawConfig() cty.Value {
 	return cty.NullVal(schemaMap(d.schema).CoreConfigSchema().ImpliedType())
 }
 
+// GetRawConfigMsgPack returns the MsgPack encoded config exactly as
+// Terraform sent it to the SDK, which GetRawConfig is decoded from. This
+// allows inspecting values the SDK cannot represent, such as attributes of
+// dynamic type.
+//
+// The bytes are only available while handling a request from Terraform that
+// includes the config, which are plan, apply and data source reads. Outside
+// of those, such as during resource Read or import, nil is returned. When
+// the original bytes are unavailable but a config value is, such as when the
+// ResourceData was created in unit testing, the value is encoded instead.
+// The returned bytes must not be modified.
+//
+// GetRawConfigMsgPack is considered experimental and advanced functionality,
+// and familiarity with the Terraform protocol is suggested when using it.
+func (d *ResourceData) GetRawConfigMsgPack() ([]byte, error) {
+	// These methods follow the field readers preference order.
+	if d.diff != nil && d.diff.RawConfigMsgPack != nil {
+		return d.diff.RawConfigMsgPack, nil
+	}
+	if d.state != nil && d.state.RawConfigMsgPack != nil {
+		return d.state.RawConfigMsgPack, nil
+	}
+
+	// Fall back to encoding the decoded config, such as when the data was
+	// created in unit testing rather than from a request.
+	rawConfig := d.GetRawConfig()
+	if rawConfig.IsNull() {
+		return nil, nil
+	}
+	return msgpack.Marshal(rawConfig, rawConfig.Type())
+}
+
 // GetRawConfigAt is a helper method for retrieving specific values
 // from the RawConfig returned from GetRawConfig. It returns the cty.Value
 // for a given cty.Path or an error diagnostic if the value at the given path does not exist.
@@ -646,7 +1066,12 @@ func (d *ResourceData) GetRawConfig() cty.Value {
 // GetRawConfigAt is considered advanced functionality, and
 // familiarity with the Terraform protocol is suggested when using it.
 func (d *ResourceData) GetRawConfigAt(valPath cty.Path) (cty.Value, diag.Diagnostics) {
-	rawConfig := d.GetRawConfig()
+	return getRawConfigAt(d.GetRawConfig(), valPath)
+}
+
+// getRawConfigAt returns the value at valPath in rawConfig, as described by
+// ResourceData GetRawConfigAt.
+func getRawConfigAt(rawConfig cty.Value, valPath cty.Path) (cty.Value, diag.Diagnostics) {
 	configVal := cty.DynamicVal
 
 	if rawConfig.IsNull() {
@@ -762,3 +1187,39 @@ func (d *ResourceData) Identity() (*IdentityData, error) {
 
 	return d.newIdentity, nil
 }
+
+// PriorIdentity returns the identity of the resource before the current
+// operation, which cannot be modified. Unlike Identity, it does not reflect
+// changes made by the provider or planned by Terraform, so it can be used to
+// reconcile the prior and new identity.
+//
+// During import, the prior identity is the identity Terraform sent in the
+// import request, such as from an import block identity, as Terraform does
+// not send existing state to import. Otherwise it is the identity from the
+// prior state. An error is returned when there is no prior identity, such as
+// when importing by ID or creating a resource.
+func (d *ResourceData) PriorIdentity() (*IdentityData, error) {
+	if d.identitySchema == nil {
+		return nil, fmt.Errorf("Resource does not have Identity schema. Please set one in order to use PriorIdentity(). This is always a problem in the provider code.")
+	}
+
+	identity := d.priorIdentity
+	if identity == nil && d.state != nil {
+		identity = d.state.Identity
+	}
+
+	if len(identity) == 0 {
+		return nil, fmt.Errorf("Resource does not have a prior identity. This can happen when importing by ID or when the resource is being created.")
+	}
+
+	raw := make(map[string]string, len(identity))
+	for k, v := range identity {
+		raw[k] = v
+	}
+
+	return &IdentityData{
+		schema:       d.identitySchema,
+		raw:          raw,
+		panicOnError: d.panicOnError,
+	}, nil
+}
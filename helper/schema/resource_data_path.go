@@ -0,0 +1,175 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/go-cty/cty/convert"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/internal/configs/hcl2shim"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/internal/tfdiags"
+)
+
+// GetPath returns the value at the given path, such as
+// cty.GetAttrPath("network").IndexInt(0).GetAttr("port"), with the same Go
+// types and merged view of the data as Get. It is an alternative to Get for
+// nested values which does not require building flatmap keys, such as
+// "network.0.port".
+//
+// Lists are indexed with a number, maps with a string, and sets with the
+// value of the element, such as a path found with cty.Walk over the value of
+// GetRawConfig. A set element is found if its value is equivalent to the
+// given value, with null values matching the zero value of their type.
+//
+// An error is returned if the path does not follow the schema, such as an
+// attribute which is not in the schema or an index of the wrong type, or if
+// an indexed element does not exist.
+func (d *ResourceData) GetPath(path cty.Path) (interface{}, error) {
+	if len(path) == 0 {
+		return nil, errors.New("GetPath: path must not be empty")
+	}
+
+	step, ok := path[0].(cty.GetAttrStep)
+	if !ok {
+		return nil, fmt.Errorf("GetPath: path must start with an attribute name")
+	}
+
+	s, ok := d.schema[step.Name]
+	if !ok {
+		return nil, fmt.Errorf("%s: attribute is not in the schema", tfdiags.FormatCtyPath(path[:1]))
+	}
+
+	var cur interface{} = s
+	v := d.Get(step.Name)
+
+	for i := 1; i < len(path); i++ {
+		var err error
+		v, cur, err = getPathStep(v, cur, path[i])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", tfdiags.FormatCtyPath(path[:i+1]), err)
+		}
+	}
+
+	return v, nil
+}
+
+// getPathStep applies a single path step to the value v, whose schema is cur,
+// either a *Schema or the *Resource of a block element, and returns the
+// resulting value along with its schema.
+func getPathStep(v interface{}, cur interface{}, step cty.PathStep) (interface{}, interface{}, error) {
+	if r, ok := cur.(*Resource); ok {
+		attr, ok := step.(cty.GetAttrStep)
+		if !ok {
+			return nil, nil, errors.New("expected an attribute name")
+		}
+
+		s, ok := r.SchemaMap()[attr.Name]
+		if !ok {
+			return nil, nil, errors.New("attribute is not in the schema")
+		}
+
+		m, _ := v.(map[string]interface{})
+
+		return m[attr.Name], s, nil
+	}
+
+	s := cur.(*Schema)
+
+	index, ok := step.(cty.IndexStep)
+	if !ok || !index.Key.IsKnown() || index.Key.IsNull() {
+		return nil, nil, fmt.Errorf("expected an index into %s", s.Type)
+	}
+
+	switch s.Type {
+	case TypeList:
+		if index.Key.Type() != cty.Number {
+			return nil, nil, errors.New("expected a number index")
+		}
+
+		i, acc := index.Key.AsBigFloat().Int64()
+		l, _ := v.([]interface{})
+		if acc != big.Exact || i < 0 || i >= int64(len(l)) {
+			return nil, nil, errors.New("list element does not exist")
+		}
+
+		return l[i], getPathElem(s), nil
+	case TypeMap:
+		if index.Key.Type() != cty.String {
+			return nil, nil, errors.New("expected a string index")
+		}
+
+		m, _ := v.(map[string]interface{})
+		elem, ok := m[index.Key.AsString()]
+		if !ok {
+			return nil, nil, errors.New("map element does not exist")
+		}
+
+		return elem, getPathElem(s), nil
+	case TypeSet:
+		set, _ := v.(*Set)
+		if set != nil {
+			for _, code := range set.listCode() {
+				elem := set.m[code]
+				if getPathSetElemMatches(index.Key, elem) {
+					return elem, getPathElem(s), nil
+				}
+			}
+		}
+
+		return nil, nil, errors.New("set element does not exist")
+	default:
+		return nil, nil, fmt.Errorf("cannot index into %s", s.Type)
+	}
+}
+
+// getPathElem returns the schema of the elements of the collection s.
+func getPathElem(s *Schema) interface{} {
+	switch elem := s.Elem.(type) {
+	case *Schema, *Resource:
+		return elem
+	default:
+		// TypeMap defaults to string elements
+		return &Schema{Type: TypeString}
+	}
+}
+
+// getPathSetElemMatches returns true if the set element elem, as returned by
+// Get, is equivalent to the cty value key.
+func getPathSetElemMatches(key cty.Value, elem interface{}) bool {
+	val, err := convert.Convert(hcl2shim.HCL2ValueFromConfigValue(getPathConfigValue(elem)), key.Type())
+	if err != nil {
+		return false
+	}
+
+	return hcl2shim.ValuesSDKEquivalent(key, val)
+}
+
+// getPathConfigValue replaces any *Set in v with the list of its elements, so
+// that v can be converted with HCL2ValueFromConfigValue.
+func getPathConfigValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case *Set:
+		return getPathConfigValue(v.List())
+	case []interface{}:
+		l := make([]interface{}, len(v))
+		for i, elem := range v {
+			l[i] = getPathConfigValue(elem)
+		}
+
+		return l
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, elem := range v {
+			m[k] = getPathConfigValue(elem)
+		}
+
+		return m
+	default:
+		return v
+	}
+}
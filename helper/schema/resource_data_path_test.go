@@ -0,0 +1,200 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package schema
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/go-cty/cty"
+)
+
+func TestResourceDataGetPath(t *testing.T) {
+	t.Parallel()
+
+	r := &Resource{
+		Schema: map[string]*Schema{
+			"name": {
+				Type:     TypeString,
+				Optional: true,
+			},
+			"tags": {
+				Type:     TypeMap,
+				Optional: true,
+			},
+			"network": {
+				Type:     TypeList,
+				Optional: true,
+				Elem: &Resource{
+					Schema: map[string]*Schema{
+						"port": {
+							Type:     TypeInt,
+							Optional: true,
+						},
+						"addresses": {
+							Type:     TypeList,
+							Optional: true,
+							Elem:     &Schema{Type: TypeString},
+						},
+					},
+				},
+			},
+			"rule": {
+				Type:     TypeSet,
+				Optional: true,
+				Elem: &Resource{
+					Schema: map[string]*Schema{
+						"name": {
+							Type:     TypeString,
+							Optional: true,
+						},
+						"description": {
+							Type:     TypeString,
+							Optional: true,
+						},
+						"ports": {
+							Type:     TypeSet,
+							Optional: true,
+							Elem:     &Schema{Type: TypeInt},
+						},
+					},
+				},
+			},
+			"zones": {
+				Type:     TypeSet,
+				Optional: true,
+				Elem:     &Schema{Type: TypeString},
+			},
+		},
+	}
+
+	d := r.TestResourceData()
+
+	values := map[string]interface{}{
+		"name": "example",
+		"tags": map[string]interface{}{
+			"env": "test",
+		},
+		"network": []interface{}{
+			map[string]interface{}{
+				"port":      80,
+				"addresses": []interface{}{"10.0.0.1", "10.0.0.2"},
+			},
+		},
+		"rule": []interface{}{
+			map[string]interface{}{
+				"name":  "http",
+				"ports": []interface{}{80, 8080},
+			},
+		},
+		"zones": []interface{}{"a", "b"},
+	}
+
+	for k, v := range values {
+		if err := d.Set(k, v); err != nil {
+			t.Fatalf("error setting %s: %s", k, err)
+		}
+	}
+
+	ruleKey := cty.ObjectVal(map[string]cty.Value{
+		"name":        cty.StringVal("http"),
+		"description": cty.NullVal(cty.String),
+		"ports":       cty.SetVal([]cty.Value{cty.NumberIntVal(80), cty.NumberIntVal(8080)}),
+	})
+
+	cases := map[string]struct {
+		path          cty.Path
+		expected      interface{}
+		expectedError string
+	}{
+		"attribute": {
+			path:     cty.GetAttrPath("name"),
+			expected: "example",
+		},
+		"map element": {
+			path:     cty.GetAttrPath("tags").IndexString("env"),
+			expected: "test",
+		},
+		"list block attribute": {
+			path:     cty.GetAttrPath("network").IndexInt(0).GetAttr("port"),
+			expected: 80,
+		},
+		"nested list element": {
+			path:     cty.GetAttrPath("network").IndexInt(0).GetAttr("addresses").IndexInt(1),
+			expected: "10.0.0.2",
+		},
+		"set element": {
+			path:     cty.GetAttrPath("zones").Index(cty.StringVal("b")),
+			expected: "b",
+		},
+		"set block attribute": {
+			path:     cty.GetAttrPath("rule").Index(ruleKey).GetAttr("name"),
+			expected: "http",
+		},
+		"nested set element": {
+			path:     cty.GetAttrPath("rule").Index(ruleKey).GetAttr("ports").Index(cty.NumberIntVal(8080)),
+			expected: 8080,
+		},
+		"empty path": {
+			path:          cty.Path{},
+			expectedError: "GetPath: path must not be empty",
+		},
+		"unknown attribute": {
+			path:          cty.GetAttrPath("nope"),
+			expectedError: ".nope: attribute is not in the schema",
+		},
+		"unknown block attribute": {
+			path:          cty.GetAttrPath("network").IndexInt(0).GetAttr("nope"),
+			expectedError: `.network[0].nope: attribute is not in the schema`,
+		},
+		"list index out of range": {
+			path:          cty.GetAttrPath("network").IndexInt(1),
+			expectedError: ".network[1]: list element does not exist",
+		},
+		"list string index": {
+			path:          cty.GetAttrPath("network").IndexString("a"),
+			expectedError: `.network["a"]: expected a number index`,
+		},
+		"missing map element": {
+			path:          cty.GetAttrPath("tags").IndexString("nope"),
+			expectedError: `.tags["nope"]: map element does not exist`,
+		},
+		"missing set element": {
+			path:          cty.GetAttrPath("zones").Index(cty.StringVal("c")),
+			expectedError: `.zones["c"]: set element does not exist`,
+		},
+		"index into primitive": {
+			path:          cty.GetAttrPath("name").IndexInt(0),
+			expectedError: ".name[0]: cannot index into TypeString",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			actual, err := d.GetPath(tc.path)
+
+			if tc.expectedError != "" {
+				if err == nil {
+					t.Fatalf("expected error %q, got value %#v", tc.expectedError, actual)
+				}
+
+				if err.Error() != tc.expectedError {
+					t.Fatalf("expected error %q, got %q", tc.expectedError, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if diff := cmp.Diff(tc.expected, actual); diff != "" {
+				t.Fatalf("unexpected difference: %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package schema
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"log"
+	"reflect"
+)
+
+// privateDataKey is the key in the resource instance private state under
+// which values written with the ResourceData type SetPrivate method are
+// stored, keeping them apart from keys used by the SDK itself, such as
+// "schema_version" and timeouts.
+const privateDataKey = "_sdk_private_data"
+
+// GetPrivate returns the private data stored under the given key and
+// whether it exists. Private data is written with SetPrivate and is not
+// visible to practitioners.
+func (d *ResourceData) GetPrivate(key string) ([]byte, bool) {
+	v, ok := d.privateData()[key]
+	return v, ok
+}
+
+// SetPrivate stores private data under the given key, such as an
+// idempotency token, without exposing it as an attribute. Setting a nil
+// value removes the key.
+//
+// Private data is persisted in the resource instance private state by
+// Terraform when written during Create, Read or Update, and carried through
+// plan to apply, so it is available to all later operations on the
+// resource. It is discarded when the resource is destroyed.
+//
+// Private data is stored base64 encoded in the Terraform state and sent
+// with every request for the resource. Terraform does not enforce a size
+// limit, but values should be kept small, ideally no more than a few
+// kilobytes in total.
+func (d *ResourceData) SetPrivate(key string, v []byte) {
+	d.once.Do(d.init)
+
+	if d.private == nil {
+		d.private = make(map[string][]byte)
+	}
+
+	d.private[key] = v
+}
+
+// copyPrivateData returns a deep copy of the private data written with
+// SetPrivate, or nil if none was written.
+func copyPrivateData(private map[string][]byte) map[string][]byte {
+	if private == nil {
+		return nil
+	}
+
+	result := make(map[string][]byte, len(private))
+	for k, v := range private {
+		if v != nil {
+			v = append([]byte{}, v...)
+		}
+		result[k] = v
+	}
+
+	return result
+}
+
+// privateData returns the private data of the prior state, updated with the
+// planned private data and any values written with SetPrivate.
+func (d *ResourceData) privateData() map[string][]byte {
+	result := make(map[string][]byte)
+
+	if d.state != nil {
+		decodePrivateData(result, d.state.Meta)
+	}
+
+	if d.diff != nil {
+		decodePrivateData(result, d.diff.Meta)
+	}
+
+	for k, v := range d.private {
+		if v == nil {
+			delete(result, k)
+			continue
+		}
+		result[k] = v
+	}
+
+	return result
+}
+
+// decodePrivateData decodes the private data stored in meta into dst.
+func decodePrivateData(dst map[string][]byte, meta map[string]interface{}) {
+	raw, ok := meta[privateDataKey].(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	for k, v := range raw {
+		s, ok := v.(string)
+		if !ok {
+			log.Printf("[WARN] Ignoring private data %q with unexpected type %T", k, v)
+			continue
+		}
+
+		b, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			log.Printf("[WARN] Ignoring private data %q: %s", k, err)
+			continue
+		}
+
+		dst[k] = b
+	}
+}
+
+// encodePrivateData encodes private data for storage in the instance state
+// Meta, matching its JSON decoded form.
+func encodePrivateData(private map[string][]byte) map[string]interface{} {
+	result := make(map[string]interface{}, len(private))
+
+	for k, v := range private {
+		result[k] = base64.StdEncoding.EncodeToString(v)
+	}
+
+	return result
+}
+
+// mergePrivateData returns the JSON encoded private state with the private
+// data from meta, leaving all other keys untouched. The original private
+// state is returned as-is when the private data is unchanged.
+func mergePrivateData(private []byte, meta map[string]interface{}) ([]byte, error) {
+	privateMap := make(map[string]interface{})
+	if len(private) > 0 {
+		if err := json.Unmarshal(private, &privateMap); err != nil {
+			return nil, err
+		}
+	}
+
+	newData, ok := meta[privateDataKey]
+	oldData, oldOk := privateMap[privateDataKey]
+
+	if ok == oldOk && reflect.DeepEqual(newData, oldData) {
+		return private, nil
+	}
+
+	if ok {
+		privateMap[privateDataKey] = newData
+	} else {
+		delete(privateMap, privateDataKey)
+	}
+
+	return json.Marshal(privateMap)
+}
@@ -4,14 +4,21 @@
 package schema
 
 import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"math"
 	"reflect"
+	"sort"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/go-cty/cty/msgpack"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
@@ -1362,6 +1369,11 @@ func TestResourceDataTimeout(t *testing.T) {
 			Rd:       &ResourceData{timeouts: timeoutForValues(10, 0, 0, 0, 7)},
 			Expected: expectedTimeoutForValues(10, 7, 7, 7, 7),
 		},
+		{
+			Name:     "Resource provides only default",
+			Rd:       &ResourceData{timeouts: timeoutForValues(0, 0, 0, 0, 7)},
+			Expected: expectedTimeoutForValues(7, 7, 7, 7, 7),
+		},
 		{
 			Name:     "Resource provides default and delete",
 			Rd:       &ResourceData{timeouts: timeoutForValues(10, 0, 0, 15, 7)},
@@ -1418,6 +1430,44 @@ func TestResourceDataTimeout(t *testing.T) {
 	}
 }
 
+func TestResourceDataTimeout_maxOperationTimeout(t *testing.T) {
+	create := 2 * time.Hour
+	read := 5 * time.Minute
+
+	d := &ResourceData{
+		timeouts: &ResourceTimeout{
+			Create: &create,
+			Read:   &read,
+		},
+		maxTimeout: 30 * time.Minute,
+	}
+
+	expected := map[string]time.Duration{
+		TimeoutCreate: 30 * time.Minute,
+		TimeoutRead:   5 * time.Minute,
+		// system default of 20 minutes
+		TimeoutUpdate: 20 * time.Minute,
+	}
+
+	for k, ex := range expected {
+		if got := d.Timeout(k); got != ex {
+			t.Errorf("expected %s timeout %s, got %s", k, ex, got)
+		}
+	}
+
+	r := &Resource{
+		Schema: map[string]*Schema{},
+		Timeouts: &ResourceTimeout{
+			Default: DefaultTimeout(24 * time.Hour),
+		},
+		MaxOperationTimeout: time.Hour,
+	}
+
+	if got := r.Data(nil).Timeout(TimeoutDelete); got != time.Hour {
+		t.Errorf("expected delete timeout capped to %s, got %s", time.Hour, got)
+	}
+}
+
 func TestResourceDataHasChanges(t *testing.T) {
 	cases := []struct {
 		Schema map[string]*Schema
@@ -4069,7 +4119,7 @@ func TestResourceDataGetRawConfigAt(t *testing.T) {
 			}
 
 			if diff := cmp.Diff(tc.ExpectedDiags, diags,
-				cmp.AllowUnexported(cty.GetAttrStep{}, cty.IndexStep{}),
+				cmp.AllowUnexported(cty.GetAttrStep{}, cty.IndexStep{}, diag.Diagnostic{}),
 				cmp.Comparer(indexStepComparer),
 			); diff != "" {
 				t.Errorf("Unexpected diagnostics (-wanted +got): %s", diff)
@@ -4082,6 +4132,427 @@ func TestResourceDataGetRawConfigAt(t *testing.T) {
 	}
 }
 
+func TestResourceDataIsSet(t *testing.T) {
+	rawConfig := cty.ObjectVal(map[string]cty.Value{
+		"enabled":  cty.False,
+		"count":    cty.NumberIntVal(0),
+		"name":     cty.StringVal(""),
+		"omitted":  cty.NullVal(cty.String),
+		"computed": cty.UnknownVal(cty.String),
+		"rule": cty.ListVal([]cty.Value{
+			cty.ObjectVal(map[string]cty.Value{
+				"enabled": cty.False,
+				"port":    cty.NullVal(cty.Number),
+			}),
+		}),
+		"tags": cty.MapVal(map[string]cty.Value{
+			"env": cty.StringVal(""),
+		}),
+		"ports": cty.SetVal([]cty.Value{
+			cty.NumberIntVal(80),
+		}),
+	})
+
+	cases := map[string]struct {
+		Key      string
+		Expected bool
+	}{
+		"false bool":               {Key: "enabled", Expected: true},
+		"zero number":              {Key: "count", Expected: true},
+		"empty string":             {Key: "name", Expected: true},
+		"null":                     {Key: "omitted", Expected: false},
+		"unknown":                  {Key: "computed", Expected: true},
+		"unknown attribute":        {Key: "missing", Expected: false},
+		"list":                     {Key: "rule", Expected: true},
+		"nested false bool":        {Key: "rule.0.enabled", Expected: true},
+		"nested null":              {Key: "rule.0.port", Expected: false},
+		"list index out of range":  {Key: "rule.1.enabled", Expected: false},
+		"invalid list index":       {Key: "rule.first.enabled", Expected: false},
+		"map key with empty value": {Key: "tags.env", Expected: true},
+		"missing map key":          {Key: "tags.team", Expected: false},
+		"set element":              {Key: "ports.80", Expected: false},
+	}
+
+	for tn, tc := range cases {
+		t.Run(tn, func(t *testing.T) {
+			d := &ResourceData{
+				diff: &terraform.InstanceDiff{
+					RawConfig: rawConfig,
+				},
+			}
+
+			if got := d.IsSet(tc.Key); got != tc.Expected {
+				t.Errorf("expected IsSet(%q) to be %t, got %t", tc.Key, tc.Expected, got)
+			}
+
+			if got := d.IsNull(tc.Key); got == tc.Expected {
+				t.Errorf("expected IsNull(%q) to be %t, got %t", tc.Key, !tc.Expected, got)
+			}
+		})
+	}
+}
+
+func TestResourceDataGetNormalized(t *testing.T) {
+	schema := map[string]*Schema{
+		"status": {
+			Type:     TypeString,
+			Optional: true,
+		},
+	}
+
+	mapping := map[string]string{
+		"enabled":  "true",
+		"disabled": "false",
+	}
+
+	cases := map[string]struct {
+		Config   map[string]interface{}
+		Expected string
+	}{
+		"mapped enabled": {
+			Config:   map[string]interface{}{"status": "enabled"},
+			Expected: "true",
+		},
+		"mapped disabled": {
+			Config:   map[string]interface{}{"status": "disabled"},
+			Expected: "false",
+		},
+		"unmapped": {
+			Config:   map[string]interface{}{"status": "paused"},
+			Expected: "paused",
+		},
+		"unset": {
+			Config:   map[string]interface{}{},
+			Expected: "",
+		},
+	}
+
+	for tn, tc := range cases {
+		t.Run(tn, func(t *testing.T) {
+			d := TestResourceDataRaw(t, schema, tc.Config)
+
+			if got := d.GetNormalized("status", mapping); got != tc.Expected {
+				t.Errorf("expected %q, got %q", tc.Expected, got)
+			}
+		})
+	}
+}
+
+func TestResourceDataGetProviderMetaValue(t *testing.T) {
+	providerMeta := cty.ObjectVal(map[string]cty.Value{
+		"module_name": cty.StringVal("network"),
+		"retries":     cty.NumberIntVal(3),
+		"tags":        cty.ListVal([]cty.Value{cty.StringVal("a")}),
+		"omitted":     cty.NullVal(cty.String),
+	})
+
+	cases := map[string]struct {
+		ProviderMeta  cty.Value
+		Key           string
+		Expected      interface{}
+		ExpectedExist bool
+	}{
+		"string": {
+			ProviderMeta:  providerMeta,
+			Key:           "module_name",
+			Expected:      "network",
+			ExpectedExist: true,
+		},
+		"number": {
+			ProviderMeta:  providerMeta,
+			Key:           "retries",
+			Expected:      3,
+			ExpectedExist: true,
+		},
+		"list": {
+			ProviderMeta:  providerMeta,
+			Key:           "tags",
+			Expected:      []interface{}{"a"},
+			ExpectedExist: true,
+		},
+		"null": {
+			ProviderMeta: providerMeta,
+			Key:          "omitted",
+		},
+		"unknown attribute": {
+			ProviderMeta: providerMeta,
+			Key:          "missing",
+		},
+		"no provider meta": {
+			ProviderMeta: cty.NullVal(providerMeta.Type()),
+			Key:          "module_name",
+		},
+		"unset provider meta": {
+			Key: "module_name",
+		},
+	}
+
+	for tn, tc := range cases {
+		t.Run(tn, func(t *testing.T) {
+			d := &ResourceData{
+				providerMeta: tc.ProviderMeta,
+			}
+
+			v, ok := d.GetProviderMetaValue(tc.Key)
+
+			if ok != tc.ExpectedExist {
+				t.Fatalf("expected exists to be %t, got %t", tc.ExpectedExist, ok)
+			}
+
+			if diff := cmp.Diff(tc.Expected, v); diff != "" {
+				t.Fatalf("unexpected difference: %s", diff)
+			}
+		})
+	}
+}
+
+func TestResourceDataSingleBlock(t *testing.T) {
+	block := &Resource{
+		Schema: map[string]*Schema{
+			"name": {
+				Type:     TypeString,
+				Optional: true,
+			},
+		},
+	}
+
+	schema := map[string]*Schema{
+		"network": {
+			Type:     TypeList,
+			Optional: true,
+			MaxItems: 1,
+			Elem:     block,
+		},
+		"disk": {
+			Type:     TypeSet,
+			Optional: true,
+			MaxItems: 1,
+			Elem:     block,
+		},
+		"empty": {
+			Type:     TypeList,
+			Optional: true,
+			MaxItems: 1,
+			Elem:     block,
+		},
+		"rule": {
+			Type:     TypeList,
+			Optional: true,
+			Elem:     block,
+		},
+		"names": {
+			Type:     TypeList,
+			Optional: true,
+			MaxItems: 1,
+			Elem:     &Schema{Type: TypeString},
+		},
+	}
+
+	d := TestResourceDataRaw(t, schema, map[string]interface{}{
+		"network": []interface{}{
+			map[string]interface{}{"name": "a"},
+		},
+		"disk": []interface{}{
+			map[string]interface{}{"name": "b"},
+		},
+		"rule": []interface{}{
+			map[string]interface{}{"name": "c"},
+		},
+		"names": []interface{}{"d"},
+	})
+
+	if v, ok := d.GetSingleBlock("network"); !ok || v["name"] != "a" {
+		t.Errorf("unexpected GetSingleBlock result for list: %#v, %t", v, ok)
+	}
+
+	if v, ok := d.GetSingleBlock("disk"); !ok || v["name"] != "b" {
+		t.Errorf("unexpected GetSingleBlock result for set: %#v, %t", v, ok)
+	}
+
+	for _, key := range []string{"empty", "rule", "names", "missing"} {
+		if v, ok := d.GetSingleBlock(key); ok || v != nil {
+			t.Errorf("expected no block for %s, got %#v, %t", key, v, ok)
+		}
+	}
+
+	if err := d.SetSingleBlock("empty", map[string]interface{}{"name": "e"}); err != nil {
+		t.Fatalf("unexpected SetSingleBlock error: %s", err)
+	}
+
+	if v, ok := d.GetSingleBlock("empty"); !ok || v["name"] != "e" {
+		t.Errorf("unexpected GetSingleBlock result after SetSingleBlock: %#v, %t", v, ok)
+	}
+
+	if err := d.SetSingleBlock("disk", map[string]interface{}{"name": "f"}); err != nil {
+		t.Fatalf("unexpected SetSingleBlock error for set: %s", err)
+	}
+
+	if v, ok := d.GetSingleBlock("disk"); !ok || v["name"] != "f" {
+		t.Errorf("unexpected GetSingleBlock result for set after SetSingleBlock: %#v, %t", v, ok)
+	}
+
+	if err := d.SetSingleBlock("network", nil); err != nil {
+		t.Fatalf("unexpected SetSingleBlock error for nil block: %s", err)
+	}
+
+	if v, ok := d.GetSingleBlock("network"); ok {
+		t.Errorf("expected no block after setting nil, got %#v", v)
+	}
+
+	for _, key := range []string{"rule", "names", "missing"} {
+		err := d.SetSingleBlock(key, map[string]interface{}{"name": "g"})
+		expected := key + ": must be a TypeList or TypeSet with MaxItems 1 and an Elem *Resource"
+
+		if err == nil || err.Error() != expected {
+			t.Errorf("expected error %q for %s, got %v", expected, key, err)
+		}
+	}
+}
+
+func TestResourceDataGetPrimitiveCollections(t *testing.T) {
+	schema := map[string]*Schema{
+		"names": {
+			Type:     TypeList,
+			Optional: true,
+			Elem:     &Schema{Type: TypeString},
+		},
+		"ports": {
+			Type:     TypeList,
+			Optional: true,
+			Elem:     &Schema{Type: TypeInt},
+		},
+		"zones": {
+			Type:     TypeSet,
+			Optional: true,
+			Elem:     &Schema{Type: TypeString},
+		},
+		"empty": {
+			Type:     TypeList,
+			Optional: true,
+			Elem:     &Schema{Type: TypeString},
+		},
+		"rule": {
+			Type:     TypeList,
+			Optional: true,
+			Elem: &Resource{
+				Schema: map[string]*Schema{
+					"name": {
+						Type:     TypeString,
+						Optional: true,
+					},
+				},
+			},
+		},
+	}
+
+	d := TestResourceDataRaw(t, schema, map[string]interface{}{
+		"names": []interface{}{"a", "b"},
+		"ports": []interface{}{80, 443},
+		"zones": []interface{}{"us-east-1a", "us-east-1b"},
+		"rule": []interface{}{
+			map[string]interface{}{"name": "a"},
+		},
+	})
+
+	if diff := cmp.Diff([]string{"a", "b"}, d.GetStringList("names")); diff != "" {
+		t.Errorf("unexpected GetStringList difference: %s", diff)
+	}
+
+	if diff := cmp.Diff([]int{80, 443}, d.GetIntList("ports")); diff != "" {
+		t.Errorf("unexpected GetIntList difference: %s", diff)
+	}
+
+	zones := d.GetStringSet("zones")
+	sort.Strings(zones)
+	if diff := cmp.Diff([]string{"us-east-1a", "us-east-1b"}, zones); diff != "" {
+		t.Errorf("unexpected GetStringSet difference: %s", diff)
+	}
+
+	if diff := cmp.Diff([]string{}, d.GetStringList("empty")); diff != "" {
+		t.Errorf("unexpected GetStringList difference for empty list: %s", diff)
+	}
+
+	if got := d.GetIntList("names"); got != nil {
+		t.Errorf("expected nil for list of strings read as integers, got %#v", got)
+	}
+
+	if got := d.GetStringList("zones"); got != nil {
+		t.Errorf("expected nil for set read as list, got %#v", got)
+	}
+
+	if got := d.GetStringList("rule"); got != nil {
+		t.Errorf("expected nil for list of blocks, got %#v", got)
+	}
+}
+
+func TestResourceDataGetRawConfigMsgPack(t *testing.T) {
+	schema := map[string]*Schema{
+		"foo": {
+			Type:     TypeString,
+			Optional: true,
+		},
+	}
+	ty := schemaMap(schema).CoreConfigSchema().ImpliedType()
+	config := cty.ObjectVal(map[string]cty.Value{
+		"foo": cty.StringVal("bar"),
+	})
+
+	encoded, err := msgpack.Marshal(config, ty)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string]struct {
+		State    *terraform.InstanceState
+		Diff     *terraform.InstanceDiff
+		Expected []byte
+	}{
+		"none": {},
+		"diff": {
+			Diff: &terraform.InstanceDiff{
+				RawConfig:        config,
+				RawConfigMsgPack: []byte("diff"),
+			},
+			State: &terraform.InstanceState{
+				RawConfigMsgPack: []byte("state"),
+			},
+			Expected: []byte("diff"),
+		},
+		"state": {
+			State: &terraform.InstanceState{
+				RawConfig:        config,
+				RawConfigMsgPack: []byte("state"),
+			},
+			Expected: []byte("state"),
+		},
+		"encoded raw config": {
+			Diff: &terraform.InstanceDiff{
+				RawConfig: config,
+			},
+			Expected: encoded,
+		},
+	}
+
+	for tn, tc := range cases {
+		t.Run(tn, func(t *testing.T) {
+			d := &ResourceData{
+				schema: schema,
+				state:  tc.State,
+				diff:   tc.Diff,
+			}
+
+			actual, err := d.GetRawConfigMsgPack()
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !bytes.Equal(actual, tc.Expected) {
+				t.Fatalf("expected %q, got %q", tc.Expected, actual)
+			}
+		})
+	}
+}
+
 func TestResourceDataSetConnInfo(t *testing.T) {
 	d := &ResourceData{}
 	d.SetId("foo")
@@ -4167,6 +4638,346 @@ func TestResourceDataSetId_override(t *testing.T) {
 	}
 }
 
+func TestResourceDataSetIdErr(t *testing.T) {
+	r := &Resource{
+		Schema: map[string]*Schema{
+			"foo": {
+				Type:     TypeString,
+				Optional: true,
+			},
+		},
+		ValidateIDFunc: func(id string) error {
+			if !strings.HasPrefix(id, "i-") {
+				return errors.New("must start with i-")
+			}
+			return nil
+		},
+	}
+
+	cases := map[string]struct {
+		Resource    *Resource
+		ID          string
+		ExpectedID  string
+		ExpectedErr string
+	}{
+		"valid": {
+			Resource:   &Resource{},
+			ID:         "foo",
+			ExpectedID: "foo",
+		},
+		"empty": {
+			Resource:    &Resource{},
+			ID:          "",
+			ExpectedID:  "bar",
+			ExpectedErr: "resource ID must not be empty",
+		},
+		"control character": {
+			Resource:    &Resource{},
+			ID:          "foo\n",
+			ExpectedID:  "bar",
+			ExpectedErr: `resource ID "foo\n" must not contain control characters`,
+		},
+		"ValidateIDFunc valid": {
+			Resource:   r,
+			ID:         "i-123",
+			ExpectedID: "i-123",
+		},
+		"ValidateIDFunc invalid": {
+			Resource:    r,
+			ID:          "123",
+			ExpectedID:  "bar",
+			ExpectedErr: `invalid resource ID "123": must start with i-`,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			d := tc.Resource.Data(&terraform.InstanceState{ID: "bar"})
+
+			err := d.SetIdErr(tc.ID)
+
+			var errStr string
+			if err != nil {
+				errStr = err.Error()
+			}
+
+			if errStr != tc.ExpectedErr {
+				t.Fatalf("expected error %q, got %q", tc.ExpectedErr, errStr)
+			}
+
+			if d.Id() != tc.ExpectedID {
+				t.Fatalf("expected ID %q, got %q", tc.ExpectedID, d.Id())
+			}
+		})
+	}
+}
+
+func TestResourceDataSnapshotRestore(t *testing.T) {
+	d := &ResourceData{
+		schema: map[string]*Schema{
+			"foo": {
+				Type:     TypeString,
+				Computed: true,
+			},
+			"bar": {
+				Type:     TypeString,
+				Computed: true,
+			},
+		},
+		state: &terraform.InstanceState{
+			ID: "test",
+			Attributes: map[string]string{
+				"id":  "test",
+				"foo": "old-foo",
+				"bar": "old-bar",
+			},
+		},
+	}
+
+	if err := d.Set("foo", "new-foo"); err != nil {
+		t.Fatalf("unexpected Set error: %s", err)
+	}
+
+	snapshot := d.Snapshot()
+
+	if err := d.Set("bar", "new-bar"); err != nil {
+		t.Fatalf("unexpected Set error: %s", err)
+	}
+	d.SetId("other")
+
+	if err := d.Restore(snapshot); err != nil {
+		t.Fatalf("unexpected Restore error: %s", err)
+	}
+
+	expected := &terraform.InstanceState{
+		ID: "test",
+		Attributes: map[string]string{
+			"id":  "test",
+			"foo": "new-foo",
+			"bar": "old-bar",
+		},
+	}
+
+	if actual := d.State(); !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("bad: %#v", actual)
+	}
+
+	// Writes after restoring are still reflected.
+	if err := d.Set("bar", "newer-bar"); err != nil {
+		t.Fatalf("unexpected Set error: %s", err)
+	}
+
+	if got := d.Get("bar"); got != "newer-bar" {
+		t.Fatalf("expected bar to be newer-bar, got: %#v", got)
+	}
+}
+
+func TestResourceDataSnapshotRestore_private(t *testing.T) {
+	d := &ResourceData{
+		state: &terraform.InstanceState{
+			ID: "test",
+		},
+	}
+
+	d.SetPrivate("kept", []byte("before"))
+
+	snapshot := d.Snapshot()
+
+	d.SetPrivate("kept", []byte("after"))
+	d.SetPrivate("added", []byte("after"))
+
+	if err := d.Restore(snapshot); err != nil {
+		t.Fatalf("unexpected Restore error: %s", err)
+	}
+
+	if v, ok := d.GetPrivate("kept"); !ok || string(v) != "before" {
+		t.Fatalf("expected kept to be before, got: %q", v)
+	}
+
+	if v, ok := d.GetPrivate("added"); ok {
+		t.Fatalf("expected added to be removed, got: %q", v)
+	}
+}
+
+func TestResourceDataRestore_invalid(t *testing.T) {
+	d := &ResourceData{}
+	other := &ResourceData{}
+
+	if err := d.Restore(nil); err == nil {
+		t.Fatal("expected error restoring nil snapshot")
+	}
+
+	if err := d.Restore(other.Snapshot()); err == nil {
+		t.Fatal("expected error restoring snapshot from a different ResourceData")
+	}
+}
+
+func TestResourceDataReset(t *testing.T) {
+	d := &ResourceData{
+		schema: map[string]*Schema{
+			"name": {
+				Type:     TypeString,
+				Optional: true,
+			},
+			"arn": {
+				Type:     TypeString,
+				Computed: true,
+			},
+			"tags": {
+				Type:     TypeMap,
+				Optional: true,
+				Elem:     &Schema{Type: TypeString},
+			},
+		},
+		state: &terraform.InstanceState{
+			ID: "test",
+			Attributes: map[string]string{
+				"id":     "test",
+				"name":   "old-name",
+				"arn":    "old-arn",
+				"tags.%": "1",
+				"tags.a": "b",
+			},
+		},
+		diff: &terraform.InstanceDiff{
+			Attributes: map[string]*terraform.ResourceAttrDiff{
+				"name": {
+					Old: "old-name",
+					New: "new-name",
+				},
+			},
+		},
+	}
+
+	if err := d.Set("name", "set-name"); err != nil {
+		t.Fatalf("unexpected Set error: %s", err)
+	}
+	if err := d.Set("arn", "set-arn"); err != nil {
+		t.Fatalf("unexpected Set error: %s", err)
+	}
+	if err := d.Set("tags", map[string]interface{}{"c": "d"}); err != nil {
+		t.Fatalf("unexpected Set error: %s", err)
+	}
+	d.SetId("new-id")
+
+	d.Reset()
+
+	expected := &terraform.InstanceState{
+		ID: "new-id",
+		Attributes: map[string]string{
+			"id":     "new-id",
+			"name":   "new-name",
+			"arn":    "old-arn",
+			"tags.%": "1",
+			"tags.a": "b",
+		},
+	}
+
+	if actual := d.State(); !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("bad: %#v", actual)
+	}
+
+	if d.HasChange("arn") {
+		t.Fatal("expected arn to not have changes after Reset")
+	}
+
+	// Writes after resetting are still reflected.
+	if err := d.Set("arn", "newer-arn"); err != nil {
+		t.Fatalf("unexpected Set error: %s", err)
+	}
+
+	if got := d.Get("arn"); got != "newer-arn" {
+		t.Fatalf("expected arn to be newer-arn, got: %#v", got)
+	}
+}
+
+func TestResourceDataPrivate(t *testing.T) {
+	d := &ResourceData{
+		schema: map[string]*Schema{
+			"foo": {
+				Type:     TypeString,
+				Optional: true,
+			},
+		},
+		state: &terraform.InstanceState{
+			ID: "test",
+			Attributes: map[string]string{
+				"id": "test",
+			},
+			Meta: map[string]interface{}{
+				"schema_version": "1",
+				privateDataKey: map[string]interface{}{
+					"prior":   base64.StdEncoding.EncodeToString([]byte("prior-value")),
+					"removed": base64.StdEncoding.EncodeToString([]byte("removed-value")),
+				},
+			},
+		},
+		diff: &terraform.InstanceDiff{
+			Meta: map[string]interface{}{
+				privateDataKey: map[string]interface{}{
+					"planned": base64.StdEncoding.EncodeToString([]byte("planned-value")),
+				},
+			},
+		},
+	}
+
+	if v, ok := d.GetPrivate("prior"); !ok || string(v) != "prior-value" {
+		t.Fatalf("expected prior-value, got %q (%t)", v, ok)
+	}
+
+	if v, ok := d.GetPrivate("planned"); !ok || string(v) != "planned-value" {
+		t.Fatalf("expected planned-value, got %q (%t)", v, ok)
+	}
+
+	if v, ok := d.GetPrivate("missing"); ok {
+		t.Fatalf("expected missing key, got %q", v)
+	}
+
+	d.SetPrivate("prior", []byte("new-value"))
+	d.SetPrivate("removed", nil)
+
+	if v, ok := d.GetPrivate("prior"); !ok || string(v) != "new-value" {
+		t.Fatalf("expected new-value, got %q (%t)", v, ok)
+	}
+
+	if v, ok := d.GetPrivate("removed"); ok {
+		t.Fatalf("expected removed key, got %q", v)
+	}
+
+	expected := map[string]interface{}{
+		"planned": base64.StdEncoding.EncodeToString([]byte("planned-value")),
+		"prior":   base64.StdEncoding.EncodeToString([]byte("new-value")),
+	}
+
+	state := d.State()
+	if !reflect.DeepEqual(state.Meta[privateDataKey], expected) {
+		t.Fatalf("unexpected private data in state: %#v", state.Meta[privateDataKey])
+	}
+
+	// The private data must survive a JSON round trip through Terraform.
+	b, err := json.Marshal(state.Meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var meta map[string]interface{}
+	if err := json.Unmarshal(b, &meta); err != nil {
+		t.Fatal(err)
+	}
+
+	next := &ResourceData{
+		schema: d.schema,
+		state: &terraform.InstanceState{
+			ID:   "test",
+			Meta: meta,
+		},
+	}
+
+	if v, ok := next.GetPrivate("prior"); !ok || string(v) != "new-value" {
+		t.Fatalf("expected new-value after round trip, got %q (%t)", v, ok)
+	}
+}
+
 func TestResourceDataSetType(t *testing.T) {
 	d := &ResourceData{}
 	d.SetId("foo")
@@ -4314,6 +5125,71 @@ func TestResourceDataIdentity_no_schema(t *testing.T) {
 	}
 }
 
+func TestResourceDataPriorIdentity_from_state(t *testing.T) {
+	d := &ResourceData{
+		identitySchema: map[string]*Schema{
+			"foo": {
+				Type:              TypeString,
+				RequiredForImport: true,
+			},
+		},
+		state: &terraform.InstanceState{
+			Identity: map[string]string{
+				"foo": "bar",
+			},
+		},
+		diff: &terraform.InstanceDiff{
+			Identity: map[string]string{
+				"foo": "baz",
+			},
+		},
+	}
+
+	identity, err := d.Identity()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := identity.Set("foo", "qux"); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	prior, err := d.PriorIdentity()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if prior.Get("foo").(string) != "bar" {
+		t.Fatalf("expected prior identity to contain bar value for foo: %#v", prior)
+	}
+}
+
+func TestResourceDataPriorIdentity_no_prior_identity(t *testing.T) {
+	d := &ResourceData{
+		identitySchema: map[string]*Schema{
+			"foo": {
+				Type:              TypeString,
+				RequiredForImport: true,
+			},
+		},
+		state: &terraform.InstanceState{},
+	}
+
+	_, err := d.PriorIdentity()
+	if err == nil {
+		t.Fatalf("expected error since there's no prior identity, got: nil")
+	}
+	if diff := cmp.Diff("Resource does not have a prior identity. This can happen when importing by ID or when the resource is being created.", err.Error()); diff != "" {
+		t.Fatalf("unexpected error message (-want +got):\n%s", diff)
+	}
+}
+
+func TestResourceDataPriorIdentity_no_schema(t *testing.T) {
+	d := &ResourceData{}
+	_, err := d.PriorIdentity()
+	if err == nil {
+		t.Fatalf("expected error since there's no identity schema, got: nil")
+	}
+}
+
 func testPtrTo(raw interface{}) interface{} {
 	return &raw
 }
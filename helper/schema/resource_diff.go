@@ -4,6 +4,7 @@
 package schema
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"reflect"
@@ -150,6 +151,10 @@ type ResourceDiff struct {
 	forcedNewKeys map[string]bool
 
 	newIdentity *IdentityData
+
+	// deferred is set by Defer to signal that the change cannot be planned
+	// yet.
+	deferred *Deferred
 }
 
 // newResourceDiff creates a new ResourceDiff instance.
@@ -600,6 +605,23 @@ func (d *ResourceDiff) getChange(key string) (getResult, getResult, bool) {
 	return oldValue, newValue, false
 }
 
+// Defer signals to Terraform that the change of the resource cannot be
+// planned yet, such as when a value it depends on is unknown, and should be
+// deferred to a later plan with the given reason. The planned change is
+// still computed and returned alongside the deferral.
+//
+// Defer is only supported in CustomizeDiff. If the Terraform request does
+// not indicate support for deferred actions, the plan returns an error.
+//
+// NOTE: This functionality is related to deferred action support, which is
+// currently experimental and is subject to change or break without warning.
+// It is not protected by version compatibility guarantees.
+func (d *ResourceDiff) Defer(reason DeferredReason) {
+	d.deferred = &Deferred{
+		Reason: reason,
+	}
+}
+
 // removed checks to see if the key is present in the existing, pre-customized
 // diff and if it was marked as NewRemoved.
 func (d *ResourceDiff) removed(k string) bool {
@@ -707,3 +729,21 @@ func (d *ResourceDiff) Identity() (*IdentityData, error) {
 
 	return d.newIdentity, nil
 }
+
+// planDeferral is stored in the context of a plan to receive the deferral
+// signaled with ResourceDiff Defer.
+type planDeferral struct {
+	deferred *Deferred
+}
+
+// recordDeferral passes the deferral signaled with Defer, if any, to the
+// plan in progress.
+func (d *ResourceDiff) recordDeferral(ctx context.Context) {
+	if d.deferred == nil {
+		return
+	}
+
+	if pd, ok := ctx.Value(planDeferralContextKey).(*planDeferral); ok {
+		pd.deferred = d.deferred
+	}
+}
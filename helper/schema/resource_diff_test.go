@@ -2405,7 +2405,7 @@ func TestResourceDiffGetRawConfigAt(t *testing.T) {
 			}
 
 			if diff := cmp.Diff(tc.ExpectedDiags, diags,
-				cmp.AllowUnexported(cty.GetAttrStep{}, cty.IndexStep{}),
+				cmp.AllowUnexported(cty.GetAttrStep{}, cty.IndexStep{}, diag.Diagnostic{}),
 				cmp.Comparer(indexStepComparer),
 			); diff != "" {
 				t.Errorf("Unexpected diagnostics (-wanted +got): %s", diff)
@@ -0,0 +1,106 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package schema
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ExampleHCL returns a minimal example configuration for the resource, such
+// as for use in generated documentation. Only Required attributes and
+// nested blocks are included, with placeholder values based on their types.
+// Optional and Computed attributes are omitted.
+//
+// For example, a resource with a required "name" string attribute results
+// in:
+//
+//	resource "example_thing" "example" {
+//	  name = "string"
+//	}
+func (r *Resource) ExampleHCL(resourceType, name string) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "resource %q %q {\n", resourceType, name)
+	writeExampleHCLBody(&b, schemaMap(r.SchemaMap()), 1)
+	b.WriteString("}\n")
+
+	return b.String()
+}
+
+func writeExampleHCLBody(b *strings.Builder, m schemaMap, depth int) {
+	indent := strings.Repeat("  ", depth)
+
+	var attrs, blocks []string
+	width := 0
+	for k, v := range m {
+		if !v.Required {
+			continue
+		}
+
+		if _, ok := v.Elem.(*Resource); ok {
+			blocks = append(blocks, k)
+			continue
+		}
+
+		attrs = append(attrs, k)
+		width = max(width, len(k))
+	}
+	sort.Strings(attrs)
+	sort.Strings(blocks)
+
+	for _, k := range attrs {
+		fmt.Fprintf(b, "%s%-*s = %s\n", indent, width, k, exampleHCLValue(m[k]))
+	}
+
+	for i, k := range blocks {
+		if i > 0 || len(attrs) > 0 {
+			b.WriteString("\n")
+		}
+
+		v := m[k]
+		elem := v.Elem.(*Resource)
+
+		for n := 0; n < max(v.MinItems, 1); n++ {
+			if n > 0 {
+				b.WriteString("\n")
+			}
+			fmt.Fprintf(b, "%s%s {\n", indent, k)
+			writeExampleHCLBody(b, schemaMap(elem.SchemaMap()), depth+1)
+			fmt.Fprintf(b, "%s}\n", indent)
+		}
+	}
+}
+
+// exampleHCLValue returns a placeholder HCL expression for an attribute.
+func exampleHCLValue(s *Schema) string {
+	switch s.Type {
+	case TypeBool:
+		return "false"
+	case TypeInt, TypeFloat:
+		return "0"
+	case TypeString:
+		return `"string"`
+	case TypeList, TypeSet:
+		return "[" + exampleHCLElemValue(s.Elem) + "]"
+	case TypeMap:
+		return "{ key = " + exampleHCLElemValue(s.Elem) + " }"
+	}
+
+	return "null"
+}
+
+// exampleHCLElemValue returns a placeholder HCL expression for an element
+// of a list, set or map attribute, which defaults to a string.
+func exampleHCLElemValue(elem interface{}) string {
+	switch elem := elem.(type) {
+	case *Schema:
+		return exampleHCLValue(elem)
+	case ValueType:
+		return exampleHCLValue(&Schema{Type: elem})
+	}
+
+	return exampleHCLValue(&Schema{Type: TypeString})
+}
@@ -0,0 +1,184 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package schema
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+func TestResourceExampleHCL(t *testing.T) {
+	r := &Resource{
+		Schema: map[string]*Schema{
+			"name": {
+				Type:     TypeString,
+				Required: true,
+			},
+			"count_limit": {
+				Type:     TypeInt,
+				Required: true,
+			},
+			"enabled": {
+				Type:     TypeBool,
+				Required: true,
+			},
+			"ratio": {
+				Type:     TypeFloat,
+				Required: true,
+			},
+			"zones": {
+				Type:     TypeList,
+				Required: true,
+				Elem:     &Schema{Type: TypeString},
+			},
+			"ports": {
+				Type:     TypeSet,
+				Required: true,
+				Elem:     &Schema{Type: TypeInt},
+			},
+			"labels": {
+				Type:     TypeMap,
+				Required: true,
+			},
+			"description": {
+				Type:     TypeString,
+				Optional: true,
+			},
+			"arn": {
+				Type:     TypeString,
+				Computed: true,
+			},
+			"rule": {
+				Type:     TypeList,
+				Required: true,
+				MinItems: 2,
+				Elem: &Resource{
+					Schema: map[string]*Schema{
+						"action": {
+							Type:     TypeString,
+							Required: true,
+						},
+						"priority": {
+							Type:     TypeInt,
+							Optional: true,
+						},
+						"match": {
+							Type:     TypeSet,
+							Required: true,
+							Elem: &Resource{
+								Schema: map[string]*Schema{
+									"path": {
+										Type:     TypeString,
+										Required: true,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			"logging": {
+				Type:     TypeList,
+				Optional: true,
+				Elem: &Resource{
+					Schema: map[string]*Schema{
+						"bucket": {
+							Type:     TypeString,
+							Required: true,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	expected := `resource "example_thing" "example" {
+  count_limit = 0
+  enabled     = false
+  labels      = { key = "string" }
+  name        = "string"
+  ports       = [0]
+  ratio       = 0
+  zones       = ["string"]
+
+  rule {
+    action = "string"
+
+    match {
+      path = "string"
+    }
+  }
+
+  rule {
+    action = "string"
+
+    match {
+      path = "string"
+    }
+  }
+}
+`
+
+	actual := r.ExampleHCL("example_thing", "example")
+
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Fatalf("unexpected difference: %s", diff)
+	}
+
+	file, diags := hclsyntax.ParseConfig([]byte(actual), "example.tf", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected parse error: %s", diags)
+	}
+
+	blocks := file.Body.(*hclsyntax.Body).Blocks
+	if len(blocks) != 1 {
+		t.Fatalf("expected 1 block, got %d", len(blocks))
+	}
+
+	if diff := cmp.Diff([]string{"example_thing", "example"}, blocks[0].Labels); diff != "" {
+		t.Fatalf("unexpected labels: %s", diff)
+	}
+
+	var attrs []string
+	for k := range blocks[0].Body.Attributes {
+		attrs = append(attrs, k)
+	}
+	sort.Strings(attrs)
+
+	expectedAttrs := []string{"count_limit", "enabled", "labels", "name", "ports", "ratio", "zones"}
+	if diff := cmp.Diff(expectedAttrs, attrs); diff != "" {
+		t.Fatalf("unexpected attributes: %s", diff)
+	}
+
+	for _, block := range blocks[0].Body.Blocks {
+		if block.Type != "rule" {
+			t.Fatalf("unexpected block %q", block.Type)
+		}
+
+		if _, ok := block.Body.Attributes["priority"]; ok {
+			t.Fatal("unexpected optional attribute in nested block")
+		}
+	}
+}
+
+func TestResourceExampleHCL_empty(t *testing.T) {
+	r := &Resource{
+		Schema: map[string]*Schema{
+			"id_prefix": {
+				Type:     TypeString,
+				Optional: true,
+			},
+		},
+	}
+
+	expected := "resource \"example_thing\" \"example\" {\n}\n"
+
+	if actual := r.ExampleHCL("example_thing", "example"); actual != expected {
+		t.Fatalf("expected %q, got %q", expected, actual)
+	}
+}
@@ -29,7 +29,8 @@ type IdentityUpgrader struct {
 }
 
 type ResourceIdentity struct {
-	// Version is the identity schema version.
+	// Version is the identity schema version. It must be at least 1 and
+	// greater than the Version of every IdentityUpgrader.
 	Version int64
 
 	// SchemaFunc is the function that returns the schema for the
@@ -44,7 +45,16 @@ type ResourceIdentity struct {
 	//   - TypeList (of any of the above types)
 	SchemaFunc func() map[string]*Schema
 
-	// New struct, will be similar to (Resource).StateUpgraders
+	// IdentityUpgraders contains the functions responsible for upgrading an
+	// existing identity with an old identity schema version to a newer one,
+	// similar to the Resource type StateUpgraders. They are called by
+	// Terraform through UpgradeResourceIdentity when the stored identity
+	// version is less than Version, each upgrading the identity data one
+	// version.
+	//
+	// The registered versions are expected to be ordered, consecutive
+	// values ending at Version-1. The chain may start above 0 when older
+	// identity versions no longer need to be upgraded.
 	IdentityUpgraders []IdentityUpgrader
 }
 
@@ -80,10 +90,94 @@ type ResourceIdentityUpgradeFunc func(ctx context.Context, rawState map[string]i
 
 // SchemaMap returns the schema information for this resource identity
 // defined via the SchemaFunc field.
+//
+// Attributes without a schema, such as those referenced with
+// IdentityFromAttributes which do not exist in the resource schema, are
+// omitted. They are reported by InternalIdentityValidate.
 func (ri *ResourceIdentity) SchemaMap() map[string]*Schema {
+	m := ri.schemaFuncMap()
+
+	for _, v := range m {
+		if v != nil {
+			continue
+		}
+
+		result := make(map[string]*Schema, len(m))
+		for k, v := range m {
+			if v != nil {
+				result[k] = v
+			}
+		}
+
+		return result
+	}
+
+	return m
+}
+
+// schemaFuncMap returns the result of the SchemaFunc field, including the
+// attributes without a schema.
+func (ri *ResourceIdentity) schemaFuncMap() map[string]*Schema {
 	if ri == nil || ri.SchemaFunc == nil {
 		return nil
 	}
 
 	return ri.SchemaFunc()
 }
+
+// IdentityFromAttributes returns a function suitable for the
+// ResourceIdentity type SchemaFunc field, which builds the identity schema
+// by copying the named attributes from the given resource schema. This
+// prevents duplicating attribute definitions that already exist in the
+// resource schema.
+//
+// The Required flag of each resource attribute chooses between
+// RequiredForImport and OptionalForImport: attributes that are Required in
+// the resource schema are marked as RequiredForImport, all others are
+// marked as OptionalForImport. This follows whether the attribute is always
+// known after import, so no separate flag is needed. Only the Type, Elem
+// (for lists of primitives), and Description fields are copied.
+//
+// The resource schema is read each time the returned function is called,
+// so it is safe to use with resources that define SchemaFunc. Referencing
+// an attribute which does not exist in the resource schema, or which is not
+// of a type supported by resource identity, is reported by
+// InternalIdentityValidate.
+func IdentityFromAttributes(resource *Resource, attrs ...string) func() map[string]*Schema {
+	return func() map[string]*Schema {
+		var resourceSchema map[string]*Schema
+		if resource != nil {
+			resourceSchema = resource.SchemaMap()
+		}
+
+		result := make(map[string]*Schema, len(attrs))
+
+		for _, attr := range attrs {
+			s, ok := resourceSchema[attr]
+			if !ok || s == nil {
+				// Reported by InternalIdentityValidate.
+				result[attr] = nil
+				continue
+			}
+
+			identitySchema := &Schema{
+				Type:              s.Type,
+				Description:       s.Description,
+				RequiredForImport: s.Required,
+				OptionalForImport: !s.Required,
+			}
+
+			switch elem := s.Elem.(type) {
+			case *Schema:
+				identitySchema.Elem = &Schema{Type: elem.Type}
+			case nil:
+			default:
+				identitySchema.Elem = elem
+			}
+
+			result[attr] = identitySchema
+		}
+
+		return result
+	}
+}
@@ -3,7 +3,11 @@
 
 package schema
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
 
 func TestResourceIdentity_SchemaMap_handles_nil_identity(t *testing.T) {
 	var ri *ResourceIdentity
@@ -11,3 +15,138 @@ func TestResourceIdentity_SchemaMap_handles_nil_identity(t *testing.T) {
 		t.Fatal("expected nil schema map")
 	}
 }
+
+func TestIdentityFromAttributes(t *testing.T) {
+	r := &Resource{
+		SchemaFunc: func() map[string]*Schema {
+			return map[string]*Schema{
+				"name": {
+					Type:        TypeString,
+					Required:    true,
+					Description: "The name.",
+				},
+				"region": {
+					Type:     TypeString,
+					Optional: true,
+					Computed: true,
+				},
+				"zones": {
+					Type:     TypeList,
+					Optional: true,
+					Elem: &Schema{
+						Type:         TypeString,
+						ValidateFunc: func(interface{}, string) ([]string, []error) { return nil, nil },
+					},
+				},
+				"tags": {
+					Type:     TypeMap,
+					Optional: true,
+					Elem:     &Schema{Type: TypeString},
+				},
+				"block": {
+					Type:     TypeList,
+					Optional: true,
+					Elem: &Resource{
+						Schema: map[string]*Schema{
+							"foo": {
+								Type:     TypeString,
+								Optional: true,
+							},
+						},
+					},
+				},
+			}
+		},
+	}
+
+	cases := map[string]struct {
+		Attrs    []string
+		Expected map[string]*Schema
+		Err      bool
+	}{
+		"required and optional": {
+			Attrs: []string{"name", "region"},
+			Expected: map[string]*Schema{
+				"name": {
+					Type:              TypeString,
+					Description:       "The name.",
+					RequiredForImport: true,
+				},
+				"region": {
+					Type:              TypeString,
+					OptionalForImport: true,
+				},
+			},
+		},
+		"list of primitives": {
+			Attrs: []string{"zones"},
+			Expected: map[string]*Schema{
+				"zones": {
+					Type:              TypeList,
+					Elem:              &Schema{Type: TypeString},
+					OptionalForImport: true,
+				},
+			},
+		},
+		"map attribute": {
+			Attrs: []string{"tags"},
+			Err:   true,
+		},
+		"nested block": {
+			Attrs: []string{"block"},
+			Err:   true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			identity := &ResourceIdentity{
+				Version:    1,
+				SchemaFunc: IdentityFromAttributes(r, tc.Attrs...),
+			}
+
+			err := identity.InternalIdentityValidate()
+			if err != nil && !tc.Err {
+				t.Fatalf("expected validation to pass: %s", err)
+			}
+			if err == nil && tc.Err {
+				t.Fatal("expected validation to fail")
+			}
+
+			if tc.Expected == nil {
+				return
+			}
+
+			if diff := cmp.Diff(tc.Expected, identity.SchemaMap()); diff != "" {
+				t.Errorf("unexpected difference: %s", diff)
+			}
+		})
+	}
+}
+
+func TestIdentityFromAttributes_missingAttribute(t *testing.T) {
+	r := &Resource{
+		Schema: map[string]*Schema{
+			"name": {
+				Type:     TypeString,
+				Required: true,
+			},
+		},
+	}
+
+	identity := &ResourceIdentity{
+		Version:    1,
+		SchemaFunc: IdentityFromAttributes(r, "name", "missing"),
+	}
+
+	err := identity.InternalIdentityValidate()
+
+	expected := `identity attribute "missing" does not exist in the resource schema`
+	if err == nil || err.Error() != expected {
+		t.Fatalf("expected error %q, got: %v", expected, err)
+	}
+
+	if _, ok := identity.SchemaMap()["missing"]; ok {
+		t.Fatal("expected missing attribute to be omitted from the identity schema")
+	}
+}
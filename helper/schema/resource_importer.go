@@ -30,6 +30,14 @@ type ResourceImporter struct {
 	// the ID is passed straight through. This function receives a context
 	// that will cancel if Terraform sends a cancellation signal.
 	StateContext StateContextFunc
+
+	// StreamStateContext is an alternative to StateContext for imports which
+	// fan out to many resources, such as thousands of rules from one ID. It
+	// emits each imported ResourceData as it is produced, instead of
+	// collecting them all in a slice, so each is converted and released
+	// before the next one is built. Only one of State, StateContext and
+	// StreamStateContext can be set.
+	StreamStateContext StreamStateContextFunc
 }
 
 // StateFunc is the function called to import a resource into the Terraform state.
@@ -53,6 +61,17 @@ type StateFunc func(*ResourceData, interface{}) ([]*ResourceData, error)
 // you have to), instantiate your resource and call the Data function.
 type StateContextFunc func(context.Context, *ResourceData, interface{}) ([]*ResourceData, error)
 
+// StreamStateContextFunc is the function called to import a resource into
+// the Terraform state, emitting each resulting ResourceData through the emit
+// callback instead of returning a slice. It is given a ResourceData with only
+// ID set, like StateContextFunc.
+//
+// The emit callback returns an error when the import cannot continue, such as
+// when the context is cancelled or the emitted ResourceData is invalid. The
+// function should then stop producing results and return that error. The
+// emit callback must not be called after the function returns.
+type StreamStateContextFunc func(ctx context.Context, d *ResourceData, meta interface{}, emit func(*ResourceData) error) error
+
 // InternalValidate should be called to validate the structure of this
 // importer. This should be called in a unit test.
 //
@@ -64,6 +83,9 @@ func (r *ResourceImporter) InternalValidate() error {
 	if r.State != nil && r.StateContext != nil {
 		return errors.New("Both State and StateContext cannot be set.")
 	}
+	if r.StreamStateContext != nil && (r.State != nil || r.StateContext != nil) {
+		return errors.New("StreamStateContext cannot be set with State or StateContext.")
+	}
 	return nil
 }
 
@@ -4,6 +4,7 @@
 package schema
 
 import (
+	"context"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
@@ -17,6 +18,16 @@ func TestInternalValidate(t *testing.T) {
 	if err := r.InternalValidate(); err == nil {
 		t.Fatal("ResourceImporter should not allow State and StateContext to be set")
 	}
+
+	r = &ResourceImporter{
+		StateContext: ImportStatePassthroughContext,
+		StreamStateContext: func(_ context.Context, d *ResourceData, _ interface{}, emit func(*ResourceData) error) error {
+			return emit(d)
+		},
+	}
+	if err := r.InternalValidate(); err == nil {
+		t.Fatal("ResourceImporter should not allow StateContext and StreamStateContext to be set")
+	}
 }
 
 func TestImportStatePassthroughWithIdentity(t *testing.T) {
@@ -6,11 +6,13 @@ package schema
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"reflect"
 	"testing"
 	"time"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/hashicorp/go-cty/cty"
 	ctyjson "github.com/hashicorp/go-cty/cty/json"
 
@@ -142,6 +144,105 @@ func TestResourceApply_Timeout_state(t *testing.T) {
 	}
 }
 
+// Operations without timeouts encoded in the diff or state, such as the
+// refresh of an imported resource, use the resource Default timeout instead of
+// the system default.
+func TestResource_Timeout_defaultWithoutMeta(t *testing.T) {
+	var got time.Duration
+	record := func(key string) func(*ResourceData, interface{}) error {
+		return func(d *ResourceData, _ interface{}) error {
+			got = d.Timeout(key)
+			if d.Id() == "" {
+				d.SetId("foo")
+			}
+			return nil
+		}
+	}
+
+	r := &Resource{
+		Schema: map[string]*Schema{
+			"foo": {
+				Type:     TypeInt,
+				Optional: true,
+			},
+		},
+		Create: record(TimeoutCreate),
+		Read:   record(TimeoutRead),
+		Update: record(TimeoutUpdate),
+		Delete: record(TimeoutDelete),
+		Timeouts: &ResourceTimeout{
+			Create:  DefaultTimeout(40 * time.Minute),
+			Default: DefaultTimeout(7 * time.Minute),
+		},
+	}
+
+	state := &terraform.InstanceState{
+		ID: "foo",
+		Attributes: map[string]string{
+			"id":  "foo",
+			"foo": "1",
+		},
+	}
+
+	cases := map[string]struct {
+		Run      func() diag.Diagnostics
+		Expected time.Duration
+	}{
+		"create": {
+			Run: func() diag.Diagnostics {
+				_, diags := r.Apply(context.Background(), nil, &terraform.InstanceDiff{
+					Attributes: map[string]*terraform.ResourceAttrDiff{
+						"foo": {New: "1"},
+					},
+				}, nil)
+				return diags
+			},
+			Expected: 40 * time.Minute,
+		},
+		"read": {
+			Run: func() diag.Diagnostics {
+				_, diags := r.RefreshWithoutUpgrade(context.Background(), state, nil)
+				return diags
+			},
+			Expected: 7 * time.Minute,
+		},
+		"update": {
+			Run: func() diag.Diagnostics {
+				_, diags := r.Apply(context.Background(), state, &terraform.InstanceDiff{
+					Attributes: map[string]*terraform.ResourceAttrDiff{
+						"foo": {Old: "1", New: "2"},
+					},
+				}, nil)
+				return diags
+			},
+			Expected: 7 * time.Minute,
+		},
+		"delete": {
+			Run: func() diag.Diagnostics {
+				_, diags := r.Apply(context.Background(), state, &terraform.InstanceDiff{
+					Destroy: true,
+				}, nil)
+				return diags
+			},
+			Expected: 7 * time.Minute,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got = 0
+
+			if diags := tc.Run(); diags.HasError() {
+				t.Fatalf("err: %s", diagutils.ErrorDiags(diags))
+			}
+
+			if got != tc.Expected {
+				t.Fatalf("expected timeout %s, got %s", tc.Expected, got)
+			}
+		})
+	}
+}
+
 // Regression test to ensure that the meta data is read from state, if a
 // resource is destroyed and the timeout meta is no longer available from the
 // config
@@ -293,6 +394,116 @@ func TestResourceDiff_CustomizeFunc(t *testing.T) {
 	}
 }
 
+func TestResourceDiff_ValidateChangeFunc(t *testing.T) {
+	r := &Resource{
+		Schema: map[string]*Schema{
+			"size": {
+				Type:     TypeInt,
+				Optional: true,
+				ValidateChangeFunc: func(o, n interface{}) error {
+					if n.(int) < o.(int) {
+						return fmt.Errorf("size cannot be decreased from %d to %d", o, n)
+					}
+					return nil
+				},
+			},
+			"name": {
+				Type:     TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+		},
+	}
+
+	cases := map[string]struct {
+		State       *terraform.InstanceState
+		Config      map[string]interface{}
+		ExpectedErr string
+	}{
+		"create": {
+			State: nil,
+			Config: map[string]interface{}{
+				"size": 10,
+			},
+		},
+		"growth": {
+			State: &terraform.InstanceState{
+				ID: "foo",
+				Attributes: map[string]string{
+					"size": "10",
+				},
+			},
+			Config: map[string]interface{}{
+				"size": 20,
+			},
+		},
+		"shrink": {
+			State: &terraform.InstanceState{
+				ID: "foo",
+				Attributes: map[string]string{
+					"size": "20",
+				},
+			},
+			Config: map[string]interface{}{
+				"size": 10,
+			},
+			ExpectedErr: "size cannot be decreased from 20 to 10",
+		},
+		"shrink with replacement": {
+			State: &terraform.InstanceState{
+				ID: "foo",
+				Attributes: map[string]string{
+					"name": "old",
+					"size": "20",
+				},
+			},
+			Config: map[string]interface{}{
+				"name": "new",
+				"size": 10,
+			},
+		},
+		"unknown": {
+			State: &terraform.InstanceState{
+				ID: "foo",
+				Attributes: map[string]string{
+					"size": "20",
+				},
+			},
+			Config: map[string]interface{}{
+				"size": hcl2shim.UnknownVariableValue,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			conf := terraform.NewResourceConfigRaw(tc.Config)
+
+			_, err := r.SimpleDiff(context.Background(), tc.State, conf, nil)
+
+			if tc.ExpectedErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				return
+			}
+
+			var pathErr cty.PathError
+			if !errors.As(err, &pathErr) {
+				t.Fatalf("expected cty.PathError, got %T: %v", err, err)
+			}
+
+			if pathErr.Error() != tc.ExpectedErr {
+				t.Fatalf("expected error %q, got %q", tc.ExpectedErr, pathErr.Error())
+			}
+
+			if !pathErr.Path.Equals(cty.GetAttrPath("size")) {
+				t.Fatalf("unexpected error path: %#v", pathErr.Path)
+			}
+		})
+	}
+}
+
 func TestResourceApply_destroy(t *testing.T) {
 	r := &Resource{
 		Schema: map[string]*Schema{
@@ -503,6 +714,73 @@ func TestResourceApply_update(t *testing.T) {
 	}
 }
 
+func TestResourceApply_updateRestore(t *testing.T) {
+	r := &Resource{
+		Schema: map[string]*Schema{
+			"foo": {
+				Type:     TypeInt,
+				Optional: true,
+			},
+			"bar": {
+				Type:     TypeString,
+				Computed: true,
+			},
+		},
+	}
+
+	r.UpdateContext = func(_ context.Context, d *ResourceData, m interface{}) diag.Diagnostics {
+		if err := d.Set("foo", 42); err != nil {
+			return diag.Errorf("unexpected Set error: %s", err)
+		}
+
+		snapshot := d.Snapshot()
+
+		if err := d.Set("bar", "changed"); err != nil {
+			return diag.Errorf("unexpected Set error: %s", err)
+		}
+
+		if err := d.Restore(snapshot); err != nil {
+			return diag.Errorf("unexpected Restore error: %s", err)
+		}
+
+		return diag.Errorf("failed to update bar")
+	}
+
+	s := &terraform.InstanceState{
+		ID: "foo",
+		Attributes: map[string]string{
+			"foo": "12",
+			"bar": "original",
+		},
+	}
+
+	d := &terraform.InstanceDiff{
+		Attributes: map[string]*terraform.ResourceAttrDiff{
+			"foo": {
+				New: "13",
+			},
+		},
+	}
+
+	actual, diags := r.Apply(context.Background(), s, d, nil)
+	if !diags.HasError() {
+		t.Fatal("expected error")
+	}
+
+	expected := &terraform.InstanceState{
+		ID: "foo",
+		Attributes: map[string]string{
+			"id":  "foo",
+			"foo": "42",
+			"bar": "original",
+		},
+	}
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("bad: %#v", actual)
+	}
+}
+
 func TestResourceApply_updateNoCallback(t *testing.T) {
 	r := &Resource{
 		Schema: map[string]*Schema{
@@ -733,6 +1011,105 @@ func TestResourceInternalValidate(t *testing.T) {
 			true,
 		},
 
+		"writable without Read and SkipRefresh": {
+			&Resource{
+				Create:      Noop,
+				Update:      Noop,
+				Delete:      Noop,
+				SkipRefresh: true,
+				Schema: map[string]*Schema{
+					"goo": {
+						Type:     TypeInt,
+						Optional: true,
+					},
+				},
+			},
+			true,
+			false,
+		},
+
+		"top-level Timeouts": {
+			&Resource{
+				Create: Noop,
+				Read:   Noop,
+				Update: Noop,
+				Delete: Noop,
+				Timeouts: &ResourceTimeout{
+					Create: DefaultTimeout(10 * time.Minute),
+				},
+				Schema: map[string]*Schema{
+					"goo": {
+						Type:     TypeInt,
+						Optional: true,
+					},
+				},
+			},
+			true,
+			false,
+		},
+
+		"nested block Timeouts": {
+			&Resource{
+				Create: Noop,
+				Read:   Noop,
+				Update: Noop,
+				Delete: Noop,
+				Schema: map[string]*Schema{
+					"block": {
+						Type:     TypeList,
+						Optional: true,
+						Elem: &Resource{
+							Timeouts: &ResourceTimeout{
+								Create: DefaultTimeout(10 * time.Minute),
+							},
+							Schema: map[string]*Schema{
+								"goo": {
+									Type:     TypeInt,
+									Optional: true,
+								},
+							},
+						},
+					},
+				},
+			},
+			true,
+			true,
+		},
+
+		"deeply nested block Timeouts": {
+			&Resource{
+				Create: Noop,
+				Read:   Noop,
+				Update: Noop,
+				Delete: Noop,
+				Schema: map[string]*Schema{
+					"block": {
+						Type:     TypeSet,
+						Optional: true,
+						Elem: &Resource{
+							Schema: map[string]*Schema{
+								"nested": {
+									Type:     TypeList,
+									Optional: true,
+									Elem: &Resource{
+										Timeouts: &ResourceTimeout{},
+										Schema: map[string]*Schema{
+											"goo": {
+												Type:     TypeInt,
+												Optional: true,
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			true,
+			true,
+		},
+
 		"writable must have Delete": {
 			&Resource{
 				Create: Noop,
@@ -822,46 +1199,269 @@ func TestResourceInternalValidate(t *testing.T) {
 			false,
 		},
 
-		"Deprecated ID should be allowed in resource": {
+		"DisableImplicitID requires an explicit ID": {
 			&Resource{
 				Create: Noop,
 				Read:   Noop,
 				Update: Noop,
 				Delete: Noop,
 				Schema: map[string]*Schema{
-					"id": {
-						Type:       TypeString,
-						Computed:   true,
-						Optional:   true,
-						Deprecated: "Use x_id instead",
+					"foo": {
+						Type:     TypeString,
+						Optional: true,
 					},
 				},
+				DisableImplicitID: true,
 			},
 			true,
-			false,
+			true,
 		},
 
-		"non-writable must not define CustomizeDiff": {
+		"DisableImplicitID allows a Required ID": {
 			&Resource{
-				Read: Noop,
+				Create: Noop,
+				Read:   Noop,
+				Delete: Noop,
 				Schema: map[string]*Schema{
-					"goo": {
-						Type:     TypeInt,
-						Optional: true,
+					"id": {
+						Type:         TypeString,
+						Required:     true,
+						ForceNew:     true,
+						ValidateFunc: func(interface{}, string) ([]string, []error) { return nil, nil },
 					},
 				},
-				CustomizeDiff: func(context.Context, *ResourceDiff, interface{}) error { return nil },
+				DisableImplicitID: true,
 			},
-			false,
 			true,
+			false,
 		},
-		"Deprecated resource": {
+
+		"DisableImplicitID ID must be Required or Computed": {
 			&Resource{
-				Read: Noop,
+				Create: Noop,
+				Read:   Noop,
+				Delete: Noop,
 				Schema: map[string]*Schema{
-					"goo": {
-						Type:     TypeInt,
-						Optional: true,
+					"id": {
+						Type:     TypeString,
+						Optional: true,
+						ForceNew: true,
+					},
+				},
+				DisableImplicitID: true,
+			},
+			true,
+			true,
+		},
+
+		"DisableImplicitID ID must be TypeString": {
+			&Resource{
+				Create: Noop,
+				Read:   Noop,
+				Delete: Noop,
+				Schema: map[string]*Schema{
+					"id": {
+						Type:     TypeInt,
+						Required: true,
+						ForceNew: true,
+					},
+				},
+				DisableImplicitID: true,
+			},
+			true,
+			true,
+		},
+
+		"DisableImplicitID is not allowed in data source": {
+			&Resource{
+				Read: Noop,
+				Schema: map[string]*Schema{
+					"id": {
+						Type:     TypeString,
+						Required: true,
+					},
+				},
+				DisableImplicitID: true,
+			},
+			false,
+			true,
+		},
+
+		"IDDescription for the implicit ID": {
+			&Resource{
+				Create: Noop,
+				Read:   Noop,
+				Delete: Noop,
+				Schema: map[string]*Schema{
+					"foo": {
+						Type:     TypeString,
+						Required: true,
+						ForceNew: true,
+					},
+				},
+				IDDescription: "The name of the bucket.",
+			},
+			true,
+			false,
+		},
+
+		"IDDescription is not allowed with an explicit ID": {
+			&Resource{
+				Create: Noop,
+				Read:   Noop,
+				Delete: Noop,
+				Schema: map[string]*Schema{
+					"id": {
+						Type:     TypeString,
+						Required: true,
+						ForceNew: true,
+					},
+				},
+				DisableImplicitID: true,
+				IDDescription:     "The name of the bucket.",
+			},
+			true,
+			true,
+		},
+
+		"CustomizeDiffContext and CustomizeDiff": {
+			&Resource{
+				Create: Noop,
+				Read:   Noop,
+				Delete: Noop,
+				Schema: map[string]*Schema{
+					"foo": {
+						Type:     TypeString,
+						Required: true,
+						ForceNew: true,
+					},
+				},
+				CustomizeDiff: func(context.Context, *ResourceDiff, interface{}) error {
+					return nil
+				},
+				CustomizeDiffContext: func(context.Context, *ResourceDiff, interface{}) diag.Diagnostics {
+					return nil
+				},
+			},
+			true,
+			true,
+		},
+
+		"CustomizeDiffContext on a data source": {
+			&Resource{
+				Read: Noop,
+				Schema: map[string]*Schema{
+					"foo": {
+						Type:     TypeString,
+						Computed: true,
+					},
+				},
+				CustomizeDiffContext: func(context.Context, *ResourceDiff, interface{}) diag.Diagnostics {
+					return nil
+				},
+			},
+			false,
+			true,
+		},
+
+		"Timeouts for implemented operations": {
+			&Resource{
+				Create: Noop,
+				Read:   Noop,
+				Update: Noop,
+				Delete: Noop,
+				Schema: map[string]*Schema{
+					"foo": {
+						Type:     TypeString,
+						Optional: true,
+					},
+				},
+				Timeouts: &ResourceTimeout{
+					Create: DefaultTimeout(10 * time.Minute),
+					Update: DefaultTimeout(10 * time.Minute),
+					Delete: DefaultTimeout(10 * time.Minute),
+				},
+			},
+			true,
+			false,
+		},
+
+		"Update timeout without Update": {
+			&Resource{
+				Create: Noop,
+				Read:   Noop,
+				Delete: Noop,
+				Schema: map[string]*Schema{
+					"foo": {
+						Type:     TypeString,
+						Optional: true,
+						ForceNew: true,
+					},
+				},
+				Timeouts: &ResourceTimeout{
+					Create: DefaultTimeout(10 * time.Minute),
+					Update: DefaultTimeout(10 * time.Minute),
+				},
+			},
+			true,
+			true,
+		},
+
+		"ValidateIDFunc is not allowed in data source": {
+			&Resource{
+				Read: Noop,
+				Schema: map[string]*Schema{
+					"foo": {
+						Type:     TypeString,
+						Optional: true,
+					},
+				},
+				ValidateIDFunc: func(string) error { return nil },
+			},
+			false,
+			true,
+		},
+
+		"Deprecated ID should be allowed in resource": {
+			&Resource{
+				Create: Noop,
+				Read:   Noop,
+				Update: Noop,
+				Delete: Noop,
+				Schema: map[string]*Schema{
+					"id": {
+						Type:       TypeString,
+						Computed:   true,
+						Optional:   true,
+						Deprecated: "Use x_id instead",
+					},
+				},
+			},
+			true,
+			false,
+		},
+
+		"non-writable must not define CustomizeDiff": {
+			&Resource{
+				Read: Noop,
+				Schema: map[string]*Schema{
+					"goo": {
+						Type:     TypeInt,
+						Optional: true,
+					},
+				},
+				CustomizeDiff: func(context.Context, *ResourceDiff, interface{}) error { return nil },
+			},
+			false,
+			true,
+		},
+		"Deprecated resource": {
+			&Resource{
+				Read: Noop,
+				Schema: map[string]*Schema{
+					"goo": {
+						Type:     TypeInt,
+						Optional: true,
 					},
 				},
 				DeprecationMessage: "This resource has been deprecated.",
@@ -1137,59 +1737,223 @@ func TestResourceInternalValidate(t *testing.T) {
 	}
 }
 
-func TestResourceRefresh(t *testing.T) {
-	r := &Resource{
-		SchemaVersion: 2,
-		Schema: map[string]*Schema{
-			"foo": {
-				Type:     TypeInt,
-				Optional: true,
-			},
+func TestResourceInternalValidate_IdentitySource(t *testing.T) {
+	identity := &ResourceIdentity{
+		Version: 1,
+		SchemaFunc: func() map[string]*Schema {
+			return map[string]*Schema{
+				"name": {
+					Type:              TypeString,
+					RequiredForImport: true,
+				},
+			}
 		},
 	}
 
-	r.Read = func(d *ResourceData, m interface{}) error {
-		if m != 42 {
-			return fmt.Errorf("meta not passed")
-		}
-
-		return d.Set("foo", d.Get("foo").(int)+1)
-	}
-
-	s := &terraform.InstanceState{
-		ID: "bar",
-		Attributes: map[string]string{
-			"foo": "12",
+	cases := map[string]struct {
+		Schema   *Schema
+		Identity *ResourceIdentity
+		Err      string
+	}{
+		"valid": {
+			Schema: &Schema{
+				Type:           TypeString,
+				Computed:       true,
+				IdentitySource: "name",
+			},
+			Identity: identity,
 		},
-	}
-
-	expected := &terraform.InstanceState{
-		ID: "bar",
-		Attributes: map[string]string{
-			"id":  "bar",
-			"foo": "13",
+		"not computed": {
+			Schema: &Schema{
+				Type:           TypeString,
+				Optional:       true,
+				ForceNew:       true,
+				IdentitySource: "name",
+			},
+			Identity: identity,
+			Err:      "source: IdentitySource is only supported on Computed attributes",
 		},
-		Meta: map[string]interface{}{
-			"schema_version": "2",
+		"no identity": {
+			Schema: &Schema{
+				Type:           TypeString,
+				Computed:       true,
+				IdentitySource: "name",
+			},
+			Err: "source: IdentitySource requires the resource to have an Identity",
+		},
+		"unknown identity attribute": {
+			Schema: &Schema{
+				Type:           TypeString,
+				Computed:       true,
+				IdentitySource: "missing",
+			},
+			Identity: identity,
+			Err:      `source: IdentitySource references unknown identity attribute "missing"`,
+		},
+		"type mismatch": {
+			Schema: &Schema{
+				Type:           TypeInt,
+				Computed:       true,
+				IdentitySource: "name",
+			},
+			Identity: identity,
+			Err:      `source: IdentitySource identity attribute "name" is TypeString, must be TypeInt`,
 		},
 	}
 
-	actual, diags := r.RefreshWithoutUpgrade(context.Background(), s, 42)
-	if diags.HasError() {
-		t.Fatalf("err: %s", diagutils.ErrorDiags(diags))
-	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			r := &Resource{
+				Create:   Noop,
+				Read:     Noop,
+				Delete:   Noop,
+				Identity: tc.Identity,
+				Schema: map[string]*Schema{
+					"name": {
+						Type:     TypeString,
+						Required: true,
+						ForceNew: true,
+					},
+					"source": tc.Schema,
+				},
+			}
 
-	if !reflect.DeepEqual(actual, expected) {
-		t.Fatalf("bad: %#v", actual)
+			err := r.InternalValidate(nil, true)
+
+			if tc.Err == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				return
+			}
+
+			if err == nil || err.Error() != tc.Err {
+				t.Fatalf("expected error %q, got: %v", tc.Err, err)
+			}
+		})
 	}
 }
 
-func TestResourceRefresh_DiffSuppressOnRefresh(t *testing.T) {
+func TestResourceInternalValidate_nestedTimeoutsPath(t *testing.T) {
 	r := &Resource{
-		SchemaVersion: 2,
+		Read: Noop,
 		Schema: map[string]*Schema{
-			"foo": {
-				Type:     TypeString,
+			"block": {
+				Type:     TypeList,
+				Optional: true,
+				Elem: &Resource{
+					Schema: map[string]*Schema{
+						"nested": {
+							Type:     TypeList,
+							Optional: true,
+							Elem: &Resource{
+								Timeouts: &ResourceTimeout{},
+								Schema: map[string]*Schema{
+									"goo": {
+										Type:     TypeInt,
+										Optional: true,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	err := r.InternalValidate(nil, false)
+	if err == nil {
+		t.Fatal("expected validation to fail")
+	}
+
+	expected := "Timeouts is only supported on top-level resources, remove it from nested block elements: block.nested"
+	if err.Error() != expected {
+		t.Fatalf("expected error %q, got %q", expected, err)
+	}
+}
+
+func TestResourceInternalValidate_timeoutWithoutOperation(t *testing.T) {
+	r := &Resource{
+		Create: Noop,
+		Read:   Noop,
+		Delete: Noop,
+		Schema: map[string]*Schema{
+			"foo": {
+				Type:     TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+		},
+		Timeouts: &ResourceTimeout{
+			Update: DefaultTimeout(10 * time.Minute),
+		},
+	}
+
+	err := r.InternalValidate(nil, true)
+	if err == nil {
+		t.Fatal("expected validation to fail")
+	}
+
+	expected := "Update timeout is set but the Update operation is not implemented"
+	if err.Error() != expected {
+		t.Fatalf("expected error %q, got %q", expected, err)
+	}
+}
+
+func TestResourceRefresh(t *testing.T) {
+	r := &Resource{
+		SchemaVersion: 2,
+		Schema: map[string]*Schema{
+			"foo": {
+				Type:     TypeInt,
+				Optional: true,
+			},
+		},
+	}
+
+	r.Read = func(d *ResourceData, m interface{}) error {
+		if m != 42 {
+			return fmt.Errorf("meta not passed")
+		}
+
+		return d.Set("foo", d.Get("foo").(int)+1)
+	}
+
+	s := &terraform.InstanceState{
+		ID: "bar",
+		Attributes: map[string]string{
+			"foo": "12",
+		},
+	}
+
+	expected := &terraform.InstanceState{
+		ID: "bar",
+		Attributes: map[string]string{
+			"id":  "bar",
+			"foo": "13",
+		},
+		Meta: map[string]interface{}{
+			"schema_version": "2",
+		},
+	}
+
+	actual, diags := r.RefreshWithoutUpgrade(context.Background(), s, 42)
+	if diags.HasError() {
+		t.Fatalf("err: %s", diagutils.ErrorDiags(diags))
+	}
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("bad: %#v", actual)
+	}
+}
+
+func TestResourceRefresh_DiffSuppressOnRefresh(t *testing.T) {
+	r := &Resource{
+		SchemaVersion: 2,
+		Schema: map[string]*Schema{
+			"foo": {
+				Type:     TypeString,
 				Optional: true,
 				DiffSuppressFunc: func(key, oldV, newV string, d *ResourceData) bool {
 					return true
@@ -1231,6 +1995,137 @@ func TestResourceRefresh_DiffSuppressOnRefresh(t *testing.T) {
 	}
 }
 
+func TestResourceRefresh_IdentitySource(t *testing.T) {
+	r := &Resource{
+		Schema: map[string]*Schema{
+			"region": {
+				Type:           TypeString,
+				Computed:       true,
+				IdentitySource: "region",
+			},
+			"name": {
+				Type:           TypeString,
+				Computed:       true,
+				IdentitySource: "name",
+			},
+		},
+		Identity: &ResourceIdentity{
+			Version: 1,
+			SchemaFunc: func() map[string]*Schema {
+				return map[string]*Schema{
+					"region": {
+						Type:              TypeString,
+						OptionalForImport: true,
+					},
+					"name": {
+						Type:              TypeString,
+						RequiredForImport: true,
+					},
+				}
+			},
+		},
+	}
+
+	r.Read = func(d *ResourceData, m interface{}) error {
+		// The remote system only returns the name.
+		return d.Set("name", "remote-name")
+	}
+
+	s := &terraform.InstanceState{
+		ID: "bar",
+		Attributes: map[string]string{
+			"id": "bar",
+		},
+		Identity: map[string]string{
+			"region": "eu-west-1",
+			"name":   "identity-name",
+		},
+	}
+
+	actual, diags := r.RefreshWithoutUpgrade(context.Background(), s, nil)
+	if diags.HasError() {
+		t.Fatalf("err: %s", diagutils.ErrorDiags(diags))
+	}
+
+	expected := map[string]string{
+		"id":     "bar",
+		"name":   "remote-name",
+		"region": "eu-west-1",
+	}
+
+	if diff := cmp.Diff(expected, actual.Attributes); diff != "" {
+		t.Fatalf("unexpected attributes difference: %s", diff)
+	}
+}
+
+func TestResourceRefresh_IdentitySourceZeroValue(t *testing.T) {
+	r := &Resource{
+		Schema: map[string]*Schema{
+			"port": {
+				Type:           TypeInt,
+				Computed:       true,
+				IdentitySource: "port",
+			},
+			"enabled": {
+				Type:           TypeBool,
+				Computed:       true,
+				IdentitySource: "enabled",
+			},
+		},
+		Identity: &ResourceIdentity{
+			Version: 1,
+			SchemaFunc: func() map[string]*Schema {
+				return map[string]*Schema{
+					"port": {
+						Type:              TypeInt,
+						RequiredForImport: true,
+					},
+					"enabled": {
+						Type:              TypeBool,
+						OptionalForImport: true,
+					},
+				}
+			},
+		},
+	}
+
+	r.Read = func(d *ResourceData, m interface{}) error {
+		// The remote system returns zero values, which must not be
+		// replaced with the identity values.
+		if err := d.Set("port", 0); err != nil {
+			return err
+		}
+
+		return d.Set("enabled", false)
+	}
+
+	s := &terraform.InstanceState{
+		ID: "bar",
+		Attributes: map[string]string{
+			"id": "bar",
+		},
+		Identity: map[string]string{
+			"port":    "8080",
+			"enabled": "true",
+		},
+	}
+
+	actual, diags := r.RefreshWithoutUpgrade(context.Background(), s, nil)
+	if diags.HasError() {
+		t.Fatalf("err: %s", diagutils.ErrorDiags(diags))
+	}
+
+	expected := map[string]string{
+		"id":      "bar",
+		"port":    "0",
+		"enabled": "false",
+	}
+
+	if diff := cmp.Diff(expected, actual.Attributes); diff != "" {
+		t.Fatalf("unexpected attributes difference: %s", diff)
+	}
+}
+
 func TestResourceRefresh_blankId(t *testing.T) {
 	r := &Resource{
 		Schema: map[string]*Schema{
@@ -1360,6 +2255,38 @@ func TestResourceRefresh_noExists(t *testing.T) {
 	}
 }
 
+func TestResourceRefresh_skipRefresh(t *testing.T) {
+	r := &Resource{
+		SkipRefresh: true,
+		Schema: map[string]*Schema{
+			"foo": {
+				Type:     TypeInt,
+				Optional: true,
+			},
+		},
+	}
+
+	r.Exists = func(*ResourceData, interface{}) (bool, error) {
+		panic("shouldn't be called")
+	}
+
+	s := &terraform.InstanceState{
+		ID: "bar",
+		Attributes: map[string]string{
+			"foo": "12",
+		},
+	}
+
+	actual, diags := r.RefreshWithoutUpgrade(context.Background(), s, 42)
+	if diags.HasError() {
+		t.Fatalf("err: %s", diagutils.ErrorDiags(diags))
+	}
+
+	if !reflect.DeepEqual(actual, s) {
+		t.Fatalf("bad: %#v", actual)
+	}
+}
+
 func TestResourceData(t *testing.T) {
 	r := &Resource{
 		SchemaVersion: 2,
@@ -1550,6 +2477,14 @@ func TestResource_ValidateUpgradeState(t *testing.T) {
 			return m, nil
 		},
 	})
+	if err := r.InternalValidate(nil, true); err == nil {
+		t.Fatal("StateUpgraders must start at version 0 without MigrateState")
+	}
+
+	// legacy versions below the first StateUpgrader are handled by MigrateState
+	r.MigrateState = func(v int, is *terraform.InstanceState, _ interface{}) (*terraform.InstanceState, error) {
+		return is, nil
+	}
 	if err := r.InternalValidate(nil, true); err != nil {
 		t.Fatal(err)
 	}
@@ -1568,9 +2503,23 @@ func TestResource_ValidateUpgradeState(t *testing.T) {
 	if err := r.InternalValidate(nil, true); err == nil {
 		t.Fatal("StateUpgrader must have an Upgrade func")
 	}
+
+	// UpgradeWithDiags may replace Upgrade
+	r.StateUpgraders[0].UpgradeWithDiags = func(ctx context.Context, m map[string]interface{}, _ interface{}) (map[string]interface{}, diag.Diagnostics) {
+		return m, nil
+	}
+	if err := r.InternalValidate(nil, true); err != nil {
+		t.Fatal(err)
+	}
+
+	// check for both Upgrade and UpgradeWithDiags
 	r.StateUpgraders[0].Upgrade = func(ctx context.Context, m map[string]interface{}, _ interface{}) (map[string]interface{}, error) {
 		return m, nil
 	}
+	if err := r.InternalValidate(nil, true); err == nil {
+		t.Fatal("StateUpgrader cannot have both Upgrade and UpgradeWithDiags")
+	}
+	r.StateUpgraders[0].UpgradeWithDiags = nil
 
 	// check for skipped version
 	r.StateUpgraders[0].Version = 0
@@ -1621,6 +2570,100 @@ func TestResource_ValidateUpgradeState(t *testing.T) {
 	}
 }
 
+func TestResource_ValidateUpgradeStateChain(t *testing.T) {
+	upgrader := func(version int) StateUpgrader {
+		return StateUpgrader{
+			Version: version,
+			Type: cty.Object(map[string]cty.Type{
+				"id": cty.String,
+			}),
+			Upgrade: func(ctx context.Context, m map[string]interface{}, _ interface{}) (map[string]interface{}, error) {
+				return m, nil
+			},
+		}
+	}
+
+	cases := map[string]struct {
+		SchemaVersion  int
+		MigrateState   StateMigrateFunc
+		StateUpgraders []StateUpgrader
+		ExpectedErr    string
+	}{
+		"no upgraders": {
+			SchemaVersion: 3,
+		},
+		"contiguous chain": {
+			SchemaVersion:  3,
+			StateUpgraders: []StateUpgrader{upgrader(0), upgrader(1), upgrader(2)},
+		},
+		"gap in chain": {
+			SchemaVersion:  3,
+			StateUpgraders: []StateUpgrader{upgrader(0), upgrader(2)},
+			ExpectedErr:    "missing StateUpgrader for version 1, between 0 and 2",
+		},
+		"missing first version": {
+			SchemaVersion:  3,
+			StateUpgraders: []StateUpgrader{upgrader(1), upgrader(2)},
+			ExpectedErr:    "missing StateUpgrader for version 0, StateUpgraders must start at version 0 unless MigrateState is set",
+		},
+		"missing first version with MigrateState": {
+			SchemaVersion: 3,
+			MigrateState: func(v int, is *terraform.InstanceState, _ interface{}) (*terraform.InstanceState, error) {
+				return is, nil
+			},
+			StateUpgraders: []StateUpgrader{upgrader(1), upgrader(2)},
+		},
+		"missing last version": {
+			SchemaVersion:  3,
+			StateUpgraders: []StateUpgrader{upgrader(0), upgrader(1)},
+			ExpectedErr:    "missing StateUpgrader for version 2, between 1 and current version 3",
+		},
+		"duplicate version": {
+			SchemaVersion:  3,
+			StateUpgraders: []StateUpgrader{upgrader(0), upgrader(1), upgrader(1), upgrader(2)},
+			ExpectedErr:    "StateUpgrader version 1 is out of order, must be greater than 1",
+		},
+		"out of order": {
+			SchemaVersion:  3,
+			StateUpgraders: []StateUpgrader{upgrader(0), upgrader(2), upgrader(1)},
+			ExpectedErr:    "missing StateUpgrader for version 1, between 0 and 2",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			r := &Resource{
+				SchemaVersion:  tc.SchemaVersion,
+				MigrateState:   tc.MigrateState,
+				StateUpgraders: tc.StateUpgraders,
+				Schema: map[string]*Schema{
+					"foo": {
+						Type:     TypeInt,
+						Optional: true,
+					},
+				},
+			}
+
+			err := r.InternalValidate(nil, true)
+
+			if tc.ExpectedErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				return
+			}
+
+			if err == nil {
+				t.Fatalf("expected error %q, got none", tc.ExpectedErr)
+			}
+
+			if err.Error() != tc.ExpectedErr {
+				t.Fatalf("expected error %q, got %q", tc.ExpectedErr, err)
+			}
+		})
+	}
+}
+
 func TestResource_ContextTimeout(t *testing.T) {
 	r := &Resource{
 		Schema: map[string]*Schema{
@@ -1677,6 +2720,7 @@ func TestResourceInternalIdentityValidate(t *testing.T) {
 
 		"OptionalForImport and RequiredForImport both false": {
 			&ResourceIdentity{
+				Version: 1,
 				SchemaFunc: func() map[string]*Schema {
 					return map[string]*Schema{
 						"foo": {
@@ -1692,6 +2736,7 @@ func TestResourceInternalIdentityValidate(t *testing.T) {
 
 		"OptionalForImport and RequiredForImport both true": {
 			&ResourceIdentity{
+				Version: 1,
 				SchemaFunc: func() map[string]*Schema {
 					return map[string]*Schema{
 						"foo": {
@@ -1707,6 +2752,7 @@ func TestResourceInternalIdentityValidate(t *testing.T) {
 
 		"TypeMap is not valid": {
 			&ResourceIdentity{
+				Version: 1,
 				SchemaFunc: func() map[string]*Schema {
 					return map[string]*Schema{
 						"foo": {Type: TypeMap, OptionalForImport: true},
@@ -1718,6 +2764,7 @@ func TestResourceInternalIdentityValidate(t *testing.T) {
 
 		"TypeSet is not valid": {
 			&ResourceIdentity{
+				Version: 1,
 				SchemaFunc: func() map[string]*Schema {
 					return map[string]*Schema{
 						"foo": {Type: TypeSet, OptionalForImport: true},
@@ -1729,6 +2776,7 @@ func TestResourceInternalIdentityValidate(t *testing.T) {
 
 		"TypeObject is not valid": {
 			&ResourceIdentity{
+				Version: 1,
 				SchemaFunc: func() map[string]*Schema {
 					return map[string]*Schema{
 						"foo": {Type: typeObject, OptionalForImport: true},
@@ -1740,6 +2788,7 @@ func TestResourceInternalIdentityValidate(t *testing.T) {
 
 		"TypeInvalid is not valid": {
 			&ResourceIdentity{
+				Version: 1,
 				SchemaFunc: func() map[string]*Schema {
 					return map[string]*Schema{
 						"foo": {Type: TypeInvalid, OptionalForImport: true},
@@ -1751,6 +2800,7 @@ func TestResourceInternalIdentityValidate(t *testing.T) {
 
 		"TypeList contains TypeMap": {
 			&ResourceIdentity{
+				Version: 1,
 				SchemaFunc: func() map[string]*Schema {
 					return map[string]*Schema{
 						"foo": {
@@ -1764,6 +2814,7 @@ func TestResourceInternalIdentityValidate(t *testing.T) {
 
 		" TypeList contains TypeSet": {
 			&ResourceIdentity{
+				Version: 1,
 				SchemaFunc: func() map[string]*Schema {
 					return map[string]*Schema{
 						"foo": {
@@ -1785,6 +2836,7 @@ func TestResourceInternalIdentityValidate(t *testing.T) {
 
 		"TypeList contains TypeInvalid": {
 			&ResourceIdentity{
+				Version: 1,
 				SchemaFunc: func() map[string]*Schema {
 					return map[string]*Schema{
 						"foo": {
@@ -1798,6 +2850,7 @@ func TestResourceInternalIdentityValidate(t *testing.T) {
 
 		"ForceNew is set": {
 			&ResourceIdentity{
+				Version: 1,
 				SchemaFunc: func() map[string]*Schema {
 					return map[string]*Schema{
 						"foo": {
@@ -1813,6 +2866,7 @@ func TestResourceInternalIdentityValidate(t *testing.T) {
 
 		"Optional is set": {
 			&ResourceIdentity{
+				Version: 1,
 				SchemaFunc: func() map[string]*Schema {
 					return map[string]*Schema{
 						"foo": {
@@ -1828,6 +2882,7 @@ func TestResourceInternalIdentityValidate(t *testing.T) {
 
 		"Required is set": {
 			&ResourceIdentity{
+				Version: 1,
 				SchemaFunc: func() map[string]*Schema {
 					return map[string]*Schema{
 						"foo": {
@@ -1843,6 +2898,7 @@ func TestResourceInternalIdentityValidate(t *testing.T) {
 
 		"WriteOnly is set": {
 			&ResourceIdentity{
+				Version: 1,
 				SchemaFunc: func() map[string]*Schema {
 					return map[string]*Schema{
 						"foo": {
@@ -1858,6 +2914,7 @@ func TestResourceInternalIdentityValidate(t *testing.T) {
 
 		"Computed is set": {
 			&ResourceIdentity{
+				Version: 1,
 				SchemaFunc: func() map[string]*Schema {
 					return map[string]*Schema{
 						"foo": {
@@ -1873,6 +2930,7 @@ func TestResourceInternalIdentityValidate(t *testing.T) {
 
 		"Deprecated is set": {
 			&ResourceIdentity{
+				Version: 1,
 				SchemaFunc: func() map[string]*Schema {
 					return map[string]*Schema{
 						"foo": {
@@ -1888,6 +2946,7 @@ func TestResourceInternalIdentityValidate(t *testing.T) {
 
 		"Default is set": {
 			&ResourceIdentity{
+				Version: 1,
 				SchemaFunc: func() map[string]*Schema {
 					return map[string]*Schema{
 						"foo": {
@@ -1903,6 +2962,7 @@ func TestResourceInternalIdentityValidate(t *testing.T) {
 
 		"MaxItems is set": {
 			&ResourceIdentity{
+				Version: 1,
 				SchemaFunc: func() map[string]*Schema {
 					return map[string]*Schema{
 						"foo": {
@@ -1918,6 +2978,7 @@ func TestResourceInternalIdentityValidate(t *testing.T) {
 
 		"MinItems is set": {
 			&ResourceIdentity{
+				Version: 1,
 				SchemaFunc: func() map[string]*Schema {
 					return map[string]*Schema{
 						"foo": {
@@ -1933,6 +2994,7 @@ func TestResourceInternalIdentityValidate(t *testing.T) {
 
 		"DiffSuppressOnRefresh is set": {
 			&ResourceIdentity{
+				Version: 1,
 				SchemaFunc: func() map[string]*Schema {
 					return map[string]*Schema{
 						"foo": {
@@ -1948,6 +3010,7 @@ func TestResourceInternalIdentityValidate(t *testing.T) {
 
 		"RequiredWith is set": {
 			&ResourceIdentity{
+				Version: 1,
 				SchemaFunc: func() map[string]*Schema {
 					return map[string]*Schema{
 						"foo": {
@@ -1963,6 +3026,7 @@ func TestResourceInternalIdentityValidate(t *testing.T) {
 
 		"ComputedWhen is set": {
 			&ResourceIdentity{
+				Version: 1,
 				SchemaFunc: func() map[string]*Schema {
 					return map[string]*Schema{
 						"foo": {
@@ -1978,6 +3042,7 @@ func TestResourceInternalIdentityValidate(t *testing.T) {
 
 		"DefaultFunc is set": {
 			&ResourceIdentity{
+				Version: 1,
 				SchemaFunc: func() map[string]*Schema {
 					return map[string]*Schema{
 						"foo": {
@@ -1993,6 +3058,7 @@ func TestResourceInternalIdentityValidate(t *testing.T) {
 
 		"StateFunc is set": {
 			&ResourceIdentity{
+				Version: 1,
 				SchemaFunc: func() map[string]*Schema {
 					return map[string]*Schema{
 						"foo": {
@@ -2008,6 +3074,7 @@ func TestResourceInternalIdentityValidate(t *testing.T) {
 
 		"ValidateFunc is set": {
 			&ResourceIdentity{
+				Version: 1,
 				SchemaFunc: func() map[string]*Schema {
 					return map[string]*Schema{
 						"foo": {
@@ -2023,6 +3090,7 @@ func TestResourceInternalIdentityValidate(t *testing.T) {
 
 		"AtLeastOneOf is set": {
 			&ResourceIdentity{
+				Version: 1,
 				SchemaFunc: func() map[string]*Schema {
 					return map[string]*Schema{
 						"foo": {
@@ -2038,6 +3106,7 @@ func TestResourceInternalIdentityValidate(t *testing.T) {
 
 		"ConflictsWith is set": {
 			&ResourceIdentity{
+				Version: 1,
 				SchemaFunc: func() map[string]*Schema {
 					return map[string]*Schema{
 						"foo": {
@@ -2053,6 +3122,7 @@ func TestResourceInternalIdentityValidate(t *testing.T) {
 
 		"Valid resource identity OptionalForImport": {
 			&ResourceIdentity{
+				Version: 1,
 				SchemaFunc: func() map[string]*Schema {
 					return map[string]*Schema{
 						"foo": {
@@ -2063,28 +3133,606 @@ func TestResourceInternalIdentityValidate(t *testing.T) {
 			false,
 		},
 
-		"Valid resource identity RequiredorImport": {
+		"version 0": {
 			&ResourceIdentity{
+				Version: 0,
 				SchemaFunc: func() map[string]*Schema {
 					return map[string]*Schema{
-						"foo": {
-							Type: TypeInt, RequiredForImport: true},
+						"foo": {Type: TypeInt, RequiredForImport: true},
 					}
 				},
 			},
-			false,
+			true,
 		},
-	}
 
-	for name, tc := range cases {
-		t.Run(name, func(t *testing.T) {
-			err := tc.In.InternalIdentityValidate()
-			if err != nil && !tc.Err {
-				t.Fatalf("%s: expected validation to pass: %s", name, err)
-			}
-			if err == nil && tc.Err {
-				t.Fatalf("%s: expected validation to fail", name)
-			}
-		})
+		"negative version": {
+			&ResourceIdentity{
+				Version: -1,
+				SchemaFunc: func() map[string]*Schema {
+					return map[string]*Schema{
+						"foo": {Type: TypeInt, RequiredForImport: true},
+					}
+				},
+			},
+			true,
+		},
+
+		"IdentityUpgrader versions not increasing": {
+			&ResourceIdentity{
+				Version: 3,
+				SchemaFunc: func() map[string]*Schema {
+					return map[string]*Schema{
+						"foo": {Type: TypeInt, RequiredForImport: true},
+					}
+				},
+				IdentityUpgraders: []IdentityUpgrader{
+					{Version: 2},
+					{Version: 1},
+				},
+			},
+			true,
+		},
+
+		"IdentityUpgrader duplicate versions": {
+			&ResourceIdentity{
+				Version: 3,
+				SchemaFunc: func() map[string]*Schema {
+					return map[string]*Schema{
+						"foo": {Type: TypeInt, RequiredForImport: true},
+					}
+				},
+				IdentityUpgraders: []IdentityUpgrader{
+					{Version: 1},
+					{Version: 1},
+				},
+			},
+			true,
+		},
+
+		"IdentityUpgrader version not below current version": {
+			&ResourceIdentity{
+				Version: 2,
+				SchemaFunc: func() map[string]*Schema {
+					return map[string]*Schema{
+						"foo": {Type: TypeInt, RequiredForImport: true},
+					}
+				},
+				IdentityUpgraders: []IdentityUpgrader{
+					{Version: 1},
+					{Version: 2},
+				},
+			},
+			true,
+		},
+
+		"IdentityUpgrader chain not starting at version 0": {
+			&ResourceIdentity{
+				Version: 3,
+				SchemaFunc: func() map[string]*Schema {
+					return map[string]*Schema{
+						"foo": {Type: TypeInt, RequiredForImport: true},
+					}
+				},
+				IdentityUpgraders: []IdentityUpgrader{
+					{Version: 1},
+					{Version: 2},
+				},
+			},
+			false,
+		},
+
+		"IdentityUpgrader chain with a gap": {
+			&ResourceIdentity{
+				Version: 3,
+				SchemaFunc: func() map[string]*Schema {
+					return map[string]*Schema{
+						"foo": {Type: TypeInt, RequiredForImport: true},
+					}
+				},
+				IdentityUpgraders: []IdentityUpgrader{
+					{Version: 0},
+					{Version: 2},
+				},
+			},
+			true,
+		},
+
+		"IdentityUpgrader chain not ending at previous version": {
+			&ResourceIdentity{
+				Version: 3,
+				SchemaFunc: func() map[string]*Schema {
+					return map[string]*Schema{
+						"foo": {Type: TypeInt, RequiredForImport: true},
+					}
+				},
+				IdentityUpgraders: []IdentityUpgrader{
+					{Version: 0},
+					{Version: 1},
+				},
+			},
+			true,
+		},
+
+		"valid IdentityUpgrader chain": {
+			&ResourceIdentity{
+				Version: 3,
+				SchemaFunc: func() map[string]*Schema {
+					return map[string]*Schema{
+						"foo": {Type: TypeInt, RequiredForImport: true},
+					}
+				},
+				IdentityUpgraders: []IdentityUpgrader{
+					{Version: 0},
+					{Version: 1},
+					{Version: 2},
+				},
+			},
+			false,
+		},
+
+		"Valid resource identity RequiredorImport": {
+			&ResourceIdentity{
+				Version: 1,
+				SchemaFunc: func() map[string]*Schema {
+					return map[string]*Schema{
+						"foo": {
+							Type: TypeInt, RequiredForImport: true},
+					}
+				},
+			},
+			false,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := tc.In.InternalIdentityValidate()
+			if err != nil && !tc.Err {
+				t.Fatalf("%s: expected validation to pass: %s", name, err)
+			}
+			if err == nil && tc.Err {
+				t.Fatalf("%s: expected validation to fail", name)
+			}
+		})
+	}
+}
+
+func TestResourceTestValidateRawConfig(t *testing.T) {
+	requireName := func(_ context.Context, req ValidateResourceConfigFuncRequest, resp *ValidateResourceConfigFuncResponse) {
+		if req.RawConfig.GetAttr("name").IsNull() {
+			resp.Diagnostics = append(resp.Diagnostics, diag.Diagnostic{
+				Severity:      diag.Error,
+				Summary:       "Missing name",
+				AttributePath: cty.GetAttrPath("name"),
+			})
+		}
+	}
+	warnName := func(_ context.Context, req ValidateResourceConfigFuncRequest, resp *ValidateResourceConfigFuncResponse) {
+		if !req.WriteOnlyAttributesAllowed {
+			resp.Diagnostics = append(resp.Diagnostics, diag.Errorf("write-only attributes not allowed")...)
+		}
+		if req.RawConfig.GetAttr("name").IsNull() {
+			resp.Diagnostics = append(resp.Diagnostics, diag.Diagnostic{
+				Severity:      diag.Warning,
+				Summary:       "Missing name",
+				AttributePath: cty.GetAttrPath("name"),
+			})
+		}
+	}
+
+	r := &Resource{
+		Schema: map[string]*Schema{
+			"name": {
+				Type:     TypeString,
+				Optional: true,
+			},
+		},
+		ValidateRawResourceConfigFuncs: []ValidateRawResourceConfigFunc{
+			requireName,
+			warnName,
+			requireName,
+		},
+	}
+
+	cases := map[string]struct {
+		Config   cty.Value
+		Expected diag.Diagnostics
+	}{
+		"valid": {
+			Config: cty.ObjectVal(map[string]cty.Value{
+				"name": cty.StringVal("example"),
+			}),
+		},
+		"overlapping diagnostics": {
+			Config: cty.ObjectVal(map[string]cty.Value{
+				"name": cty.NullVal(cty.String),
+			}),
+			Expected: diag.Diagnostics{
+				{
+					Severity:      diag.Error,
+					Summary:       "Missing name",
+					AttributePath: cty.GetAttrPath("name"),
+				},
+				{
+					Severity:      diag.Warning,
+					Summary:       "Missing name",
+					AttributePath: cty.GetAttrPath("name"),
+				},
+				{
+					Severity:      diag.Error,
+					Summary:       "Missing name",
+					AttributePath: cty.GetAttrPath("name"),
+				},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			resp := r.TestValidateRawConfig(context.Background(), tc.Config)
+
+			if diff := cmp.Diff(tc.Expected, resp.Diagnostics, cmp.AllowUnexported(cty.GetAttrStep{}, diag.Diagnostic{})); diff != "" {
+				t.Fatalf("unexpected difference: %s", diff)
+			}
+		})
+	}
+}
+
+func TestValidateResourceConfigFuncAttributeDiagnostics(t *testing.T) {
+	validatePort := func(_ context.Context, req ValidateResourceConfigFuncRequest, resp *ValidateResourceConfigFuncResponse) {
+		path := cty.GetAttrPath("network").IndexInt(0).GetAttr("port")
+
+		port, diags := req.GetRawConfigAt(path)
+		if diags.HasError() {
+			resp.Diagnostics = append(resp.Diagnostics, diags...)
+			return
+		}
+
+		switch {
+		case port.IsNull():
+			resp.AddAttributeWarning(path, "Missing port", "The default port is used.")
+		case port.IsKnown() && port.RawEquals(cty.NumberIntVal(0)):
+			resp.AddAttributeError(path, "Invalid port", "The port must not be 0.")
+		}
+	}
+
+	r := &Resource{
+		Schema: map[string]*Schema{
+			"network": {
+				Type:     TypeList,
+				Optional: true,
+				Elem: &Resource{
+					Schema: map[string]*Schema{
+						"port": {
+							Type:     TypeInt,
+							Optional: true,
+						},
+					},
+				},
+			},
+		},
+		ValidateRawResourceConfigFuncs: []ValidateRawResourceConfigFunc{
+			validatePort,
+		},
+	}
+
+	network := func(port cty.Value) cty.Value {
+		return cty.ObjectVal(map[string]cty.Value{
+			"network": cty.ListVal([]cty.Value{
+				cty.ObjectVal(map[string]cty.Value{
+					"port": port,
+				}),
+			}),
+		})
+	}
+
+	path := cty.GetAttrPath("network").IndexInt(0).GetAttr("port")
+
+	cases := map[string]struct {
+		Config   cty.Value
+		Expected diag.Diagnostics
+	}{
+		"valid": {
+			Config: network(cty.NumberIntVal(80)),
+		},
+		"error": {
+			Config: network(cty.NumberIntVal(0)),
+			Expected: diag.Diagnostics{
+				{
+					Severity:      diag.Error,
+					Summary:       "Invalid port",
+					Detail:        "The port must not be 0.",
+					AttributePath: path,
+				},
+			},
+		},
+		"warning": {
+			Config: network(cty.NullVal(cty.Number)),
+			Expected: diag.Diagnostics{
+				{
+					Severity:      diag.Warning,
+					Summary:       "Missing port",
+					Detail:        "The default port is used.",
+					AttributePath: path,
+				},
+			},
+		},
+		"missing path": {
+			Config: cty.ObjectVal(map[string]cty.Value{
+				"network": cty.ListValEmpty(cty.Object(map[string]cty.Type{
+					"port": cty.Number,
+				})),
+			}),
+			Expected: diag.Diagnostics{
+				{
+					Severity: diag.Error,
+					Summary:  "Invalid config path",
+					Detail: "The Terraform Provider unexpectedly provided a path that does not match the current schema. " +
+						"This can happen if the path does not correctly follow the schema in structure or types. " +
+						"Please report this to the provider developers. \n\n" +
+						"Cannot find config value for given path.",
+					AttributePath: path,
+				},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			resp := r.TestValidateRawConfig(context.Background(), tc.Config)
+
+			if diff := cmp.Diff(tc.Expected, resp.Diagnostics, cmp.Comparer(cty.Path.Equals), cmp.AllowUnexported(diag.Diagnostic{})); diff != "" {
+				t.Fatalf("unexpected difference: %s", diff)
+			}
+		})
+	}
+}
+
+func TestResourceComputedAttributes(t *testing.T) {
+	r := &Resource{
+		Schema: map[string]*Schema{
+			"name": {
+				Type:     TypeString,
+				Required: true,
+			},
+			"description": {
+				Type:     TypeString,
+				Optional: true,
+				Computed: true,
+			},
+			"arn": {
+				Type:     TypeString,
+				Computed: true,
+			},
+			"tags": {
+				Type:     TypeMap,
+				Computed: true,
+				Elem:     &Schema{Type: TypeString},
+			},
+			"rule": {
+				Type:     TypeList,
+				Optional: true,
+				Elem: &Resource{
+					Schema: map[string]*Schema{
+						"port": {
+							Type:     TypeInt,
+							Required: true,
+						},
+						"priority": {
+							Type:     TypeInt,
+							Optional: true,
+							Computed: true,
+						},
+						"rule_id": {
+							Type:     TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+			"endpoint": {
+				Type:     TypeSet,
+				Computed: true,
+				Elem: &Resource{
+					Schema: map[string]*Schema{
+						"address": {
+							Type:     TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	expected := []cty.Path{
+		cty.GetAttrPath("arn"),
+		cty.GetAttrPath("endpoint"),
+		cty.GetAttrPath("endpoint").GetAttr("address"),
+		cty.GetAttrPath("rule").GetAttr("rule_id"),
+		cty.GetAttrPath("tags"),
+	}
+
+	if diff := cmp.Diff(expected, r.ComputedAttributes(), cmp.AllowUnexported(cty.GetAttrStep{})); diff != "" {
+		t.Fatalf("unexpected difference: %s", diff)
+	}
+}
+
+func TestResourceDataOperation(t *testing.T) {
+	var operations []Operation
+
+	record := func(_ context.Context, d *ResourceData, _ interface{}) diag.Diagnostics {
+		operations = append(operations, d.Operation())
+		if d.Id() == "" {
+			d.SetId("foo")
+		}
+		return nil
+	}
+
+	r := &Resource{
+		Schema: map[string]*Schema{
+			"foo": {
+				Type:     TypeInt,
+				Optional: true,
+			},
+		},
+		CreateContext: record,
+		ReadContext:   record,
+		UpdateContext: record,
+		DeleteContext: record,
+	}
+
+	_, diags := r.Apply(context.Background(), nil, &terraform.InstanceDiff{
+		Attributes: map[string]*terraform.ResourceAttrDiff{
+			"foo": {
+				New: "12",
+			},
+		},
+	}, nil)
+	if diags.HasError() {
+		t.Fatalf("unexpected create error: %s", diagutils.ErrorDiags(diags))
+	}
+
+	s := &terraform.InstanceState{
+		ID: "foo",
+		Attributes: map[string]string{
+			"foo": "12",
+		},
+	}
+
+	_, diags = r.RefreshWithoutUpgrade(context.Background(), s, nil)
+	if diags.HasError() {
+		t.Fatalf("unexpected read error: %s", diagutils.ErrorDiags(diags))
+	}
+
+	_, diags = r.Apply(context.Background(), s, &terraform.InstanceDiff{
+		Attributes: map[string]*terraform.ResourceAttrDiff{
+			"foo": {
+				Old: "12",
+				New: "13",
+			},
+		},
+	}, nil)
+	if diags.HasError() {
+		t.Fatalf("unexpected update error: %s", diagutils.ErrorDiags(diags))
+	}
+
+	_, diags = r.Apply(context.Background(), s, &terraform.InstanceDiff{
+		Destroy: true,
+	}, nil)
+	if diags.HasError() {
+		t.Fatalf("unexpected delete error: %s", diagutils.ErrorDiags(diags))
+	}
+
+	expected := []Operation{OperationCreate, OperationRead, OperationUpdate, OperationDelete}
+	if diff := cmp.Diff(expected, operations); diff != "" {
+		t.Fatalf("unexpected difference: %s", diff)
+	}
+
+	if got := r.TestResourceData().Operation(); got != OperationUnknown {
+		t.Fatalf("expected %s outside of operations, got %s", OperationUnknown, got)
+	}
+}
+
+func TestOperationFromContext(t *testing.T) {
+	var operations []Operation
+
+	record := func(ctx context.Context, d *ResourceData, _ interface{}) diag.Diagnostics {
+		op, ok := OperationFromContext(ctx)
+		if !ok {
+			return diag.Errorf("missing operation in %s context", d.Operation())
+		}
+		if op != d.Operation() {
+			return diag.Errorf("expected %s operation in context, got %s", d.Operation(), op)
+		}
+
+		operations = append(operations, op)
+		if d.Id() == "" {
+			d.SetId("foo")
+		}
+		return nil
+	}
+
+	r := &Resource{
+		Schema: map[string]*Schema{
+			"foo": {
+				Type:     TypeInt,
+				Optional: true,
+			},
+		},
+		CreateContext: record,
+		ReadContext:   record,
+		UpdateContext: record,
+		DeleteContext: record,
+		Importer: &ResourceImporter{
+			StateContext: func(ctx context.Context, d *ResourceData, meta interface{}) ([]*ResourceData, error) {
+				if diags := record(ctx, d, meta); diags.HasError() {
+					return nil, diagutils.ErrorDiags(diags)
+				}
+				return []*ResourceData{d}, nil
+			},
+		},
+	}
+
+	_, diags := r.Apply(context.Background(), nil, &terraform.InstanceDiff{
+		Attributes: map[string]*terraform.ResourceAttrDiff{
+			"foo": {
+				New: "12",
+			},
+		},
+	}, nil)
+	if diags.HasError() {
+		t.Fatalf("unexpected create error: %s", diagutils.ErrorDiags(diags))
+	}
+
+	s := &terraform.InstanceState{
+		ID: "foo",
+		Attributes: map[string]string{
+			"foo": "12",
+		},
+	}
+
+	_, diags = r.RefreshWithoutUpgrade(context.Background(), s, nil)
+	if diags.HasError() {
+		t.Fatalf("unexpected read error: %s", diagutils.ErrorDiags(diags))
+	}
+
+	_, diags = r.Apply(context.Background(), s, &terraform.InstanceDiff{
+		Attributes: map[string]*terraform.ResourceAttrDiff{
+			"foo": {
+				Old: "12",
+				New: "13",
+			},
+		},
+	}, nil)
+	if diags.HasError() {
+		t.Fatalf("unexpected update error: %s", diagutils.ErrorDiags(diags))
+	}
+
+	_, diags = r.Apply(context.Background(), s, &terraform.InstanceDiff{
+		Destroy: true,
+	}, nil)
+	if diags.HasError() {
+		t.Fatalf("unexpected delete error: %s", diagutils.ErrorDiags(diags))
+	}
+
+	p := &Provider{
+		ResourcesMap: map[string]*Resource{
+			"test_resource": r,
+		},
+	}
+
+	_, err := p.ImportState(context.Background(), &terraform.InstanceInfo{Type: "test_resource"}, "foo")
+	if err != nil {
+		t.Fatalf("unexpected import error: %s", err)
+	}
+
+	expected := []Operation{OperationCreate, OperationRead, OperationUpdate, OperationDelete, OperationImport}
+	if diff := cmp.Diff(expected, operations); diff != "" {
+		t.Fatalf("unexpected difference: %s", diff)
+	}
+
+	if op, ok := OperationFromContext(context.Background()); ok {
+		t.Fatalf("expected no operation outside of operations, got %s", op)
 	}
 }
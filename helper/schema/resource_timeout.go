@@ -53,6 +53,12 @@ func DefaultTimeout(tx interface{}) *time.Duration {
 
 type ResourceTimeout struct {
 	Create, Read, Update, Delete, Default *time.Duration
+
+	// MaxConfigurable, when greater than zero, is the maximum duration
+	// practitioners can configure for any timeout in the timeouts block.
+	// Larger durations return an error during plan. It does not apply to
+	// the default durations declared in the resource.
+	MaxConfigurable time.Duration
 }
 
 // ConfigDecode takes a schema and the configuration (available in Diff) and
@@ -118,6 +124,10 @@ func (t *ResourceTimeout) ConfigDecode(s *Resource, c *terraform.ResourceConfig)
 					return fmt.Errorf("Error parsing %q timeout: %s", timeKey, err)
 				}
 
+				if t.MaxConfigurable > 0 && rt > t.MaxConfigurable {
+					return fmt.Errorf("%q timeout of %s exceeds the maximum of %s", timeKey, rt, t.MaxConfigurable)
+				}
+
 				var timeout *time.Duration
 				switch timeKey {
 				case TimeoutCreate:
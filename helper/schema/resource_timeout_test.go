@@ -125,6 +125,68 @@ func TestResourceTimeout_ConfigDecode(t *testing.T) {
 	}
 }
 
+func TestResourceTimeout_ConfigDecode_maxConfigurable(t *testing.T) {
+	cases := map[string]struct {
+		Config      map[string]interface{}
+		Expected    *ResourceTimeout
+		ExpectedErr string
+	}{
+		"within bounds": {
+			Config: map[string]interface{}{
+				"create": "2h",
+			},
+			Expected: &ResourceTimeout{
+				Create:          DefaultTimeout(2 * time.Hour),
+				Update:          DefaultTimeout(5 * time.Minute),
+				MaxConfigurable: 2 * time.Hour,
+			},
+		},
+		"over bounds": {
+			Config: map[string]interface{}{
+				"create": "100h",
+			},
+			ExpectedErr: `"create" timeout of 100h0m0s exceeds the maximum of 2h0m0s`,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			r := &Resource{
+				Timeouts: &ResourceTimeout{
+					Create:          DefaultTimeout(10 * time.Minute),
+					Update:          DefaultTimeout(5 * time.Minute),
+					MaxConfigurable: 2 * time.Hour,
+				},
+			}
+
+			conf := terraform.NewResourceConfigRaw(
+				map[string]interface{}{
+					"foo":             "bar",
+					TimeoutsConfigKey: c.Config,
+				},
+			)
+
+			timeout := &ResourceTimeout{}
+			err := timeout.ConfigDecode(r, conf)
+
+			if c.ExpectedErr != "" {
+				if err == nil || err.Error() != c.ExpectedErr {
+					t.Fatalf("expected error %q, got: %v", c.ExpectedErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !reflect.DeepEqual(c.Expected, timeout) {
+				t.Fatalf("bad timeout decode.\nExpected:\n%#v\nGot:\n%#v\n", c.Expected, timeout)
+			}
+		})
+	}
+}
+
 func TestResourceTimeout_legacyConfigDecode(t *testing.T) {
 	r := &Resource{
 		Timeouts: &ResourceTimeout{
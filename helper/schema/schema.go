@@ -18,6 +18,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"math/bits"
 	"os"
 	"reflect"
 	"regexp"
@@ -25,6 +26,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-log/tfsdklog"
 	"github.com/mitchellh/copystructure"
@@ -116,6 +118,33 @@ type Schema struct {
 	// its value.
 	Computed bool
 
+	// ComputeIfUnset uses the configured value of an Optional and Computed
+	// attribute when it is set, and otherwise lets the provider compute it.
+	// Unlike Optional and Computed alone:
+	//
+	//   - When a configured value is removed from the configuration, the
+	//     value is planned as unknown so it is computed again, rather than
+	//     keeping the previously configured value.
+	//   - While the value is configured, refresh never overwrites it with
+	//     the value returned by Read.
+	//
+	// Which attributes were configured is tracked in the resource instance
+	// private state. ComputeIfUnset is only valid for top level TypeBool,
+	// TypeInt, TypeFloat and TypeString attributes of managed resources and
+	// requires both Optional and Computed.
+	ComputeIfUnset bool
+
+	// IdentitySource is the name of a resource identity attribute whose value
+	// is copied into this attribute after the resource is refreshed, when the
+	// Read implementation leaves this attribute unset. This allows restoring
+	// values which the remote system does not return, such as after an
+	// import by identity.
+	//
+	// IdentitySource is only valid for top level Computed attributes of
+	// managed resources with an Identity, and the identity attribute must
+	// have the same primitive type as this attribute.
+	IdentitySource string
+
 	// ForceNew indicates whether a change in this value requires the
 	// replacement (destroy and create) of the managed resource instance,
 	// rather than an in-place update. This field is only valid when the
@@ -125,6 +154,24 @@ type Schema struct {
 	// CustomizeDiff field to call the ResourceDiff type ForceNew method.
 	ForceNew bool
 
+	// ForceNewFunc is called during plan when the configured value of this
+	// attribute differs from its prior state value, with both values as
+	// the zero value of the attribute type when unset, and returns whether
+	// the change requires the replacement of the managed resource instance.
+	// It is also called when CustomizeDiff sets a new value for the
+	// attribute, and is not called when the new value is unknown. This
+	// allows changes which the remote system can update in-place, such as
+	// changing a value, to be distinguished from those it cannot, such as
+	// removing it.
+	//
+	//	ForceNewFunc: func(ctx context.Context, oldValue, newValue interface{}) bool {
+	//		return oldValue.(string) != "" && newValue.(string) == ""
+	//	},
+	//
+	// ForceNewFunc is only valid for top-level attributes, and cannot be set
+	// with ForceNew or WriteOnly.
+	ForceNewFunc func(ctx context.Context, oldValue, newValue interface{}) bool
+
 	// If this is non-nil, the provided function will be used during diff
 	// of this field. If this is nil, a default diff for the type of the
 	// schema will be used.
@@ -172,6 +219,17 @@ type Schema struct {
 	// for existing providers if activated everywhere all at once.
 	DiffSuppressOnRefresh bool
 
+	// ValidateChangeFunc allows rejecting an invalid transition from the
+	// prior state value to the planned value of this attribute, such as a
+	// disk size which can only grow. It is called during diff with the old
+	// and new values when the attribute changes during an in-place update,
+	// and any returned error is reported against this attribute.
+	//
+	// ValidateChangeFunc is not called when the resource is being created
+	// or replaced, or when the new value is unknown. It is only supported on
+	// top-level attributes.
+	ValidateChangeFunc SchemaValidateChangeFunc
+
 	// Default indicates a value to set if this attribute is not set in the
 	// configuration. Default cannot be used with DefaultFunc or Required.
 	// Default is only supported if the Type is TypeBool, TypeFloat, TypeInt,
@@ -202,6 +260,21 @@ type Schema struct {
 	// default.
 	DefaultFunc SchemaDefaultFunc
 
+	// DefaultFromState keeps the prior state value of an Optional attribute
+	// when it is removed from the configuration during update, instead of
+	// planning the zero value. On create, an unset attribute remains unset.
+	//
+	// Unlike Computed, the provider cannot set a value for an unset
+	// attribute and the value is never planned as unknown. The kept value is
+	// always the last value from the configuration or set by the provider in
+	// state. Terraform tolerates the kept value differing from the null
+	// configuration value for providers built on this SDK, but logs warnings.
+	//
+	// DefaultFromState is only supported if the Type is TypeBool, TypeFloat,
+	// TypeInt, or TypeString and cannot be used with Required, Computed,
+	// Default, or DefaultFunc.
+	DefaultFromState bool
+
 	// Description is used as the description for docs, the language server and
 	// other user facing usage. It can be plain-text or markdown depending on the
 	// global DescriptionKind setting.
@@ -229,8 +302,9 @@ type Schema struct {
 	//
 	//   https://www.terraform.io/docs/language/syntax/configuration.html
 	//
-	// The underlying *Schema is only required to implement Type. ValidateFunc
-	// or ValidateDiagFunc can be used to validate each element value.
+	// The underlying *Schema is only required to implement Type. ValidateFunc,
+	// ValidateDiagFunc or ValidateDiagContextFunc can be used to validate each
+	// element value.
 	//
 	// If the Elem is a *Resource, the surrounding Schema represents a
 	// configuration block. Blocks can contain underlying attributes or blocks.
@@ -261,14 +335,33 @@ type Schema struct {
 	// underlying structure and type information of the Elem field.
 	Set SchemaSetFunc
 
-	// ComputedWhen is a set of queries on the configuration. Whenever any
-	// of these things is changed, it will require a recompute (this requires
-	// that Computed is set to true).
+	// ComputedWhen is a set of attribute paths, in the same format as
+	// ConflictsWith, whose changes require this attribute to be recomputed.
+	// When any of the referenced attributes changes during an update, this
+	// attribute is planned as unknown unless it is set in the configuration.
+	// Otherwise the prior value is kept as with any other Computed attribute.
 	//
-	// Deprecated: This functionality is not implemented and this field
-	// declaration should be removed.
+	// This requires that Computed is set to true and is only supported on
+	// top-level attributes.
 	ComputedWhen []string
 
+	// StableSeedFunc derives the value of this attribute during plan from a
+	// seed, such as a combination of other configured attributes, instead
+	// of leaving it unknown until apply. The value is the hex encoding of the
+	// first 8 bytes of the SHA-256 hash of the seed, which is suitable for
+	// random looking suffixes, so it stays the same across plans while the
+	// seed is unchanged and is replaced when the seed changes.
+	//
+	// The function should return an empty string when the seed cannot be
+	// determined, such as when it depends on unknown values, in which case
+	// the prior state value is kept, or the attribute is planned as unknown
+	// if there is none. The resource implementation must not set the
+	// attribute.
+	//
+	// This requires that Computed is set to true and Optional is not, and is
+	// only supported on top-level TypeString attributes.
+	StableSeedFunc func(d *ResourceData) string
+
 	// ConflictsWith is a set of attribute paths, including this attribute,
 	// whose configurations cannot be set simultaneously. This implements the
 	// validation logic declaratively within the schema and can trigger earlier
@@ -355,6 +448,25 @@ type Schema struct {
 	//  - https://github.com/hashicorp/terraform/issues/7569
 	Deprecated string
 
+	// DeprecatedValues defines warning diagnostic details to display when
+	// practitioner configurations set this attribute to specific values,
+	// rather than deprecating the attribute as a whole. The map keys are the
+	// deprecated values and the map values are the warning diagnostic
+	// details. The warning diagnostic summary is automatically set to
+	// "Argument value is deprecated".
+	//
+	// This is useful for phasing out individual members of an enumeration,
+	// for example:
+	//
+	//  DeprecatedValues: map[string]string{
+	//    "legacy": "Use \"standard\" instead. This value will be removed in the next major version of the provider.",
+	//  }
+	//
+	// DeprecatedValues is only valid for TypeString and TypeInt attributes.
+	// For TypeInt, the map keys must be the base 10 string representation of
+	// the deprecated values.
+	DeprecatedValues map[string]string
+
 	// ValidateFunc allows individual fields to define arbitrary validation
 	// logic. It is yielded the provided config value as an interface{} that is
 	// guaranteed to be of the proper Schema type, and it can yield warnings or
@@ -387,6 +499,15 @@ type Schema struct {
 	//  AttributePath: append(path, cty.IndexStep{Key: cty.StringVal("key_name")})
 	ValidateDiagFunc SchemaValidateDiagFunc
 
+	// ValidateDiagContextFunc is the same as ValidateDiagFunc, but is also
+	// yielded the context of the validation request. This allows validation
+	// logic which calls external services to honor cancellation and deadlines
+	// from Terraform.
+	//
+	// ValidateDiagContextFunc cannot be set together with ValidateFunc or
+	// ValidateDiagFunc.
+	ValidateDiagContextFunc SchemaValidateDiagContextFunc
+
 	// Sensitive ensures that the attribute's value does not get displayed in
 	// the Terraform user interface output. It should be used for password or
 	// other values which should be hidden.
@@ -464,6 +585,13 @@ func EnvDefaultFunc(k string, dv interface{}) SchemaDefaultFunc {
 // environment variable in the given list that returns a non-empty value. If
 // none of the environment variables return a value, the default value is
 // returned.
+//
+// The environment variables are checked in the order of the list, so the
+// first non-empty one wins, and variables which are set to an empty string
+// are skipped as if they were unset. For example, with
+// []string{"EXAMPLE_TOKEN", "EXAMPLE_API_TOKEN"}, EXAMPLE_API_TOKEN is only
+// used when EXAMPLE_TOKEN is unset or empty. Values are returned as strings,
+// like with EnvDefaultFunc.
 func MultiEnvDefaultFunc(ks []string, dv interface{}) SchemaDefaultFunc {
 	return func() (interface{}, error) {
 		for _, k := range ks {
@@ -483,6 +611,10 @@ type SchemaSetFunc func(interface{}) int
 // to be stored in the state.
 type SchemaStateFunc func(interface{}) string
 
+// SchemaValidateChangeFunc is a function used to validate the transition of
+// a single field from its prior state value to its planned value.
+type SchemaValidateChangeFunc func(oldValue, newValue interface{}) error
+
 // SchemaValidateFunc is a function used to validate a single field in the
 // schema.
 //
@@ -493,6 +625,10 @@ type SchemaValidateFunc func(interface{}, string) ([]string, []error)
 // schema and has Diagnostic support.
 type SchemaValidateDiagFunc func(interface{}, cty.Path) diag.Diagnostics
 
+// SchemaValidateDiagContextFunc is a function used to validate a single field
+// in the schema with Diagnostic support and access to the request context.
+type SchemaValidateDiagContextFunc func(ctx context.Context, v interface{}, p cty.Path) diag.Diagnostics
+
 func (s *Schema) GoString() string {
 	return fmt.Sprintf("*%#v", *s)
 }
@@ -539,6 +675,28 @@ func (s *Schema) ZeroValue() interface{} {
 	}
 }
 
+// forceNewFromFunc returns a copy of the schema with ForceNew enabled if
+// ForceNewFunc returns true for the given change of the attribute, otherwise
+// the schema itself.
+func (s *Schema) forceNewFromFunc(ctx context.Context, o, n getResult) *Schema {
+	if s == nil || s.ForceNewFunc == nil {
+		return s
+	}
+
+	if n.Computed || cmp.Equal(o.Value, n.Value) {
+		return s
+	}
+
+	if !s.ForceNewFunc(ctx, o.Value, n.Value) {
+		return s
+	}
+
+	forceNew := *s
+	forceNew.ForceNew = true
+
+	return &forceNew
+}
+
 func (s *Schema) finalizeDiff(d *terraform.ResourceAttrDiff, customized bool) *terraform.ResourceAttrDiff {
 	if d == nil {
 		return d
@@ -608,11 +766,15 @@ func (s *Schema) finalizeDiff(d *terraform.ResourceAttrDiff, customized bool) *t
 	return d
 }
 
-func (s *Schema) validateFunc(decoded interface{}, k string, path cty.Path) diag.Diagnostics {
+func (s *Schema) validateFunc(ctx context.Context, decoded interface{}, k string, path cty.Path) diag.Diagnostics {
 	var diags diag.Diagnostics
 
-	if s.ValidateDiagFunc != nil {
-		diags = s.ValidateDiagFunc(decoded, path)
+	if s.ValidateDiagContextFunc != nil || s.ValidateDiagFunc != nil {
+		if s.ValidateDiagContextFunc != nil {
+			diags = s.ValidateDiagContextFunc(ctx, decoded, path)
+		} else {
+			diags = s.ValidateDiagFunc(decoded, path)
+		}
 		for i := range diags {
 			if !diags[i].AttributePath.HasPrefix(path) {
 				diags[i].AttributePath = append(path, diags[i].AttributePath...)
@@ -719,6 +881,7 @@ func (m schemaMapWithIdentity) Diff(
 	if s != nil {
 		result.DestroyTainted = s.Tainted
 		result.RawConfig = s.RawConfig
+		result.RawConfigMsgPack = s.RawConfigMsgPack
 		result.RawState = s.RawState
 		result.RawPlan = s.RawPlan
 		result.Identity = s.Identity
@@ -733,7 +896,8 @@ func (m schemaMapWithIdentity) Diff(
 	}
 
 	for k, schema := range m.schemaMap {
-		err := m.diff(ctx, k, schema, result, d, false)
+		o, n := d.getChange(k, getSourceState, getSourceConfig|getSourceExact)
+		err := m.diff(ctx, k, schema.forceNewFromFunc(ctx, o, n), result, d, false)
 		if err != nil {
 			return nil, err
 		}
@@ -746,6 +910,10 @@ func (m schemaMapWithIdentity) Diff(
 		}
 	}
 
+	m.diffComputeIfUnset(s, result)
+	m.diffComputedWhen(s, result)
+	m.diffStableSeed(d, s, result)
+
 	// If this is a non-destroy diff, call any custom diff logic that has been
 	// defined.
 	if !result.DestroyTainted && customizeDiff != nil {
@@ -759,8 +927,10 @@ func (m schemaMapWithIdentity) Diff(
 		if err != nil {
 			return nil, err
 		}
+		rd.recordDeferral(ctx)
 		for _, k := range rd.UpdatedKeys() {
-			err := m.diff(ctx, k, mc.schemaMap[k], result, rd, false)
+			o, n, _ := rd.getChange(k)
+			err := m.diff(ctx, k, mc.schemaMap[k].forceNewFromFunc(ctx, o, n), result, rd, false)
 			if err != nil {
 				return nil, err
 			}
@@ -792,6 +962,10 @@ func (m schemaMapWithIdentity) Diff(
 		} // TODO: else log error?
 	}
 
+	if err := m.validateChanges(s, c, result); err != nil {
+		return nil, err
+	}
+
 	if handleRequiresNew {
 		// If the diff requires a new resource, then we recompute the diff
 		// so we have the complete new resource diff, and preserve the
@@ -805,6 +979,7 @@ func (m schemaMapWithIdentity) Diff(
 			// Preserve the DestroyTainted flag
 			result2.DestroyTainted = result.DestroyTainted
 			result2.RawConfig = result.RawConfig
+			result2.RawConfigMsgPack = result.RawConfigMsgPack
 			result2.RawPlan = result.RawPlan
 			result2.RawState = result.RawState
 
@@ -828,6 +1003,7 @@ func (m schemaMapWithIdentity) Diff(
 				if err := customizeDiff(ctx, rd, meta); err != nil {
 					return nil, err
 				}
+				rd.recordDeferral(ctx)
 				for _, k := range rd.UpdatedKeys() {
 					err := m.diff(ctx, k, mc.schemaMap[k], result2, rd, false)
 					if err != nil {
@@ -862,6 +1038,10 @@ func (m schemaMapWithIdentity) Diff(
 
 			}
 
+			// Plan the derived values for the new resource, the prior state
+			// value is restored below.
+			m.diffStableSeed(d, nil, result2)
+
 			// Force all the fields to not force a new since we know what we
 			// want to force new.
 			for k, attr := range result2.Attributes {
@@ -902,10 +1082,6 @@ func (m schemaMapWithIdentity) Diff(
 
 	}
 
-	// Go through and detect all of the ComputedWhens now that we've
-	// finished the diff.
-	// TODO
-
 	if result.Empty() {
 		// If we don't have any diff elements, just return nil
 		return nil, nil
@@ -926,9 +1102,52 @@ func (m schemaMap) Diff(
 	return schemaMapWithIdentity{m, nil}.Diff(ctx, s, c, customizeDiff, meta, handleRequiresNew)
 }
 
+// validateChanges calls the ValidateChangeFunc of top-level attributes which
+// change during an in-place update of an existing resource.
+func (m schemaMapWithIdentity) validateChanges(
+	s *terraform.InstanceState,
+	c *terraform.ResourceConfig,
+	diff *terraform.InstanceDiff) error {
+	if s == nil || s.ID == "" || diff.DestroyTainted || diff.RequiresNew() {
+		return nil
+	}
+
+	var rd *ResourceDiff
+	keys := make([]string, 0, len(m.schemaMap))
+	for k, v := range m.schemaMap {
+		if v.ValidateChangeFunc != nil {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if rd == nil {
+			rd = newResourceDiff(m, c, s, diff)
+		}
+
+		if !rd.HasChange(k) || !rd.NewValueKnown(k) {
+			continue
+		}
+
+		o, n := rd.GetChange(k)
+		if err := m.schemaMap[k].ValidateChangeFunc(o, n); err != nil {
+			return cty.GetAttrPath(k).NewError(err)
+		}
+	}
+
+	return nil
+}
+
 // Validate validates the configuration against this schema mapping.
 func (m schemaMap) Validate(c *terraform.ResourceConfig) diag.Diagnostics {
-	return m.validateObject("", m, c, cty.Path{})
+	return m.ValidateContext(context.Background(), c)
+}
+
+// ValidateContext validates the configuration against this schema mapping.
+// The given context is passed to any ValidateDiagContextFunc.
+func (m schemaMap) ValidateContext(ctx context.Context, c *terraform.ResourceConfig) diag.Diagnostics {
+	return m.validateObject(ctx, "", m, c, cty.Path{})
 }
 
 // InternalValidate validates the format of this schema. This should be called
@@ -972,6 +1191,20 @@ func (m schemaMap) internalValidate(topSchemaMap schemaMap, attrsOnly bool) erro
 			return fmt.Errorf("%s: WriteOnly cannot be set with ForceNew", k)
 		}
 
+		if v.ForceNewFunc != nil {
+			if v.ForceNew {
+				return fmt.Errorf("%s: ForceNewFunc cannot be set with ForceNew", k)
+			}
+
+			if v.WriteOnly {
+				return fmt.Errorf("%s: WriteOnly cannot be set with ForceNewFunc", k)
+			}
+
+			if topSchemaMap[k] != v {
+				return fmt.Errorf("%s: ForceNewFunc is only supported on top-level attributes", k)
+			}
+		}
+
 		if v.RequiredForImport {
 			return fmt.Errorf("%s: RequiredForImport is only valid for resource identity schemas", k)
 		}
@@ -1027,8 +1260,43 @@ func (m schemaMap) internalValidate(topSchemaMap schemaMap, attrsOnly bool) erro
 			return fmt.Errorf("%s: DefaultFunc cannot be set with WriteOnly", k)
 		}
 
-		if len(v.ComputedWhen) > 0 && !v.Computed {
-			return fmt.Errorf("%s: ComputedWhen can only be set with Computed", k)
+		if len(v.ComputedWhen) > 0 {
+			if !v.Computed {
+				return fmt.Errorf("%s: ComputedWhen can only be set with Computed", k)
+			}
+
+			if topSchemaMap[k] != v {
+				return fmt.Errorf("%s: ComputedWhen is only supported on top-level attributes", k)
+			}
+
+			for _, key := range v.ComputedWhen {
+				target, err := schemaFlagTarget(k, key, topSchemaMap)
+				if err != nil {
+					return fmt.Errorf("ComputedWhen: %+v", err)
+				}
+
+				if target == v {
+					return fmt.Errorf("ComputedWhen: %s cannot reference self (%s)", k, key)
+				}
+
+				if len(target.ComputedWhen) > 0 {
+					return fmt.Errorf("ComputedWhen: %s cannot contain Computed(When) attribute (%s)", k, key)
+				}
+			}
+		}
+
+		if v.StableSeedFunc != nil {
+			if !v.Computed || v.Optional {
+				return fmt.Errorf("%s: StableSeedFunc is only supported on computed-only attributes", k)
+			}
+
+			if v.Type != TypeString {
+				return fmt.Errorf("%s: StableSeedFunc is only supported on TypeString attributes", k)
+			}
+
+			if topSchemaMap[k] != v {
+				return fmt.Errorf("%s: StableSeedFunc is only supported on top-level attributes", k)
+			}
 		}
 
 		if len(v.ConflictsWith) > 0 && v.Required {
@@ -1092,6 +1360,12 @@ func (m schemaMap) internalValidate(topSchemaMap schemaMap, attrsOnly bool) erro
 				return fmt.Errorf("%s: Set can only be set for TypeSet", k)
 			}
 
+			if v.Set != nil {
+				if err := validateSetFunc(v.Set); err != nil {
+					return fmt.Errorf("%s: %s", k, err)
+				}
+			}
+
 			switch t := v.Elem.(type) {
 			case *Resource:
 				attrsOnly := attrsOnly || v.ConfigMode == SchemaConfigModeAttr
@@ -1107,7 +1381,7 @@ func (m schemaMap) internalValidate(topSchemaMap schemaMap, attrsOnly bool) erro
 				}
 
 				if err := schemaMap(t.SchemaMap()).internalValidate(topSchemaMap, attrsOnly); err != nil {
-					return err
+					return fmt.Errorf("%s: %w", k, err)
 				}
 			case *Schema:
 				bad := t.Computed || t.Optional || t.Required
@@ -1160,6 +1434,14 @@ func (m schemaMap) internalValidate(topSchemaMap schemaMap, attrsOnly bool) erro
 				return fmt.Errorf("%s: ExactlyOneOf is for configurable attributes,"+
 					"there's nothing to configure on computed-only field", k)
 			}
+			if v.ForceNew {
+				return fmt.Errorf("%s: ForceNew is for configurable attributes,"+
+					"there's nothing to configure on computed-only field", k)
+			}
+			if v.ForceNewFunc != nil {
+				return fmt.Errorf("%s: ForceNewFunc is for configurable attributes,"+
+					"there's nothing to configure on computed-only field", k)
+			}
 			if v.InputDefault != "" {
 				return fmt.Errorf("%s: InputDefault is for configurable attributes,"+
 					"there's nothing to configure on computed-only field", k)
@@ -1184,12 +1466,64 @@ func (m schemaMap) internalValidate(topSchemaMap schemaMap, attrsOnly bool) erro
 				return fmt.Errorf("%s: ValidateDiagFunc is for validating user input, "+
 					"there's nothing to validate on computed-only field", k)
 			}
+			if v.ValidateDiagContextFunc != nil {
+				return fmt.Errorf("%s: ValidateDiagContextFunc is for validating user input, "+
+					"there's nothing to validate on computed-only field", k)
+			}
+			if v.ValidateChangeFunc != nil {
+				return fmt.Errorf("%s: ValidateChangeFunc is for validating user input, "+
+					"there's nothing to validate on computed-only field", k)
+			}
+		}
+
+		if v.DefaultFromState {
+			if !v.Optional || v.Computed {
+				return fmt.Errorf("%s: DefaultFromState requires Optional without Computed", k)
+			}
+
+			if v.Default != nil || v.DefaultFunc != nil {
+				return fmt.Errorf("%s: DefaultFromState cannot be used with Default or DefaultFunc", k)
+			}
+
+			switch v.Type {
+			case TypeBool, TypeInt, TypeFloat, TypeString:
+			default:
+				return fmt.Errorf("%s: DefaultFromState is only supported on primitive attributes", k)
+			}
+		}
+
+		if v.ComputeIfUnset {
+			if !v.Optional || !v.Computed {
+				return fmt.Errorf("%s: ComputeIfUnset requires both Optional and Computed", k)
+			}
+
+			if !isComputeIfUnsetType(v.Type) {
+				return fmt.Errorf("%s: ComputeIfUnset is only supported on primitive attributes", k)
+			}
+
+			if topSchemaMap[k] != v {
+				return fmt.Errorf("%s: ComputeIfUnset is only supported on top-level attributes", k)
+			}
+		}
+
+		if v.IdentitySource != "" {
+			if !v.Computed {
+				return fmt.Errorf("%s: IdentitySource is only supported on Computed attributes", k)
+			}
+
+			if topSchemaMap[k] != v {
+				return fmt.Errorf("%s: IdentitySource is only supported on top-level attributes", k)
+			}
 		}
 
-		if v.ValidateFunc != nil || v.ValidateDiagFunc != nil {
+		if v.ValidateChangeFunc != nil && topSchemaMap[k] != v {
+			return fmt.Errorf("%s: ValidateChangeFunc is only supported on top-level attributes", k)
+		}
+
+		if v.ValidateFunc != nil || v.ValidateDiagFunc != nil || v.ValidateDiagContextFunc != nil {
 			switch v.Type {
 			case TypeList, TypeSet:
-				return fmt.Errorf("%s: ValidateFunc and ValidateDiagFunc are not yet supported on lists or sets.", k)
+				return fmt.Errorf("%s: ValidateFunc, ValidateDiagFunc and ValidateDiagContextFunc are not yet supported on lists or sets.", k)
 			}
 		}
 
@@ -1197,6 +1531,24 @@ func (m schemaMap) internalValidate(topSchemaMap schemaMap, attrsOnly bool) erro
 			return fmt.Errorf("%s: ValidateFunc and ValidateDiagFunc cannot both be set", k)
 		}
 
+		if v.ValidateDiagContextFunc != nil && (v.ValidateFunc != nil || v.ValidateDiagFunc != nil) {
+			return fmt.Errorf("%s: ValidateDiagContextFunc cannot be set with ValidateFunc or ValidateDiagFunc", k)
+		}
+
+		if len(v.DeprecatedValues) > 0 {
+			switch v.Type {
+			case TypeString:
+			case TypeInt:
+				for dv := range v.DeprecatedValues {
+					if _, err := strconv.Atoi(dv); err != nil {
+						return fmt.Errorf("%s: DeprecatedValues key %q is not a valid TypeInt value", k, dv)
+					}
+				}
+			default:
+				return fmt.Errorf("%s: DeprecatedValues is only supported for TypeString and TypeInt", k)
+			}
+		}
+
 		if v.Deprecated == "" {
 			if !isValidFieldName(k) {
 				return fmt.Errorf("%s: Field name may only contain lowercase alphanumeric characters & underscores.", k)
@@ -1204,47 +1556,178 @@ func (m schemaMap) internalValidate(topSchemaMap schemaMap, attrsOnly bool) erro
 		}
 	}
 
-	return nil
+	return m.validateOneOfGroups(topSchemaMap)
 }
 
-func checkKeysAgainstSchemaFlags(k string, keys []string, topSchemaMap schemaMap, self *Schema, allowSelfReference bool) error {
-	for _, key := range keys {
-		parts := strings.Split(key, ".")
-		sm := topSchemaMap
-		var target *Schema
-		for idx, part := range parts {
-			// Skip index fields if 0
-			partInt, err := strconv.Atoi(part)
-
-			if err == nil {
-				if partInt != 0 {
-					return fmt.Errorf("%s configuration block reference (%s) can only use the .0. index for TypeList and MaxItems: 1 configuration blocks", k, key)
-				}
+// oneOfGroup is an ExactlyOneOf or AtLeastOneOf group of attribute paths,
+// including the attribute declaring it.
+type oneOfGroup struct {
+	exactlyOne bool
+	keys       []string
+}
 
-				continue
-			}
+func (g oneOfGroup) String() string {
+	if g.exactlyOne {
+		return fmt.Sprintf("ExactlyOneOf %v", g.keys)
+	}
+	return fmt.Sprintf("AtLeastOneOf %v", g.keys)
+}
 
-			var ok bool
-			if target, ok = sm[part]; !ok {
-				return fmt.Errorf("%s references unknown attribute (%s) at part (%s)", k, key, part)
-			}
+// maxOneOfGroupKeys is the maximum number of attributes in overlapping
+// groups that validateOneOfGroups checks, as every combination of them is
+// tried.
+const maxOneOfGroupKeys = 16
 
-			subResource, ok := target.Elem.(*Resource)
+// validateOneOfGroups returns an error if the ExactlyOneOf and AtLeastOneOf
+// groups declared on top level attributes overlap in a way that no
+// configuration can satisfy all of them, such as ExactlyOneOf groups of
+// [a b], [a c] and [b c]. Groups on nested attributes are only enforced when
+// their block is present, so are not checked.
+func (m schemaMap) validateOneOfGroups(topSchemaMap schemaMap) error {
+	seen := make(map[string]struct{})
+	var groups []oneOfGroup
 
-			if !ok {
-				continue
+	addGroup := func(k string, keys []string, exactlyOne bool) {
+		if len(keys) == 0 {
+			return
+		}
+
+		g := oneOfGroup{
+			exactlyOne: exactlyOne,
+			keys:       removeDuplicates(append([]string{k}, keys...)),
+		}
+		sort.Strings(g.keys)
+
+		if _, ok := seen[g.String()]; ok {
+			return
+		}
+		seen[g.String()] = struct{}{}
+		groups = append(groups, g)
+	}
+
+	for k, v := range m {
+		if topSchemaMap[k] != v {
+			continue
+		}
+		addGroup(k, v.ExactlyOneOf, true)
+		addGroup(k, v.AtLeastOneOf, false)
+	}
+
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].String() < groups[j].String()
+	})
+
+	// Group the overlapping groups together, as groups without shared
+	// attributes can always be satisfied independently.
+	parent := make(map[string]string)
+	var find func(string) string
+	find = func(k string) string {
+		if p, ok := parent[k]; ok && p != k {
+			parent[k] = find(p)
+			return parent[k]
+		}
+		parent[k] = k
+		return k
+	}
+	for _, g := range groups {
+		for _, k := range g.keys[1:] {
+			parent[find(k)] = find(g.keys[0])
+		}
+	}
+
+	components := make(map[string][]oneOfGroup)
+	var roots []string
+	for _, g := range groups {
+		root := find(g.keys[0])
+		if _, ok := components[root]; !ok {
+			roots = append(roots, root)
+		}
+		components[root] = append(components[root], g)
+	}
+
+	for _, root := range roots {
+		component := components[root]
+		if len(component) < 2 {
+			continue
+		}
+
+		if err := validateOneOfComponent(component); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateOneOfComponent returns an error if no combination of configured
+// attributes satisfies all the given overlapping groups.
+func validateOneOfComponent(groups []oneOfGroup) error {
+	index := make(map[string]int)
+	var keys []string
+	for _, g := range groups {
+		for _, k := range g.keys {
+			if _, ok := index[k]; !ok {
+				index[k] = len(keys)
+				keys = append(keys, k)
 			}
+		}
+	}
+
+	if len(keys) > maxOneOfGroupKeys {
+		log.Printf("[WARN] Skipping validation of %d overlapping ExactlyOneOf and AtLeastOneOf attributes", len(keys))
+		return nil
+	}
 
-			// Skip Type/MaxItems check if not the last element
-			if (target.Type == TypeSet || target.MaxItems != 1) && idx+1 != len(parts) {
-				return fmt.Errorf("%s configuration block reference (%s) can only be used with TypeList and MaxItems: 1 configuration blocks", k, key)
+	masks := make([]uint32, len(groups))
+	for i, g := range groups {
+		for _, k := range g.keys {
+			masks[i] |= 1 << index[k]
+		}
+	}
+
+	for set := uint32(0); set < 1<<len(keys); set++ {
+		satisfied := true
+		for i, g := range groups {
+			n := bits.OnesCount32(set & masks[i])
+			if n == 0 || (g.exactlyOne && n > 1) {
+				satisfied = false
+				break
 			}
+		}
+		if satisfied {
+			return nil
+		}
+	}
 
-			sm = subResource.SchemaMap()
+	// Report the first attribute shared between groups.
+	counts := make(map[string]int)
+	for _, g := range groups {
+		for _, k := range g.keys {
+			counts[k]++
 		}
+	}
+	sort.Strings(keys)
+	var shared string
+	for _, k := range keys {
+		if counts[k] > 1 {
+			shared = k
+			break
+		}
+	}
 
-		if target == nil {
-			return fmt.Errorf("%s cannot find target attribute (%s), sm: %#v", k, key, sm)
+	descs := make([]string, len(groups))
+	for i, g := range groups {
+		descs[i] = g.String()
+	}
+
+	return fmt.Errorf("%s: overlapping ExactlyOneOf and AtLeastOneOf groups cannot all be satisfied: %s", shared, strings.Join(descs, ", "))
+}
+
+func checkKeysAgainstSchemaFlags(k string, keys []string, topSchemaMap schemaMap, self *Schema, allowSelfReference bool) error {
+	for _, key := range keys {
+		target, err := schemaFlagTarget(k, key, topSchemaMap)
+		if err != nil {
+			return err
 		}
 
 		if target == self && !allowSelfReference {
@@ -1263,6 +1746,50 @@ func checkKeysAgainstSchemaFlags(k string, keys []string, topSchemaMap schemaMap
 	return nil
 }
 
+// schemaFlagTarget returns the schema of the attribute referenced by key in a
+// schema flag of the k attribute, such as ConflictsWith.
+func schemaFlagTarget(k string, key string, topSchemaMap schemaMap) (*Schema, error) {
+	parts := strings.Split(key, ".")
+	sm := topSchemaMap
+	var target *Schema
+	for idx, part := range parts {
+		// Skip index fields if 0
+		partInt, err := strconv.Atoi(part)
+
+		if err == nil {
+			if partInt != 0 {
+				return nil, fmt.Errorf("%s configuration block reference (%s) can only use the .0. index for TypeList and MaxItems: 1 configuration blocks", k, key)
+			}
+
+			continue
+		}
+
+		var ok bool
+		if target, ok = sm[part]; !ok {
+			return nil, fmt.Errorf("%s references unknown attribute (%s) at part (%s)", k, key, part)
+		}
+
+		subResource, ok := target.Elem.(*Resource)
+
+		if !ok {
+			continue
+		}
+
+		// Skip Type/MaxItems check if not the last element
+		if (target.Type == TypeSet || target.MaxItems != 1) && idx+1 != len(parts) {
+			return nil, fmt.Errorf("%s configuration block reference (%s) can only be used with TypeList and MaxItems: 1 configuration blocks", k, key)
+		}
+
+		sm = subResource.SchemaMap()
+	}
+
+	if target == nil {
+		return nil, fmt.Errorf("%s cannot find target attribute (%s), sm: %#v", k, key, sm)
+	}
+
+	return target, nil
+}
+
 var validFieldNameRe = regexp.MustCompile("^[a-z0-9_]+$")
 
 func isValidFieldName(name string) bool {
@@ -1429,10 +1956,12 @@ func (m schemaMap) diffList(
 	switch t := schema.Elem.(type) {
 	case *Resource:
 		// This is a complex resource
+		nested := t.SchemaMap()
+		attrs := t.diffKeyAttrs(nested)
 		for i := 0; i < maxLen; i++ {
-			for k2, schema := range t.SchemaMap() {
-				subK := fmt.Sprintf("%s.%d.%s", k, i, k2)
-				err := m.diff(ctx, subK, schema, diff, d, all)
+			prefix := k + "." + strconv.Itoa(i)
+			for _, attr := range attrs {
+				err := m.diff(ctx, prefix+attr.suffix, nested[attr.name], diff, d, all)
 				if err != nil {
 					return err
 				}
@@ -1447,7 +1976,7 @@ func (m schemaMap) diffList(
 		// This is just a primitive element, so go through each and
 		// just diff each.
 		for i := 0; i < maxLen; i++ {
-			subK := fmt.Sprintf("%s.%d", k, i)
+			subK := k + "." + strconv.Itoa(i)
 			err := m.diff(ctx, subK, &t2, diff, d, all)
 			if err != nil {
 				return err
@@ -1672,14 +2201,22 @@ func (m schemaMap) diffSet(
 	codes := make([][]string, 2)
 	codes[0] = os.Difference(ns).listCode()
 	codes[1] = ns.listCode()
+
+	var nested map[string]*Schema
+	var attrs []diffKeyAttr
+	if t, ok := schema.Elem.(*Resource); ok {
+		nested = t.SchemaMap()
+		attrs = t.diffKeyAttrs(nested)
+	}
+
 	for _, list := range codes {
 		for _, code := range list {
 			switch t := schema.Elem.(type) {
 			case *Resource:
 				// This is a complex resource
-				for k2, schema := range t.SchemaMap() {
-					subK := fmt.Sprintf("%s.%s.%s", k, code, k2)
-					err := m.diff(ctx, subK, schema, diff, d, true)
+				prefix := k + "." + code
+				for _, attr := range attrs {
+					err := m.diff(ctx, prefix+attr.suffix, nested[attr.name], diff, d, true)
 					if err != nil {
 						return err
 					}
@@ -1692,7 +2229,7 @@ func (m schemaMap) diffSet(
 
 				// This is just a primitive element, so go through each and
 				// just diff each.
-				subK := fmt.Sprintf("%s.%s", k, code)
+				subK := k + "." + code
 				err := m.diff(ctx, subK, &t2, diff, d, true)
 				if err != nil {
 					return err
@@ -1747,7 +2284,7 @@ func (m schemaMap) diffString(
 	if o != nil && n == nil && !computed {
 		removed = true
 	}
-	if removed && schema.Computed {
+	if removed && (schema.Computed || schema.DefaultFromState && d.Id() != "") {
 		return nil
 	}
 
@@ -1823,6 +2360,7 @@ func (m schemaMapWithIdentity) handleDiffSuppressOnRefresh(ctx context.Context,
 }
 
 func (m schemaMap) validate(
+	ctx context.Context,
 	k string,
 	schema *Schema,
 	c *terraform.ResourceConfig,
@@ -1904,8 +2442,13 @@ func (m schemaMap) validate(
 	// In particular, this avoids spurious type errors where downstream
 	// validation code sees UnknownVariableValue as being just a string.
 	// The SDK has to allow the unknown value through initially, so that
-	// Required fields set via an interpolated value are accepted.
+	// Required fields set via an interpolated value are accepted. The item
+	// count of lists and sets can still be validated against the structured
+	// configuration.
 	if !isWhollyKnown(raw) {
+		if schema.Type == TypeList || schema.Type == TypeSet {
+			return m.validateList(ctx, k, raw, schema, c, path)
+		}
 		return nil
 	}
 
@@ -1919,7 +2462,7 @@ func (m schemaMap) validate(
 		})
 	}
 
-	return m.validateType(k, raw, schema, c, path)
+	return m.validateType(ctx, k, raw, schema, c, path)
 }
 
 // isWhollyKnown returns false if the argument contains an UnknownVariableValue
@@ -2063,6 +2606,7 @@ func validateAtLeastOneAttribute(
 }
 
 func (m schemaMap) validateList(
+	ctx context.Context,
 	k string,
 	raw interface{},
 	schema *Schema,
@@ -2095,34 +2639,46 @@ func (m schemaMap) validateList(
 		})
 	}
 
-	// We can't validate list length if this came from a dynamic block.
-	// Since there's no way to determine if something was from a dynamic block
-	// at this point, we're going to skip validation in the new protocol if
-	// there are any unknowns. Validate will eventually be called again once
-	// all values are known.
-	if !isWhollyKnown(raw) {
-		return diags
+	// Prefer the length of the structured configuration value, which counts
+	// elements with unknown values correctly. The flatmap configuration can
+	// collapse set elements whose unknown values hash the same.
+	length, ok := configCollectionLength(c, path)
+	if !ok {
+		// We can't validate list length if this came from a dynamic block.
+		// Since there's no way to determine if something was from a dynamic
+		// block at this point, we're going to skip validation in the new
+		// protocol if there are any unknowns. Validate will eventually be
+		// called again once all values are known.
+		if !isWhollyKnown(raw) {
+			return diags
+		}
+
+		length = rawV.Len()
 	}
 
 	// Validate length
-	if schema.MaxItems > 0 && rawV.Len() > schema.MaxItems {
+	if schema.MaxItems > 0 && length > schema.MaxItems {
 		return append(diags, diag.Diagnostic{
 			Severity:      diag.Error,
 			Summary:       "Too many list items",
-			Detail:        fmt.Sprintf("Attribute %s supports %d item maximum, but config has %d declared.", k, schema.MaxItems, rawV.Len()),
+			Detail:        fmt.Sprintf("Attribute %s supports %d item maximum, but config has %d declared.", k, schema.MaxItems, length),
 			AttributePath: path,
 		})
 	}
 
-	if schema.MinItems > 0 && rawV.Len() < schema.MinItems {
+	if schema.MinItems > 0 && length < schema.MinItems {
 		return append(diags, diag.Diagnostic{
 			Severity:      diag.Error,
 			Summary:       "Not enough list items",
-			Detail:        fmt.Sprintf("Attribute %s requires %d item minimum, but config has only %d declared.", k, schema.MinItems, rawV.Len()),
+			Detail:        fmt.Sprintf("Attribute %s requires %d item minimum, but config has only %d declared.", k, schema.MinItems, length),
 			AttributePath: path,
 		})
 	}
 
+	if !isWhollyKnown(raw) {
+		return diags
+	}
+
 	// Now build the []interface{}
 	raws := make([]interface{}, rawV.Len())
 	for i := range raws {
@@ -2144,9 +2700,9 @@ func (m schemaMap) validateList(
 		switch t := schema.Elem.(type) {
 		case *Resource:
 			// This is a sub-resource
-			diags = append(diags, m.validateObject(key, t.SchemaMap(), c, p)...)
+			diags = append(diags, m.validateObject(ctx, key, t.SchemaMap(), c, p)...)
 		case *Schema:
-			diags = append(diags, m.validateType(key, raw, t, c, p)...)
+			diags = append(diags, m.validateType(ctx, key, raw, t, c, p)...)
 		}
 
 	}
@@ -2154,7 +2710,29 @@ func (m schemaMap) validateList(
 	return diags
 }
 
+// configCollectionLength returns the number of elements of the list or set
+// at the given path of the structured configuration, if it is available and
+// known. Dynamic blocks with an unknown for_each produce a wholly unknown
+// collection, whose length cannot be determined until apply.
+func configCollectionLength(c *terraform.ResourceConfig, path cty.Path) (int, bool) {
+	if c == nil || c.CtyValue == cty.NilVal || len(path) == 0 {
+		return 0, false
+	}
+
+	v, err := path.Apply(c.CtyValue)
+	if err != nil || !v.IsKnown() || v.IsNull() {
+		return 0, false
+	}
+
+	if !v.Type().IsListType() && !v.Type().IsSetType() {
+		return 0, false
+	}
+
+	return v.LengthInt(), true
+}
+
 func (m schemaMap) validateMap(
+	ctx context.Context,
 	k string,
 	raw interface{},
 	schema *Schema,
@@ -2209,7 +2787,7 @@ func (m schemaMap) validateMap(
 			return diags
 		}
 
-		return schema.validateFunc(mapIface, k, path)
+		return schema.validateFunc(ctx, mapIface, k, path)
 	}
 
 	// It is a slice, verify that all the elements are maps
@@ -2241,7 +2819,7 @@ func (m schemaMap) validateMap(
 		}
 	}
 
-	return schema.validateFunc(validatableMap, k, path)
+	return schema.validateFunc(ctx, validatableMap, k, path)
 }
 
 func validateMapValues(k string, m map[string]interface{}, schema *Schema, path cty.Path) diag.Diagnostics {
@@ -2327,6 +2905,7 @@ func getValueType(k string, schema *Schema) (ValueType, error) {
 }
 
 func (m schemaMap) validateObject(
+	ctx context.Context,
 	k string,
 	schema map[string]*Schema,
 	c *terraform.ResourceConfig,
@@ -2355,7 +2934,7 @@ func (m schemaMap) validateObject(
 		if k != "" {
 			key = fmt.Sprintf("%s.%s", k, subK)
 		}
-		diags = append(diags, m.validate(key, s, c, append(path, cty.GetAttrStep{Name: subK}))...)
+		diags = append(diags, m.validate(ctx, key, s, c, append(path, cty.GetAttrStep{Name: subK}))...)
 	}
 
 	// Detect any extra/unknown keys and report those as errors.
@@ -2378,6 +2957,7 @@ func (m schemaMap) validateObject(
 }
 
 func (m schemaMap) validatePrimitive(
+	ctx context.Context,
 	k string,
 	raw interface{},
 	schema *Schema,
@@ -2482,10 +3062,24 @@ func (m schemaMap) validatePrimitive(
 		panic(fmt.Sprintf("Unknown validation type: %#v", schema.Type))
 	}
 
-	return append(diags, schema.validateFunc(decoded, k, path)...)
+	diags = append(diags, schema.validateFunc(ctx, decoded, k, path)...)
+
+	if len(schema.DeprecatedValues) > 0 {
+		if detail, ok := schema.DeprecatedValues[fmt.Sprintf("%v", decoded)]; ok {
+			diags = append(diags, diag.Diagnostic{
+				Severity:      diag.Warning,
+				Summary:       "Argument value is deprecated",
+				Detail:        detail,
+				AttributePath: path,
+			})
+		}
+	}
+
+	return diags
 }
 
 func (m schemaMap) validateType(
+	ctx context.Context,
 	k string,
 	raw interface{},
 	schema *Schema,
@@ -2495,11 +3089,11 @@ func (m schemaMap) validateType(
 	var diags diag.Diagnostics
 	switch schema.Type {
 	case TypeList:
-		diags = m.validateList(k, raw, schema, c, path)
+		diags = m.validateList(ctx, k, raw, schema, c, path)
 	case TypeSet:
 		// indexing into sets is not representable in the current protocol
 		// best we can do is associate the path up to this attribute.
-		diags = m.validateList(k, raw, schema, c, path)
+		diags = m.validateList(ctx, k, raw, schema, c, path)
 		if len(diags) > 0 {
 			log.Printf("[WARN] Truncating attribute path of %d diagnostics for TypeSet", len(diags))
 			for i := range diags {
@@ -2507,9 +3101,9 @@ func (m schemaMap) validateType(
 			}
 		}
 	case TypeMap:
-		diags = m.validateMap(k, raw, schema, c, path)
+		diags = m.validateMap(ctx, k, raw, schema, c, path)
 	default:
-		diags = m.validatePrimitive(k, raw, schema, c, path)
+		diags = m.validatePrimitive(ctx, k, raw, schema, c, path)
 	}
 
 	if schema.Deprecated != "" {
@@ -2554,6 +3148,117 @@ func (m schemaMap) hasWriteOnly() bool {
 	return false
 }
 
+// setHashOptionalComputedAttrs returns the paths of Optional and Computed
+// attributes within TypeSet element schemas that use the default hash
+// function. Since the SDK does not know the final value of these attributes
+// during plan, including them in the hash can cause unstable set hashing and
+// unexpected plan differences.
+func (m schemaMap) setHashOptionalComputedAttrs() []string {
+	var result []string
+
+	for k, v := range m {
+		elem, ok := v.Elem.(*Resource)
+		if !ok {
+			continue
+		}
+
+		elemSchema := schemaMap(elem.SchemaMap())
+
+		if v.Type == TypeSet && v.Set == nil {
+			for ek, ev := range elemSchema {
+				if ev.Optional && ev.Computed {
+					result = append(result, k+"."+ek)
+				}
+			}
+		}
+
+		for _, nested := range elemSchema.setHashOptionalComputedAttrs() {
+			result = append(result, k+"."+nested)
+		}
+	}
+
+	sort.Strings(result)
+
+	return result
+}
+
+// emptyNestedBlocks returns the paths of all nested blocks in the schema
+// whose element Resource has no attributes.
+func (m schemaMap) emptyNestedBlocks() []string {
+	var result []string
+
+	for k, v := range m {
+		elem, ok := v.Elem.(*Resource)
+		if !ok {
+			continue
+		}
+
+		elemSchema := schemaMap(elem.SchemaMap())
+
+		if len(elemSchema) == 0 {
+			result = append(result, k)
+		}
+
+		for _, nested := range elemSchema.emptyNestedBlocks() {
+			result = append(result, k+"."+nested)
+		}
+	}
+
+	sort.Strings(result)
+
+	return result
+}
+
+// nestedTimeoutsAttrs returns the paths of all nested blocks in the schema
+// whose element Resource sets Timeouts, which are only supported on
+// top-level resources.
+func (m schemaMap) nestedTimeoutsAttrs() []string {
+	var result []string
+
+	for k, v := range m {
+		elem, ok := v.Elem.(*Resource)
+		if !ok {
+			continue
+		}
+
+		if elem.Timeouts != nil {
+			result = append(result, k)
+		}
+
+		for _, nested := range schemaMap(elem.SchemaMap()).nestedTimeoutsAttrs() {
+			result = append(result, k+"."+nested)
+		}
+	}
+
+	sort.Strings(result)
+
+	return result
+}
+
+// deprecatedAttrs returns the Deprecated message of every attribute and
+// block in the schema, including those nested within blocks, keyed by
+// dot-separated attribute path.
+func (m schemaMap) deprecatedAttrs() map[string]string {
+	result := make(map[string]string)
+
+	for k, v := range m {
+		if v.Deprecated != "" {
+			result[k] = v.Deprecated
+		}
+
+		elem, ok := v.Elem.(*Resource)
+		if !ok {
+			continue
+		}
+
+		for nk, nv := range schemaMap(elem.SchemaMap()).deprecatedAttrs() {
+			result[k+"."+nk] = nv
+		}
+	}
+
+	return result
+}
+
 // Zero returns the zero value for a type.
 func (t ValueType) Zero() interface{} {
 	switch t {
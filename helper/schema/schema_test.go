@@ -1269,16 +1269,16 @@ func TestSchemaMap_Diff(t *testing.T) {
 			Err: false,
 		},
 
-		/* TODO
 		{
+			Name: "ComputedWhen triggered",
 			Schema: map[string]*Schema{
-				"availability_zone": &Schema{
+				"availability_zone": {
 					Type:         TypeString,
 					Computed:     true,
 					ComputedWhen: []string{"port"},
 				},
 
-				"port": &Schema{
+				"port": {
 					Type:     TypeInt,
 					Optional: true,
 				},
@@ -1292,16 +1292,411 @@ func TestSchemaMap_Diff(t *testing.T) {
 			},
 
 			Config: map[string]interface{}{
-				"port": 8080,
+				"port": 8080,
+			},
+
+			Diff: &terraform.InstanceDiff{
+				Attributes: map[string]*terraform.ResourceAttrDiff{
+					"availability_zone": {
+						Old:         "foo",
+						NewComputed: true,
+					},
+					"port": {
+						Old: "80",
+						New: "8080",
+					},
+				},
+			},
+
+			Err: false,
+		},
+
+		{
+			Name: "ForceNewFunc not triggered",
+			Schema: map[string]*Schema{
+				"description": {
+					Type:     TypeString,
+					Optional: true,
+					ForceNewFunc: func(_ context.Context, oldValue, newValue interface{}) bool {
+						return oldValue.(string) != "" && newValue.(string) == ""
+					},
+				},
+			},
+
+			State: &terraform.InstanceState{
+				ID: "id",
+				Attributes: map[string]string{
+					"description": "foo",
+				},
+			},
+
+			Config: map[string]interface{}{
+				"description": "bar",
+			},
+
+			Diff: &terraform.InstanceDiff{
+				Attributes: map[string]*terraform.ResourceAttrDiff{
+					"description": {
+						Old: "foo",
+						New: "bar",
+					},
+				},
+			},
+
+			Err: false,
+		},
+
+		{
+			Name: "ForceNewFunc triggered",
+			Schema: map[string]*Schema{
+				"description": {
+					Type:     TypeString,
+					Optional: true,
+					ForceNewFunc: func(_ context.Context, oldValue, newValue interface{}) bool {
+						return oldValue.(string) != "" && newValue.(string) == ""
+					},
+				},
+			},
+
+			State: &terraform.InstanceState{
+				ID: "id",
+				Attributes: map[string]string{
+					"description": "foo",
+				},
+			},
+
+			Config: map[string]interface{}{},
+
+			Diff: &terraform.InstanceDiff{
+				Attributes: map[string]*terraform.ResourceAttrDiff{
+					"description": {
+						Old:         "foo",
+						New:         "",
+						NewRemoved:  true,
+						RequiresNew: true,
+					},
+				},
+			},
+
+			Err: false,
+		},
+
+		{
+			Name: "ForceNewFunc triggered by CustomizeDiff",
+			Schema: map[string]*Schema{
+				"size": {
+					Type:     TypeInt,
+					Optional: true,
+					Computed: true,
+					ForceNewFunc: func(_ context.Context, oldValue, newValue interface{}) bool {
+						return newValue.(int) < oldValue.(int)
+					},
+				},
+			},
+
+			State: &terraform.InstanceState{
+				ID: "id",
+				Attributes: map[string]string{
+					"size": "10",
+				},
+			},
+
+			Config: map[string]interface{}{},
+
+			CustomizeDiff: func(_ context.Context, d *ResourceDiff, meta interface{}) error {
+				return d.SetNew("size", 5)
+			},
+
+			Diff: &terraform.InstanceDiff{
+				Attributes: map[string]*terraform.ResourceAttrDiff{
+					"size": {
+						Old:         "10",
+						New:         "5",
+						RequiresNew: true,
+					},
+				},
+			},
+
+			Err: false,
+		},
+
+		{
+			Name: "StableSeedFunc on create",
+			Schema: map[string]*Schema{
+				"name": {
+					Type:     TypeString,
+					Required: true,
+				},
+
+				"suffix": {
+					Type:     TypeString,
+					Computed: true,
+					StableSeedFunc: func(d *ResourceData) string {
+						return d.Get("name").(string)
+					},
+				},
+			},
+
+			State: nil,
+
+			Config: map[string]interface{}{
+				"name": "foo",
+			},
+
+			Diff: &terraform.InstanceDiff{
+				Attributes: map[string]*terraform.ResourceAttrDiff{
+					"name": {
+						Old: "",
+						New: "foo",
+					},
+					"suffix": {
+						Old: "",
+						New: stableSeedValue("foo"),
+					},
+				},
+			},
+
+			Err: false,
+		},
+
+		{
+			Name: "StableSeedFunc with unchanged seed",
+			Schema: map[string]*Schema{
+				"name": {
+					Type:     TypeString,
+					Required: true,
+				},
+
+				"suffix": {
+					Type:     TypeString,
+					Computed: true,
+					StableSeedFunc: func(d *ResourceData) string {
+						return d.Get("name").(string)
+					},
+				},
+			},
+
+			State: &terraform.InstanceState{
+				ID: "id",
+				Attributes: map[string]string{
+					"name":   "foo",
+					"suffix": stableSeedValue("foo"),
+				},
+			},
+
+			Config: map[string]interface{}{
+				"name": "foo",
+			},
+
+			Diff: nil,
+
+			Err: false,
+		},
+
+		{
+			Name: "StableSeedFunc with changed seed",
+			Schema: map[string]*Schema{
+				"name": {
+					Type:     TypeString,
+					Required: true,
+				},
+
+				"suffix": {
+					Type:     TypeString,
+					Computed: true,
+					StableSeedFunc: func(d *ResourceData) string {
+						return d.Get("name").(string)
+					},
+				},
+			},
+
+			State: &terraform.InstanceState{
+				ID: "id",
+				Attributes: map[string]string{
+					"name":   "foo",
+					"suffix": stableSeedValue("foo"),
+				},
+			},
+
+			Config: map[string]interface{}{
+				"name": "bar",
+			},
+
+			Diff: &terraform.InstanceDiff{
+				Attributes: map[string]*terraform.ResourceAttrDiff{
+					"name": {
+						Old: "foo",
+						New: "bar",
+					},
+					"suffix": {
+						Old: stableSeedValue("foo"),
+						New: stableSeedValue("bar"),
+					},
+				},
+			},
+
+			Err: false,
+		},
+
+		{
+			Name: "StableSeedFunc with changed seed forcing new",
+			Schema: map[string]*Schema{
+				"name": {
+					Type:     TypeString,
+					Required: true,
+					ForceNew: true,
+				},
+
+				"suffix": {
+					Type:     TypeString,
+					Computed: true,
+					StableSeedFunc: func(d *ResourceData) string {
+						return d.Get("name").(string)
+					},
+				},
+			},
+
+			State: &terraform.InstanceState{
+				ID: "id",
+				Attributes: map[string]string{
+					"name":   "foo",
+					"suffix": stableSeedValue("foo"),
+				},
+			},
+
+			Config: map[string]interface{}{
+				"name": "bar",
+			},
+
+			Diff: &terraform.InstanceDiff{
+				Attributes: map[string]*terraform.ResourceAttrDiff{
+					"name": {
+						Old:         "foo",
+						New:         "bar",
+						RequiresNew: true,
+					},
+					"suffix": {
+						Old: stableSeedValue("foo"),
+						New: stableSeedValue("bar"),
+					},
+				},
+			},
+
+			Err: false,
+		},
+
+		{
+			Name: "StableSeedFunc with empty seed",
+			Schema: map[string]*Schema{
+				"name": {
+					Type:     TypeString,
+					Optional: true,
+				},
+
+				"suffix": {
+					Type:     TypeString,
+					Computed: true,
+					StableSeedFunc: func(d *ResourceData) string {
+						return d.Get("name").(string)
+					},
+				},
+			},
+
+			State: &terraform.InstanceState{
+				ID: "id",
+				Attributes: map[string]string{
+					"suffix": "abc",
+				},
+			},
+
+			Config: map[string]interface{}{},
+
+			Diff: nil,
+
+			Err: false,
+		},
+
+		{
+			Name: "StableSeedFunc with empty seed on create",
+			Schema: map[string]*Schema{
+				"name": {
+					Type:     TypeString,
+					Optional: true,
+				},
+
+				"suffix": {
+					Type:     TypeString,
+					Computed: true,
+					StableSeedFunc: func(d *ResourceData) string {
+						return d.Get("name").(string)
+					},
+				},
+			},
+
+			State: nil,
+
+			Config: map[string]interface{}{},
+
+			Diff: &terraform.InstanceDiff{
+				Attributes: map[string]*terraform.ResourceAttrDiff{
+					"suffix": {
+						Old:         "",
+						NewComputed: true,
+					},
+				},
+			},
+
+			Err: false,
+		},
+
+		{
+			Name: "ComputedWhen triggered by nested block",
+			Schema: map[string]*Schema{
+				"endpoints": {
+					Type:         TypeList,
+					Computed:     true,
+					ComputedWhen: []string{"listener"},
+					Elem:         &Schema{Type: TypeString},
+				},
+
+				"listener": {
+					Type:     TypeList,
+					Optional: true,
+					Elem: &Resource{
+						Schema: map[string]*Schema{
+							"port": {
+								Type:     TypeInt,
+								Optional: true,
+							},
+						},
+					},
+				},
+			},
+
+			State: &terraform.InstanceState{
+				Attributes: map[string]string{
+					"endpoints.#":     "1",
+					"endpoints.0":     "foo:80",
+					"listener.#":      "1",
+					"listener.0.port": "80",
+				},
+			},
+
+			Config: map[string]interface{}{
+				"listener": []interface{}{
+					map[string]interface{}{
+						"port": 8080,
+					},
+				},
 			},
 
-			Diff: &terraform.ResourceDiff{
+			Diff: &terraform.InstanceDiff{
 				Attributes: map[string]*terraform.ResourceAttrDiff{
-					"availability_zone": &terraform.ResourceAttrDiff{
-						Old:         "foo",
+					"endpoints.#": {
+						Old:         "1",
 						NewComputed: true,
 					},
-					"port": &terraform.ResourceAttrDiff{
+					"listener.0.port": {
 						Old: "80",
 						New: "8080",
 					},
@@ -1310,7 +1705,6 @@ func TestSchemaMap_Diff(t *testing.T) {
 
 			Err: false,
 		},
-		*/
 
 		{
 			Name: "Maps",
@@ -3273,6 +3667,150 @@ func TestSchemaMap_InternalValidate(t *testing.T) {
 			true,
 		},
 
+		"ComputedWhen references existing attribute": {
+			map[string]*Schema{
+				"port": {
+					Type:     TypeInt,
+					Required: true,
+				},
+				"endpoint": {
+					Type:         TypeString,
+					Computed:     true,
+					ComputedWhen: []string{"port"},
+				},
+			},
+			false,
+		},
+
+		"ComputedWhen references unknown attribute": {
+			map[string]*Schema{
+				"endpoint": {
+					Type:         TypeString,
+					Computed:     true,
+					ComputedWhen: []string{"port"},
+				},
+			},
+			true,
+		},
+
+		"ComputedWhen cannot reference self": {
+			map[string]*Schema{
+				"endpoint": {
+					Type:         TypeString,
+					Computed:     true,
+					ComputedWhen: []string{"endpoint"},
+				},
+			},
+			true,
+		},
+
+		"ComputedWhen cannot reference ComputedWhen attribute": {
+			map[string]*Schema{
+				"port": {
+					Type:     TypeInt,
+					Optional: true,
+				},
+				"address": {
+					Type:         TypeString,
+					Computed:     true,
+					ComputedWhen: []string{"port"},
+				},
+				"endpoint": {
+					Type:         TypeString,
+					Computed:     true,
+					ComputedWhen: []string{"address"},
+				},
+			},
+			true,
+		},
+
+		"StableSeedFunc on computed-only attribute": {
+			map[string]*Schema{
+				"suffix": {
+					Type:           TypeString,
+					Computed:       true,
+					StableSeedFunc: func(d *ResourceData) string { return "" },
+				},
+			},
+			false,
+		},
+
+		"StableSeedFunc with Optional": {
+			map[string]*Schema{
+				"suffix": {
+					Type:           TypeString,
+					Optional:       true,
+					Computed:       true,
+					StableSeedFunc: func(d *ResourceData) string { return "" },
+				},
+			},
+			true,
+		},
+
+		"StableSeedFunc without Computed": {
+			map[string]*Schema{
+				"suffix": {
+					Type:           TypeString,
+					Optional:       true,
+					StableSeedFunc: func(d *ResourceData) string { return "" },
+				},
+			},
+			true,
+		},
+
+		"StableSeedFunc on non-string attribute": {
+			map[string]*Schema{
+				"suffix": {
+					Type:           TypeInt,
+					Computed:       true,
+					StableSeedFunc: func(d *ResourceData) string { return "" },
+				},
+			},
+			true,
+		},
+
+		"StableSeedFunc is only supported on top-level attributes": {
+			map[string]*Schema{
+				"block": {
+					Type:     TypeList,
+					Optional: true,
+					Elem: &Resource{
+						Schema: map[string]*Schema{
+							"suffix": {
+								Type:           TypeString,
+								Computed:       true,
+								StableSeedFunc: func(d *ResourceData) string { return "" },
+							},
+						},
+					},
+				},
+			},
+			true,
+		},
+
+		"ComputedWhen is only supported on top-level attributes": {
+			map[string]*Schema{
+				"port": {
+					Type:     TypeInt,
+					Optional: true,
+				},
+				"block": {
+					Type:     TypeList,
+					Optional: true,
+					Elem: &Resource{
+						Schema: map[string]*Schema{
+							"endpoint": {
+								Type:         TypeString,
+								Computed:     true,
+								ComputedWhen: []string{"port"},
+							},
+						},
+					},
+				},
+			},
+			true,
+		},
+
 		"Conflicting attributes cannot be required": {
 			map[string]*Schema{
 				"blacklist": {
@@ -3995,6 +4533,73 @@ func TestSchemaMap_InternalValidate(t *testing.T) {
 			true,
 		},
 
+		"ExactlyOneOf consistent overlapping groups": {
+			map[string]*Schema{
+				"a": {
+					Type:         TypeString,
+					Optional:     true,
+					ExactlyOneOf: []string{"a", "b"},
+				},
+				"b": {
+					Type:         TypeString,
+					Optional:     true,
+					ExactlyOneOf: []string{"a", "b", "c"},
+				},
+				"c": {
+					Type:         TypeString,
+					Optional:     true,
+					AtLeastOneOf: []string{"c", "d"},
+				},
+				"d": {
+					Type:     TypeString,
+					Optional: true,
+				},
+			},
+			false,
+		},
+
+		"ExactlyOneOf contradictory overlapping groups": {
+			map[string]*Schema{
+				"a": {
+					Type:         TypeString,
+					Optional:     true,
+					ExactlyOneOf: []string{"b"},
+				},
+				"b": {
+					Type:         TypeString,
+					Optional:     true,
+					ExactlyOneOf: []string{"c"},
+				},
+				"c": {
+					Type:         TypeString,
+					Optional:     true,
+					ExactlyOneOf: []string{"a"},
+				},
+			},
+			true,
+		},
+
+		"ExactlyOneOf contradicting AtLeastOneOf": {
+			map[string]*Schema{
+				"a": {
+					Type:         TypeString,
+					Optional:     true,
+					ExactlyOneOf: []string{"b"},
+				},
+				"b": {
+					Type:         TypeString,
+					Optional:     true,
+					ExactlyOneOf: []string{"a", "c"},
+				},
+				"c": {
+					Type:         TypeString,
+					Optional:     true,
+					AtLeastOneOf: []string{"c"},
+				},
+			},
+			true,
+		},
+
 		"ExactlyOneOf list index syntax with self reference": {
 			map[string]*Schema{
 				"config_block_attr": {
@@ -4809,38 +5414,164 @@ func TestSchemaMap_InternalValidate(t *testing.T) {
 			true,
 		},
 
-		"DiffSuppressOnRefresh with DiffSuppressFunc": {
+		"DiffSuppressOnRefresh with DiffSuppressFunc": {
+			map[string]*Schema{
+				"string": {
+					Type:                  TypeString,
+					Optional:              true,
+					DiffSuppressFunc:      func(k, oldValue, newValue string, d *ResourceData) bool { return false },
+					DiffSuppressOnRefresh: true,
+				},
+			},
+			false,
+		},
+
+		"Computed-only with ExactlyOneOf": {
+			map[string]*Schema{
+				"string_one": {
+					Type:     TypeString,
+					Computed: true,
+					ExactlyOneOf: []string{
+						"string_one",
+						"string_two",
+					},
+				},
+				"string_two": {
+					Type:     TypeString,
+					Computed: true,
+					ExactlyOneOf: []string{
+						"string_one",
+						"string_two",
+					},
+				},
+			},
+			true,
+		},
+
+		"DefaultFromState with Optional": {
+			map[string]*Schema{
+				"string": {
+					Type:             TypeString,
+					Optional:         true,
+					DefaultFromState: true,
+				},
+			},
+			false,
+		},
+
+		"DefaultFromState with Computed": {
+			map[string]*Schema{
+				"string": {
+					Type:             TypeString,
+					Optional:         true,
+					Computed:         true,
+					DefaultFromState: true,
+				},
+			},
+			true,
+		},
+
+		"DefaultFromState with Default": {
+			map[string]*Schema{
+				"string": {
+					Type:             TypeString,
+					Optional:         true,
+					Default:          "foo",
+					DefaultFromState: true,
+				},
+			},
+			true,
+		},
+
+		"DefaultFromState with TypeMap": {
+			map[string]*Schema{
+				"map": {
+					Type:             TypeMap,
+					Optional:         true,
+					DefaultFromState: true,
+					Elem:             &Schema{Type: TypeString},
+				},
+			},
+			true,
+		},
+
+		"ComputeIfUnset with Optional and Computed": {
+			map[string]*Schema{
+				"string": {
+					Type:           TypeString,
+					Optional:       true,
+					Computed:       true,
+					ComputeIfUnset: true,
+				},
+			},
+			false,
+		},
+
+		"ComputeIfUnset without Computed": {
+			map[string]*Schema{
+				"string": {
+					Type:           TypeString,
+					Optional:       true,
+					ComputeIfUnset: true,
+				},
+			},
+			true,
+		},
+
+		"ComputeIfUnset with TypeList": {
+			map[string]*Schema{
+				"list": {
+					Type:           TypeList,
+					Optional:       true,
+					Computed:       true,
+					ComputeIfUnset: true,
+					Elem:           &Schema{Type: TypeString},
+				},
+			},
+			true,
+		},
+
+		"ComputeIfUnset nested": {
+			map[string]*Schema{
+				"block": {
+					Type:     TypeList,
+					Optional: true,
+					Elem: &Resource{
+						Schema: map[string]*Schema{
+							"string": {
+								Type:           TypeString,
+								Optional:       true,
+								Computed:       true,
+								ComputeIfUnset: true,
+							},
+						},
+					},
+				},
+			},
+			true,
+		},
+
+		"Computed-only with ForceNew": {
 			map[string]*Schema{
 				"string": {
-					Type:                  TypeString,
-					Optional:              true,
-					DiffSuppressFunc:      func(k, oldValue, newValue string, d *ResourceData) bool { return false },
-					DiffSuppressOnRefresh: true,
+					Type:     TypeString,
+					Computed: true,
+					ForceNew: true,
 				},
 			},
-			false,
+			true,
 		},
 
-		"Computed-only with ExactlyOneOf": {
+		"Optional and Computed with ForceNew": {
 			map[string]*Schema{
-				"string_one": {
-					Type:     TypeString,
-					Computed: true,
-					ExactlyOneOf: []string{
-						"string_one",
-						"string_two",
-					},
-				},
-				"string_two": {
+				"string": {
 					Type:     TypeString,
+					Optional: true,
 					Computed: true,
-					ExactlyOneOf: []string{
-						"string_one",
-						"string_two",
-					},
+					ForceNew: true,
 				},
 			},
-			true,
+			false,
 		},
 
 		"Computed-only with InputDefault": {
@@ -4931,6 +5662,78 @@ func TestSchemaMap_InternalValidate(t *testing.T) {
 			false,
 		},
 
+		"invalid field name format with hyphen": {
+			map[string]*Schema{
+				"with-hyphen": {
+					Type:     TypeString,
+					Optional: true,
+				},
+			},
+			true,
+		},
+
+		"valid nested field names": {
+			map[string]*Schema{
+				"block": {
+					Type:     TypeList,
+					Optional: true,
+					Elem: &Resource{
+						Schema: map[string]*Schema{
+							"with_underscores_123": {
+								Type:     TypeString,
+								Optional: true,
+							},
+						},
+					},
+				},
+			},
+			false,
+		},
+
+		"invalid nested field name format with hyphen": {
+			map[string]*Schema{
+				"block": {
+					Type:     TypeList,
+					Optional: true,
+					Elem: &Resource{
+						Schema: map[string]*Schema{
+							"with-hyphen": {
+								Type:     TypeString,
+								Optional: true,
+							},
+						},
+					},
+				},
+			},
+			true,
+		},
+
+		"invalid nested field name format with capitals": {
+			map[string]*Schema{
+				"block": {
+					Type:     TypeSet,
+					Optional: true,
+					Elem: &Resource{
+						Schema: map[string]*Schema{
+							"nested": {
+								Type:     TypeList,
+								Optional: true,
+								Elem: &Resource{
+									Schema: map[string]*Schema{
+										"WithCapitals": {
+											Type:     TypeString,
+											Optional: true,
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			true,
+		},
+
 		"ConfigModeBlock with Elem *Resource": {
 			map[string]*Schema{
 				"block": {
@@ -5053,6 +5856,125 @@ func TestSchemaMap_InternalValidate(t *testing.T) {
 			true,
 		},
 
+		"ValidateDiagContextFunc and ValidateDiagFunc cannot both be set": {
+			map[string]*Schema{
+				"foo": {
+					Type:     TypeInt,
+					Required: true,
+					ValidateDiagFunc: func(interface{}, cty.Path) diag.Diagnostics {
+						return nil
+					},
+					ValidateDiagContextFunc: func(context.Context, interface{}, cty.Path) diag.Diagnostics {
+						return nil
+					},
+				},
+			},
+			true,
+		},
+
+		"ValidateDiagContextFunc and ValidateFunc cannot both be set": {
+			map[string]*Schema{
+				"foo": {
+					Type:     TypeInt,
+					Required: true,
+					ValidateFunc: func(interface{}, string) ([]string, []error) {
+						return nil, nil
+					},
+					ValidateDiagContextFunc: func(context.Context, interface{}, cty.Path) diag.Diagnostics {
+						return nil
+					},
+				},
+			},
+			true,
+		},
+
+		"ValidateDiagContextFunc on list": {
+			map[string]*Schema{
+				"foo": {
+					Type:     TypeList,
+					Required: true,
+					Elem:     &Schema{Type: TypeString},
+					ValidateDiagContextFunc: func(context.Context, interface{}, cty.Path) diag.Diagnostics {
+						return nil
+					},
+				},
+			},
+			true,
+		},
+
+		"ValidateDiagContextFunc on computed-only": {
+			map[string]*Schema{
+				"foo": {
+					Type:     TypeString,
+					Computed: true,
+					ValidateDiagContextFunc: func(context.Context, interface{}, cty.Path) diag.Diagnostics {
+						return nil
+					},
+				},
+			},
+			true,
+		},
+
+		"ValidateDiagContextFunc": {
+			map[string]*Schema{
+				"foo": {
+					Type:     TypeString,
+					Required: true,
+					ValidateDiagContextFunc: func(context.Context, interface{}, cty.Path) diag.Diagnostics {
+						return nil
+					},
+				},
+			},
+			false,
+		},
+
+		"ValidateChangeFunc on top-level attribute": {
+			map[string]*Schema{
+				"foo": {
+					Type:     TypeInt,
+					Optional: true,
+					ValidateChangeFunc: func(o, n interface{}) error {
+						return nil
+					},
+				},
+			},
+			false,
+		},
+
+		"ValidateChangeFunc on computed-only": {
+			map[string]*Schema{
+				"foo": {
+					Type:     TypeInt,
+					Computed: true,
+					ValidateChangeFunc: func(o, n interface{}) error {
+						return nil
+					},
+				},
+			},
+			true,
+		},
+
+		"ValidateChangeFunc on nested attribute": {
+			map[string]*Schema{
+				"foo": {
+					Type:     TypeList,
+					Optional: true,
+					Elem: &Resource{
+						Schema: map[string]*Schema{
+							"bar": {
+								Type:     TypeInt,
+								Optional: true,
+								ValidateChangeFunc: func(o, n interface{}) error {
+									return nil
+								},
+							},
+						},
+					},
+				},
+			},
+			true,
+		},
+
 		"Attribute with WriteOnly and Required set returns no errors": {
 			map[string]*Schema{
 				"foo": {
@@ -5086,13 +6008,98 @@ func TestSchemaMap_InternalValidate(t *testing.T) {
 			true,
 		},
 
-		"Attribute with WriteOnly and ForceNew set returns error": {
+		"Attribute with WriteOnly and ForceNew set returns error": {
+			map[string]*Schema{
+				"foo": {
+					Type:      TypeString,
+					ForceNew:  true,
+					Optional:  true,
+					WriteOnly: true,
+				},
+			},
+			true,
+		},
+
+		"Attribute with ForceNewFunc set returns no errors": {
+			map[string]*Schema{
+				"foo": {
+					Type:         TypeString,
+					Optional:     true,
+					ForceNewFunc: func(context.Context, interface{}, interface{}) bool { return true },
+				},
+			},
+			false,
+		},
+
+		"Attribute with ForceNewFunc and ForceNew set returns error": {
+			map[string]*Schema{
+				"foo": {
+					Type:         TypeString,
+					Optional:     true,
+					ForceNew:     true,
+					ForceNewFunc: func(context.Context, interface{}, interface{}) bool { return true },
+				},
+			},
+			true,
+		},
+
+		"Attribute with ForceNewFunc and WriteOnly set returns error": {
+			map[string]*Schema{
+				"foo": {
+					Type:         TypeString,
+					Optional:     true,
+					WriteOnly:    true,
+					ForceNewFunc: func(context.Context, interface{}, interface{}) bool { return true },
+				},
+			},
+			true,
+		},
+
+		"Computed-only attribute with ForceNewFunc set returns error": {
+			map[string]*Schema{
+				"foo": {
+					Type:         TypeString,
+					Computed:     true,
+					ForceNewFunc: func(context.Context, interface{}, interface{}) bool { return true },
+				},
+			},
+			true,
+		},
+
+		"Nested attribute with ForceNewFunc set returns error": {
+			map[string]*Schema{
+				"block": {
+					Type:     TypeList,
+					Optional: true,
+					Elem: &Resource{
+						Schema: map[string]*Schema{
+							"foo": {
+								Type:         TypeString,
+								Optional:     true,
+								ForceNewFunc: func(context.Context, interface{}, interface{}) bool { return true },
+							},
+						},
+					},
+				},
+			},
+			true,
+		},
+
+		"Nested attribute with WriteOnly and ForceNew set returns error": {
 			map[string]*Schema{
-				"foo": {
-					Type:      TypeString,
-					ForceNew:  true,
-					Optional:  true,
-					WriteOnly: true,
+				"block": {
+					Type:     TypeList,
+					Optional: true,
+					Elem: &Resource{
+						Schema: map[string]*Schema{
+							"foo": {
+								Type:      TypeString,
+								ForceNew:  true,
+								Optional:  true,
+								WriteOnly: true,
+							},
+						},
+					},
 				},
 			},
 			true,
@@ -5434,6 +6441,36 @@ func TestSchemaMap_InternalValidate(t *testing.T) {
 			},
 			true,
 		},
+		"DeprecatedValues with TypeInt": {
+			map[string]*Schema{
+				"foo": {
+					Type:             TypeInt,
+					Optional:         true,
+					DeprecatedValues: map[string]string{"1": "deprecated"},
+				},
+			},
+			false,
+		},
+		"DeprecatedValues with invalid TypeInt key returns error": {
+			map[string]*Schema{
+				"foo": {
+					Type:             TypeInt,
+					Optional:         true,
+					DeprecatedValues: map[string]string{"one": "deprecated"},
+				},
+			},
+			true,
+		},
+		"DeprecatedValues with TypeBool returns error": {
+			map[string]*Schema{
+				"foo": {
+					Type:             TypeBool,
+					Optional:         true,
+					DeprecatedValues: map[string]string{"true": "deprecated"},
+				},
+			},
+			true,
+		},
 	}
 
 	for tn, tc := range cases {
@@ -5450,6 +6487,151 @@ func TestSchemaMap_InternalValidate(t *testing.T) {
 
 }
 
+func TestSchemaMap_InternalValidate_nestedFieldName(t *testing.T) {
+	m := schemaMap{
+		"block": {
+			Type:     TypeList,
+			Optional: true,
+			Elem: &Resource{
+				Schema: map[string]*Schema{
+					"nested": {
+						Type:     TypeList,
+						Optional: true,
+						Elem: &Resource{
+							Schema: map[string]*Schema{
+								"Bad-Name": {
+									Type:     TypeString,
+									Optional: true,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	err := m.InternalValidate(nil)
+	if err == nil {
+		t.Fatal("expected validation to fail")
+	}
+
+	expected := "block: nested: Bad-Name: Field name may only contain lowercase alphanumeric characters & underscores."
+	if err.Error() != expected {
+		t.Fatalf("expected error %q, got %q", expected, err)
+	}
+}
+
+func TestSchemaMap_InternalValidate_writeOnlyForceNew(t *testing.T) {
+	m := schemaMap{
+		"block": {
+			Type:     TypeList,
+			Optional: true,
+			Elem: &Resource{
+				Schema: map[string]*Schema{
+					"password": {
+						Type:      TypeString,
+						Optional:  true,
+						ForceNew:  true,
+						WriteOnly: true,
+					},
+				},
+			},
+		},
+	}
+
+	err := m.InternalValidate(nil)
+	if err == nil {
+		t.Fatal("expected validation to fail")
+	}
+
+	expected := "block: password: WriteOnly cannot be set with ForceNew"
+	if err.Error() != expected {
+		t.Fatalf("expected error %q, got %q", expected, err)
+	}
+}
+
+func TestSchemaMap_InternalValidate_oneOfGroups(t *testing.T) {
+	m := schemaMap{
+		"a": {
+			Type:         TypeString,
+			Optional:     true,
+			ExactlyOneOf: []string{"b"},
+		},
+		"b": {
+			Type:         TypeString,
+			Optional:     true,
+			ExactlyOneOf: []string{"c"},
+		},
+		"c": {
+			Type:         TypeString,
+			Optional:     true,
+			ExactlyOneOf: []string{"a"},
+		},
+	}
+
+	err := m.InternalValidate(nil)
+	if err == nil {
+		t.Fatal("expected validation to fail")
+	}
+
+	expected := "a: overlapping ExactlyOneOf and AtLeastOneOf groups cannot all be satisfied: ExactlyOneOf [a b], ExactlyOneOf [a c], ExactlyOneOf [b c]"
+	if err.Error() != expected {
+		t.Fatalf("expected error %q, got %q", expected, err)
+	}
+}
+
+func BenchmarkSchemaMap_Diff_nestedBlocks(b *testing.B) {
+	const blocks, attrs = 50, 20
+
+	nested := map[string]*Schema{}
+	for i := 0; i < attrs; i++ {
+		nested[fmt.Sprintf("attr_%d", i)] = &Schema{
+			Type:     TypeString,
+			Optional: true,
+		}
+	}
+
+	sm := schemaMap(map[string]*Schema{
+		"block": {
+			Type:     TypeList,
+			Optional: true,
+			Elem: &Resource{
+				Schema: nested,
+			},
+		},
+	})
+
+	stateAttrs := map[string]string{
+		"block.#": strconv.Itoa(blocks),
+	}
+	configBlocks := make([]interface{}, 0, blocks)
+	for i := 0; i < blocks; i++ {
+		block := map[string]interface{}{}
+		for j := 0; j < attrs; j++ {
+			stateAttrs[fmt.Sprintf("block.%d.attr_%d", i, j)] = "old"
+			block[fmt.Sprintf("attr_%d", j)] = "new"
+		}
+		configBlocks = append(configBlocks, block)
+	}
+
+	state := &terraform.InstanceState{
+		ID:         "id",
+		Attributes: stateAttrs,
+	}
+	c := terraform.NewResourceConfigRaw(map[string]interface{}{
+		"block": configBlocks,
+	})
+
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := sm.Diff(context.Background(), state, c, nil, nil, true); err != nil {
+			b.Fatalf("unexpected error: %s", err)
+		}
+	}
+}
+
 func TestSchemaMap_DiffSuppress(t *testing.T) {
 	cases := map[string]struct {
 		Schema       map[string]*Schema
@@ -6571,6 +7753,68 @@ func TestSchemaMap_Validate(t *testing.T) {
 			},
 		},
 
+		"DeprecatedValues generates warning for deprecated string value": {
+			Schema: map[string]*Schema{
+				"type": {
+					Type:     TypeString,
+					Optional: true,
+					DeprecatedValues: map[string]string{
+						"legacy": "please use 'standard' instead",
+					},
+				},
+			},
+
+			Config: map[string]interface{}{
+				"type": "legacy",
+			},
+
+			Err: false,
+
+			Warnings: []string{
+				"Warning: Argument value is deprecated: please use 'standard' instead",
+			},
+		},
+
+		"DeprecatedValues generates warning for deprecated int value": {
+			Schema: map[string]*Schema{
+				"version": {
+					Type:     TypeInt,
+					Optional: true,
+					DeprecatedValues: map[string]string{
+						"1": "please use version 2 instead",
+					},
+				},
+			},
+
+			Config: map[string]interface{}{
+				"version": 1,
+			},
+
+			Err: false,
+
+			Warnings: []string{
+				"Warning: Argument value is deprecated: please use version 2 instead",
+			},
+		},
+
+		"DeprecatedValues generates no warnings for other values": {
+			Schema: map[string]*Schema{
+				"type": {
+					Type:     TypeString,
+					Optional: true,
+					DeprecatedValues: map[string]string{
+						"legacy": "please use 'standard' instead",
+					},
+				},
+			},
+
+			Config: map[string]interface{}{
+				"type": "standard",
+			},
+
+			Err: false,
+		},
+
 		"Deprecated generates no warnings if attr not used": {
 			Schema: map[string]*Schema{
 				"old_news": {
@@ -7148,6 +8392,97 @@ func TestSchemaMap_Validate(t *testing.T) {
 				},
 			},
 		},
+		"Optional and Computed list elements are validated when configured": {
+			Schema: map[string]*Schema{
+				"ports": {
+					Type:     TypeList,
+					Optional: true,
+					Computed: true,
+					Elem: &Schema{
+						Type: TypeInt,
+						ValidateDiagFunc: func(v interface{}, path cty.Path) diag.Diagnostics {
+							if v.(int) < 1 {
+								return diag.Errorf("port must be positive")
+							}
+							return nil
+						},
+					},
+				},
+			},
+
+			Config: map[string]interface{}{
+				"ports": []interface{}{80, 0},
+			},
+
+			Err: true,
+		},
+		"Optional and Computed set elements are validated when configured": {
+			Schema: map[string]*Schema{
+				"ports": {
+					Type:     TypeSet,
+					Optional: true,
+					Computed: true,
+					Elem: &Schema{
+						Type: TypeInt,
+						ValidateDiagFunc: func(v interface{}, path cty.Path) diag.Diagnostics {
+							if v.(int) < 1 {
+								return diag.Errorf("port must be positive")
+							}
+							return nil
+						},
+					},
+				},
+			},
+
+			Config: map[string]interface{}{
+				"ports": []interface{}{80, 0},
+			},
+
+			Err: true,
+		},
+		"Optional and Computed list elements are not validated when not configured": {
+			Schema: map[string]*Schema{
+				"ports": {
+					Type:     TypeList,
+					Optional: true,
+					Computed: true,
+					Elem: &Schema{
+						Type: TypeInt,
+						ValidateDiagFunc: func(v interface{}, path cty.Path) diag.Diagnostics {
+							if v.(int) < 1 {
+								return diag.Errorf("port must be positive")
+							}
+							return nil
+						},
+					},
+				},
+			},
+
+			Config: map[string]interface{}{},
+		},
+		"Computed-only list cannot be configured": {
+			Schema: map[string]*Schema{
+				"ports": {
+					Type:     TypeList,
+					Computed: true,
+					Elem: &Schema{
+						Type: TypeInt,
+						ValidateDiagFunc: func(v interface{}, path cty.Path) diag.Diagnostics {
+							if v.(int) < 1 {
+								return diag.Errorf("port must be positive")
+							}
+							return nil
+						},
+					},
+				},
+			},
+
+			Config: map[string]interface{}{
+				"ports": []interface{}{80, 0},
+			},
+
+			Err: true,
+		},
 	}
 
 	for tn, tc := range cases {
@@ -7198,6 +8533,111 @@ func errorEquals(a []error, b []error) bool {
 	return true
 }
 
+func TestSchemaMap_ValidateContext(t *testing.T) {
+	type ctxKey struct{}
+
+	validateFunc := func(ctx context.Context, v interface{}, path cty.Path) diag.Diagnostics {
+		if err := ctx.Err(); err != nil {
+			return diag.FromErr(err)
+		}
+
+		if ctx.Value(ctxKey{}) != "expected" {
+			return diag.Errorf("unexpected context value: %v", ctx.Value(ctxKey{}))
+		}
+
+		return diag.Diagnostics{
+			{
+				Severity: diag.Warning,
+				Summary:  "validated",
+			},
+		}
+	}
+
+	cancelledCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	cases := map[string]struct {
+		Ctx      context.Context
+		Schema   map[string]*Schema
+		Config   map[string]interface{}
+		Expected diag.Diagnostics
+	}{
+		"context passed": {
+			Ctx: context.WithValue(context.Background(), ctxKey{}, "expected"),
+			Schema: map[string]*Schema{
+				"foo": {
+					Type:                    TypeString,
+					Required:                true,
+					ValidateDiagContextFunc: validateFunc,
+				},
+			},
+			Config: map[string]interface{}{
+				"foo": "bar",
+			},
+			Expected: diag.Diagnostics{
+				{
+					Severity:      diag.Warning,
+					Summary:       "validated",
+					AttributePath: cty.GetAttrPath("foo"),
+				},
+			},
+		},
+		"context passed to map": {
+			Ctx: context.WithValue(context.Background(), ctxKey{}, "expected"),
+			Schema: map[string]*Schema{
+				"foo": {
+					Type:                    TypeMap,
+					Required:                true,
+					Elem:                    &Schema{Type: TypeString},
+					ValidateDiagContextFunc: validateFunc,
+				},
+			},
+			Config: map[string]interface{}{
+				"foo": map[string]interface{}{"a": "b"},
+			},
+			Expected: diag.Diagnostics{
+				{
+					Severity:      diag.Warning,
+					Summary:       "validated",
+					AttributePath: cty.GetAttrPath("foo"),
+				},
+			},
+		},
+		"context cancelled": {
+			Ctx: cancelledCtx,
+			Schema: map[string]*Schema{
+				"foo": {
+					Type:                    TypeString,
+					Required:                true,
+					ValidateDiagContextFunc: validateFunc,
+				},
+			},
+			Config: map[string]interface{}{
+				"foo": "bar",
+			},
+			Expected: diag.Diagnostics{
+				{
+					Severity:      diag.Error,
+					Summary:       context.Canceled.Error(),
+					AttributePath: cty.GetAttrPath("foo"),
+				},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c := terraform.NewResourceConfigRaw(tc.Config)
+
+			diags := schemaMap(tc.Schema).ValidateContext(tc.Ctx, c)
+
+			if diff := cmp.Diff(tc.Expected, diags, cmp.AllowUnexported(cty.GetAttrStep{}, diag.Diagnostic{})); diff != "" {
+				t.Fatalf("unexpected difference: %s", diff)
+			}
+		})
+	}
+}
+
 func TestSchemaSet_ValidateMaxItems(t *testing.T) {
 	cases := map[string]struct {
 		Schema          map[string]*Schema
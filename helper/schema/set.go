@@ -5,11 +5,14 @@ package schema
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"log"
 	"reflect"
 	"sort"
 	"strconv"
 	"sync"
+	"unsafe"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/internal/helper/hashcode"
@@ -38,6 +41,92 @@ func HashResource(resource *Resource) SchemaSetFunc {
 	}
 }
 
+// HashResourceByKeys hashes complex structures that are described using a
+// *Resource based only on the given key attributes, which identify an
+// element of the set. Unlike HashResource, adding or changing other
+// attributes of the element does not change its hash, which avoids
+// unnecessary churn of set elements in plans. Elements with equal values
+// for all of the key attributes are considered the same element.
+//
+// For example:
+//
+//	Set: schema.HashResourceByKeys(ruleResource, "name")
+//
+// If no keys are given, or if a key is not a Required or Optional attribute
+// of the resource schema, the returned function hashes the whole element
+// like HashResource and InternalValidate reports the error for the TypeSet
+// attribute using it.
+func HashResourceByKeys(resource *Resource, keys ...string) SchemaSetFunc {
+	sm := resource.SchemaMap()
+	keys = append([]string(nil), keys...)
+	sort.Strings(keys)
+
+	if err := validateHashResourceByKeys(sm, keys); err != nil {
+		log.Printf("[WARN] %s, falling back to HashResource", err)
+
+		f := HashResource(resource)
+		invalidHashResourceByKeys.Store(setFuncKey(f), err)
+
+		return f
+	}
+
+	return func(v interface{}) int {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return 0
+		}
+
+		var buf bytes.Buffer
+		for _, k := range keys {
+			buf.WriteString(k)
+			buf.WriteRune(':')
+			SerializeValueForHash(&buf, m[k], sm[k])
+		}
+		return hashcode.String(buf.String())
+	}
+}
+
+// invalidHashResourceByKeys records the validation error of each Set
+// function returned by HashResourceByKeys with invalid keys, keyed by
+// setFuncKey, so InternalValidate can report it without calling the function.
+var invalidHashResourceByKeys sync.Map
+
+// setFuncKey returns a key identifying the given function value. Unlike the
+// code pointer returned by reflect, it differs between closures created by
+// the same function literal.
+func setFuncKey(f SchemaSetFunc) unsafe.Pointer {
+	return *(*unsafe.Pointer)(unsafe.Pointer(&f))
+}
+
+func validateHashResourceByKeys(sm map[string]*Schema, keys []string) error {
+	if len(keys) == 0 {
+		return errors.New("HashResourceByKeys: at least one key attribute must be given")
+	}
+
+	for _, k := range keys {
+		s, ok := sm[k]
+		if !ok || s == nil {
+			return fmt.Errorf("HashResourceByKeys: key %q does not exist in the resource schema", k)
+		}
+
+		if !s.Required && !s.Optional {
+			return fmt.Errorf("HashResourceByKeys: key %q must be Required or Optional", k)
+		}
+	}
+
+	return nil
+}
+
+// validateSetFunc returns the error of a Set function returned by
+// HashResourceByKeys with invalid keys.
+func validateSetFunc(f SchemaSetFunc) error {
+	if err, ok := invalidHashResourceByKeys.Load(setFuncKey(f)); ok {
+		return err.(error)
+	}
+
+	return nil
+}
+
 // HashSchema hashes values that are described using a *Schema. This is the
 // default set implementation used when a set's element type is a single
 // schema.
@@ -69,11 +158,63 @@ func NewSet(f SchemaSetFunc, items []interface{}) *Set {
 	return s
 }
 
+// NewSetFromResource creates a new set of the given items, which are elements
+// of a TypeSet whose Elem is the given *Resource. The set uses the
+// HashResource hash of the element resource, which is the default hash of
+// such a TypeSet. An error is returned if an item has a key which is not an
+// attribute of the element resource schema.
+func NewSetFromResource(elem *Resource, items []map[string]interface{}) (*Set, error) {
+	if elem == nil {
+		return nil, fmt.Errorf("element resource is nil")
+	}
+
+	sm := elem.SchemaMap()
+	list := make([]interface{}, 0, len(items))
+
+	for i, item := range items {
+		keys := make([]string, 0, len(item))
+		for k := range item {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			if _, ok := sm[k]; !ok {
+				return nil, fmt.Errorf("item %d: %q is not an attribute of the element schema", i, k)
+			}
+		}
+
+		list = append(list, item)
+	}
+
+	return NewSet(HashResource(elem), list), nil
+}
+
 // CopySet returns a copy of another set.
 func CopySet(otherSet *Set) *Set {
 	return NewSet(otherSet.F, otherSet.List())
 }
 
+// SetChanges returns the elements which are only in the new set, to add, and
+// the elements which are only in the old set, to remove, such as when
+// reconciling the members of a set with a remote API during an update. The
+// elements are compared with the hash function of the sets and returned in
+// the order of the Set type List method. A nil set is treated as empty.
+//
+//	o, n := d.GetChange("rule")
+//	add, remove := schema.SetChanges(o.(*schema.Set), n.(*schema.Set))
+func SetChanges(oldSet, newSet *Set) (add []interface{}, remove []interface{}) {
+	if oldSet == nil {
+		oldSet = &Set{}
+	}
+
+	if newSet == nil {
+		newSet = &Set{}
+	}
+
+	return newSet.Difference(oldSet).List(), oldSet.Difference(newSet).List()
+}
+
 // Add adds an item to the set if it isn't already in the set.
 func (s *Set) Add(item interface{}) {
 	s.add(item, false)
@@ -84,6 +225,24 @@ func (s *Set) Remove(item interface{}) {
 	s.remove(item)
 }
 
+// With returns a new set with the items of this set and the given item,
+// using the same hash function. This set is left unchanged.
+func (s *Set) With(item interface{}) *Set {
+	result := s.copy()
+	result.add(item, false)
+
+	return result
+}
+
+// Without returns a new set with the items of this set except the given
+// item, using the same hash function. This set is left unchanged.
+func (s *Set) Without(item interface{}) *Set {
+	result := s.copy()
+	result.remove(item)
+
+	return result
+}
+
 // Contains checks if the set has the given item.
 func (s *Set) Contains(item interface{}) bool {
 	_, ok := s.m[s.hash(item)]
@@ -195,6 +354,19 @@ func (s *Set) init() {
 	s.m = make(map[string]interface{})
 }
 
+// copy returns a new set with the same hash function and items, including
+// computed items.
+func (s *Set) copy() *Set {
+	result := &Set{F: s.F}
+	result.once.Do(result.init)
+
+	for k, v := range s.m {
+		result.m[k] = v
+	}
+
+	return result
+}
+
 func (s *Set) add(item interface{}, computed bool) string {
 	s.once.Do(s.init)
 
@@ -51,6 +51,53 @@ func TestSetContains(t *testing.T) {
 	}
 }
 
+func TestSetWith(t *testing.T) {
+	s := NewSet(testSetInt, []interface{}{1, 5})
+
+	result := s.With(25)
+
+	if !result.Equal(NewSet(testSetInt, []interface{}{1, 5, 25})) {
+		t.Fatalf("bad: %#v", result.List())
+	}
+
+	if !s.Equal(NewSet(testSetInt, []interface{}{1, 5})) {
+		t.Fatalf("original set modified: %#v", s.List())
+	}
+}
+
+func TestSetWithout(t *testing.T) {
+	s := NewSet(testSetInt, []interface{}{1, 5, 25})
+
+	result := s.Without(5)
+
+	if !result.Equal(NewSet(testSetInt, []interface{}{1, 25})) {
+		t.Fatalf("bad: %#v", result.List())
+	}
+
+	if !s.Equal(NewSet(testSetInt, []interface{}{1, 5, 25})) {
+		t.Fatalf("original set modified: %#v", s.List())
+	}
+
+	result.Add(50)
+	if s.Contains(50) {
+		t.Fatal("original set should not contain items added to the new set")
+	}
+}
+
+func TestSetWith_empty(t *testing.T) {
+	s := &Set{F: testSetInt}
+
+	result := s.With(1).With(5).Without(1)
+
+	if !result.Equal(NewSet(testSetInt, []interface{}{5})) {
+		t.Fatalf("bad: %#v", result.List())
+	}
+
+	if s.Len() != 0 {
+		t.Fatalf("original set modified: %#v", s.List())
+	}
+}
+
 func TestSetDifference(t *testing.T) {
 	s1 := &Set{F: testSetInt}
 	s2 := &Set{F: testSetInt}
@@ -71,6 +118,52 @@ func TestSetDifference(t *testing.T) {
 	}
 }
 
+func TestSetChanges(t *testing.T) {
+	cases := map[string]struct {
+		Old            *Set
+		New            *Set
+		ExpectedAdd    []interface{}
+		ExpectedRemove []interface{}
+	}{
+		"added and removed": {
+			Old:            NewSet(testSetInt, []interface{}{1, 5, 25}),
+			New:            NewSet(testSetInt, []interface{}{5, 25, 50, 2}),
+			ExpectedAdd:    []interface{}{2, 50},
+			ExpectedRemove: []interface{}{1},
+		},
+		"unchanged": {
+			Old:            NewSet(testSetInt, []interface{}{1, 5}),
+			New:            NewSet(testSetInt, []interface{}{5, 1}),
+			ExpectedAdd:    []interface{}{},
+			ExpectedRemove: []interface{}{},
+		},
+		"nil old": {
+			New:            NewSet(testSetInt, []interface{}{1}),
+			ExpectedAdd:    []interface{}{1},
+			ExpectedRemove: []interface{}{},
+		},
+		"nil new": {
+			Old:            NewSet(testSetInt, []interface{}{1}),
+			ExpectedAdd:    []interface{}{},
+			ExpectedRemove: []interface{}{1},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			add, remove := SetChanges(tc.Old, tc.New)
+
+			if !reflect.DeepEqual(add, tc.ExpectedAdd) {
+				t.Errorf("expected added %#v, got %#v", tc.ExpectedAdd, add)
+			}
+
+			if !reflect.DeepEqual(remove, tc.ExpectedRemove) {
+				t.Errorf("expected removed %#v, got %#v", tc.ExpectedRemove, remove)
+			}
+		})
+	}
+}
+
 func TestSetIntersection(t *testing.T) {
 	s1 := &Set{F: testSetInt}
 	s2 := &Set{F: testSetInt}
@@ -137,6 +230,187 @@ func TestHashResource_nil(t *testing.T) {
 	}
 }
 
+func TestNewSetFromResource(t *testing.T) {
+	elem := &Resource{
+		Schema: map[string]*Schema{
+			"name": {
+				Type:     TypeString,
+				Required: true,
+			},
+			"port": {
+				Type:     TypeInt,
+				Optional: true,
+			},
+		},
+	}
+
+	items := []map[string]interface{}{
+		{"name": "http", "port": 80},
+		{"name": "https", "port": 443},
+		{"name": "http", "port": 80},
+	}
+
+	s, err := NewSetFromResource(elem, items)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := NewSet(HashResource(elem), []interface{}{
+		map[string]interface{}{"name": "http", "port": 80},
+		map[string]interface{}{"name": "https", "port": 443},
+	})
+
+	if !s.Equal(expected) {
+		t.Fatalf("expected %#v, got %#v", expected.List(), s.List())
+	}
+}
+
+func TestNewSetFromResource_invalid(t *testing.T) {
+	elem := &Resource{
+		Schema: map[string]*Schema{
+			"name": {
+				Type:     TypeString,
+				Required: true,
+			},
+		},
+	}
+
+	_, err := NewSetFromResource(elem, []map[string]interface{}{
+		{"name": "http"},
+		{"name": "https", "prot": 443},
+	})
+
+	expected := `item 1: "prot" is not an attribute of the element schema`
+	if err == nil || err.Error() != expected {
+		t.Fatalf("expected error %q, got: %v", expected, err)
+	}
+
+	if _, err := NewSetFromResource(nil, nil); err == nil {
+		t.Fatal("expected error for nil element resource")
+	}
+}
+
+func TestHashResourceByKeys(t *testing.T) {
+	resource := &Resource{
+		Schema: map[string]*Schema{
+			"name": {
+				Type:     TypeString,
+				Required: true,
+			},
+			"port": {
+				Type:     TypeInt,
+				Optional: true,
+			},
+			"description": {
+				Type:     TypeString,
+				Optional: true,
+			},
+			"arn": {
+				Type:     TypeString,
+				Computed: true,
+			},
+		},
+	}
+
+	f := HashResourceByKeys(resource, "port", "name")
+
+	base := map[string]interface{}{
+		"name":        "foo",
+		"port":        80,
+		"description": "first",
+	}
+
+	cases := map[string]struct {
+		value    map[string]interface{}
+		expected bool
+	}{
+		"unrelated attribute changed": {
+			value: map[string]interface{}{
+				"name":        "foo",
+				"port":        80,
+				"description": "second",
+				"arn":         "arn:example",
+			},
+			expected: true,
+		},
+		"key attribute changed": {
+			value: map[string]interface{}{
+				"name":        "bar",
+				"port":        80,
+				"description": "first",
+			},
+			expected: false,
+		},
+		"second key attribute changed": {
+			value: map[string]interface{}{
+				"name":        "foo",
+				"port":        443,
+				"description": "first",
+			},
+			expected: false,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if actual := f(tc.value) == f(base); actual != tc.expected {
+				t.Fatalf("expected hash equality %t, got %t", tc.expected, actual)
+			}
+		})
+	}
+
+	if f(nil) != 0 {
+		t.Fatalf("expected 0 when hashing nil, given: %d", f(nil))
+	}
+
+	if HashResourceByKeys(resource, "name", "port")(base) != f(base) {
+		t.Fatal("expected key order not to affect the hash")
+	}
+}
+
+func TestHashResourceByKeys_invalid(t *testing.T) {
+	resource := &Resource{
+		Schema: map[string]*Schema{
+			"name": {
+				Type:     TypeString,
+				Required: true,
+			},
+			"arn": {
+				Type:     TypeString,
+				Computed: true,
+			},
+		},
+	}
+
+	cases := map[string][]string{
+		"no keys":      nil,
+		"missing key":  {"name", "missing"},
+		"computed key": {"arn"},
+	}
+
+	for name, keys := range cases {
+		t.Run(name, func(t *testing.T) {
+			sm := schemaMap{
+				"rule": {
+					Type:     TypeSet,
+					Optional: true,
+					Elem:     resource,
+					Set:      HashResourceByKeys(resource, keys...),
+				},
+			}
+
+			if err := sm.InternalValidate(nil); err == nil {
+				t.Fatal("expected InternalValidate error")
+			}
+
+			v := map[string]interface{}{"name": "a", "arn": "b"}
+			if got, want := sm["rule"].Set(v), HashResource(resource)(v); got != want {
+				t.Fatalf("expected HashResource hash %d, got %d", want, got)
+			}
+		})
+	}
+}
+
 func TestHashEqual(t *testing.T) {
 	nested := &Resource{
 		Schema: map[string]*Schema{
@@ -0,0 +1,66 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package schema
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+// stableSeedValue returns the value derived from the seed of a StableSeedFunc
+// attribute, which is the hex encoding of the first 8 bytes of the SHA-256
+// hash of the seed.
+func stableSeedValue(seed string) string {
+	sum := sha256.Sum256([]byte(seed))
+
+	return hex.EncodeToString(sum[:8])
+}
+
+// diffStableSeed plans the value of StableSeedFunc attributes as derived
+// from their seed, replacing the unknown value planned for computed
+// attributes. Attributes whose prior state value already matches are left
+// out of the diff, as are those whose seed is empty and which have a prior
+// state value. Otherwise attributes whose seed is empty are planned as
+// unknown.
+func (m schemaMapWithIdentity) diffStableSeed(d *ResourceData, s *terraform.InstanceState, result *terraform.InstanceDiff) {
+	for k, schema := range m.schemaMap {
+		if schema.StableSeedFunc == nil {
+			continue
+		}
+
+		var old string
+		if s != nil {
+			old = s.Attributes[k]
+		}
+
+		seed := schema.StableSeedFunc(d)
+		if seed == "" {
+			// Keep the prior state value, so it is not replaced on every
+			// plan while the seed cannot be determined.
+			if old != "" {
+				delete(result.Attributes, k)
+				continue
+			}
+
+			result.Attributes[k] = &terraform.ResourceAttrDiff{
+				Old:         old,
+				NewComputed: true,
+			}
+			continue
+		}
+
+		value := stableSeedValue(seed)
+		if s != nil && old == value {
+			delete(result.Attributes, k)
+			continue
+		}
+
+		result.Attributes[k] = &terraform.ResourceAttrDiff{
+			Old: old,
+			New: value,
+		}
+	}
+}
@@ -4,8 +4,12 @@
 package schema
 
 import (
+	"bytes"
 	"context"
+	"fmt"
+	"sync"
 
+	"github.com/hashicorp/terraform-plugin-log/tflogtest"
 	testing "github.com/mitchellh/go-testing-interface"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
@@ -47,3 +51,47 @@ func TestResourceDataWithIdentityRaw(t testing.T, schema map[string]*Schema, ide
 
 	return result
 }
+
+// TestCaptureLogs returns a context carrying a provider root logger which
+// captures the log entries written with tflog, such as in CRUD functions
+// called with this context, and a function returning the entries captured
+// so far, decoded from JSON. Each entry contains the "@level", "@message"
+// and "@module" keys along with any additional fields. Logs written by the
+// SDK itself are not captured.
+//
+//	ctx, logs := schema.TestCaptureLogs(context.Background())
+//	diags := r.CreateContext(ctx, d, meta)
+//	entries := logs()
+func TestCaptureLogs(ctx context.Context) (context.Context, func() []map[string]interface{}) {
+	output := &testLogOutput{}
+
+	ctx = tflogtest.RootLogger(ctx, output)
+
+	return ctx, output.entries
+}
+
+// testLogOutput is a concurrency safe buffer receiving the log output of
+// TestCaptureLogs, as CRUD functions may log from multiple goroutines.
+type testLogOutput struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (o *testLogOutput) Write(p []byte) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return o.buf.Write(p)
+}
+
+func (o *testLogOutput) entries() []map[string]interface{} {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	entries, err := tflogtest.MultilineJSONDecode(bytes.NewReader(o.buf.Bytes()))
+	if err != nil {
+		panic(fmt.Sprintf("unable to decode captured log entries: %s", err))
+	}
+
+	return entries
+}
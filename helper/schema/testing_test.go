@@ -0,0 +1,72 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package schema
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+)
+
+func TestTestCaptureLogs(t *testing.T) {
+	t.Parallel()
+
+	r := &Resource{
+		Schema: map[string]*Schema{
+			"name": {
+				Type:     TypeString,
+				Required: true,
+			},
+		},
+		CreateContext: func(ctx context.Context, d *ResourceData, meta interface{}) diag.Diagnostics {
+			tflog.Debug(ctx, "creating resource", map[string]interface{}{
+				"name": d.Get("name"),
+			})
+
+			d.SetId("test")
+
+			tflog.Info(ctx, "created resource")
+
+			return nil
+		},
+		ReadContext:   NoopContext,
+		DeleteContext: NoopContext,
+	}
+
+	ctx, logs := TestCaptureLogs(context.Background())
+
+	if diff := cmp.Diff([]map[string]interface{}(nil), logs()); diff != "" {
+		t.Fatalf("unexpected entries before create: %s", diff)
+	}
+
+	d := TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		"name": "example",
+	})
+
+	if diags := r.create(ctx, d, nil); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+
+	expected := []map[string]interface{}{
+		{
+			"@level":   "debug",
+			"@message": "creating resource",
+			"@module":  "provider",
+			"name":     "example",
+		},
+		{
+			"@level":   "info",
+			"@message": "created resource",
+			"@module":  "provider",
+		},
+	}
+
+	if diff := cmp.Diff(expected, logs()); diff != "" {
+		t.Fatalf("unexpected difference: %s", diff)
+	}
+}
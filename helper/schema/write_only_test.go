@@ -915,7 +915,7 @@ func Test_validateWriteOnlyNullValues(t *testing.T) {
 			got := validateWriteOnlyNullValues(tc.Val, tc.Schema, cty.Path{})
 
 			if diff := cmp.Diff(got, tc.Expected,
-				cmp.AllowUnexported(cty.GetAttrStep{}, cty.IndexStep{}),
+				cmp.AllowUnexported(cty.GetAttrStep{}, cty.IndexStep{}, diag.Diagnostic{}),
 				cmp.Comparer(indexStepComparer)); diff != "" {
 				t.Errorf("unexpected difference: %s", diff)
 			}
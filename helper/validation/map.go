@@ -27,7 +27,7 @@ func MapKeyLenBetween(minVal, maxVal int) schema.SchemaValidateDiagFunc {
 					Severity:      diag.Error,
 					Summary:       "Bad map key length",
 					Detail:        fmt.Sprintf("Map key lengths should be in the range (%d - %d): %s (length = %d)", minVal, maxVal, key, keyLen),
-					AttributePath: append(path, cty.IndexStep{Key: cty.StringVal(key)}),
+					AttributePath: path.IndexString(key),
 				})
 			}
 		}
@@ -52,7 +52,7 @@ func MapValueLenBetween(minVal, maxVal int) schema.SchemaValidateDiagFunc {
 					Severity:      diag.Error,
 					Summary:       "Bad map value type",
 					Detail:        fmt.Sprintf("Map values should be strings: %s => %v (type = %T)", key, val, val),
-					AttributePath: append(path, cty.IndexStep{Key: cty.StringVal(key)}),
+					AttributePath: path.IndexString(key),
 				})
 				continue
 			}
@@ -63,7 +63,7 @@ func MapValueLenBetween(minVal, maxVal int) schema.SchemaValidateDiagFunc {
 					Severity:      diag.Error,
 					Summary:       "Bad map value length",
 					Detail:        fmt.Sprintf("Map value lengths should be in the range (%d - %d): %s => %v (length = %d)", minVal, maxVal, key, val, valLen),
-					AttributePath: append(path, cty.IndexStep{Key: cty.StringVal(key)}),
+					AttributePath: path.IndexString(key),
 				})
 			}
 		}
@@ -92,7 +92,7 @@ func MapKeyMatch(r *regexp.Regexp, message string) schema.SchemaValidateDiagFunc
 					Severity:      diag.Error,
 					Summary:       "Invalid map key",
 					Detail:        detail,
-					AttributePath: append(path, cty.IndexStep{Key: cty.StringVal(key)}),
+					AttributePath: path.IndexString(key),
 				})
 			}
 		}
@@ -118,7 +118,7 @@ func MapValueMatch(r *regexp.Regexp, message string) schema.SchemaValidateDiagFu
 					Severity:      diag.Error,
 					Summary:       "Bad map value type",
 					Detail:        fmt.Sprintf("Map values should be strings: %s => %v (type = %T)", key, val, val),
-					AttributePath: append(path, cty.IndexStep{Key: cty.StringVal(key)}),
+					AttributePath: path.IndexString(key),
 				})
 				continue
 			}
@@ -135,7 +135,7 @@ func MapValueMatch(r *regexp.Regexp, message string) schema.SchemaValidateDiagFu
 					Severity:      diag.Error,
 					Summary:       "Invalid map value",
 					Detail:        detail,
-					AttributePath: append(path, cty.IndexStep{Key: cty.StringVal(key)}),
+					AttributePath: path.IndexString(key),
 				})
 			}
 		}
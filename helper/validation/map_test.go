@@ -9,6 +9,7 @@ import (
 
 	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
 func TestValidationMapKeyLenBetween(t *testing.T) {
@@ -257,6 +258,43 @@ func TestValidationValueKeyMatch(t *testing.T) {
 	}
 }
 
+func TestValidationMap_attributePathCapacity(t *testing.T) {
+	// A path with spare capacity must not be shared between diagnostics.
+	path := make(cty.Path, 1, 4)
+	path[0] = cty.GetAttrStep{Name: "tags"}
+
+	value := map[string]interface{}{
+		"a": "1",
+		"b": "2",
+	}
+
+	expected := diag.Diagnostics{
+		{
+			Severity:      diag.Error,
+			AttributePath: cty.GetAttrPath("tags").IndexString("a"),
+		},
+		{
+			Severity:      diag.Error,
+			AttributePath: cty.GetAttrPath("tags").IndexString("b"),
+		},
+	}
+
+	fns := map[string]schema.SchemaValidateDiagFunc{
+		"MapKeyLenBetween":   MapKeyLenBetween(2, 5),
+		"MapValueLenBetween": MapValueLenBetween(2, 5),
+		"MapKeyMatch":        MapKeyMatch(regexp.MustCompile("^x$"), ""),
+		"MapValueMatch":      MapValueMatch(regexp.MustCompile("^x$"), ""),
+	}
+
+	for name, fn := range fns {
+		t.Run(name, func(t *testing.T) {
+			diags := fn(value, path)
+
+			checkDiagnostics(t, name, diags, expected)
+		})
+	}
+}
+
 func checkDiagnostics(t *testing.T, tn string, got, expected diag.Diagnostics) {
 	if len(got) != len(expected) {
 		t.Fatalf("%s: wrong number of diags, expected %d, got %d", tn, len(expected), len(got))
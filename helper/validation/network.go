@@ -9,6 +9,9 @@ import (
 	"net"
 	"strings"
 
+	"github.com/hashicorp/go-cty/cty"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
@@ -108,13 +111,13 @@ func IsCIDRNetwork(minVal, maxVal int) schema.SchemaValidateFunc {
 			return warnings, errors
 		}
 
-		_, ipnet, err := net.ParseCIDR(v)
+		ipnet, isNetwork, err := parseCIDRNetwork(v)
 		if err != nil {
 			errors = append(errors, fmt.Errorf("expected %s to contain a valid Value, got: %s with err: %s", k, v, err))
 			return warnings, errors
 		}
 
-		if ipnet == nil || v != ipnet.String() {
+		if !isNetwork {
 			errors = append(errors, fmt.Errorf("expected %s to contain a valid network Value, expected %s, got %s",
 				k, ipnet, v))
 		}
@@ -128,6 +131,113 @@ func IsCIDRNetwork(minVal, maxVal int) schema.SchemaValidateFunc {
 	}
 }
 
+// parseCIDRNetwork parses the given CIDR and returns whether it is written
+// in canonical network notation, such as 10.0.0.0/16, rather than with host
+// bits set or a prefix length with leading zeros. It is shared by
+// IsCIDRNetwork and IsCIDRNetworkDiag so both accept the same values.
+func parseCIDRNetwork(v string) (*net.IPNet, bool, error) {
+	_, ipnet, err := net.ParseCIDR(v)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return ipnet, v == ipnet.String(), nil
+}
+
+// IsIPAddressDiag is a SchemaValidateDiagFunc which ensures a string is a
+// single IP (v4 or v6). It is the SchemaValidateDiagFunc variant of
+// IsIPAddress.
+func IsIPAddressDiag(i interface{}, path cty.Path) diag.Diagnostics {
+	v, ok := i.(string)
+	if !ok {
+		return stringTypeDiagnostics(i, path)
+	}
+
+	if net.ParseIP(v) == nil {
+		return diag.Diagnostics{
+			{
+				Severity:      diag.Error,
+				Summary:       "Invalid IP address",
+				Detail:        fmt.Sprintf("Expected a valid IP address, got: %s.", v),
+				AttributePath: path,
+			},
+		}
+	}
+
+	return nil
+}
+
+// IsCIDRDiag is a SchemaValidateDiagFunc which ensures a string is a valid
+// CIDR, such as "10.0.0.1/8". It is the SchemaValidateDiagFunc variant of
+// IsCIDR, with the parsing error in the diagnostic detail.
+func IsCIDRDiag(i interface{}, path cty.Path) diag.Diagnostics {
+	v, ok := i.(string)
+	if !ok {
+		return stringTypeDiagnostics(i, path)
+	}
+
+	if _, _, err := net.ParseCIDR(v); err != nil {
+		return diag.Diagnostics{
+			{
+				Severity:      diag.Error,
+				Summary:       "Invalid CIDR",
+				Detail:        fmt.Sprintf("Expected a valid CIDR: %s.", err),
+				AttributePath: path,
+			},
+		}
+	}
+
+	return nil
+}
+
+// IsCIDRNetworkDiag returns a SchemaValidateDiagFunc which ensures a string
+// is a CIDR in network notation, without host bits set, such as
+// "10.0.0.0/8", and has significant bits between minVal and maxVal
+// (inclusive). It is the SchemaValidateDiagFunc variant of IsCIDRNetwork
+// and accepts the same values.
+func IsCIDRNetworkDiag(minVal, maxVal int) schema.SchemaValidateDiagFunc {
+	return func(i interface{}, path cty.Path) diag.Diagnostics {
+		v, ok := i.(string)
+		if !ok {
+			return stringTypeDiagnostics(i, path)
+		}
+
+		ipnet, isNetwork, err := parseCIDRNetwork(v)
+		if err != nil {
+			return diag.Diagnostics{
+				{
+					Severity:      diag.Error,
+					Summary:       "Invalid CIDR",
+					Detail:        fmt.Sprintf("Expected a valid CIDR: %s.", err),
+					AttributePath: path,
+				},
+			}
+		}
+
+		var diags diag.Diagnostics
+
+		if !isNetwork {
+			diags = append(diags, diag.Diagnostic{
+				Severity:      diag.Error,
+				Summary:       "Invalid CIDR network",
+				Detail:        fmt.Sprintf("Expected a CIDR in network notation, expected %s, got %s.", ipnet, v),
+				AttributePath: path,
+			})
+		}
+
+		if sigbits, _ := ipnet.Mask.Size(); sigbits < minVal || sigbits > maxVal {
+			diags = append(diags, diag.Diagnostic{
+				Severity:      diag.Error,
+				Summary:       "Invalid CIDR network",
+				Detail:        fmt.Sprintf("Expected a CIDR network with between %d and %d significant bits, got: %d.", minVal, maxVal, sigbits),
+				AttributePath: path,
+			})
+		}
+
+		return diags
+	}
+}
+
 // IsMACAddress is a SchemaValidateFunc which tests if the provided value is of type string and a valid MAC address
 func IsMACAddress(i interface{}, k string) (warnings []string, errors []error) {
 	v, ok := i.(string)
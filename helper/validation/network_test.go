@@ -5,6 +5,10 @@ package validation
 
 import (
 	"testing"
+
+	"github.com/hashicorp/go-cty/cty"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 )
 
 func TestValidateIsIPAddress(t *testing.T) {
@@ -307,6 +311,138 @@ func TestValidateIsCIDR(t *testing.T) {
 	}
 }
 
+func TestValidateIsIPAddressDiag(t *testing.T) {
+	path := cty.GetAttrPath("address")
+
+	cases := map[string]struct {
+		Value         interface{}
+		ExpectedDiags diag.Diagnostics
+	}{
+		"NotString": {
+			Value: 777,
+			ExpectedDiags: diag.Diagnostics{
+				{Severity: diag.Error, AttributePath: path},
+			},
+		},
+		"CIDR": {
+			Value: "1.1.1.0/20",
+			ExpectedDiags: diag.Diagnostics{
+				{Severity: diag.Error, AttributePath: path},
+			},
+		},
+		"IPv4": {
+			Value: "1.2.3.4",
+		},
+		"IPv6": {
+			Value: "2001:db8::1",
+		},
+	}
+
+	for tn, tc := range cases {
+		t.Run(tn, func(t *testing.T) {
+			checkDiagnostics(t, tn, IsIPAddressDiag(tc.Value, path), tc.ExpectedDiags)
+		})
+	}
+}
+
+func TestValidateIsCIDRDiag(t *testing.T) {
+	path := cty.GetAttrPath("cidr_block")
+
+	cases := map[string]struct {
+		Value         interface{}
+		ExpectedDiags diag.Diagnostics
+	}{
+		"NotString": {
+			Value: 777,
+			ExpectedDiags: diag.Diagnostics{
+				{Severity: diag.Error, AttributePath: path},
+			},
+		},
+		"Zeros": {
+			Value: "0.0.0.0",
+			ExpectedDiags: diag.Diagnostics{
+				{Severity: diag.Error, AttributePath: path},
+			},
+		},
+		"Slash33": {
+			Value: "127.0.0.1/33",
+			ExpectedDiags: diag.Diagnostics{
+				{Severity: diag.Error, AttributePath: path},
+			},
+		},
+		"Slash8": {
+			Value: "127.0.0.1/8",
+		},
+	}
+
+	for tn, tc := range cases {
+		t.Run(tn, func(t *testing.T) {
+			checkDiagnostics(t, tn, IsCIDRDiag(tc.Value, path), tc.ExpectedDiags)
+		})
+	}
+}
+
+func TestValidateIsCIDRNetworkDiag(t *testing.T) {
+	path := cty.GetAttrPath("cidr_block")
+
+	cases := map[string]struct {
+		Value         interface{}
+		ExpectedDiags diag.Diagnostics
+	}{
+		"NotString": {
+			Value: 777,
+			ExpectedDiags: diag.Diagnostics{
+				{Severity: diag.Error, AttributePath: path},
+			},
+		},
+		"NotCIDR": {
+			Value: "10.0.0.0",
+			ExpectedDiags: diag.Diagnostics{
+				{Severity: diag.Error, AttributePath: path},
+			},
+		},
+		"HostBits": {
+			Value: "10.0.0.1/16",
+			ExpectedDiags: diag.Diagnostics{
+				{Severity: diag.Error, AttributePath: path},
+			},
+		},
+		"HostBitsAndTooFewBits": {
+			Value: "10.0.0.1/8",
+			ExpectedDiags: diag.Diagnostics{
+				{Severity: diag.Error, AttributePath: path},
+				{Severity: diag.Error, AttributePath: path},
+			},
+		},
+		"TooManyBits": {
+			Value: "10.0.0.0/28",
+			ExpectedDiags: diag.Diagnostics{
+				{Severity: diag.Error, AttributePath: path},
+			},
+		},
+		"Network": {
+			Value: "10.0.0.0/16",
+		},
+		"LeadingZeroPrefixLength": {
+			Value: "10.0.0.0/016",
+			ExpectedDiags: diag.Diagnostics{
+				{Severity: diag.Error, AttributePath: path},
+			},
+		},
+	}
+
+	for tn, tc := range cases {
+		t.Run(tn, func(t *testing.T) {
+			checkDiagnostics(t, tn, IsCIDRNetworkDiag(16, 24)(tc.Value, path), tc.ExpectedDiags)
+
+			// IsCIDRNetwork must accept the same values
+			if _, errs := IsCIDRNetwork(16, 24)(tc.Value, "cidr_block"); (len(errs) > 0) != (len(tc.ExpectedDiags) > 0) {
+				t.Fatalf("%s: IsCIDRNetwork errors %v do not match IsCIDRNetworkDiag", tn, errs)
+			}
+		})
+	}
+}
+
 func TestValidationIsMACAddress(t *testing.T) {
 	cases := map[string]struct {
 		Value interface{}
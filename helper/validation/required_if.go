@@ -0,0 +1,84 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package validation
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/go-cty/cty/gocty"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// RequiredIfValue is a ValidateRawResourceConfigFunc that returns an error
+// if the top-level attribute otherKey is configured with the given value and
+// the top-level attribute key is not configured. Use it for attributes that
+// are only required depending on the value of another attribute, which
+// RequiredWith cannot express as it only checks the presence of attributes.
+//
+//	ValidateRawResourceConfigFuncs: []schema.ValidateRawResourceConfigFunc{
+//		validation.RequiredIfValue("kms_key_id", "encryption", "kms"),
+//	},
+//
+// The value is converted to the type of otherKey, such as a Go string for a
+// TypeString attribute or int for a TypeInt attribute. As with other
+// ValidateRawResourceConfigFuncs, the check runs when Terraform validates the
+// configuration of a managed resource, before planning, and the error is
+// reported against key. It is skipped while otherKey is unknown, and an
+// unknown value of key counts as configured.
+func RequiredIfValue(key string, otherKey string, value interface{}) schema.ValidateRawResourceConfigFunc {
+	return func(ctx context.Context, req schema.ValidateResourceConfigFuncRequest, resp *schema.ValidateResourceConfigFuncResponse) {
+		cfg := req.RawConfig
+
+		if cfg.IsNull() || !cfg.IsKnown() || !cfg.Type().IsObjectType() {
+			return
+		}
+
+		if !cfg.Type().HasAttribute(key) || !cfg.Type().HasAttribute(otherKey) {
+			resp.Diagnostics = append(resp.Diagnostics, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Invalid RequiredIfValue attribute",
+				Detail: "The Terraform Provider unexpectedly provided an attribute that does not match the current schema. " +
+					"Please report this to the provider developers.\n\n" +
+					fmt.Sprintf("The attributes %q and %q must be top-level attributes of the resource.", key, otherKey),
+			})
+			return
+		}
+
+		other := cfg.GetAttr(otherKey)
+		if !other.IsWhollyKnown() || other.IsNull() {
+			return
+		}
+
+		expected, err := gocty.ToCtyValue(value, other.Type())
+		if err != nil {
+			resp.Diagnostics = append(resp.Diagnostics, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Invalid RequiredIfValue value",
+				Detail: "The Terraform Provider unexpectedly provided a value that does not match the current schema. " +
+					"Please report this to the provider developers.\n\n" +
+					fmt.Sprintf("The value %#v cannot be compared to the attribute %q: %s", value, otherKey, err),
+			})
+			return
+		}
+
+		if !other.Equals(expected).True() {
+			return
+		}
+
+		if !cfg.GetAttr(key).IsNull() {
+			return
+		}
+
+		resp.Diagnostics = append(resp.Diagnostics, diag.Diagnostic{
+			Severity:      diag.Error,
+			Summary:       "Missing required argument",
+			Detail:        fmt.Sprintf("The argument %q is required when %q is set to %#v.", key, otherKey, value),
+			AttributePath: cty.GetAttrPath(key),
+		})
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package validation
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/go-cty/cty"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestRequiredIfValue(t *testing.T) {
+	config := func(encryption, kmsKeyID cty.Value) cty.Value {
+		return cty.ObjectVal(map[string]cty.Value{
+			"encryption": encryption,
+			"kms_key_id": kmsKeyID,
+			"retries":    cty.NumberIntVal(3),
+		})
+	}
+
+	cases := map[string]struct {
+		key           string
+		otherKey      string
+		value         interface{}
+		rawConfig     cty.Value
+		expectedDiags diag.Diagnostics
+	}{
+		"condition met and attribute set returns no diags": {
+			key:       "kms_key_id",
+			otherKey:  "encryption",
+			value:     "kms",
+			rawConfig: config(cty.StringVal("kms"), cty.StringVal("key")),
+		},
+		"condition met and attribute unknown returns no diags": {
+			key:       "kms_key_id",
+			otherKey:  "encryption",
+			value:     "kms",
+			rawConfig: config(cty.StringVal("kms"), cty.UnknownVal(cty.String)),
+		},
+		"condition not met returns no diags": {
+			key:       "kms_key_id",
+			otherKey:  "encryption",
+			value:     "kms",
+			rawConfig: config(cty.StringVal("aes256"), cty.NullVal(cty.String)),
+		},
+		"other attribute null returns no diags": {
+			key:       "kms_key_id",
+			otherKey:  "encryption",
+			value:     "kms",
+			rawConfig: config(cty.NullVal(cty.String), cty.NullVal(cty.String)),
+		},
+		"other attribute unknown returns no diags": {
+			key:       "kms_key_id",
+			otherKey:  "encryption",
+			value:     "kms",
+			rawConfig: config(cty.UnknownVal(cty.String), cty.NullVal(cty.String)),
+		},
+		"condition met and attribute null returns error diag": {
+			key:       "kms_key_id",
+			otherKey:  "encryption",
+			value:     "kms",
+			rawConfig: config(cty.StringVal("kms"), cty.NullVal(cty.String)),
+			expectedDiags: diag.Diagnostics{
+				{
+					Severity:      diag.Error,
+					Summary:       "Missing required argument",
+					Detail:        `The argument "kms_key_id" is required when "encryption" is set to "kms".`,
+					AttributePath: cty.GetAttrPath("kms_key_id"),
+				},
+			},
+		},
+		"number condition met and attribute null returns error diag": {
+			key:       "kms_key_id",
+			otherKey:  "retries",
+			value:     3,
+			rawConfig: config(cty.NullVal(cty.String), cty.NullVal(cty.String)),
+			expectedDiags: diag.Diagnostics{
+				{
+					Severity:      diag.Error,
+					Summary:       "Missing required argument",
+					Detail:        `The argument "kms_key_id" is required when "retries" is set to 3.`,
+					AttributePath: cty.GetAttrPath("kms_key_id"),
+				},
+			},
+		},
+		"unknown attribute returns error diag": {
+			key:       "missing",
+			otherKey:  "encryption",
+			value:     "kms",
+			rawConfig: config(cty.StringVal("kms"), cty.NullVal(cty.String)),
+			expectedDiags: diag.Diagnostics{
+				{
+					Severity: diag.Error,
+					Summary:  "Invalid RequiredIfValue attribute",
+					Detail: "The Terraform Provider unexpectedly provided an attribute that does not match the current schema. " +
+						"Please report this to the provider developers.\n\n" +
+						`The attributes "missing" and "encryption" must be top-level attributes of the resource.`,
+				},
+			},
+		},
+		"value of wrong type returns error diag": {
+			key:       "kms_key_id",
+			otherKey:  "retries",
+			value:     true,
+			rawConfig: config(cty.NullVal(cty.String), cty.NullVal(cty.String)),
+			expectedDiags: diag.Diagnostics{
+				{
+					Severity: diag.Error,
+					Summary:  "Invalid RequiredIfValue value",
+					Detail: "The Terraform Provider unexpectedly provided a value that does not match the current schema. " +
+						"Please report this to the provider developers.\n\n" +
+						`The value true cannot be compared to the attribute "retries": can't convert Go bool to number`,
+				},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			f := RequiredIfValue(tc.key, tc.otherKey, tc.value)
+
+			resp := &schema.ValidateResourceConfigFuncResponse{}
+			f(context.Background(), schema.ValidateResourceConfigFuncRequest{RawConfig: tc.rawConfig}, resp)
+
+			if diff := cmp.Diff(tc.expectedDiags, resp.Diagnostics, cmp.AllowUnexported(cty.GetAttrStep{}, diag.Diagnostic{})); diff != "" {
+				t.Errorf("unexpected difference: %s", diff)
+			}
+		})
+	}
+}
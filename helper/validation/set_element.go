@@ -0,0 +1,103 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package validation
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/go-cty/cty/convert"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// UniqueSetElementKey is a ValidateRawResourceConfigFunc that returns an error
+// if two elements of the top level TypeSet block setKey have the same value
+// for the keyField attribute, such as two elements with the same name. The
+// default set hash would otherwise keep both elements when any other
+// attribute differs, or silently collapse them when all attributes match.
+//
+// Elements with a null or unknown keyField value are ignored.
+func UniqueSetElementKey(setKey, keyField string) schema.ValidateRawResourceConfigFunc {
+	return func(ctx context.Context, req schema.ValidateResourceConfigFuncRequest, resp *schema.ValidateResourceConfigFuncResponse) {
+		cfg := req.RawConfig
+
+		if cfg.IsNull() || !cfg.IsKnown() {
+			return
+		}
+
+		setPath := cty.GetAttrPath(setKey)
+
+		if !cfg.Type().IsObjectType() || !cfg.Type().HasAttribute(setKey) {
+			resp.Diagnostics = append(resp.Diagnostics, invalidSetElementKeyDiagnostic(setPath, fmt.Sprintf("%q is not a top level attribute or block of the resource.", setKey)))
+			return
+		}
+
+		set := cfg.GetAttr(setKey)
+
+		if !set.Type().IsSetType() && !set.Type().IsListType() {
+			resp.Diagnostics = append(resp.Diagnostics, invalidSetElementKeyDiagnostic(setPath, fmt.Sprintf("%q is not a set or list.", setKey)))
+			return
+		}
+
+		elemType := set.Type().ElementType()
+
+		if !elemType.IsObjectType() || !elemType.HasAttribute(keyField) {
+			resp.Diagnostics = append(resp.Diagnostics, invalidSetElementKeyDiagnostic(setPath, fmt.Sprintf("%q is not an attribute of the %q elements.", keyField, setKey)))
+			return
+		}
+
+		if set.IsNull() || !set.IsKnown() {
+			return
+		}
+
+		seen := make(map[string]int)
+
+		for it := set.ElementIterator(); it.Next(); {
+			_, elem := it.Element()
+
+			if elem.IsNull() || !elem.IsKnown() {
+				continue
+			}
+
+			key := elem.GetAttr(keyField)
+
+			if key.IsNull() || !key.IsKnown() {
+				continue
+			}
+
+			keyStr, err := convert.Convert(key, cty.String)
+			if err != nil {
+				resp.Diagnostics = append(resp.Diagnostics, invalidSetElementKeyDiagnostic(setPath, fmt.Sprintf("%q is not a primitive attribute of the %q elements.", keyField, setKey)))
+				return
+			}
+
+			// Report each duplicate key once.
+			seen[keyStr.AsString()]++
+			if seen[keyStr.AsString()] != 2 {
+				continue
+			}
+
+			resp.Diagnostics = append(resp.Diagnostics, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Duplicate set element key",
+				Detail: fmt.Sprintf("Multiple %s elements have the %s value %q. "+
+					"Each %s element must have a unique %s.", setKey, keyField, keyStr.AsString(), setKey, keyField),
+				AttributePath: setPath,
+			})
+		}
+	}
+}
+
+func invalidSetElementKeyDiagnostic(path cty.Path, detail string) diag.Diagnostic {
+	return diag.Diagnostic{
+		Severity: diag.Error,
+		Summary:  "Invalid UniqueSetElementKey configuration",
+		Detail: "The Terraform Provider unexpectedly provided a set key or key field that does not match the current schema. " +
+			"Please report this to the provider developers. \n\n" + detail,
+		AttributePath: path,
+	}
+}
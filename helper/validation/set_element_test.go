@@ -0,0 +1,129 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package validation
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/go-cty/cty"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestUniqueSetElementKey(t *testing.T) {
+	rule := func(name, port cty.Value) cty.Value {
+		return cty.ObjectVal(map[string]cty.Value{
+			"name": name,
+			"port": port,
+		})
+	}
+	ruleType := rule(cty.NullVal(cty.String), cty.NullVal(cty.Number)).Type()
+
+	config := func(rules cty.Value) cty.Value {
+		return cty.ObjectVal(map[string]cty.Value{
+			"rule": rules,
+		})
+	}
+
+	cases := map[string]struct {
+		setKey        string
+		keyField      string
+		rawConfig     cty.Value
+		expectedDiags diag.Diagnostics
+	}{
+		"unique keys returns no diags": {
+			setKey:   "rule",
+			keyField: "name",
+			rawConfig: config(cty.SetVal([]cty.Value{
+				rule(cty.StringVal("http"), cty.NumberIntVal(80)),
+				rule(cty.StringVal("https"), cty.NumberIntVal(443)),
+			})),
+		},
+		"duplicate keys returns error diag": {
+			setKey:   "rule",
+			keyField: "name",
+			rawConfig: config(cty.SetVal([]cty.Value{
+				rule(cty.StringVal("http"), cty.NumberIntVal(80)),
+				rule(cty.StringVal("http"), cty.NumberIntVal(8080)),
+				rule(cty.StringVal("http"), cty.NumberIntVal(8081)),
+				rule(cty.StringVal("https"), cty.NumberIntVal(443)),
+			})),
+			expectedDiags: diag.Diagnostics{
+				{
+					Severity:      diag.Error,
+					Summary:       "Duplicate set element key",
+					Detail:        "Multiple rule elements have the name value \"http\". Each rule element must have a unique name.",
+					AttributePath: cty.GetAttrPath("rule"),
+				},
+			},
+		},
+		"duplicate number keys returns error diag": {
+			setKey:   "rule",
+			keyField: "port",
+			rawConfig: config(cty.SetVal([]cty.Value{
+				rule(cty.StringVal("http"), cty.NumberIntVal(80)),
+				rule(cty.StringVal("web"), cty.NumberIntVal(80)),
+			})),
+			expectedDiags: diag.Diagnostics{
+				{
+					Severity:      diag.Error,
+					Summary:       "Duplicate set element key",
+					Detail:        "Multiple rule elements have the port value \"80\". Each rule element must have a unique port.",
+					AttributePath: cty.GetAttrPath("rule"),
+				},
+			},
+		},
+		"null and unknown keys are ignored": {
+			setKey:   "rule",
+			keyField: "name",
+			rawConfig: config(cty.SetVal([]cty.Value{
+				rule(cty.NullVal(cty.String), cty.NumberIntVal(80)),
+				rule(cty.NullVal(cty.String), cty.NumberIntVal(443)),
+				rule(cty.UnknownVal(cty.String), cty.NumberIntVal(8080)),
+				rule(cty.UnknownVal(cty.String), cty.NumberIntVal(8081)),
+			})),
+		},
+		"unknown set returns no diags": {
+			setKey:    "rule",
+			keyField:  "name",
+			rawConfig: config(cty.UnknownVal(cty.Set(ruleType))),
+		},
+		"null set returns no diags": {
+			setKey:    "rule",
+			keyField:  "name",
+			rawConfig: config(cty.NullVal(cty.Set(ruleType))),
+		},
+		"invalid key field returns error diag": {
+			setKey:    "rule",
+			keyField:  "missing",
+			rawConfig: config(cty.SetValEmpty(ruleType)),
+			expectedDiags: diag.Diagnostics{
+				{
+					Severity: diag.Error,
+					Summary:  "Invalid UniqueSetElementKey configuration",
+					Detail: "The Terraform Provider unexpectedly provided a set key or key field that does not match the current schema. " +
+						"Please report this to the provider developers. \n\n" +
+						"\"missing\" is not an attribute of the \"rule\" elements.",
+					AttributePath: cty.GetAttrPath("rule"),
+				},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			f := UniqueSetElementKey(tc.setKey, tc.keyField)
+
+			actual := &schema.ValidateResourceConfigFuncResponse{}
+			f(context.Background(), schema.ValidateResourceConfigFuncRequest{RawConfig: tc.rawConfig}, actual)
+
+			if diff := cmp.Diff(tc.expectedDiags, actual.Diagnostics, cmp.AllowUnexported(cty.GetAttrStep{}, diag.Diagnostic{})); diff != "" {
+				t.Errorf("unexpected difference: %s", diff)
+			}
+		})
+	}
+}
@@ -7,8 +7,12 @@ import (
 	"encoding/base64"
 	"fmt"
 	"regexp"
+	"sort"
 	"strings"
 
+	"github.com/hashicorp/go-cty/cty"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/structure"
 )
@@ -173,6 +177,33 @@ func StringNotInSlice(invalid []string, ignoreCase bool) schema.SchemaValidateFu
 	}
 }
 
+// StringIsOneOfNormalized returns a SchemaValidateFunc which tests if the
+// provided value is of type string and is one of the keys of the mapping. It
+// is intended for attributes whose configured values are normalized with the
+// ResourceData type GetNormalized method using the same mapping, such as
+// "enabled" and "disabled" mapped to "true" and "false".
+func StringIsOneOfNormalized(mapping map[string]string) schema.SchemaValidateFunc {
+	valid := make([]string, 0, len(mapping))
+	for k := range mapping {
+		valid = append(valid, k)
+	}
+	sort.Strings(valid)
+
+	return func(i interface{}, k string) (warnings []string, errors []error) {
+		v, ok := i.(string)
+		if !ok {
+			errors = append(errors, fmt.Errorf("expected type of %s to be string", k))
+			return warnings, errors
+		}
+
+		if _, ok := mapping[v]; !ok {
+			errors = append(errors, fmt.Errorf("expected %s to be one of %q, got %s", k, valid, v))
+		}
+
+		return warnings, errors
+	}
+}
+
 // StringDoesNotContainAny returns a SchemaValidateFunc which validates that the
 // provided value does not contain any of the specified Unicode code points in chars.
 func StringDoesNotContainAny(chars string) schema.SchemaValidateFunc {
@@ -224,6 +255,77 @@ func StringIsJSON(i interface{}, k string) (warnings []string, errors []error) {
 	return warnings, errors
 }
 
+// StringIsBase64Diag is a SchemaValidateDiagFunc which ensures a string is
+// not empty and can be decoded as standard Base64. It is the
+// SchemaValidateDiagFunc variant of StringIsBase64, with the decoding error
+// in the diagnostic detail.
+func StringIsBase64Diag(i interface{}, path cty.Path) diag.Diagnostics {
+	v, ok := i.(string)
+	if !ok {
+		return stringTypeDiagnostics(i, path)
+	}
+
+	if v == "" {
+		return diag.Diagnostics{
+			{
+				Severity:      diag.Error,
+				Summary:       "Invalid Base64 value",
+				Detail:        "Expected a non-empty base64 string.",
+				AttributePath: path,
+			},
+		}
+	}
+
+	if _, err := base64.StdEncoding.DecodeString(v); err != nil {
+		return diag.Diagnostics{
+			{
+				Severity:      diag.Error,
+				Summary:       "Invalid Base64 value",
+				Detail:        fmt.Sprintf("Expected a base64 string: %s.", err),
+				AttributePath: path,
+			},
+		}
+	}
+
+	return nil
+}
+
+// StringIsJSONDiag is a SchemaValidateDiagFunc which ensures a string is
+// empty or valid JSON. It is the SchemaValidateDiagFunc variant of
+// StringIsJSON, with the parsing error in the diagnostic detail.
+func StringIsJSONDiag(i interface{}, path cty.Path) diag.Diagnostics {
+	v, ok := i.(string)
+	if !ok {
+		return stringTypeDiagnostics(i, path)
+	}
+
+	if _, err := structure.NormalizeJsonString(v); err != nil {
+		return diag.Diagnostics{
+			{
+				Severity:      diag.Error,
+				Summary:       "Invalid JSON value",
+				Detail:        fmt.Sprintf("Expected a JSON string: %s.", err),
+				AttributePath: path,
+			},
+		}
+	}
+
+	return nil
+}
+
+// stringTypeDiagnostics returns the error diagnostic of a
+// SchemaValidateDiagFunc for strings called with a value of another type.
+func stringTypeDiagnostics(i interface{}, path cty.Path) diag.Diagnostics {
+	return diag.Diagnostics{
+		{
+			Severity:      diag.Error,
+			Summary:       "Invalid value type",
+			Detail:        fmt.Sprintf("Expected a string, got %T.", i),
+			AttributePath: path,
+		},
+	}
+}
+
 // StringIsValidRegExp returns a SchemaValidateFunc which tests to make sure the supplied string is a valid regular expression.
 func StringIsValidRegExp(i interface{}, k string) (warnings []string, errors []error) {
 	v, ok := i.(string)
@@ -6,6 +6,9 @@ package validation
 import (
 	"regexp"
 	"testing"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 )
 
 func TestValidationStringIsNotEmpty(t *testing.T) {
@@ -298,6 +301,53 @@ func TestValidationStringIsBase64(t *testing.T) {
 	}
 }
 
+func TestValidationStringIsBase64Diag(t *testing.T) {
+	path := cty.GetAttrPath("content")
+
+	cases := map[string]struct {
+		Value          interface{}
+		ExpectedDiags  diag.Diagnostics
+		ExpectedDetail string
+	}{
+		"NotString": {
+			Value: 7,
+			ExpectedDiags: diag.Diagnostics{
+				{Severity: diag.Error, AttributePath: path},
+			},
+			ExpectedDetail: "Expected a string, got int.",
+		},
+		"Empty": {
+			Value: "",
+			ExpectedDiags: diag.Diagnostics{
+				{Severity: diag.Error, AttributePath: path},
+			},
+			ExpectedDetail: "Expected a non-empty base64 string.",
+		},
+		"NotBase64": {
+			Value: "Do'h!",
+			ExpectedDiags: diag.Diagnostics{
+				{Severity: diag.Error, AttributePath: path},
+			},
+			ExpectedDetail: "Expected a base64 string: illegal base64 data at input byte 2.",
+		},
+		"Base64": {
+			Value: "RG8naCE=",
+		},
+	}
+
+	for tn, tc := range cases {
+		t.Run(tn, func(t *testing.T) {
+			diags := StringIsBase64Diag(tc.Value, path)
+
+			checkDiagnostics(t, tn, diags, tc.ExpectedDiags)
+
+			if len(diags) > 0 && diags[0].Detail != tc.ExpectedDetail {
+				t.Fatalf("expected detail %q, got %q", tc.ExpectedDetail, diags[0].Detail)
+			}
+		})
+	}
+}
+
 func TestValidationStringInSlice(t *testing.T) {
 	runTestCases(t, []testCase{
 		{
@@ -327,6 +377,39 @@ func TestValidationStringInSlice(t *testing.T) {
 	})
 }
 
+func TestValidationStringIsOneOfNormalized(t *testing.T) {
+	mapping := map[string]string{
+		"enabled":  "true",
+		"disabled": "false",
+	}
+
+	runTestCases(t, []testCase{
+		{
+			val: "enabled",
+			f:   StringIsOneOfNormalized(mapping),
+		},
+		{
+			val: "disabled",
+			f:   StringIsOneOfNormalized(mapping),
+		},
+		{
+			val:         "true",
+			f:           StringIsOneOfNormalized(mapping),
+			expectedErr: regexp.MustCompile(`expected [\w]+ to be one of \["disabled" "enabled"\], got true`),
+		},
+		{
+			val:         "Enabled",
+			f:           StringIsOneOfNormalized(mapping),
+			expectedErr: regexp.MustCompile(`expected [\w]+ to be one of \["disabled" "enabled"\], got Enabled`),
+		},
+		{
+			val:         1,
+			f:           StringIsOneOfNormalized(mapping),
+			expectedErr: regexp.MustCompile(`expected type of [\w]+ to be string`),
+		},
+	})
+}
+
 func TestValidationStringNotInSlice(t *testing.T) {
 	runTestCases(t, []testCase{
 		{
@@ -491,3 +574,46 @@ func TestStringIsValidRegExp(t *testing.T) {
 		},
 	})
 }
+
+func TestStringIsJSONDiag(t *testing.T) {
+	path := cty.GetAttrPath("policy").IndexInt(0)
+
+	cases := map[string]struct {
+		Value          interface{}
+		ExpectedDiags  diag.Diagnostics
+		ExpectedDetail string
+	}{
+		"NotString": {
+			Value: true,
+			ExpectedDiags: diag.Diagnostics{
+				{Severity: diag.Error, AttributePath: path},
+			},
+			ExpectedDetail: "Expected a string, got bool.",
+		},
+		"InvalidJSON": {
+			Value: `{"def":}`,
+			ExpectedDiags: diag.Diagnostics{
+				{Severity: diag.Error, AttributePath: path},
+			},
+			ExpectedDetail: "Expected a JSON string: invalid character '}' looking for beginning of value.",
+		},
+		"Empty": {
+			Value: ``,
+		},
+		"ValidJSON": {
+			Value: `{"abc":["1","2"]}`,
+		},
+	}
+
+	for tn, tc := range cases {
+		t.Run(tn, func(t *testing.T) {
+			diags := StringIsJSONDiag(tc.Value, path)
+
+			checkDiagnostics(t, tn, diags, tc.ExpectedDiags)
+
+			if len(diags) > 0 && diags[0].Detail != tc.ExpectedDetail {
+				t.Fatalf("expected detail %q, got %q", tc.ExpectedDetail, diags[0].Detail)
+			}
+		})
+	}
+}
@@ -769,7 +769,7 @@ func TestPreferWriteOnlyAttribute(t *testing.T) {
 			}
 
 			if diff := cmp.Diff(tc.expectedDiags, actual.Diagnostics,
-				cmp.AllowUnexported(cty.GetAttrStep{}, cty.IndexStep{}),
+				cmp.AllowUnexported(cty.GetAttrStep{}, cty.IndexStep{}, diag.Diagnostic{}),
 				cmp.Comparer(indexStepComparer),
 			); diff != "" {
 				t.Errorf("Unexpected diagnostics (-wanted +got): %s", diff)
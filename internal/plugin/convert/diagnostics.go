@@ -5,6 +5,7 @@ package convert
 
 import (
 	"context"
+	"sync"
 
 	"github.com/hashicorp/go-cty/cty"
 
@@ -15,6 +16,55 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/internal/logging"
 )
 
+type diagnosticSourcesKey struct{}
+
+// diagnosticSources are the diag.Diagnostic values which protocol
+// diagnostics were converted from.
+type diagnosticSources struct {
+	mu      sync.Mutex
+	sources map[*tfprotov5.Diagnostic]diag.Diagnostic
+}
+
+// WithDiagnosticSources returns a context in which AppendProtoDiag records
+// the diag.Diagnostic each protocol diagnostic is converted from, so it can
+// later be retrieved with DiagnosticSource without converting the protocol
+// diagnostic back, which would lose fields such as DocURL.
+func WithDiagnosticSources(ctx context.Context) context.Context {
+	return context.WithValue(ctx, diagnosticSourcesKey{}, &diagnosticSources{
+		sources: make(map[*tfprotov5.Diagnostic]diag.Diagnostic),
+	})
+}
+
+// DiagnosticSource returns the diag.Diagnostic the given protocol diagnostic
+// was converted from by AppendProtoDiag, if recorded in the context.
+func DiagnosticSource(ctx context.Context, d *tfprotov5.Diagnostic) (diag.Diagnostic, bool) {
+	s, ok := ctx.Value(diagnosticSourcesKey{}).(*diagnosticSources)
+	if !ok {
+		return diag.Diagnostic{}, false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	source, ok := s.sources[d]
+
+	return source, ok
+}
+
+func recordDiagnosticSources(ctx context.Context, diags diag.Diagnostics, protoDiags []*tfprotov5.Diagnostic) {
+	s, ok := ctx.Value(diagnosticSourcesKey{}).(*diagnosticSources)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, d := range protoDiags {
+		s.sources[d] = diags[i]
+	}
+}
+
 // AppendProtoDiag appends a new diagnostic from a warning string or an error.
 // This panics if d is not a string or error.
 func AppendProtoDiag(ctx context.Context, diags []*tfprotov5.Diagnostic, d interface{}) []*tfprotov5.Diagnostic {
@@ -35,7 +85,9 @@ func AppendProtoDiag(ctx context.Context, diags []*tfprotov5.Diagnostic, d inter
 
 		diags = append(diags, diagnostic)
 	case diag.Diagnostics:
-		diags = append(diags, DiagsToProto(d)...)
+		protoDiags := DiagsToProto(d)
+		recordDiagnosticSources(ctx, d, protoDiags)
+		diags = append(diags, protoDiags...)
 	case error:
 		if d == nil {
 			logging.HelperSchemaDebug(ctx, "skipping diagnostic for nil error in AppendProtoDiag")
@@ -111,11 +163,25 @@ func DiagsToProto(diags diag.Diagnostics) []*tfprotov5.Diagnostic {
 		if d.Summary == "" {
 			protoDiag.Summary = "Empty Summary: This is always a bug in the provider and should be reported to the provider developers."
 		}
+		if d.DocURL != "" {
+			protoDiag.Detail = appendDocURL(protoDiag.Detail, d.DocURL)
+		}
 		ds = append(ds, protoDiag)
 	}
 	return ds
 }
 
+// appendDocURL appends a link to documentation to the detail of a
+// diagnostic, as the protocol has no dedicated field for it.
+func appendDocURL(detail string, url string) string {
+	link := "For more information, see: " + url
+	if detail == "" {
+		return link
+	}
+
+	return detail + "\n\n" + link
+}
+
 // AttributePathToPath takes the proto encoded path and converts it to a cty.Path
 func AttributePathToPath(ap *tftypes.AttributePath) cty.Path {
 	var p cty.Path
@@ -4,6 +4,7 @@
 package convert
 
 import (
+	"context"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -274,6 +275,70 @@ func TestDiagnostics(t *testing.T) {
 	}
 }
 
+func TestDiagsToProto_docURL(t *testing.T) {
+	diags := diag.Diagnostics{
+		diag.WithDocURL(diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "quota exceeded",
+			Detail:   "The instance quota of the project is exceeded.",
+		}, "https://example.com/docs/quota"),
+		diag.WithDocURL(diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  "deprecated region",
+		}, "https://example.com/docs/regions"),
+		{
+			Severity: diag.Error,
+			Summary:  "no link",
+			Detail:   "detail",
+		},
+	}
+
+	expected := []*tfprotov5.Diagnostic{
+		{
+			Severity: tfprotov5.DiagnosticSeverityError,
+			Summary:  "quota exceeded",
+			Detail:   "The instance quota of the project is exceeded.\n\nFor more information, see: https://example.com/docs/quota",
+		},
+		{
+			Severity: tfprotov5.DiagnosticSeverityWarning,
+			Summary:  "deprecated region",
+			Detail:   "For more information, see: https://example.com/docs/regions",
+		},
+		{
+			Severity: tfprotov5.DiagnosticSeverityError,
+			Summary:  "no link",
+			Detail:   "detail",
+		},
+	}
+
+	if diff := cmp.Diff(expected, DiagsToProto(diags)); diff != "" {
+		t.Fatalf("unexpected difference: %s", diff)
+	}
+}
+
+func TestDiagnosticSource(t *testing.T) {
+	source := diag.WithDocURL(diag.Diagnostic{
+		Severity: diag.Warning,
+		Summary:  "summary",
+	}, "https://example.com")
+
+	ctx := WithDiagnosticSources(context.Background())
+	protoDiags := AppendProtoDiag(ctx, nil, diag.Diagnostics{source})
+
+	actual, ok := DiagnosticSource(ctx, protoDiags[0])
+	if !ok {
+		t.Fatal("expected recorded diagnostic source")
+	}
+
+	if diff := cmp.Diff(source, actual, cmp.AllowUnexported(diag.Diagnostic{})); diff != "" {
+		t.Fatalf("unexpected difference: %s", diff)
+	}
+
+	if _, ok := DiagnosticSource(context.Background(), protoDiags[0]); ok {
+		t.Fatal("expected no diagnostic source without WithDiagnosticSources")
+	}
+}
+
 func TestPathToAttributePath(t *testing.T) {
 	tests := map[string]struct {
 		path cty.Path
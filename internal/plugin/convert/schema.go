@@ -131,6 +131,18 @@ func ctyTypeFromTFType(in tftypes.Type) (cty.Type, error) {
 	return cty.Type{}, fmt.Errorf("unknown tftypes.Type %s", in)
 }
 
+// CtyTypeToProto converts a cty.Type, such as a provider function parameter
+// type, to a tftypes.Type for a grpc response.
+func CtyTypeToProto(in cty.Type) (tftypes.Type, error) {
+	return tftypeFromCtyType(in)
+}
+
+// StringKindToProto converts a configschema.StringKind, such as a provider
+// function description kind, to a tfprotov5.StringKind for a grpc response.
+func StringKindToProto(ctx context.Context, k configschema.StringKind) tfprotov5.StringKind {
+	return protoStringKind(ctx, k)
+}
+
 // ConfigSchemaToProto takes a *configschema.Block and converts it to a
 // tfprotov5.SchemaBlock for a grpc response.
 func ConfigSchemaToProto(ctx context.Context, b *configschema.Block) *tfprotov5.SchemaBlock {
@@ -199,6 +199,38 @@ func (wd *WorkingDir) CreatePlan(ctx context.Context) error {
 	return nil
 }
 
+// CreateTargetPlan runs "terraform plan" targeting the given resource
+// address to create a saved plan file, which if successful will then be used
+// for the next call to Apply. This is used to import a resource with an
+// import block of the configuration, without planning the other resources.
+func (wd *WorkingDir) CreateTargetPlan(ctx context.Context, address string) error {
+	logging.HelperResourceTrace(ctx, "Calling Terraform CLI plan command")
+
+	hasChanges, err := wd.tf.Plan(context.Background(), tfexec.Reattach(wd.reattachInfo), tfexec.Refresh(false), tfexec.Out(PlanFileName), tfexec.Target(address))
+
+	logging.HelperResourceTrace(ctx, "Called Terraform CLI plan command")
+
+	if err != nil {
+		return err
+	}
+
+	if !hasChanges {
+		logging.HelperResourceTrace(ctx, "Created plan with no changes")
+
+		return nil
+	}
+
+	stdout, err := wd.SavedPlanRawStdout(ctx)
+
+	if err != nil {
+		return fmt.Errorf("error retrieving formatted plan output: %w", err)
+	}
+
+	logging.HelperResourceTrace(ctx, "Created plan with changes", map[string]any{logging.KeyTestTerraformPlan: stdout})
+
+	return nil
+}
+
 // CreateDestroyPlan runs "terraform plan -destroy" to create a saved plan
 // file, which if successful will then be used for the next call to Apply.
 func (wd *WorkingDir) CreateDestroyPlan(ctx context.Context) error {
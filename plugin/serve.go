@@ -10,6 +10,7 @@ import (
 	hclog "github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/go-plugin"
 	testing "github.com/mitchellh/go-testing-interface"
+	"google.golang.org/grpc"
 
 	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
 	"github.com/hashicorp/terraform-plugin-go/tfprotov5/tf5server"
@@ -29,8 +30,9 @@ const (
 
 // Handshake is the HandshakeConfig used to configure clients and servers.
 //
-// Deprecated: This is no longer used, but left for backwards compatibility
-// since it is exported. It will be removed in the next major version.
+// Deprecated: This is only used internally when ServeOpts.MaxMsgSize is set,
+// but left for backwards compatibility since it is exported. It will be
+// removed in the next major version.
 var Handshake = plugin.HandshakeConfig{
 	// The magic cookie values should NEVER be changed.
 	MagicCookieKey:   "TF_PLUGIN_MAGIC_COOKIE",
@@ -89,6 +91,20 @@ type ServeOpts struct {
 	// Terraform can correctly match the provider address in the Terraform
 	// configuration to the running provider binary.
 	ProviderAddr string
+
+	// MaxMsgSize overrides the maximum size in bytes of gRPC messages sent
+	// and received by the provider server. When zero, the terraform-plugin-go
+	// default of 256MB is used, which is sufficient for nearly all
+	// providers.
+	//
+	// Raising the limit allows larger configuration and state data, such as
+	// big JSON blobs or many set elements, at the cost of higher memory usage
+	// of the provider, as whole messages are held in memory. Terraform
+	// enforces its own limits on the messages it receives, so raising this
+	// value does not guarantee larger responses are accepted.
+	//
+	// This option cannot be combined with Debug.
+	MaxMsgSize int
 }
 
 // Serve serves a plugin. This function never returns and should be the final
@@ -99,6 +115,11 @@ func Serve(opts *ServeOpts) {
 		return
 	}
 
+	if opts.Debug && opts.MaxMsgSize > 0 {
+		log.Printf("[ERROR] Error starting provider: cannot set both Debug and MaxMsgSize")
+		return
+	}
+
 	if !opts.NoLogOutputOverride {
 		// In order to allow go-plugin to correctly pass log-levels through to
 		// terraform, we need to use an hclog.Logger with JSON output. We can
@@ -150,6 +171,20 @@ func tf5serverServe(opts *ServeOpts) error {
 		tf5serveOpts = append(tf5serveOpts, tf5server.WithGoPluginLogger(opts.Logger))
 	}
 
+	if opts.UseTFLogSink != nil {
+		tf5serveOpts = append(tf5serveOpts, tf5server.WithLoggingSink(opts.UseTFLogSink))
+	}
+
+	// servePlugin passes opts.TestConfig to go-plugin directly, so the
+	// channels are only forwarded for the terraform-plugin-go server.
+	if opts.MaxMsgSize > 0 {
+		return servePlugin(opts, 5, &tf5server.GRPCProviderPlugin{
+			Name:         opts.ProviderAddr,
+			Opts:         tf5serveOpts,
+			GRPCProvider: opts.GRPCProviderFunc,
+		})
+	}
+
 	if opts.TestConfig != nil {
 		// Convert send-only channels to bi-directional channels to appease
 		// the compiler. WithDebug is errantly defined to require
@@ -181,10 +216,6 @@ func tf5serverServe(opts *ServeOpts) error {
 		)
 	}
 
-	if opts.UseTFLogSink != nil {
-		tf5serveOpts = append(tf5serveOpts, tf5server.WithLoggingSink(opts.UseTFLogSink))
-	}
-
 	return tf5server.Serve(opts.ProviderAddr, opts.GRPCProviderFunc, tf5serveOpts...)
 }
 
@@ -199,6 +230,20 @@ func tf6serverServe(opts *ServeOpts) error {
 		tf6serveOpts = append(tf6serveOpts, tf6server.WithGoPluginLogger(opts.Logger))
 	}
 
+	if opts.UseTFLogSink != nil {
+		tf6serveOpts = append(tf6serveOpts, tf6server.WithLoggingSink(opts.UseTFLogSink))
+	}
+
+	// servePlugin passes opts.TestConfig to go-plugin directly, so the
+	// channels are only forwarded for the terraform-plugin-go server.
+	if opts.MaxMsgSize > 0 {
+		return servePlugin(opts, 6, &tf6server.GRPCProviderPlugin{
+			Name:         opts.ProviderAddr,
+			Opts:         tf6serveOpts,
+			GRPCProvider: opts.GRPCProviderV6Func,
+		})
+	}
+
 	if opts.TestConfig != nil {
 		// Convert send-only channels to bi-directional channels to appease
 		// the compiler. WithDebug is errantly defined to require
@@ -230,9 +275,30 @@ func tf6serverServe(opts *ServeOpts) error {
 		)
 	}
 
-	if opts.UseTFLogSink != nil {
-		tf6serveOpts = append(tf6serveOpts, tf6server.WithLoggingSink(opts.UseTFLogSink))
-	}
-
 	return tf6server.Serve(opts.ProviderAddr, opts.GRPCProviderV6Func, tf6serveOpts...)
 }
+
+// servePlugin serves the provider plugin for the given protocol version with
+// go-plugin directly, mirroring the terraform-plugin-go server, which does
+// not support customizing the gRPC server options.
+func servePlugin(opts *ServeOpts, protocolVersion uint, p plugin.Plugin) error {
+	handshake := Handshake
+	handshake.ProtocolVersion = protocolVersion
+
+	plugin.Serve(&plugin.ServeConfig{
+		HandshakeConfig: handshake,
+		Plugins: plugin.PluginSet{
+			"provider": p,
+		},
+		GRPCServer: func(serverOpts []grpc.ServerOption) *grpc.Server {
+			serverOpts = append(serverOpts, grpc.MaxRecvMsgSize(opts.MaxMsgSize))
+			serverOpts = append(serverOpts, grpc.MaxSendMsgSize(opts.MaxMsgSize))
+
+			return grpc.NewServer(serverOpts...)
+		},
+		Logger: opts.Logger,
+		Test:   opts.TestConfig,
+	})
+
+	return nil
+}
@@ -46,6 +46,10 @@ type InstanceDiff struct {
 	RawState  cty.Value
 	RawPlan   cty.Value
 
+	// RawConfigMsgPack is the MsgPack encoded config as received from
+	// Terraform, which RawConfig was decoded from.
+	RawConfigMsgPack []byte
+
 	// Meta is a simple K/V map that is stored in a diff and persisted to
 	// plans but otherwise is completely ignored by Terraform core. It is
 	// meant to be used for additional data a resource may want to pass through.
@@ -332,8 +336,9 @@ func (d *InstanceDiff) applySingleAttrDiff(path []string, attrs map[string]strin
 	}
 
 	// "id" must exist and not be an empty string, or it must be unknown.
-	// This only applied to top-level "id" fields.
-	if attr == "id" && len(path) == 1 {
+	// This only applied to top-level computed "id" fields, a resource may
+	// declare its own configurable "id" when the implicit one is disabled.
+	if attr == "id" && len(path) == 1 && attrSchema.Computed {
 		if old == "" {
 			result[attr] = hcl2shim.UnknownVariableValue
 		} else {
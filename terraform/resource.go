@@ -45,9 +45,11 @@ type ResourceConfig struct {
 	//
 	// This field was only added as a targeted fix for passing raw protocol data
 	// through the existing (helper/schema.Provider).Configure() exported method
-	// and is only populated in that situation. The data could theoretically be
-	// set in the NewResourceConfigShimmed() function, however the consequences
-	// of doing this were not investigated at the time the fix was introduced.
+	// and is only populated in that situation and during managed resource
+	// validation, where it is used to count list and set items. The data
+	// could theoretically be set in the NewResourceConfigShimmed() function,
+	// however the consequences of doing this were not investigated at the time
+	// the fix was introduced.
 	//
 	// This field is ignored in the Equal() method to prevent a breaking
 	// behavior change since the entirety of the terraform package and this type
@@ -1322,6 +1322,10 @@ type InstanceState struct {
 	RawState  cty.Value
 	RawPlan   cty.Value
 
+	// RawConfigMsgPack is the MsgPack encoded config as received from
+	// Terraform, which RawConfig was decoded from.
+	RawConfigMsgPack []byte
+
 	// Tainted is used to mark a resource for recreation.
 	Tainted bool `json:"tainted"`
 